@@ -25,6 +25,41 @@ var messageList = map[string]string{
 	"ERROR_GET_EMAIL":          "MSG_S0005",  // ERROR GET EMAIL
 	"LOGOUT_SUCCESS":              "MSG_S0006",  // LOGOUT SUCCESS
 	"SIGN_UP_SUCCESS":             "MSG_S0007",  // SIGN UP SUCCESS
+	"PERMISSION_DENIED":           "MSG_S0008",  // User is not allowed to access the resource
+	"LINK_EXPIRED":                "MSG_S0009",  // Signed link expired or invalid
+	"UPLOAD_FAIL":                 "MSG_S0010",  // Failed to store uploaded file
+	"FILE_TOO_LARGE":              "MSG_V0006",  // Uploaded file exceeds the size limit
+	"FILE_TYPE_NOT_ALLOWED":       "MSG_V0007",  // Uploaded file type is not allowed
+	"FORMAT_DATETIME":             "MSG_V0008",  // param is not a recognised date/time. Ex: 2024-01-10 17:00 or RFC3339
+	"INVALID_TIME_RANGE":          "MSG_V0009",  // start must be before end and within the thesis period
+	"DUPLICATE_OVERRIDE_REQUIRED": "MSG_V1001",  // Thesis has near-duplicate topics and needs an explicit override
+	"INVALID_STATUS_TRANSITION":   "MSG_V1002",  // Task status change is not an allowed transition
+	"TASK_DEPENDENCY_CYCLE":       "MSG_V1003",  // Task dependency or parent would create a cycle
+	"TASK_BLOCKED":                "MSG_V1004",  // Task has unfinished dependencies or subtasks
+	"SLOT_OVERLAP":                "MSG_V1005",  // Availability slot overlaps another slot of the advisor
+	"BOOKING_CONFLICT":            "MSG_V1006",  // Advisor or student already has a meeting at that time
+	"SLOT_NOT_AVAILABLE":          "MSG_V1007",  // Requested time is not an open meeting in the slot
+	"PROGRAM_INACTIVE":            "MSG_V1008",  // Study program is deactivated and cannot be chosen
+	"PROGRAM_IN_USE":              "MSG_V1009",  // Study program is used by theses or students and can only be deactivated
+	"CHANGE_DEADLINE_PASSED":      "MSG_V1010",  // Change request deadline of the semester has passed
+	"CHANGE_REQUEST_PENDING":      "MSG_V1011",  // Student already has a change request waiting for approval
+	"ADVISOR_ASSIGNED":            "MSG_V1012",  // Advisor already supervises another thesis
+	"UNIT_IN_USE":                 "MSG_V1013",  // Organization unit still has child units or attached records
+	"COMMITTEE_INCOMPLETE":        "MSG_V1014",  // Defense committee does not meet the minimum composition
+	"CONFLICT_OF_INTEREST":        "MSG_V1015",  // Advisor of a thesis cannot sit on the committee of that thesis
+	"SCHEDULE_CONFLICT":           "MSG_V1016",  // Room, committee member, advisor or student is already booked at that time
+	"ROOM_IN_USE":                 "MSG_V1017",  // Room has defense sessions and can only be deactivated
+	"RUBRIC_IN_USE":               "MSG_V1018",  // Rubric already has score sheets and cannot be changed
+	"GRADING_INCOMPLETE":          "MSG_V1019",  // Required score sheets have not been submitted yet
+	"SHEET_SUBMITTED":             "MSG_V1020",  // Score sheet was submitted and is read-only
+	"REVIEW_PENDING":              "MSG_V1021",  // Defense cannot be scheduled before the reviewer's report is submitted
+	"REVIEW_DEADLINE_PASSED":      "MSG_V1022",  // Review report due date has passed
+	"DOCUMENT_FONT_MISSING":       "MSG_V1023",  // PDF fonts set through PDF_FONT_DIR are missing
+	"GRADE_LOCKED":                "MSG_V1024",  // Grades were published and can only change through an appeal
+	"APPEAL_DEADLINE_PASSED":      "MSG_V1025",  // Appeal period of the published grade has ended
+	"APPEAL_EXISTS":               "MSG_V1026",  // An appeal was already filed for this grade
+	"THESIS_NOT_OPEN":             "MSG_V1027",  // Thesis is not approved or already has students
+	"PROGRAM_NOT_ELIGIBLE":        "MSG_V1028",  // Thesis is limited to study programs the student does not belong to
 	//"RESTORE_SUCCESS":
 	//"CONFLICT_EMAIL"
 	//"ERROR_END_TIME_ADMIN"
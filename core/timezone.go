@@ -0,0 +1,99 @@
+package core
+
+import (
+	"app/config"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Múi giờ mặc định của trường khi APP_TIME_ZONE không được cấu hình
+const defaultTimeZone = "Asia/Ho_Chi_Minh"
+
+var (
+	campusOnce     sync.Once
+	campusLocation *time.Location
+)
+
+var ErrTimeFormat = errors.New("unrecognised date/time format")
+
+// Các định dạng có múi giờ được giữ nguyên, còn lại được hiểu theo giờ của trường
+var zonedLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05Z07:00",
+}
+
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02-01-2006 15:04",
+}
+
+// Chỉ có ngày thì hạn chót là cuối ngày đó
+var dateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"02-01-2006",
+	"2006/01/02",
+}
+
+// CampusLocation trả về múi giờ của trường (APP_TIME_ZONE), dùng khi tải múi giờ thất bại thì lấy UTC+7
+func CampusLocation() *time.Location {
+	campusOnce.Do(func() {
+		name := strings.TrimSpace(config.Config("APP_TIME_ZONE"))
+		if name == "" {
+			name = defaultTimeZone
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			loc = time.FixedZone("ICT", 7*60*60)
+		}
+		campusLocation = loc
+	})
+	return campusLocation
+}
+
+// Now trả về thời điểm hiện tại theo giờ của trường
+func Now() time.Time {
+	return time.Now().In(CampusLocation())
+}
+
+// ParseCampusTime đọc một mốc thời gian do người dùng nhập. Chuỗi không có múi giờ được hiểu theo
+// giờ của trường; chuỗi chỉ có ngày được hiểu là 23:59:59 của ngày đó.
+func ParseCampusTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, ErrTimeFormat
+	}
+	loc := CampusLocation()
+
+	for _, layout := range zonedLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return EndOfDay(t), nil
+		}
+	}
+	return time.Time{}, ErrTimeFormat
+}
+
+// EndOfDay trả về 23:59:59 của ngày chứa t theo giờ của trường
+func EndOfDay(t time.Time) time.Time {
+	t = t.In(CampusLocation())
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// config.Config đọc .env ở thư mục hiện tại; test chạy trong thư mục tạm có .env rỗng để dùng múi giờ mặc định
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".env"), nil, 0644); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	os.Unsetenv("APP_TIME_ZONE")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+var ict = time.FixedZone("ICT", 7*60*60)
+
+func TestCampusLocation(t *testing.T) {
+	loc := CampusLocation()
+	if name := loc.String(); name != defaultTimeZone && name != "ICT" {
+		t.Errorf("CampusLocation() = %s, want %s", name, defaultTimeZone)
+	}
+	for _, instant := range []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if _, offset := instant.In(loc).Zone(); offset != 7*60*60 {
+			t.Errorf("offset at %v = %d, want %d", instant, offset, 7*60*60)
+		}
+	}
+	if CampusLocation() != loc {
+		t.Errorf("CampusLocation() is not shared between calls")
+	}
+}
+
+func TestParseCampusTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"RFC3339 UTC", "2026-03-01T10:00:00Z", time.Date(2026, 3, 1, 17, 0, 0, 0, ict), false},
+		{"RFC3339 other zone", "2026-03-01T10:00:00+09:00", time.Date(2026, 3, 1, 8, 0, 0, 0, ict), false},
+		{"RFC3339 nano", "2026-03-01T10:00:00.5Z", time.Date(2026, 3, 1, 17, 0, 0, 500000000, ict), false},
+		{"compact offset", "2026-03-01T10:00:00+0000", time.Date(2026, 3, 1, 17, 0, 0, 0, ict), false},
+		{"space and offset", "2026-03-01 10:00:00+07:00", time.Date(2026, 3, 1, 10, 0, 0, 0, ict), false},
+		{"local ISO", "2026-03-01T10:00:00", time.Date(2026, 3, 1, 10, 0, 0, 0, ict), false},
+		{"local with space", "2026-03-01 10:00:30", time.Date(2026, 3, 1, 10, 0, 30, 0, ict), false},
+		{"local minutes", "2026-03-01 10:00", time.Date(2026, 3, 1, 10, 0, 0, 0, ict), false},
+		{"local day first", "01/03/2026 10:00:05", time.Date(2026, 3, 1, 10, 0, 5, 0, ict), false},
+		{"local day first dashes", "01-03-2026 10:00", time.Date(2026, 3, 1, 10, 0, 0, 0, ict), false},
+		{"surrounding spaces", "  2026-03-01 10:00  ", time.Date(2026, 3, 1, 10, 0, 0, 0, ict), false},
+		{"date only is end of day", "2026-03-01", time.Date(2026, 3, 1, 23, 59, 59, 0, ict), false},
+		{"date day first", "01/03/2026", time.Date(2026, 3, 1, 23, 59, 59, 0, ict), false},
+		{"date day first dashes", "01-03-2026", time.Date(2026, 3, 1, 23, 59, 59, 0, ict), false},
+		{"date with slashes", "2026/03/01", time.Date(2026, 3, 1, 23, 59, 59, 0, ict), false},
+		{"empty", "", time.Time{}, true},
+		{"text", "next friday", time.Time{}, true},
+		{"invalid month", "2026-13-01", time.Time{}, true},
+		{"invalid day", "31/02/2026", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCampusTime(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCampusTime(%q) err = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err != nil {
+				if err != ErrTimeFormat {
+					t.Errorf("err = %v, want %v", err, ErrTimeFormat)
+				}
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseCampusTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			if got.Location() != CampusLocation() {
+				t.Errorf("ParseCampusTime(%q) location = %v, want %v", tt.value, got.Location(), CampusLocation())
+			}
+		})
+	}
+}
+
+func TestEndOfDay(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"campus morning", time.Date(2026, 3, 1, 8, 0, 0, 0, ict), time.Date(2026, 3, 1, 23, 59, 59, 0, ict)},
+		{"UTC evening is the next campus day", time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 23, 59, 59, 0, ict)},
+		{"already end of day", time.Date(2026, 3, 1, 23, 59, 59, 0, ict), time.Date(2026, 3, 1, 23, 59, 59, 0, ict)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EndOfDay(tt.t); !got.Equal(tt.want) {
+				t.Errorf("EndOfDay(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package document
+
+import (
+	"fmt"
+	"time"
+)
+
+// Text là một nội dung song ngữ; En để trống thì chỉ in tiếng Việt
+type Text struct {
+	Vi string
+	En string
+}
+
+func T(vi, en string) Text {
+	return Text{Vi: vi, En: en}
+}
+
+// Document là một văn bản theo thể thức hành chính: đơn vị ban hành và quốc hiệu ở đầu trang,
+// số văn bản, địa danh và ngày, tiêu đề, các khối nội dung và phần ký tên
+type Document struct {
+	Issuer     []string
+	Number     string
+	Place      string
+	Date       time.Time
+	Title      Text
+	Subtitle   Text
+	Blocks     []Block
+	Signatures []Signature
+}
+
+// Block là một khối nội dung; các phần khác rỗng được in theo thứ tự tiêu đề, đoạn văn, trường, danh sách, bảng
+type Block struct {
+	Heading   Text
+	Paragraph Text
+	Fields    []Field
+	Lines     []string
+	Table     *Table
+}
+
+type Field struct {
+	Label Text
+	Value string
+}
+
+// Table là bảng có dòng tiêu đề song ngữ; Width của cột là tỉ lệ so với các cột khác
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+type Column struct {
+	Label Text
+	Width float64
+	Align string
+}
+
+type Signature struct {
+	Title Text
+	Name  string
+}
+
+// DateText in ngày theo hai cách viết, vd "ngày 05 tháng 01 năm 2025" và "January 5, 2025"
+func DateText(place string, date time.Time) Text {
+	vi := fmt.Sprintf("ngày %02d tháng %02d năm %d", date.Day(), int(date.Month()), date.Year())
+	en := date.Format("January 2, 2006")
+	if place != "" {
+		vi = place + ", " + vi
+		en = place + ", " + en
+	} else {
+		vi = "N" + vi[1:]
+	}
+	return Text{Vi: vi, En: en}
+}
+
+// Score in điểm thang 10 với một chữ số thập phân; điểm chưa có in "-"
+func Score(value float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", value)
+}
@@ -0,0 +1,397 @@
+package document
+
+import (
+	"app/config"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-pdf/fpdf"
+)
+
+var ErrFontMissing = errors.New("DOCUMENT_FONT_MISSING")
+
+// Font mặc định được nhúng vào chương trình (DejaVu Sans Condensed, có đủ dấu tiếng Việt, giấy phép trong fonts/LICENSE).
+// PDF_FONT_DIR chỉ dùng để thay font, vd Noto Serif, và phải chứa đủ ba file dưới đây.
+// Font được nhúng vào từng file PDF nên không cần cài font trên máy chủ.
+var FontRegular, FontBold, FontItalic = "NotoSerif-Regular.ttf", "NotoSerif-Bold.ttf", "NotoSerif-Italic.ttf"
+
+//go:embed fonts/*.ttf
+var embeddedFonts embed.FS
+
+var embeddedRegular, embeddedBold, embeddedItalic = "fonts/DejaVuSansCondensed.ttf", "fonts/DejaVuSansCondensed-Bold.ttf", "fonts/DejaVuSansCondensed-Oblique.ttf"
+
+const (
+	family       = "doc"
+	marginLeft   = 25.0
+	marginRight  = 15.0
+	marginTop    = 15.0
+	marginBottom = 18.0
+	lineHeight   = 5.5
+	cellLine     = 4.8
+	cellPadding  = 1.2
+	cellMargin   = 1.5
+)
+
+var (
+	fonts   map[string][]byte
+	fontsMu sync.Mutex
+)
+
+// loadFonts đọc font một lần và dùng chung cho mọi văn bản. Lần đọc lỗi không được ghi nhớ
+// để sửa PDF_FONT_DIR xong không cần khởi động lại.
+func loadFonts() (map[string][]byte, error) {
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+	if fonts != nil {
+		return fonts, nil
+	}
+
+	read := embeddedFonts.ReadFile
+	names := map[string]string{"": embeddedRegular, "B": embeddedBold, "I": embeddedItalic}
+	if dir := config.Config("PDF_FONT_DIR"); dir != "" {
+		read = func(name string) ([]byte, error) { return os.ReadFile(filepath.Join(dir, name)) }
+		names = map[string]string{"": FontRegular, "B": FontBold, "I": FontItalic}
+	}
+
+	loaded := map[string][]byte{}
+	for style, name := range names {
+		data, err := read(name)
+		if err != nil {
+			return nil, ErrFontMissing
+		}
+		loaded[style] = data
+	}
+	fonts = loaded
+	return fonts, nil
+}
+
+// Render dựng văn bản thành file PDF khổ A4 ngay trong tiến trình, không gọi dịch vụ ngoài
+func Render(doc Document) ([]byte, error) {
+	data, err := loadFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	for style, font := range data {
+		pdf.AddUTF8FontFromBytes(family, style, font)
+	}
+	pdf.SetMargins(marginLeft, marginTop, marginRight)
+	pdf.SetAutoPageBreak(true, marginBottom)
+	pdf.SetCellMargin(cellMargin)
+	pdf.SetTitle(doc.Title.Vi, true)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(family, "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	w := &writer{pdf: pdf}
+	w.header(doc)
+	w.title(doc)
+	for _, block := range doc.Blocks {
+		w.block(block)
+	}
+	w.signatures(doc.Signatures)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type writer struct {
+	pdf *fpdf.Fpdf
+}
+
+func (w *writer) width() float64 {
+	pageWidth, _ := w.pdf.GetPageSize()
+	return pageWidth - marginLeft - marginRight
+}
+
+func (w *writer) bottom() float64 {
+	_, pageHeight := w.pdf.GetPageSize()
+	return pageHeight - marginBottom
+}
+
+// ensure sang trang mới nếu phần còn lại của trang không đủ height
+func (w *writer) ensure(height float64) {
+	if w.pdf.GetY()+height > w.bottom() {
+		w.pdf.AddPage()
+	}
+}
+
+// header in đơn vị ban hành bên trái, quốc hiệu và tiêu ngữ bên phải, rồi số văn bản và ngày
+func (w *writer) header(doc Document) {
+	half := w.width() / 2
+	top := w.pdf.GetY()
+
+	w.pdf.SetXY(marginLeft, top)
+	for i, line := range doc.Issuer {
+		style := ""
+		if i == len(doc.Issuer)-1 {
+			style = "B"
+		}
+		w.pdf.SetFont(family, style, 10)
+		w.pdf.SetX(marginLeft)
+		w.pdf.MultiCell(half, lineHeight, strings.ToUpper(line), "", "C", false)
+	}
+	left := w.pdf.GetY()
+
+	right := [][3]string{
+		{"B", "10", "CỘNG HÒA XÃ HỘI CHỦ NGHĨA VIỆT NAM"},
+		{"B", "10", "Độc lập - Tự do - Hạnh phúc"},
+		{"I", "8", "SOCIALIST REPUBLIC OF VIETNAM"},
+		{"I", "8", "Independence - Freedom - Happiness"},
+	}
+	w.pdf.SetXY(marginLeft+half, top)
+	for _, line := range right {
+		size := 10.0
+		if line[1] == "8" {
+			size = 8
+		}
+		w.pdf.SetFont(family, line[0], size)
+		w.pdf.SetX(marginLeft + half)
+		w.pdf.MultiCell(half, lineHeight-0.5, line[2], "", "C", false)
+	}
+	if w.pdf.GetY() < left {
+		w.pdf.SetY(left)
+	}
+	w.pdf.Ln(3)
+
+	row := w.pdf.GetY()
+	if doc.Number != "" {
+		w.pdf.SetFont(family, "", 10)
+		w.pdf.SetXY(marginLeft, row)
+		w.pdf.MultiCell(half, lineHeight, "Số / No.: "+doc.Number, "", "C", false)
+	}
+	if !doc.Date.IsZero() {
+		date := DateText(doc.Place, doc.Date)
+		w.pdf.SetXY(marginLeft+half, row)
+		w.pdf.SetFont(family, "I", 10)
+		w.pdf.MultiCell(half, lineHeight, date.Vi, "", "C", false)
+		w.pdf.SetX(marginLeft + half)
+		w.pdf.SetFont(family, "I", 8)
+		w.pdf.MultiCell(half, lineHeight-0.5, date.En, "", "C", false)
+	}
+	w.pdf.Ln(6)
+}
+
+func (w *writer) title(doc Document) {
+	w.pdf.SetFont(family, "B", 14)
+	w.pdf.MultiCell(0, 7, strings.ToUpper(doc.Title.Vi), "", "C", false)
+	if doc.Title.En != "" {
+		w.pdf.SetFont(family, "I", 11)
+		w.pdf.MultiCell(0, 6, doc.Title.En, "", "C", false)
+	}
+	if doc.Subtitle.Vi != "" {
+		w.pdf.Ln(1)
+		w.pdf.SetFont(family, "B", 11)
+		w.pdf.MultiCell(0, lineHeight, doc.Subtitle.Vi, "", "C", false)
+		if doc.Subtitle.En != "" {
+			w.pdf.SetFont(family, "I", 10)
+			w.pdf.MultiCell(0, lineHeight, doc.Subtitle.En, "", "C", false)
+		}
+	}
+	w.pdf.Ln(5)
+}
+
+func (w *writer) block(block Block) {
+	if block.Heading.Vi != "" {
+		w.ensure(lineHeight * 3)
+		w.label(block.Heading, 11)
+		w.pdf.Ln(lineHeight + 1)
+	}
+	if block.Paragraph.Vi != "" {
+		w.pdf.SetFont(family, "", 11)
+		w.pdf.MultiCell(0, lineHeight, block.Paragraph.Vi, "", "J", false)
+		if block.Paragraph.En != "" {
+			w.pdf.SetFont(family, "I", 9)
+			w.pdf.MultiCell(0, lineHeight-0.5, block.Paragraph.En, "", "J", false)
+		}
+		w.pdf.Ln(1)
+	}
+	for _, field := range block.Fields {
+		w.label(field.Label, 11)
+		w.pdf.SetFont(family, "", 11)
+		w.pdf.Write(lineHeight, ": "+field.Value)
+		w.pdf.Ln(lineHeight)
+	}
+	for i, line := range block.Lines {
+		w.pdf.SetFont(family, "", 11)
+		w.pdf.SetX(marginLeft + 5)
+		w.pdf.MultiCell(w.width()-5, lineHeight, fmt.Sprintf("%d. %s", i+1, line), "", "L", false)
+	}
+	if block.Table != nil {
+		w.pdf.Ln(1)
+		w.table(block.Table)
+	}
+	w.pdf.Ln(3)
+}
+
+// label in phần tiếng Việt in đậm và phần tiếng Anh in nghiêng trên cùng một dòng
+func (w *writer) label(text Text, size float64) {
+	w.pdf.SetFont(family, "B", size)
+	w.pdf.Write(lineHeight, text.Vi)
+	if text.En != "" {
+		w.pdf.SetFont(family, "I", size-1)
+		w.pdf.Write(lineHeight, " / "+text.En)
+	}
+}
+
+func (w *writer) columnWidths(columns []Column) []float64 {
+	total := 0.0
+	for _, column := range columns {
+		total += weight(column)
+	}
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = w.width() * weight(column) / total
+	}
+	return widths
+}
+
+func weight(column Column) float64 {
+	if column.Width <= 0 {
+		return 1
+	}
+	return column.Width
+}
+
+// table in bảng; dòng không vừa trang được chuyển sang trang sau cùng với dòng tiêu đề
+func (w *writer) table(table *Table) {
+	widths := w.columnWidths(table.Columns)
+	w.tableHeader(table.Columns, widths)
+
+	for _, row := range table.Rows {
+		w.pdf.SetFont(family, "", 10)
+		lines := make([][]string, len(table.Columns))
+		rows := 1
+		for i := range table.Columns {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			lines[i] = w.split(cell, widths[i])
+			if len(lines[i]) > rows {
+				rows = len(lines[i])
+			}
+		}
+		height := float64(rows)*cellLine + 2*cellPadding
+		if w.pdf.GetY()+height > w.bottom() {
+			w.pdf.AddPage()
+			w.tableHeader(table.Columns, widths)
+			w.pdf.SetFont(family, "", 10)
+		}
+
+		x, y := marginLeft, w.pdf.GetY()
+		for i, column := range table.Columns {
+			w.pdf.Rect(x, y, widths[i], height, "D")
+			for j, line := range lines[i] {
+				w.pdf.SetXY(x, y+cellPadding+float64(j)*cellLine)
+				w.pdf.CellFormat(widths[i], cellLine, line, "", 0, align(column.Align), false, 0, "")
+			}
+			x += widths[i]
+		}
+		w.pdf.SetXY(marginLeft, y+height)
+	}
+}
+
+func (w *writer) tableHeader(columns []Column, widths []float64) {
+	vi := make([][]string, len(columns))
+	en := make([][]string, len(columns))
+	rows := 1
+	for i, column := range columns {
+		w.pdf.SetFont(family, "B", 10)
+		vi[i] = w.split(column.Label.Vi, widths[i])
+		w.pdf.SetFont(family, "I", 8)
+		en[i] = []string{}
+		if column.Label.En != "" {
+			en[i] = w.split(column.Label.En, widths[i])
+		}
+		if len(vi[i])+len(en[i]) > rows {
+			rows = len(vi[i]) + len(en[i])
+		}
+	}
+	height := float64(rows)*cellLine + 2*cellPadding
+	w.ensure(height + cellLine*2)
+
+	x, y := marginLeft, w.pdf.GetY()
+	w.pdf.SetFillColor(235, 235, 235)
+	for i := range columns {
+		w.pdf.Rect(x, y, widths[i], height, "FD")
+		at := y + cellPadding
+		w.pdf.SetFont(family, "B", 10)
+		for _, line := range vi[i] {
+			w.pdf.SetXY(x, at)
+			w.pdf.CellFormat(widths[i], cellLine, line, "", 0, "C", false, 0, "")
+			at += cellLine
+		}
+		w.pdf.SetFont(family, "I", 8)
+		for _, line := range en[i] {
+			w.pdf.SetXY(x, at)
+			w.pdf.CellFormat(widths[i], cellLine, line, "", 0, "C", false, 0, "")
+			at += cellLine
+		}
+		x += widths[i]
+	}
+	w.pdf.SetXY(marginLeft, y+height)
+}
+
+// split ngắt chữ theo độ rộng ô với font hiện tại, giữ các dòng xuống hàng có sẵn
+func (w *writer) split(text string, width float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		if strings.TrimSpace(paragraph) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, w.pdf.SplitText(paragraph, width-2*cellMargin)...)
+	}
+	return lines
+}
+
+func align(value string) string {
+	switch value {
+	case "C", "R":
+		return value
+	}
+	return "L"
+}
+
+// signatures chia đều chiều ngang cho các chức danh ký tên, chừa chỗ ký giữa chức danh và họ tên
+func (w *writer) signatures(signatures []Signature) {
+	if len(signatures) == 0 {
+		return
+	}
+	w.ensure(45)
+	w.pdf.Ln(4)
+	width := w.width() / float64(len(signatures))
+	top := w.pdf.GetY()
+	for i, signature := range signatures {
+		x := marginLeft + float64(i)*width
+		w.pdf.SetXY(x, top)
+		w.pdf.SetFont(family, "B", 11)
+		w.pdf.MultiCell(width, lineHeight, strings.ToUpper(signature.Title.Vi), "", "C", false)
+		if signature.Title.En != "" {
+			w.pdf.SetX(x)
+			w.pdf.SetFont(family, "I", 9)
+			w.pdf.MultiCell(width, lineHeight-0.5, signature.Title.En, "", "C", false)
+		}
+		w.pdf.SetX(x)
+		w.pdf.SetFont(family, "I", 8)
+		w.pdf.MultiCell(width, lineHeight-0.5, "(Ký và ghi rõ họ tên / Signature and full name)", "", "C", false)
+		w.pdf.SetXY(x, top+32)
+		w.pdf.SetFont(family, "B", 11)
+		w.pdf.MultiCell(width, lineHeight, signature.Name, "", "C", false)
+	}
+}
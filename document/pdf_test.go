@@ -0,0 +1,120 @@
+package document
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// config.Config đọc .env ở thư mục hiện tại; test chạy trong thư mục tạm có .env rỗng để dùng font nhúng
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "document")
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".env"), nil, 0644); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	os.Unsetenv("PDF_FONT_DIR")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+const vietnamese = "ÀÁÂÃÈÉÊÌÍÒÓÔÕÙÚÝàáâãèéêìíòóôõùúýĂăĐđĨĩŨũƠơƯưẠạẢảẤấẦầẨẩẪẫẬậẮắẰằẲẳẴẵẶặẸẹẺẻẼẽẾếỀềỂểỄễỆệỈỉỊịỌọỎỏỐốỒồỔổỖỗỘộỚớỜờỞởỠỡỢợỤụỦủỨứỪừỬửỮữỰựỲỳỴỵỶỷỸỹ"
+
+func TestFontsCoverVietnamese(t *testing.T) {
+	data, err := loadFonts()
+	if err != nil {
+		t.Fatalf("loadFonts() error = %v", err)
+	}
+	for _, style := range []string{"", "B", "I"} {
+		t.Run("style "+style, func(t *testing.T) {
+			pdf := fpdf.New("P", "mm", "A4", "")
+			pdf.AddUTF8FontFromBytes(family, style, data[style])
+			pdf.SetFont(family, style, 12)
+			if err := pdf.Error(); err != nil {
+				t.Fatalf("font not usable: %v", err)
+			}
+			// Ký tự không có trong font được tính bằng độ rộng mặc định; chữ Hán chắc chắn thiếu trong font nhúng
+			missing := pdf.GetStringSymbolWidth("中")
+			for _, r := range vietnamese {
+				if width := pdf.GetStringSymbolWidth(string(r)); width == 0 || width == missing {
+					t.Errorf("glyph %q missing from font (width %d)", r, width)
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc := Document{
+		Issuer: []string{"TRƯỜNG ĐẠI HỌC", "KHOA CÔNG NGHỆ THÔNG TIN"},
+		Number: "12/QĐ-CNTT",
+		Place:  "Thành phố Hồ Chí Minh",
+		Date:   time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		Title:  T("QUYẾT ĐỊNH GIAO ĐỀ TÀI LUẬN VĂN", "Thesis assignment decision"),
+		Blocks: []Block{
+			{Heading: T("Điều 1", "Article 1"), Paragraph: T("Giao đề tài “Ứng dụng học sâu trong nhận dạng chữ viết tay tiếng Việt” cho sinh viên.", "")},
+			{Fields: []Field{{Label: T("Học kỳ", "Semester"), Value: "HK1 2026–2027"}}},
+			{Table: &Table{
+				Columns: []Column{{Label: T("Họ và tên", "Full name"), Width: 3}, {Label: T("Điểm", "Score"), Width: 1, Align: "C"}},
+				Rows:    [][]string{{"Nguyễn Thị Ánh Tuyết", Score(8.5, true)}, {"Trần Đức Thắng", Score(0, false)}},
+			}},
+		},
+		Signatures: []Signature{{Title: T("TRƯỞNG KHOA", "Dean"), Name: "Lê Văn Dũng"}},
+	}
+
+	out, err := Render(doc)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Fatalf("Render() output is not a PDF: %q", out[:16])
+	}
+	if !bytes.Contains(out, []byte("/FontFile2")) {
+		t.Errorf("Render() output does not embed the TrueType font")
+	}
+}
+
+func TestLoadFontsOverride(t *testing.T) {
+	defer func() {
+		fonts = nil
+		os.Unsetenv("PDF_FONT_DIR")
+	}()
+
+	dir, err := ioutil.TempDir("", "fonts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fonts = nil
+	os.Setenv("PDF_FONT_DIR", dir)
+	if _, err := loadFonts(); err != ErrFontMissing {
+		t.Fatalf("loadFonts() with an empty PDF_FONT_DIR error = %v, want %v", err, ErrFontMissing)
+	}
+
+	// Lần lỗi trước không được ghi nhớ: thêm font vào thư mục là đọc được ngay
+	for _, name := range []string{FontRegular, FontBold, FontItalic} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.TrimSuffix(name, ".ttf")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := loadFonts()
+	if err != nil {
+		t.Fatalf("loadFonts() after adding fonts error = %v", err)
+	}
+	if string(data["B"]) != "NotoSerif-Bold" {
+		t.Errorf("bold font = %q, want the file from PDF_FONT_DIR", data["B"])
+	}
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/advisor/model"
+	"app/utils"
+
+	researchAreaController "app/modules/researchArea/controller"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateAdvisorExpertise cập nhật lĩnh vực chuyên môn của một Advisor
+// @Summary Set advisor expertise
+// @Description Replace the research areas an advisor has expertise in (the advisor themself, faculty office or head of subject)
+// @Tags Advisor
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Advisor UUID"
+// @Param body body model.UpdateAdvisorExpertise true "Research areas"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /advisor/{uuid}/expertise [put]
+func UpdateAdvisorExpertise(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.UpdateAdvisorExpertise
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var advisor model.Advisor
+	if err := db.First(&advisor, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	isSelf := tokenData.Role == modelUsers.AdvisorRole && tokenData.ID == advisor.ID
+	isStaff := tokenData.Role == modelUsers.FacultyOfficeRole || tokenData.Role == modelUsers.HeadOfSubjectRole
+	if !isSelf && !isStaff {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	areas, err := researchAreaController.LoadActiveAreas(db, payload.ResearchAreaIDs)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"researchAreaIDs": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+
+	if err := db.Model(&advisor).Association("Expertise").Replace(areas); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	advisor.Expertise = areas
+	response.Data = advisor
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetAdvisorsByExpertise trả về danh sách Advisor theo lĩnh vực chuyên môn
+// @Summary Find advisors by expertise
+// @Description Find advisors whose expertise matches a research area code or name (sub-areas included)
+// @Tags Advisor
+// @Produce json
+// @Param area query string true "Research area code or name"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /advisor/expertise [get]
+func GetAdvisorsByExpertise(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	area := c.Query("area")
+	if area == "" {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"area": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	areaIDs, err := researchAreaController.ResolveAreaIDs(db, area)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	advisors := []model.Advisor{}
+	if len(areaIDs) > 0 {
+		if err := db.Preload("Expertise").
+			Where("ID IN (?)", db.Table("TBL_ADVISOR_EXPERTISE").Select("ADVISOR_ID").Where("RESEARCH_AREA_ID IN ?", areaIDs)).
+			Order("ID").Find(&advisors).Error; err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("GET_DATA_FAIL")
+			return c.JSON(response)
+		}
+	}
+
+	response.Data = advisors
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
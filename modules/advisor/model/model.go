@@ -2,6 +2,7 @@ package model
 
 import (
 	"app/model"
+	modelResearchArea "app/modules/researchArea/model"
 	// "errors"
 	// "time"
 	// "gorm.io/gorm"
@@ -11,6 +12,12 @@ type Advisor struct {
 	model.Info `gorm:"embedded;-:migration"`
 	Code       string `json:"code" gorm:"column:CODE;size:10;not null"`
 	ThesisID uint   `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	SubjectID *uint `json:"subjectID" gorm:"column:SUBJECT_ID;index"`
+	Expertise []modelResearchArea.ResearchArea `json:"expertise" gorm:"many2many:TBL_ADVISOR_EXPERTISE;joinForeignKey:ADVISOR_ID;joinReferences:RESEARCH_AREA_ID"`
+}
+
+type UpdateAdvisorExpertise struct {
+	ResearchAreaIDs []uint `json:"researchAreaIDs"`
 }
 
 type CreateAdvisor struct {
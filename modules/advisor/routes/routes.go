@@ -11,6 +11,7 @@ func InitAdvisorRoutes(app *fiber.App) {
 
 	getList := advisor.Group("")
 	getList.Get("/", controller.GetAdvisor)
+	getList.Get("/expertise", controller.GetAdvisorsByExpertise)
 	getList.Get("/:uuid", controller.GetAdvisorByUUID)
 	getList.Get("/code/:code", controller.GetAdvisorByMSCB)
 
@@ -18,6 +19,7 @@ func InitAdvisorRoutes(app *fiber.App) {
 
 	getList.Post("/", controller.CreateAdvisor)
 	getList.Put("/", controller.UpdateAdvisor)
+	getList.Put("/:uuid/expertise", controller.UpdateAdvisorExpertise)
 	getList.Delete("/:uuid", controller.DeleteAdvisor)
 	getList.Put("/restore/:uuid", controller.RestoreAdvisor)
 }
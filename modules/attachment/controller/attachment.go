@@ -0,0 +1,524 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/attachment/model"
+	"app/similarity"
+	"app/storage"
+	"app/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	organizationController "app/modules/organization/controller"
+	thesisModel "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Định dạng đóng gói (zip/OLE) không nhận diện được bằng nội dung, dùng phần mở rộng để xác định
+var containerMimeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".doc":  "application/msword",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".7z":   "application/x-7z-compressed",
+	".tar":  "application/x-tar",
+}
+
+// @title Attachment API
+// @version 1.0
+// @description API for thesis submissions and file storage
+// @termsOfService http://swagger.io/terms/
+// @BasePath /attachment
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetSubmissionLimits trả về giới hạn dung lượng và kiểu file của từng loại bài nộp
+// @Summary Get submission limits
+// @Description Get size and MIME limits per submission type
+// @Tags Attachment
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Router /attachment/limits [get]
+func GetSubmissionLimits(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	response.Data = model.SubmissionLimits
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// UploadAttachment tải file bài nộp lên cho một Thesis
+// @Summary Upload a thesis attachment
+// @Description Upload a file (multipart field "file") with a submissionType of PROPOSAL, REPORT, SOURCE_CODE or SLIDES. Uploading the same file name again creates a new version.
+// @Tags Attachment
+// @Accept mpfd
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Param submissionType formData string true "Submission type"
+// @Param file formData file true "File"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /attachment/thesis/{uuid} [post]
+func UploadAttachment(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis thesisModel.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canAccessThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	submissionType := c.FormValue("submissionType")
+	limit, ok := model.SubmissionLimits[submissionType]
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"submissionType": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"file": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	if fileHeader.Size > limit.MaxSize {
+		response.Status = false
+		response.Message = config.GetMessageCode("FILE_TOO_LARGE")
+		return c.JSON(response)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("UPLOAD_FAIL")
+		return c.JSON(response)
+	}
+	defer file.Close()
+
+	fileName := filepath.Base(fileHeader.Filename)
+	mimeType, err := detectMimeType(file, fileName)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("UPLOAD_FAIL")
+		return c.JSON(response)
+	}
+	if !mimeAllowed(limit, mimeType) {
+		response.Status = false
+		response.Message = config.GetMessageCode("FILE_TYPE_NOT_ALLOWED")
+		return c.JSON(response)
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	// Tính SHA-256 trong lúc ghi file để không phải đọc lại
+	key := fmt.Sprintf("thesis/%d/%s/%s", thesis.ID, submissionType, uuid.NewString())
+	hasher := sha256.New()
+	if err := store.Put(key, io.TeeReader(file, hasher), fileHeader.Size, mimeType); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("UPLOAD_FAIL")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	// Khóa dòng thesis để các lần tải lên đồng thời nhận số phiên bản lần lượt
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("ID").First(&thesisModel.Thesis{}, thesis.ID).Error; err != nil {
+		tx.Rollback()
+		store.Delete(key)
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	// Tính cả phiên bản đã xóa vì chỉ mục phiên bản không bỏ qua dòng đã xóa mềm
+	var latestVersion int
+	if err := tx.Unscoped().Model(&model.Attachment{}).
+		Where("THESIS_ID = ? AND SUBMISSION_TYPE = ? AND FILE_NAME = ?", thesis.ID, submissionType, fileName).
+		Select("COALESCE(MAX(VERSION), 0)").Scan(&latestVersion).Error; err != nil {
+		tx.Rollback()
+		store.Delete(key)
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	attachment := model.Attachment{
+		ThesisID:       thesis.ID,
+		SubmissionType: submissionType,
+		FileName:       fileName,
+		Version:        latestVersion + 1,
+		Size:           fileHeader.Size,
+		MimeType:       mimeType,
+		Checksum:       hex.EncodeToString(hasher.Sum(nil)),
+		StorageKey:     key,
+		UploaderID:     tokenData.ID,
+		UploaderRole:   tokenData.Role,
+	}
+	attachment.CreatedBy = tokenData.Code
+
+	if isTextSubmission(submissionType) {
+		if f, err := fileHeader.Open(); err == nil {
+			data, _ := ioutil.ReadAll(f)
+			f.Close()
+			attachment.TextContent, _ = similarity.ExtractText(data, mimeType)
+		}
+	}
+
+	if err := tx.Create(&attachment).Error; err != nil {
+		tx.Rollback()
+		store.Delete(key)
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = attachment
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ListThesisAttachments trả về tất cả phiên bản file của một Thesis
+// @Summary List thesis attachments
+// @Description List all attachment versions of a thesis, newest version first
+// @Tags Attachment
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Param submissionType query string false "Submission type"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /attachment/thesis/{uuid} [get]
+func ListThesisAttachments(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis thesisModel.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canAccessThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	query := db.Omit("TEXT_CONTENT").Where("THESIS_ID = ?", thesis.ID)
+	if submissionType := c.Query("submissionType"); submissionType != "" {
+		query = query.Where("SUBMISSION_TYPE = ?", submissionType)
+	}
+
+	var attachments []model.Attachment
+	if err := query.Order("SUBMISSION_TYPE").Order("FILE_NAME").Order("VERSION DESC").Find(&attachments).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = attachments
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetDownloadLink tạo đường dẫn tải file có thời hạn
+// @Summary Get a download link
+// @Description Create a short-lived signed download link for an attachment
+// @Tags Attachment
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /attachment/{id}/link [get]
+func GetDownloadLink(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var attachment model.Attachment
+	if err := db.First(&attachment, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var thesis thesisModel.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, attachment.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canAccessThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	token, err := utils.GenerateLinkToken("attachment", attachment.ID)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("FAILED_TO_GENERATE_TOKEN")
+		return c.JSON(response)
+	}
+
+	expiresIn, err := strconv.Atoi(config.Config("LINK_EXPIRED_TIME"))
+	if err != nil || expiresIn <= 0 {
+		expiresIn = 15
+	}
+
+	response.Data = model.DownloadLink{
+		URL:       "/attachment/download?token=" + token,
+		ExpiresIn: expiresIn * 60,
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// DownloadAttachment tải file bằng đường dẫn đã ký
+// @Summary Download an attachment
+// @Description Download an attachment using a token from /attachment/{id}/link
+// @Tags Attachment
+// @Produce octet-stream
+// @Param token query string true "Signed link token"
+// @Success 200 {file} file
+// @Failure 500 {object} config.DataResponse
+// @Router /attachment/download [get]
+func DownloadAttachment(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	id, err := utils.ParseLinkToken(c.Query("token"), "attachment")
+	if err != nil {
+		response.Status = false
+		response.Message = err.Error()
+		return c.Status(fiber.StatusForbidden).JSON(response)
+	}
+
+	var attachment model.Attachment
+	if err := database.DB.First(&attachment, id).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.Status(fiber.StatusNotFound).JSON(response)
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.Status(fiber.StatusInternalServerError).JSON(response)
+	}
+
+	reader, err := store.Get(attachment.StorageKey)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.Status(fiber.StatusNotFound).JSON(response)
+	}
+
+	c.Attachment(attachment.FileName)
+	c.Set(fiber.HeaderContentType, attachment.MimeType)
+	c.Set("X-Checksum-Sha256", attachment.Checksum)
+	return c.SendStream(reader, int(attachment.Size))
+}
+
+// DeleteAttachment xóa một phiên bản file
+// @Summary Delete an attachment
+// @Description Soft delete an attachment version. Only the uploader or the faculty office within its scope can delete.
+// @Tags Attachment
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /attachment/{id} [delete]
+func DeleteAttachment(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	var attachment model.Attachment
+	if err := tx.First(&attachment, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var thesis thesisModel.Thesis
+	if err := tx.Select("ID, SUBJECT_ID").First(&thesis, attachment.ThesisID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	isUploader := attachment.UploaderID == tokenData.ID && attachment.UploaderRole == tokenData.Role
+	isOffice := tokenData.Role == modelUsers.FacultyOfficeRole && organizationController.ScopeOf(tx, tokenData).Allows(thesis.SubjectID)
+	if !isUploader && !isOffice {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	if err := tx.Model(&attachment).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// LoadText trả về nội dung chữ của file nộp, trích lại từ kho lưu trữ nếu chưa có
+func LoadText(attachment *model.Attachment) (string, error) {
+	if attachment.TextContent != "" || !isTextSubmission(attachment.SubmissionType) {
+		return attachment.TextContent, nil
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		return "", err
+	}
+	reader, err := store.Get(attachment.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	text, err := similarity.ExtractText(data, attachment.MimeType)
+	if err != nil {
+		return "", err
+	}
+
+	attachment.TextContent = text
+	database.DB.Model(attachment).UpdateColumn("TEXT_CONTENT", text)
+	return text, nil
+}
+
+func isTextSubmission(submissionType string) bool {
+	for _, t := range model.TextSubmissionTypes {
+		if t == submissionType {
+			return true
+		}
+	}
+	return false
+}
+
+// canAccessThesis: sinh viên và giảng viên chỉ truy cập luận văn của mình, trưởng bộ môn và văn phòng khoa
+// trong phạm vi của mình, các vai trò khác không được truy cập
+func canAccessThesis(db *gorm.DB, tokenData *utils.TokenData, thesis *thesisModel.Thesis) bool {
+	switch tokenData.Role {
+	case modelUsers.StudentRole:
+		return thesis.HasStudent(tokenData.ID)
+	case modelUsers.AdvisorRole:
+		return thesis.HasAdvisor(tokenData.ID)
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		return organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+	}
+	return false
+}
+
+func detectMimeType(file io.ReadSeeker, fileName string) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	sniffed := http.DetectContentType(head[:n])
+	if i := strings.Index(sniffed, ";"); i >= 0 {
+		sniffed = sniffed[:i]
+	}
+
+	if sniffed == "application/octet-stream" || sniffed == "application/zip" {
+		if byExt, ok := containerMimeTypes[strings.ToLower(filepath.Ext(fileName))]; ok {
+			return byExt, nil
+		}
+	}
+	return sniffed, nil
+}
+
+func mimeAllowed(limit model.SubmissionLimit, mimeType string) bool {
+	for _, allowed := range limit.MimeTypes {
+		if allowed == mimeType {
+			return true
+		}
+	}
+	return false
+}
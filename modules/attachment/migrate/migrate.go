@@ -0,0 +1,14 @@
+package attachmentMigrate
+
+import (
+	"app/database"
+	model "app/modules/attachment/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Attachment{})
+
+	return true
+}
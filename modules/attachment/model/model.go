@@ -0,0 +1,59 @@
+package model
+
+import (
+	"app/model"
+)
+
+var SubmissionProposal, SubmissionReport, SubmissionSourceCode, SubmissionSlides = "PROPOSAL", "REPORT", "SOURCE_CODE", "SLIDES"
+
+// Nội dung chữ của các loại bài nộp này được lưu lại để kiểm tra trùng lặp
+var TextSubmissionTypes = []string{SubmissionProposal, SubmissionReport}
+
+type SubmissionLimit struct {
+	MaxSize   int64    `json:"maxSize"`
+	MimeTypes []string `json:"mimeTypes"`
+}
+
+// SubmissionLimits giới hạn dung lượng và kiểu file cho từng loại bài nộp
+var SubmissionLimits = map[string]SubmissionLimit{
+	SubmissionProposal: {
+		MaxSize:   10 << 20,
+		MimeTypes: []string{"application/pdf", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/msword"},
+	},
+	SubmissionReport: {
+		MaxSize:   50 << 20,
+		MimeTypes: []string{"application/pdf", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/msword"},
+	},
+	SubmissionSourceCode: {
+		MaxSize:   200 << 20,
+		MimeTypes: []string{"application/zip", "application/x-zip-compressed", "application/x-tar", "application/gzip", "application/x-gzip", "application/x-7z-compressed"},
+	},
+	SubmissionSlides: {
+		MaxSize:   50 << 20,
+		MimeTypes: []string{"application/pdf", "application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/vnd.ms-powerpoint"},
+	},
+}
+
+type Attachment struct {
+	model.Header
+	ThesisID       uint   `json:"thesisID" gorm:"column:THESIS_ID;index;uniqueIndex:IDX_ATTACHMENT_VERSION"`
+	SubmissionType string `json:"submissionType" gorm:"column:SUBMISSION_TYPE;size:20;index;uniqueIndex:IDX_ATTACHMENT_VERSION"`
+	FileName       string `json:"fileName" gorm:"column:FILE_NAME;size:255;uniqueIndex:IDX_ATTACHMENT_VERSION"`
+	Version        int    `json:"version" gorm:"column:VERSION;uniqueIndex:IDX_ATTACHMENT_VERSION"`
+	Size           int64  `json:"size" gorm:"column:FILE_SIZE"`
+	MimeType       string `json:"mimeType" gorm:"column:MIME_TYPE;size:100"`
+	Checksum       string `json:"checksum" gorm:"column:CHECKSUM;size:64"`
+	StorageKey     string `json:"-" gorm:"column:STORAGE_KEY;size:255"`
+	UploaderID     uint   `json:"uploaderID" gorm:"column:UPLOADER_ID"`
+	UploaderRole   int    `json:"uploaderRole" gorm:"column:UPLOADER_ROLE"`
+	TextContent    string `json:"-" gorm:"column:TEXT_CONTENT;type:clob"`
+}
+
+type DownloadLink struct {
+	URL       string `json:"url"`
+	ExpiresIn int    `json:"expiresIn"`
+}
+
+func (Attachment) TableName() string {
+	return "TBL_THESIS_ATTACHMENT"
+}
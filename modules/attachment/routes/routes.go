@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"app/modules/attachment/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitAttachmentRoutes(app *fiber.App) {
+	attachment := app.Group("/attachment")
+
+	attachment.Get("/limits", controller.GetSubmissionLimits)
+	attachment.Get("/download", controller.DownloadAttachment)
+	attachment.Get("/thesis/:uuid", controller.ListThesisAttachments)
+	attachment.Get("/:id/link", controller.GetDownloadLink)
+
+	attachment.Post("/thesis/:uuid", controller.UploadAttachment)
+	attachment.Delete("/:id", controller.DeleteAttachment)
+}
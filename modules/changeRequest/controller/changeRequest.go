@@ -0,0 +1,720 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/changeRequest/model"
+	"app/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelHeadOfSubject "app/modules/headOfSubject/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	organizationController "app/modules/organization/controller"
+	semesterController "app/modules/semester/controller"
+	modelStudent "app/modules/student/model"
+	thesisController "app/modules/thesis/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Change Request API
+// @version 1.0
+// @description Thesis withdrawal, topic change and advisor transfer requests
+// @termsOfService http://swagger.io/terms/
+// @BasePath /change-request
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetChangeRequests trả về các yêu cầu thay đổi người dùng được xem
+// @Summary List change requests
+// @Description Students see their own requests, advisors the requests they have to approve, heads of subject and the faculty office the requests on theses of their subject or faculty
+// @Tags ChangeRequest
+// @Produce json
+// @Param status query string false "PENDING, APPROVED, REJECTED or CANCELLED"
+// @Param type query string false "WITHDRAWAL, TOPIC_CHANGE or ADVISOR_TRANSFER"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /change-request [get]
+func GetChangeRequests(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	query := db.Preload("Approvals").Order("CREATED_AT DESC")
+	switch tokenData.Role {
+	case modelUsers.StudentRole:
+		query = query.Where("STUDENT_ID = ?", tokenData.ID)
+	case modelUsers.AdvisorRole:
+		query = query.Where("ID IN (?)", db.Model(&model.ChangeApproval{}).Select("REQUEST_ID").
+			Where("APPROVER_ID = ? AND APPROVER_ROLE = ?", tokenData.ID, modelUsers.AdvisorRole))
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		query = query.Where("THESIS_ID IN (?)", scopedTheses(db, tokenData))
+	default:
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if status := strings.ToUpper(c.Query("status")); status != "" {
+		query = query.Where("STATUS = ?", status)
+	}
+	if changeType := strings.ToUpper(c.Query("type")); changeType != "" {
+		query = query.Where("TYPE = ?", changeType)
+	}
+
+	var requests []model.ChangeRequest
+	if err := query.Find(&requests).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = requests
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetChangeRequest trả về một yêu cầu thay đổi cùng các bước duyệt
+// @Summary Get a change request
+// @Description Get a change request with its approval steps
+// @Tags ChangeRequest
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /change-request/{id} [get]
+func GetChangeRequest(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var request model.ChangeRequest
+	if err := database.DB.Preload("Approvals").First(&request, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canView(database.DB, tokenData, request) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	response.Data = request
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateChangeRequest sinh viên gửi yêu cầu rút đề tài, đổi đề tài hoặc đổi giảng viên
+// @Summary Create a change request
+// @Description A student asks to withdraw from their thesis, move to another approved thesis (newThesisUUID) or replace an advisor (currentAdvisorID, newAdvisorID). The request needs the approval of the current advisor, the new advisor and a head of subject, and must be sent before the deadline of the semester calendar.
+// @Tags ChangeRequest
+// @Accept json
+// @Produce json
+// @Param body body model.CreateChangeRequest true "Change request"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /change-request [post]
+func CreateChangeRequest(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateChangeRequest
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	changeType := strings.ToUpper(strings.TrimSpace(payload.Type))
+	vItem := map[string]string{"type": changeType, "reason": strings.TrimSpace(payload.Reason)}
+	errors := utils.RequireCheck([]string{"type", "reason"}, vItem, map[string]string{})
+	if changeType != "" && changeType != model.ChangeWithdrawal && changeType != model.ChangeTopic && changeType != model.ChangeAdvisor {
+		errors["type"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	var student modelStudent.Student
+	if err := db.First(&student, tokenData.ID).Error; err != nil || student.ThesisID == 0 {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Advisors").First(&thesis, student.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if deadline := requestDeadline(db, thesis, changeType); deadline != nil && core.Now().After(*deadline) {
+		response.Status = false
+		response.Message = config.GetMessageCode("CHANGE_DEADLINE_PASSED")
+		response.ValidateError = map[string]string{"deadline": deadline.Format(time.RFC3339)}
+		return c.JSON(response)
+	}
+
+	var pending int64
+	db.Model(&model.ChangeRequest{}).Where("STUDENT_ID = ? AND STATUS = ?", student.ID, model.RequestPending).Count(&pending)
+	if pending > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("CHANGE_REQUEST_PENDING")
+		return c.JSON(response)
+	}
+
+	request := model.ChangeRequest{
+		Type:      changeType,
+		StudentID: student.ID,
+		ThesisID:  thesis.ID,
+		Reason:    vItem["reason"],
+		Status:    model.RequestPending,
+	}
+	request.CreatedBy = tokenData.Code
+
+	approvals := []model.ChangeApproval{}
+	switch changeType {
+	case model.ChangeWithdrawal:
+		approvals = append(approvals, advisorSteps(model.StepCurrentAdvisor, thesis.Advisors)...)
+
+	case model.ChangeTopic:
+		var newThesis modelThesis.Thesis
+		if err := db.Preload("Advisors").First(&newThesis, "uuid = ?", payload.NewThesisUUID).Error; err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			response.ValidateError = map[string]string{"newThesisUUID": config.GetMessageCode("NOT_ID_EXISTS")}
+			return c.JSON(response)
+		}
+		if newThesis.ID == thesis.ID || newThesis.ApprovalStatus != modelThesis.ApprovalApproved {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"newThesisUUID": config.GetMessageCode("PARAM_ERROR")}
+			return c.JSON(response)
+		}
+		if !openThesis(db, newThesis.ID) {
+			response.Status = false
+			response.Message = config.GetMessageCode("THESIS_NOT_OPEN")
+			response.ValidateError = map[string]string{"newThesisUUID": config.GetMessageCode("THESIS_NOT_OPEN")}
+			return c.JSON(response)
+		}
+		if !thesisController.StudentEligible(db, newThesis.ID, student) {
+			response.Status = false
+			response.Message = config.GetMessageCode("PROGRAM_NOT_ELIGIBLE")
+			response.ValidateError = map[string]string{"newThesisUUID": config.GetMessageCode("PROGRAM_NOT_ELIGIBLE")}
+			return c.JSON(response)
+		}
+		request.NewThesisID = &newThesis.ID
+		approvals = append(approvals, advisorSteps(model.StepCurrentAdvisor, thesis.Advisors)...)
+		approvals = append(approvals, advisorSteps(model.StepNewAdvisor, newThesis.Advisors)...)
+
+	case model.ChangeAdvisor:
+		currentID := payload.CurrentAdvisorID
+		if currentID == 0 && len(thesis.Advisors) == 1 {
+			currentID = thesis.Advisors[0].ID
+		}
+		if !thesis.HasAdvisor(currentID) {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"currentAdvisorID": config.GetMessageCode("PARAM_ERROR")}
+			return c.JSON(response)
+		}
+		var newAdvisor modelAdvisor.Advisor
+		if err := db.First(&newAdvisor, payload.NewAdvisorID).Error; err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			response.ValidateError = map[string]string{"newAdvisorID": config.GetMessageCode("NOT_ID_EXISTS")}
+			return c.JSON(response)
+		}
+		if newAdvisor.ThesisID != 0 {
+			response.Status = false
+			response.Message = config.GetMessageCode("ADVISOR_ASSIGNED")
+			response.ValidateError = map[string]string{"newAdvisorID": config.GetMessageCode("ADVISOR_ASSIGNED")}
+			return c.JSON(response)
+		}
+		request.CurrentAdvisorID = &currentID
+		request.NewAdvisorID = &newAdvisor.ID
+		approvals = append(approvals,
+			model.ChangeApproval{Step: model.StepCurrentAdvisor, ApproverID: currentID, ApproverRole: modelUsers.AdvisorRole},
+			model.ChangeApproval{Step: model.StepNewAdvisor, ApproverID: newAdvisor.ID, ApproverRole: modelUsers.AdvisorRole},
+		)
+	}
+	approvals = append(approvals, model.ChangeApproval{Step: model.StepHeadOfSubject, ApproverRole: modelUsers.HeadOfSubjectRole})
+	for i := range approvals {
+		approvals[i].Decision = model.RequestPending
+		approvals[i].CreatedBy = tokenData.Code
+	}
+	request.Approvals = approvals
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Create(&request).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	recipients := approverRecipients(tx, request)
+	notification := modelNotification.Message{
+		Type:  modelNotification.NotificationChangeRequested,
+		Title: "Change request to approve",
+		Body:  fmt.Sprintf("%s requested %s on \"%s\": %s", tokenData.Code, describe(request.Type), thesis.TitleVi, request.Reason),
+		Link:  fmt.Sprintf("/change-request/%d", request.ID),
+	}
+	if err := notificationController.Notify(tx, notification, recipients...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = request
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DecideChangeRequest giảng viên hoặc trưởng bộ môn duyệt bước của mình
+// @Summary Approve or reject a change request
+// @Description An advisor decides their own pending step, a head of subject of the thesis's subject (or the faculty office) decides the head of subject step. One rejection rejects the request; when every step is approved the change is applied and recorded in the audit log.
+// @Tags ChangeRequest
+// @Accept json
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Param body body model.DecideChangeRequest true "Decision: APPROVED or REJECTED"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /change-request/{id}/decision [put]
+func DecideChangeRequest(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.DecideChangeRequest
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	decision := strings.ToUpper(strings.TrimSpace(payload.Decision))
+	if decision != model.RequestApproved && decision != model.RequestRejected {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"decision": config.GetMessageCode("PARAM_ERROR")}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	// Khóa yêu cầu để hai người duyệt cùng lúc không áp dụng thay đổi hai lần
+	var request model.ChangeRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if err := tx.Where("REQUEST_ID = ?", request.ID).Order("ID").Find(&request.Approvals).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	if request.Status != model.RequestPending {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	// Bước trưởng bộ môn do trưởng bộ môn của subject của luận văn hoặc văn phòng khoa quản lý subject đó quyết định
+	headStep := false
+	if tokenData.Role == modelUsers.HeadOfSubjectRole || tokenData.Role == modelUsers.FacultyOfficeRole {
+		var thesis modelThesis.Thesis
+		headStep = tx.Select("ID, SUBJECT_ID").First(&thesis, request.ThesisID).Error == nil &&
+			organizationController.ScopeOf(tx, tokenData).Allows(thesis.SubjectID)
+	}
+
+	step := -1
+	for i, approval := range request.Approvals {
+		if approval.Decision != model.RequestPending {
+			continue
+		}
+		mine := (tokenData.Role == modelUsers.AdvisorRole && approval.ApproverRole == modelUsers.AdvisorRole && approval.ApproverID == tokenData.ID) ||
+			(headStep && approval.Step == model.StepHeadOfSubject)
+		if mine {
+			step = i
+			break
+		}
+	}
+	if step < 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	approval := &request.Approvals[step]
+	approval.Decision = decision
+	approval.ApproverID = tokenData.ID
+	approval.ApproverRole = tokenData.Role
+	approval.DecidedBy = tokenData.Code
+	approval.Comment = payload.Comment
+	approval.DecidedAt = &now
+	approval.UpdatedBy = tokenData.Code
+	if err := tx.Save(approval).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	approved, rejected := request.Decided()
+	if approved || rejected {
+		if approved {
+			if err := applyChange(tx, &request, now); err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = err.Error()
+				return c.JSON(response)
+			}
+			request.Status = model.RequestApproved
+		} else {
+			request.Status = model.RequestRejected
+		}
+		request.DecidedAt = &now
+		request.UpdatedBy = tokenData.Code
+		if err := tx.Model(&request).Updates(map[string]interface{}{
+			"STATUS":     request.Status,
+			"DECIDED_AT": now,
+			"UPDATED_BY": tokenData.Code,
+		}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+
+		recipients := append(approverRecipients(tx, request), modelNotification.Recipient{UserID: request.StudentID, Role: modelUsers.StudentRole})
+		notification := modelNotification.Message{
+			Type:  modelNotification.NotificationChangeDecided,
+			Title: "Change request " + strings.ToLower(request.Status),
+			Body:  fmt.Sprintf("The %s request was %s", describe(request.Type), strings.ToLower(request.Status)),
+			Link:  fmt.Sprintf("/change-request/%d", request.ID),
+		}
+		if rejected && payload.Comment != "" {
+			notification.Body += ": " + payload.Comment
+		}
+		if err := notificationController.Notify(tx, notification, recipients...); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Data = request
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// CancelChangeRequest sinh viên hủy yêu cầu đang chờ duyệt
+// @Summary Cancel a change request
+// @Description The student who sent a pending request withdraws it
+// @Tags ChangeRequest
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /change-request/{id}/cancel [put]
+func CancelChangeRequest(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var request model.ChangeRequest
+	if err := db.First(&request, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if tokenData.Role != modelUsers.StudentRole || request.StudentID != tokenData.ID {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	// Chỉ hủy khi yêu cầu vẫn đang chờ, tránh ghi đè quyết định vừa được đưa ra
+	result := db.Model(&model.ChangeRequest{}).Where("ID = ? AND STATUS = ?", request.ID, model.RequestPending).
+		Updates(map[string]interface{}{"STATUS": model.RequestCancelled, "DECIDED_AT": core.Now(), "UPDATED_BY": tokenData.Code})
+	if result.Error != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if result.RowsAffected == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetChangeAudits trả về nhật ký các thay đổi đã áp dụng
+// @Summary Change audit log
+// @Description Applied withdrawals, topic changes and advisor transfers on theses of the caller's subject or faculty (head of subject and faculty office)
+// @Tags ChangeRequest
+// @Produce json
+// @Param studentID query int false "Student ID"
+// @Param thesisID query int false "Thesis ID, matches the old or the new thesis"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /change-request/audit [get]
+func GetChangeAudits(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || (tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	query := database.DB.Order("APPLIED_AT DESC").Where("FROM_THESIS_ID IN (?)", scopedTheses(database.DB, tokenData))
+	if studentID, err := strconv.Atoi(c.Query("studentID")); err == nil {
+		query = query.Where("STUDENT_ID = ?", studentID)
+	}
+	if thesisID, err := strconv.Atoi(c.Query("thesisID")); err == nil {
+		query = query.Where("(FROM_THESIS_ID = ? OR TO_THESIS_ID = ?)", thesisID, thesisID)
+	}
+
+	var audits []model.ChangeAudit
+	if err := query.Find(&audits).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = audits
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// applyChange áp dụng yêu cầu đã được duyệt và ghi nhật ký. Dữ liệu được kiểm tra lại
+// vì luận văn hoặc giảng viên có thể đã thay đổi trong lúc chờ duyệt.
+func applyChange(tx *gorm.DB, request *model.ChangeRequest, now time.Time) error {
+	var student modelStudent.Student
+	if err := tx.First(&student, request.StudentID).Error; err != nil {
+		return errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+	}
+	if student.ThesisID != request.ThesisID {
+		return errors.New(config.GetMessageCode("INVALID_STATUS_TRANSITION"))
+	}
+
+	audit := model.ChangeAudit{
+		RequestID:     request.ID,
+		Type:          request.Type,
+		StudentID:     request.StudentID,
+		FromThesisID:  request.ThesisID,
+		FromAdvisorID: request.CurrentAdvisorID,
+		ToAdvisorID:   request.NewAdvisorID,
+		Reason:        request.Reason,
+		AppliedAt:     now,
+	}
+
+	switch request.Type {
+	case model.ChangeWithdrawal:
+		if err := tx.Model(&student).UpdateColumn("THESIS_ID", nil).Error; err != nil {
+			return err
+		}
+
+	case model.ChangeTopic:
+		// Khóa đề tài mới rồi kiểm tra lại: sinh viên khác có thể đã nhận đề tài hoặc đề tài đã đổi chương trình trong lúc chờ duyệt
+		var target modelThesis.Thesis
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("ID").First(&target, *request.NewThesisID).Error; err != nil {
+			return errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+		}
+		if !openThesis(tx, target.ID) {
+			return errors.New(config.GetMessageCode("THESIS_NOT_OPEN"))
+		}
+		if !thesisController.StudentEligible(tx, target.ID, student) {
+			return errors.New(config.GetMessageCode("PROGRAM_NOT_ELIGIBLE"))
+		}
+		if err := tx.Model(&student).UpdateColumn("THESIS_ID", *request.NewThesisID).Error; err != nil {
+			return err
+		}
+		audit.ToThesisID = request.NewThesisID
+
+	case model.ChangeAdvisor:
+		var current, next modelAdvisor.Advisor
+		if err := tx.First(&current, *request.CurrentAdvisorID).Error; err != nil || current.ThesisID != request.ThesisID {
+			return errors.New(config.GetMessageCode("INVALID_STATUS_TRANSITION"))
+		}
+		if err := tx.First(&next, *request.NewAdvisorID).Error; err != nil {
+			return errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+		}
+		if next.ThesisID != 0 {
+			return errors.New(config.GetMessageCode("ADVISOR_ASSIGNED"))
+		}
+		if err := tx.Model(&current).UpdateColumn("THESIS_ID", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&next).UpdateColumn("THESIS_ID", request.ThesisID).Error; err != nil {
+			return err
+		}
+		audit.ToThesisID = &request.ThesisID
+	}
+
+	approvedBy := []string{}
+	for _, approval := range request.Approvals {
+		approvedBy = append(approvedBy, approval.Step+":"+approval.DecidedBy)
+	}
+	audit.ApprovedBy = strings.Join(approvedBy, ", ")
+	audit.CreatedBy = request.CreatedBy
+	return tx.Create(&audit).Error
+}
+
+// openThesis kiểm tra đề tài còn mở (đã duyệt và chưa có sinh viên) để chuyển sang
+func openThesis(db *gorm.DB, thesisID uint) bool {
+	var count int64
+	if err := thesisController.OpenThesesQuery(db).Where("ID = ?", thesisID).Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// requestDeadline trả về hạn gửi yêu cầu theo lịch học kỳ của luận văn; nil nếu học kỳ chưa có trong lịch
+func requestDeadline(db *gorm.DB, thesis modelThesis.Thesis, changeType string) *time.Time {
+	semester, err := semesterController.Lookup(db, thesis.Semester)
+	if err != nil {
+		return nil
+	}
+	deadline := semester.EndDate
+	switch {
+	case changeType == model.ChangeWithdrawal && semester.WithdrawalDeadline != nil:
+		deadline = *semester.WithdrawalDeadline
+	case changeType == model.ChangeTopic && semester.TopicChangeDeadline != nil:
+		deadline = *semester.TopicChangeDeadline
+	case changeType == model.ChangeAdvisor && semester.AdvisorChangeDeadline != nil:
+		deadline = *semester.AdvisorChangeDeadline
+	}
+	return &deadline
+}
+
+func advisorSteps(step string, advisors []modelAdvisor.Advisor) []model.ChangeApproval {
+	approvals := []model.ChangeApproval{}
+	for _, advisor := range advisors {
+		approvals = append(approvals, model.ChangeApproval{Step: step, ApproverID: advisor.ID, ApproverRole: modelUsers.AdvisorRole})
+	}
+	return approvals
+}
+
+// approverRecipients: giảng viên trong các bước duyệt và trưởng bộ môn của subject của luận văn
+func approverRecipients(db *gorm.DB, request model.ChangeRequest) []modelNotification.Recipient {
+	recipients := []modelNotification.Recipient{}
+	for _, approval := range request.Approvals {
+		if approval.ApproverRole == modelUsers.AdvisorRole {
+			recipients = append(recipients, modelNotification.Recipient{UserID: approval.ApproverID, Role: modelUsers.AdvisorRole})
+		}
+	}
+	var thesis modelThesis.Thesis
+	if err := db.Select("ID, SUBJECT_ID").First(&thesis, request.ThesisID).Error; err != nil || thesis.SubjectID == nil {
+		return recipients
+	}
+	var heads []modelHeadOfSubject.HeadOfSubject
+	db.Select("ID").Where("SUBJECT_ID = ?", *thesis.SubjectID).Find(&heads)
+	for _, head := range heads {
+		recipients = append(recipients, modelNotification.Recipient{UserID: head.ID, Role: modelUsers.HeadOfSubjectRole})
+	}
+	return recipients
+}
+
+// scopedTheses là truy vấn con ID các luận văn trong phạm vi của trưởng bộ môn hoặc văn phòng khoa
+func scopedTheses(db *gorm.DB, tokenData *utils.TokenData) *gorm.DB {
+	return organizationController.ScopeOf(db, tokenData).Apply(db.Model(&modelThesis.Thesis{}).Select("ID"), "SUBJECT_ID")
+}
+
+func canView(db *gorm.DB, tokenData *utils.TokenData, request model.ChangeRequest) bool {
+	switch tokenData.Role {
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		var thesis modelThesis.Thesis
+		return db.Select("ID, SUBJECT_ID").First(&thesis, request.ThesisID).Error == nil &&
+			organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+	case modelUsers.StudentRole:
+		return request.StudentID == tokenData.ID
+	case modelUsers.AdvisorRole:
+		for _, approval := range request.Approvals {
+			if approval.ApproverRole == modelUsers.AdvisorRole && approval.ApproverID == tokenData.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describe(changeType string) string {
+	switch changeType {
+	case model.ChangeWithdrawal:
+		return "thesis withdrawal"
+	case model.ChangeTopic:
+		return "topic change"
+	case model.ChangeAdvisor:
+		return "advisor transfer"
+	}
+	return changeType
+}
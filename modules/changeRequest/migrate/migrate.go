@@ -0,0 +1,16 @@
+package changeRequestMigrate
+
+import (
+	"app/database"
+	model "app/modules/changeRequest/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.ChangeRequest{})
+	db.AutoMigrate(&model.ChangeApproval{})
+	db.AutoMigrate(&model.ChangeAudit{})
+
+	return true
+}
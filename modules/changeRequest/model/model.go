@@ -0,0 +1,98 @@
+package model
+
+import (
+	"app/model"
+	"time"
+)
+
+var ChangeWithdrawal, ChangeTopic, ChangeAdvisor = "WITHDRAWAL", "TOPIC_CHANGE", "ADVISOR_TRANSFER"
+
+var RequestPending, RequestApproved, RequestRejected, RequestCancelled = "PENDING", "APPROVED", "REJECTED", "CANCELLED"
+
+var StepCurrentAdvisor, StepNewAdvisor, StepHeadOfSubject = "CURRENT_ADVISOR", "NEW_ADVISOR", "HEAD_OF_SUBJECT"
+
+// ChangeRequest là yêu cầu của sinh viên rút khỏi luận văn, đổi sang đề tài khác (NewThesisID)
+// hoặc đổi giảng viên hướng dẫn (CurrentAdvisorID sang NewAdvisorID).
+// Thay đổi chỉ được áp dụng khi mọi bước duyệt đã đồng ý.
+type ChangeRequest struct {
+	model.Header
+	Type             string           `json:"type" gorm:"column:TYPE;size:20;index"`
+	StudentID        uint             `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	ThesisID         uint             `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	NewThesisID      *uint            `json:"newThesisID" gorm:"column:NEW_THESIS_ID"`
+	CurrentAdvisorID *uint            `json:"currentAdvisorID" gorm:"column:CURRENT_ADVISOR_ID"`
+	NewAdvisorID     *uint            `json:"newAdvisorID" gorm:"column:NEW_ADVISOR_ID"`
+	Reason           string           `json:"reason" gorm:"column:REASON"`
+	Status           string           `json:"status" gorm:"column:STATUS;size:20;default:PENDING;index"`
+	DecidedAt        *time.Time       `json:"decidedAt" gorm:"column:DECIDED_AT"`
+	Approvals        []ChangeApproval `json:"approvals" gorm:"foreignKey:REQUEST_ID"`
+}
+
+// ChangeApproval là một bước duyệt. Bước của giảng viên gắn với một giảng viên cụ thể;
+// bước trưởng bộ môn có ApproverID = 0 cho tới khi một trưởng bộ môn quyết định.
+type ChangeApproval struct {
+	model.Header
+	RequestID    uint       `json:"requestID" gorm:"column:REQUEST_ID;index"`
+	Step         string     `json:"step" gorm:"column:STEP;size:20"`
+	ApproverID   uint       `json:"approverID" gorm:"column:APPROVER_ID;index"`
+	ApproverRole int        `json:"approverRole" gorm:"column:APPROVER_ROLE"`
+	Decision     string     `json:"decision" gorm:"column:DECISION;size:20;default:PENDING"`
+	DecidedBy    string     `json:"decidedBy" gorm:"column:DECIDED_BY;size:50"`
+	Comment      string     `json:"comment" gorm:"column:COMMENT"`
+	DecidedAt    *time.Time `json:"decidedAt" gorm:"column:DECIDED_AT"`
+}
+
+// ChangeAudit ghi lại thay đổi đã áp dụng, không sửa hoặc xóa
+type ChangeAudit struct {
+	model.Header
+	RequestID     uint      `json:"requestID" gorm:"column:REQUEST_ID;index"`
+	Type          string    `json:"type" gorm:"column:TYPE;size:20"`
+	StudentID     uint      `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	FromThesisID  uint      `json:"fromThesisID" gorm:"column:FROM_THESIS_ID;index"`
+	ToThesisID    *uint     `json:"toThesisID" gorm:"column:TO_THESIS_ID"`
+	FromAdvisorID *uint     `json:"fromAdvisorID" gorm:"column:FROM_ADVISOR_ID"`
+	ToAdvisorID   *uint     `json:"toAdvisorID" gorm:"column:TO_ADVISOR_ID"`
+	Reason        string    `json:"reason" gorm:"column:REASON"`
+	ApprovedBy    string    `json:"approvedBy" gorm:"column:APPROVED_BY"`
+	AppliedAt     time.Time `json:"appliedAt" gorm:"column:APPLIED_AT"`
+}
+
+type CreateChangeRequest struct {
+	Type             string `json:"type" validate:"required"`
+	Reason           string `json:"reason" validate:"required"`
+	NewThesisUUID    string `json:"newThesisUUID"`
+	CurrentAdvisorID uint   `json:"currentAdvisorID"`
+	NewAdvisorID     uint   `json:"newAdvisorID"`
+}
+
+type DecideChangeRequest struct {
+	Decision string `json:"decision" validate:"required"`
+	Comment  string `json:"comment"`
+}
+
+// Decided: mọi bước đã đồng ý (true, false), hoặc có bước từ chối (false, true)
+func (r ChangeRequest) Decided() (approved bool, rejected bool) {
+	approved = len(r.Approvals) > 0
+	for _, approval := range r.Approvals {
+		switch approval.Decision {
+		case RequestRejected:
+			return false, true
+		case RequestApproved:
+		default:
+			approved = false
+		}
+	}
+	return approved, false
+}
+
+func (ChangeRequest) TableName() string {
+	return "TBL_CHANGE_REQUEST"
+}
+
+func (ChangeApproval) TableName() string {
+	return "TBL_CHANGE_APPROVAL"
+}
+
+func (ChangeAudit) TableName() string {
+	return "TBL_CHANGE_AUDIT"
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"app/modules/changeRequest/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitChangeRequestRoutes(app *fiber.App) {
+	changeRequest := app.Group("/change-request")
+
+	changeRequest.Get("/", controller.GetChangeRequests)
+	changeRequest.Get("/audit", controller.GetChangeAudits)
+	changeRequest.Get("/:id", controller.GetChangeRequest)
+
+	changeRequest.Post("/", controller.CreateChangeRequest)
+	changeRequest.Put("/:id/decision", controller.DecideChangeRequest)
+	changeRequest.Put("/:id/cancel", controller.CancelChangeRequest)
+}
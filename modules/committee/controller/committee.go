@@ -0,0 +1,686 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/committee/model"
+	"app/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelCouncil "app/modules/council/model"
+	modelHeadOfSubject "app/modules/headOfSubject/model"
+	organizationController "app/modules/organization/controller"
+	semesterController "app/modules/semester/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Committee API
+// @version 1.0
+// @description Defense committees, their members and the theses they examine
+// @termsOfService http://swagger.io/terms/
+// @BasePath /committee
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetCommittees trả về các hội đồng bảo vệ
+// @Summary List defense committees
+// @Description Committees with their members, optionally of one semester. Heads of subject only see the committees of their subject.
+// @Tags Committee
+// @Produce json
+// @Param semester query string false "Semester"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee [get]
+func GetCommittees(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	query := db.Preload("Members").Order("SEMESTER DESC, CODE")
+	if tokenData, err := utils.ExtractTokenData(c); err == nil && tokenData.Role == modelUsers.HeadOfSubjectRole {
+		query = organizationController.ScopeOf(db, tokenData).Apply(query, "SUBJECT_ID")
+	}
+	if semester := strings.TrimSpace(c.Query("semester")); semester != "" {
+		query = query.Where("UPPER(SEMESTER) = ?", strings.ToUpper(semester))
+	}
+
+	var committees []model.Committee
+	if err := query.Find(&committees).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = committees
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetCommittee trả về một hội đồng cùng thành viên và các luận văn được gán
+// @Summary Get a defense committee
+// @Description Committee with its members, the assigned theses and the composition problems that remain, if any
+// @Tags Committee
+// @Produce json
+// @Param id path int true "Committee ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee/{id} [get]
+func GetCommittee(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	var committee model.Committee
+	if err := database.DB.Preload("Members").Preload("Theses").Preload("Theses.Students").Preload("Theses.Advisors").
+		First(&committee, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Data = committee
+	if errors := committee.Composition(); len(errors) > 0 {
+		response.ValidateError = errors
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateCommittee tạo hội đồng bảo vệ cho một học kỳ
+// @Summary Create a defense committee
+// @Description Create a committee of a semester, optionally with its members. A head of subject creates committees of their own subject; the faculty office may leave the subject empty for a faculty-wide committee.
+// @Tags Committee
+// @Accept json
+// @Produce json
+// @Param body body model.CreateCommittee true "Committee"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee [post]
+func CreateCommittee(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateCommittee
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	vItem := map[string]string{"semester": strings.TrimSpace(payload.Semester), "code": strings.TrimSpace(payload.Code)}
+	errors := utils.RequireCheck([]string{"semester", "code"}, vItem, map[string]string{})
+	errors = utils.MaxLengthCheck([]string{"code:20"}, vItem, errors)
+	semester, err := semesterController.Lookup(db, vItem["semester"])
+	if vItem["semester"] != "" && err != nil {
+		errors["semester"] = config.GetMessageCode("NOT_ID_EXISTS")
+	}
+	subjectID := subjectOf(db, tokenData, payload.SubjectID)
+	if payload.SubjectID != 0 && !organizationController.SubjectExists(db, payload.SubjectID) {
+		errors["subjectID"] = config.GetMessageCode("NOT_ID_EXISTS")
+	} else if !organizationController.ScopeOf(db, tokenData).Allows(subjectID) {
+		errors["subjectID"] = config.GetMessageCode("PERMISSION_DENIED")
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	code := strings.ToUpper(vItem["code"])
+	var count int64
+	db.Model(&model.Committee{}).Where("SEMESTER = ? AND CODE = ?", semester.Code, code).Count(&count)
+	if count > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"code": "code is already used in this semester"}
+		return c.JSON(response)
+	}
+
+	members, errors := resolveMembers(db, payload.Members)
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	committee := model.Committee{Semester: semester.Code, Code: code, Name: strings.TrimSpace(payload.Name), SubjectID: subjectID}
+	committee.ID = payload.ID
+	committee.CreatedBy = tokenData.Code
+	if err := tx.Omit("Members", "Theses").Create(&committee).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	for i := range members {
+		members[i].CommitteeID = committee.ID
+		members[i].CreatedBy = tokenData.Code
+	}
+	if len(members) > 0 {
+		if err := tx.Create(&members).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+	committee.Members = members
+
+	response.Data = committee
+	if errors := committee.Composition(); len(errors) > 0 {
+		response.ValidateError = errors
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateCommittee sửa mã, tên hoặc subject của hội đồng
+// @Summary Update a defense committee
+// @Description Update the code, name or subject of a committee
+// @Tags Committee
+// @Accept json
+// @Produce json
+// @Param id path int true "Committee ID"
+// @Param body body model.UpdateCommittee true "Committee"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee/{id} [put]
+func UpdateCommittee(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.UpdateCommittee
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var committee model.Committee
+	if err := db.First(&committee, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	scope := organizationController.ScopeOf(db, tokenData)
+	if !scope.Allows(committee.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	values := map[string]interface{}{"UPDATED_BY": tokenData.Code}
+	if code := strings.ToUpper(strings.TrimSpace(payload.Code)); code != "" && code != committee.Code {
+		var count int64
+		db.Model(&model.Committee{}).Where("SEMESTER = ? AND CODE = ? AND ID <> ?", committee.Semester, code, committee.ID).Count(&count)
+		if count > 0 || len(code) > 20 {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"code": "code is already used in this semester"}
+			return c.JSON(response)
+		}
+		values["CODE"] = code
+	}
+	if name := strings.TrimSpace(payload.Name); name != "" {
+		values["NAME"] = name
+	}
+	if payload.SubjectID != 0 {
+		if !organizationController.SubjectExists(db, payload.SubjectID) || !scope.Allows(&payload.SubjectID) {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"subjectID": config.GetMessageCode("NOT_ID_EXISTS")}
+			return c.JSON(response)
+		}
+		values["SUBJECT_ID"] = payload.SubjectID
+	}
+
+	if err := db.Model(&committee).Updates(values).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	db.Preload("Members").First(&committee, committee.ID)
+	response.Data = committee
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteCommittee xóa hội đồng; các luận văn đã gán được gỡ khỏi hội đồng
+// @Summary Delete a defense committee
+// @Description Delete a committee and its members. Assigned theses become unassigned.
+// @Tags Committee
+// @Produce json
+// @Param id path int true "Committee ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee/{id} [delete]
+func DeleteCommittee(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var committee model.Committee
+	if err := db.First(&committee, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !organizationController.ScopeOf(db, tokenData).Allows(committee.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	deleted := map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}
+	if err := tx.Model(&modelThesis.Thesis{}).Where("COMMITTEE_ID = ?", committee.ID).Update("COMMITTEE_ID", nil).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&model.CommitteeMember{}).Where("COMMITTEE_ID = ?", committee.ID).Updates(deleted).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&committee).Updates(deleted).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// SetCommitteeMembers thay toàn bộ thành viên của hội đồng
+// @Summary Set committee members
+// @Description Replace the members of a committee. Each member is an advisor (memberRole 2), head of subject (3) or council member (5) holding the role CHAIR, SECRETARY, MEMBER or REVIEWER. Once theses are assigned the committee must keep its minimum composition and may not include an advisor of any of those theses.
+// @Tags Committee
+// @Accept json
+// @Produce json
+// @Param id path int true "Committee ID"
+// @Param body body []model.MemberRecord true "Members"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee/{id}/members [put]
+func SetCommitteeMembers(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []model.MemberRecord
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	members, errors := resolveMembers(db, payload)
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	var committee model.Committee
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&committee, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !organizationController.ScopeOf(tx, tokenData).Allows(committee.SubjectID) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var thesisIDs []uint
+	tx.Model(&modelThesis.Thesis{}).Where("COMMITTEE_ID = ?", committee.ID).Pluck("ID", &thesisIDs)
+	if len(thesisIDs) > 0 {
+		committee.Members = members
+		if errors := committee.Composition(); len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("COMMITTEE_INCOMPLETE")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+		if errors := conflicts(tx, members, thesisIDs); len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("CONFLICT_OF_INTEREST")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+	}
+
+	if err := tx.Where("COMMITTEE_ID = ?", committee.ID).Delete(&model.CommitteeMember{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	for i := range members {
+		members[i].CommitteeID = committee.ID
+		members[i].CreatedBy = tokenData.Code
+	}
+	if len(members) > 0 {
+		if err := tx.Create(&members).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+	committee.Members = members
+
+	response.Data = committee
+	if errors := committee.Composition(); len(errors) > 0 {
+		response.ValidateError = errors
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AssignCommitteeTheses gán luận văn vào hội đồng
+// @Summary Assign theses to a committee
+// @Description Assign approved theses of the committee's semester to a committee that meets its minimum composition. A thesis already assigned elsewhere is moved. Fails for every thesis when one of them has an advisor on the committee.
+// @Tags Committee
+// @Accept json
+// @Produce json
+// @Param id path int true "Committee ID"
+// @Param body body model.AssignTheses true "Thesis IDs"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee/{id}/theses [put]
+func AssignCommitteeTheses(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.AssignTheses
+	if err := c.BodyParser(&payload); err != nil || len(payload.ThesisIDs) == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	var committee model.Committee
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Members").First(&committee, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	scope := organizationController.ScopeOf(tx, tokenData)
+	if !scope.Allows(committee.SubjectID) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if errors := committee.Composition(); len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("COMMITTEE_INCOMPLETE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	var theses []modelThesis.Thesis
+	tx.Where("ID IN ?", payload.ThesisIDs).Find(&theses)
+	found := map[uint]modelThesis.Thesis{}
+	for _, thesis := range theses {
+		found[thesis.ID] = thesis
+	}
+	errors := map[string]string{}
+	for _, id := range payload.ThesisIDs {
+		key := fmt.Sprint(id)
+		thesis, ok := found[id]
+		switch {
+		case !ok:
+			errors[key] = config.GetMessageCode("NOT_ID_EXISTS")
+		case thesis.ApprovalStatus != modelThesis.ApprovalApproved:
+			errors[key] = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		case !strings.EqualFold(thesis.Semester, committee.Semester):
+			errors[key] = fmt.Sprintf("thesis belongs to semester %s", thesis.Semester)
+		case !scope.Allows(thesis.SubjectID):
+			errors[key] = config.GetMessageCode("PERMISSION_DENIED")
+		}
+	}
+	if len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+	if errors := conflicts(tx, committee.Members, payload.ThesisIDs); len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("CONFLICT_OF_INTEREST")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	if err := tx.Model(&modelThesis.Thesis{}).Where("ID IN ?", payload.ThesisIDs).Updates(map[string]interface{}{
+		"COMMITTEE_ID": committee.ID,
+		"UPDATED_BY":   tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx.Preload("Members").Preload("Theses").First(&committee, committee.ID)
+	response.Data = committee
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UnassignCommitteeThesis gỡ một luận văn khỏi hội đồng
+// @Summary Remove a thesis from a committee
+// @Description Remove a thesis from a committee
+// @Tags Committee
+// @Produce json
+// @Param id path int true "Committee ID"
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /committee/{id}/theses/{thesisID} [delete]
+func UnassignCommitteeThesis(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var committee model.Committee
+	if err := db.First(&committee, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !organizationController.ScopeOf(db, tokenData).Allows(committee.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	result := db.Model(&modelThesis.Thesis{}).Where("ID = ? AND COMMITTEE_ID = ?", c.Params("thesisID"), committee.ID).
+		Updates(map[string]interface{}{"COMMITTEE_ID": nil, "UPDATED_BY": tokenData.Code})
+	if result.Error != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if result.RowsAffected == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// canManage: trưởng bộ môn và văn phòng khoa lập hội đồng trong phạm vi của mình
+func canManage(tokenData *utils.TokenData) bool {
+	return tokenData.Role == modelUsers.HeadOfSubjectRole || tokenData.Role == modelUsers.FacultyOfficeRole
+}
+
+// subjectOf: subject được chọn, hoặc subject của trưởng bộ môn khi không chọn
+func subjectOf(db *gorm.DB, tokenData *utils.TokenData, subjectID uint) *uint {
+	if subjectID != 0 {
+		return &subjectID
+	}
+	return organizationController.DefaultSubject(db, tokenData)
+}
+
+// resolveMembers kiểm tra vai trò và tìm tài khoản của từng thành viên; một người chỉ giữ một vai trò
+func resolveMembers(db *gorm.DB, records []model.MemberRecord) ([]model.CommitteeMember, map[string]string) {
+	members := []model.CommitteeMember{}
+	errors := map[string]string{}
+	seen := map[string]bool{}
+	for i, record := range records {
+		key := fmt.Sprintf("members[%d]", i)
+		role := strings.ToUpper(strings.TrimSpace(record.Role))
+		if !model.ValidRole(role) {
+			errors[key] = config.GetMessageCode("PARAM_ERROR")
+			continue
+		}
+
+		var info struct {
+			Code     string
+			FullName string
+		}
+		var table interface{}
+		switch record.MemberRole {
+		case modelUsers.AdvisorRole:
+			table = &modelAdvisor.Advisor{}
+		case modelUsers.HeadOfSubjectRole:
+			table = &modelHeadOfSubject.HeadOfSubject{}
+		case modelUsers.CouncilRole:
+			table = &modelCouncil.Council{}
+		default:
+			errors[key] = config.GetMessageCode("PARAM_ERROR")
+			continue
+		}
+		if err := db.Model(table).Select("CODE, FULL_NAME").Where("ID = ?", record.MemberID).Take(&info).Error; err != nil {
+			errors[key] = config.GetMessageCode("NOT_ID_EXISTS")
+			continue
+		}
+		person := info.Code
+		if person == "" {
+			person = fmt.Sprintf("%d/%d", record.MemberRole, record.MemberID)
+		}
+		if seen[person] {
+			errors[key] = "member appears more than once"
+			continue
+		}
+		seen[person] = true
+
+		members = append(members, model.CommitteeMember{
+			MemberID:   record.MemberID,
+			MemberRole: record.MemberRole,
+			MemberCode: info.Code,
+			FullName:   info.FullName,
+			Role:       role,
+		})
+	}
+	return members, errors
+}
+
+// conflicts trả về các luận văn có giảng viên hướng dẫn ngồi trong hội đồng, theo ID luận văn.
+// Giảng viên được so theo mã cán bộ để bắt cả trường hợp cùng người có tài khoản ở bảng khác.
+func conflicts(db *gorm.DB, members []model.CommitteeMember, thesisIDs []uint) map[string]string {
+	errors := map[string]string{}
+	if len(members) == 0 || len(thesisIDs) == 0 {
+		return errors
+	}
+
+	var advisors []modelAdvisor.Advisor
+	db.Select("ID, CODE, FULL_NAME, THESIS_ID").Where("THESIS_ID IN ?", thesisIDs).Find(&advisors)
+	for _, advisor := range advisors {
+		for _, member := range members {
+			if (member.MemberCode != "" && member.MemberCode == advisor.Code) || (member.MemberRole == modelUsers.AdvisorRole && member.MemberID == advisor.ID) {
+				errors[fmt.Sprint(advisor.ThesisID)] = fmt.Sprintf("advisor %s (%s) is %s of the committee", advisor.FullName, advisor.Code, strings.ToLower(member.Role))
+			}
+		}
+	}
+	return errors
+}
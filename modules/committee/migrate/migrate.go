@@ -0,0 +1,15 @@
+package committeeMigrate
+
+import (
+	"app/database"
+	model "app/modules/committee/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Committee{})
+	db.AutoMigrate(&model.CommitteeMember{})
+
+	return true
+}
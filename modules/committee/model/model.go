@@ -0,0 +1,96 @@
+package model
+
+import (
+	"app/model"
+	"fmt"
+
+	modelThesis "app/modules/thesis/model"
+)
+
+var RoleChair, RoleSecretary, RoleMember, RoleReviewer = "CHAIR", "SECRETARY", "MEMBER", "REVIEWER"
+
+// Thành phần tối thiểu của một hội đồng: đúng một chủ tịch, một thư ký, ít nhất một phản biện
+// và tổng số thành viên không dưới MinMembers
+var MinMembers, MinReviewers = 3, 1
+
+// Committee là hội đồng bảo vệ của một học kỳ. Luận văn được gán vào hội đồng qua THESIS.COMMITTEE_ID.
+type Committee struct {
+	model.Header
+	Semester  string               `json:"semester" gorm:"column:SEMESTER;size:20;index"`
+	Code      string               `json:"code" gorm:"column:CODE;size:20"`
+	Name      string               `json:"name" gorm:"column:NAME"`
+	SubjectID *uint                `json:"subjectID" gorm:"column:SUBJECT_ID;index"`
+	Members   []CommitteeMember    `json:"members" gorm:"foreignKey:COMMITTEE_ID"`
+	Theses    []modelThesis.Thesis `json:"theses" gorm:"foreignKey:COMMITTEE_ID"`
+}
+
+// CommitteeMember là một giảng viên, trưởng bộ môn hoặc thành viên hội đồng (MemberRole là vai trò tài khoản)
+// giữ một vai trò trong hội đồng. MemberCode là mã cán bộ, dùng để kiểm tra xung đột lợi ích giữa các bảng.
+type CommitteeMember struct {
+	model.Header
+	CommitteeID uint   `json:"committeeID" gorm:"column:COMMITTEE_ID;index"`
+	MemberID    uint   `json:"memberID" gorm:"column:MEMBER_ID"`
+	MemberRole  int    `json:"memberRole" gorm:"column:MEMBER_ROLE"`
+	MemberCode  string `json:"memberCode" gorm:"column:MEMBER_CODE;size:10;index"`
+	FullName    string `json:"fullName" gorm:"column:FULL_NAME"`
+	Role        string `json:"role" gorm:"column:ROLE;size:20"`
+}
+
+type CreateCommittee struct {
+	ID        uint           `json:"id"`
+	Semester  string         `json:"semester" validate:"required"`
+	Code      string         `json:"code" validate:"required"`
+	Name      string         `json:"name"`
+	SubjectID uint           `json:"subjectID"`
+	Members   []MemberRecord `json:"members"`
+}
+
+type UpdateCommittee struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	SubjectID uint   `json:"subjectID"`
+}
+
+type MemberRecord struct {
+	MemberID   uint   `json:"memberID" validate:"required"`
+	MemberRole int    `json:"memberRole" validate:"required"`
+	Role       string `json:"role" validate:"required"`
+}
+
+type AssignTheses struct {
+	ThesisIDs []uint `json:"thesisIDs"`
+}
+
+// Composition kiểm tra thành phần tối thiểu, trả về lỗi theo vai trò (rỗng nếu hợp lệ)
+func (c Committee) Composition() map[string]string {
+	count := map[string]int{}
+	for _, member := range c.Members {
+		count[member.Role]++
+	}
+	errors := map[string]string{}
+	if count[RoleChair] != 1 {
+		errors[RoleChair] = "exactly one chair is required"
+	}
+	if count[RoleSecretary] != 1 {
+		errors[RoleSecretary] = "exactly one secretary is required"
+	}
+	if count[RoleReviewer] < MinReviewers {
+		errors[RoleReviewer] = "at least one reviewer is required"
+	}
+	if len(c.Members) < MinMembers {
+		errors["members"] = fmt.Sprintf("at least %d members are required", MinMembers)
+	}
+	return errors
+}
+
+func ValidRole(role string) bool {
+	return role == RoleChair || role == RoleSecretary || role == RoleMember || role == RoleReviewer
+}
+
+func (Committee) TableName() string {
+	return "TBL_COMMITTEE"
+}
+
+func (CommitteeMember) TableName() string {
+	return "TBL_COMMITTEE_MEMBER"
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"app/modules/committee/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitCommitteeRoutes(app *fiber.App) {
+	committee := app.Group("/committee")
+
+	committee.Get("/", controller.GetCommittees)
+	committee.Get("/:id", controller.GetCommittee)
+
+	committee.Post("/", controller.CreateCommittee)
+	committee.Put("/:id", controller.UpdateCommittee)
+	committee.Put("/:id/members", controller.SetCommitteeMembers)
+	committee.Put("/:id/theses", controller.AssignCommitteeTheses)
+	committee.Delete("/:id/theses/:thesisID", controller.UnassignCommitteeThesis)
+	committee.Delete("/:id", controller.DeleteCommittee)
+}
@@ -0,0 +1,916 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/defense/model"
+	"app/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	modelCommittee "app/modules/committee/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	organizationController "app/modules/organization/controller"
+	reviewerController "app/modules/reviewer/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Defense API
+// @version 1.0
+// @description Rooms, defense sessions and defense slots
+// @termsOfService http://swagger.io/terms/
+// @BasePath /defense
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetRooms trả về danh sách phòng
+// @Summary List rooms
+// @Description Rooms available for defenses; add all=true to include deactivated rooms
+// @Tags Defense
+// @Produce json
+// @Param all query bool false "Include deactivated rooms"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/room [get]
+func GetRooms(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	query := database.DB.Order("CODE")
+	if c.Query("all") != "true" {
+		query = query.Where("ACTIVE = ?", true)
+	}
+	var rooms []model.Room
+	if err := query.Find(&rooms).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = rooms
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateRoom thêm phòng
+// @Summary Create a room
+// @Description Create a room for defenses. Faculty office only.
+// @Tags Defense
+// @Accept json
+// @Produce json
+// @Param body body model.CreateRoom true "Room"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/room [post]
+func CreateRoom(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateRoom
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	vItem := map[string]string{"code": strings.ToUpper(strings.TrimSpace(payload.Code))}
+	errors := utils.RequireCheck([]string{"code"}, vItem, map[string]string{})
+	errors = utils.MaxLengthCheck([]string{"code:20"}, vItem, errors)
+	var count int64
+	db.Model(&model.Room{}).Where("CODE = ?", vItem["code"]).Count(&count)
+	if count > 0 {
+		errors["code"] = "code is already used by another room"
+	}
+	if payload.Capacity < 0 {
+		errors["capacity"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	room := model.Room{Code: vItem["code"], Name: strings.TrimSpace(payload.Name), Building: strings.TrimSpace(payload.Building), Capacity: payload.Capacity, Active: true}
+	room.ID = payload.ID
+	room.CreatedBy = tokenData.Code
+	if err := db.Create(&room).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = room
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateRoom sửa thông tin hoặc ngừng sử dụng phòng
+// @Summary Update a room
+// @Description Update a room or deactivate it (active=false); deactivated rooms cannot get new sessions. Faculty office only.
+// @Tags Defense
+// @Accept json
+// @Produce json
+// @Param id path int true "Room ID"
+// @Param body body model.UpdateRoom true "Room"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/room/{id} [put]
+func UpdateRoom(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.UpdateRoom
+	if err := c.BodyParser(&payload); err != nil || payload.Capacity < 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var room model.Room
+	if err := db.First(&room, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	values := map[string]interface{}{"UPDATED_BY": tokenData.Code}
+	if name := strings.TrimSpace(payload.Name); name != "" {
+		values["NAME"] = name
+	}
+	if building := strings.TrimSpace(payload.Building); building != "" {
+		values["BUILDING"] = building
+	}
+	if payload.Capacity > 0 {
+		values["CAPACITY"] = payload.Capacity
+	}
+	if payload.Active != nil {
+		values["ACTIVE"] = *payload.Active
+	}
+	if err := db.Model(&room).Updates(values).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	db.First(&room, room.ID)
+	response.Data = room
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteRoom xóa phòng chưa từng được xếp buổi bảo vệ
+// @Summary Delete a room
+// @Description Delete a room that has no defense session; rooms in use can only be deactivated. Faculty office only.
+// @Tags Defense
+// @Produce json
+// @Param id path int true "Room ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/room/{id} [delete]
+func DeleteRoom(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var room model.Room
+	if err := db.First(&room, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	var count int64
+	db.Model(&model.DefenseSession{}).Where("ROOM_ID = ?", room.ID).Count(&count)
+	if count > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("ROOM_IN_USE")
+		return c.JSON(response)
+	}
+
+	if err := db.Model(&room).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetSessions trả về các buổi bảo vệ
+// @Summary List defense sessions
+// @Description Defense sessions with their room, committee and slots, including unpublished ones. Heads of subject only see the sessions of their subject's committees.
+// @Tags Defense
+// @Produce json
+// @Param semester query string false "Semester"
+// @Param committee query int false "Committee ID"
+// @Param room query int false "Room ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/session [get]
+func GetSessions(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	committees := organizationController.ScopeOf(db, tokenData).Apply(db.Model(&modelCommittee.Committee{}).Select("ID"), "SUBJECT_ID")
+	query := db.Preload("Room").Preload("Committee.Members").Preload("Slots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("START_AT")
+	}).Preload("Slots.Thesis").Where("COMMITTEE_ID IN (?)", committees).Order("START_AT")
+	if semester := strings.TrimSpace(c.Query("semester")); semester != "" {
+		query = query.Where("UPPER(SEMESTER) = ?", strings.ToUpper(semester))
+	}
+	if committeeID := c.QueryInt("committee"); committeeID != 0 {
+		query = query.Where("COMMITTEE_ID = ?", committeeID)
+	}
+	if roomID := c.QueryInt("room"); roomID != 0 {
+		query = query.Where("ROOM_ID = ?", roomID)
+	}
+
+	var sessions []model.DefenseSession
+	if err := query.Find(&sessions).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = sessions
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetSession trả về một buổi bảo vệ cùng các lịch đang trùng của buổi đó
+// @Summary Get a defense session
+// @Description Defense session with its room, committee and slots; validate_error lists the conflicts it currently has
+// @Tags Defense
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/session/{id} [get]
+func GetSession(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	session, err := loadSession(db, c.Params("id"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Data = session
+	if conflicts := findConflicts(bookingsBetween(db, session.StartAt, session.EndAt), session.ID); len(conflicts) > 0 {
+		response.ValidateError = conflicts
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateSession xếp một buổi bảo vệ cho hội đồng tại một phòng
+// @Summary Create a defense session
+// @Description Create a session of a committee in a room from startAt to endAt, split into slots of slotMinutes (default 45). Rejected when the room or a committee member is already booked at that time.
+// @Tags Defense
+// @Accept json
+// @Produce json
+// @Param body body model.CreateSession true "Session"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/session [post]
+func CreateSession(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateSession
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	errors := map[string]string{}
+	var committee modelCommittee.Committee
+	if err := db.First(&committee, payload.CommitteeID).Error; err != nil {
+		errors["committeeID"] = config.GetMessageCode("NOT_ID_EXISTS")
+	} else if !organizationController.ScopeOf(db, tokenData).Allows(committee.SubjectID) {
+		errors["committeeID"] = config.GetMessageCode("PERMISSION_DENIED")
+	}
+	if !activeRoom(db, payload.RoomID) {
+		errors["roomID"] = config.GetMessageCode("NOT_ID_EXISTS")
+	}
+	session := model.DefenseSession{CommitteeID: payload.CommitteeID, RoomID: payload.RoomID, Semester: committee.Semester}
+	for key, message := range applyTimes(&session, payload.StartAt, payload.EndAt, payload.SlotMinutes) {
+		errors[key] = message
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	session.ID = payload.ID
+	session.CreatedBy = tokenData.Code
+	if err := tx.Omit(clause.Associations).Create(&session).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := createSlots(tx, &session, nil, tokenData.Code); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if conflicts := findConflicts(bookingsBetween(tx, session.StartAt, session.EndAt), session.ID); len(conflicts) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SCHEDULE_CONFLICT")
+		response.ValidateError = conflicts
+		return c.JSON(response)
+	}
+
+	session, _ = loadSession(tx, session.ID)
+	response.Data = session
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateSession đổi phòng hoặc thời gian của buổi bảo vệ
+// @Summary Update a defense session
+// @Description Move a session to another room or time. When the time or slot length changes the slots are rebuilt and the assigned theses keep their order. Rejected on conflicts. Participants of a published session are notified.
+// @Tags Defense
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param body body model.UpdateSession true "Session"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/session/{id} [put]
+func UpdateSession(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.UpdateSession
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	session, err := lockSession(tx, tokenData, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = err.Error()
+		return c.JSON(response)
+	}
+
+	errors := map[string]string{}
+	if payload.RoomID != 0 && payload.RoomID != session.RoomID {
+		if !activeRoom(tx, payload.RoomID) {
+			errors["roomID"] = config.GetMessageCode("NOT_ID_EXISTS")
+		}
+		session.RoomID = payload.RoomID
+	}
+	retime := payload.StartAt != "" || payload.EndAt != "" || (payload.SlotMinutes != 0 && payload.SlotMinutes != session.SlotMinutes)
+	if retime {
+		startAt, endAt, minutes := payload.StartAt, payload.EndAt, payload.SlotMinutes
+		if startAt == "" {
+			startAt = session.StartAt.Format(time.RFC3339)
+		}
+		if endAt == "" {
+			endAt = session.EndAt.Format(time.RFC3339)
+		}
+		if minutes == 0 {
+			minutes = session.SlotMinutes
+		}
+		for key, message := range applyTimes(&session, startAt, endAt, minutes) {
+			errors[key] = message
+		}
+	}
+	if len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	if err := tx.Model(&session).Omit(clause.Associations).Updates(map[string]interface{}{
+		"ROOM_ID":      session.RoomID,
+		"START_AT":     session.StartAt,
+		"END_AT":       session.EndAt,
+		"SLOT_MINUTES": session.SlotMinutes,
+		"UPDATED_BY":   tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	if retime {
+		// Giữ thứ tự các luận văn đã xếp khi chia lại lượt
+		assigned := []uint{}
+		for _, slot := range session.Slots {
+			if slot.ThesisID != nil {
+				assigned = append(assigned, *slot.ThesisID)
+			}
+		}
+		if len(assigned) > len(model.Split(session.StartAt, session.EndAt, session.SlotMinutes)) {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"endAt": fmt.Sprintf("%d theses are assigned and no longer fit in the session", len(assigned))}
+			return c.JSON(response)
+		}
+		if err := tx.Where("SESSION_ID = ?", session.ID).Delete(&model.DefenseSlot{}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		if err := createSlots(tx, &session, assigned, tokenData.Code); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	if conflicts := findConflicts(bookingsBetween(tx, session.StartAt, session.EndAt), session.ID); len(conflicts) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SCHEDULE_CONFLICT")
+		response.ValidateError = conflicts
+		return c.JSON(response)
+	}
+
+	session, _ = loadSession(tx, session.ID)
+	if session.Published {
+		if err := notifySession(tx, session, modelNotification.NotificationDefenseChanged, "Defense schedule changed"); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Data = session
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteSession hủy buổi bảo vệ
+// @Summary Delete a defense session
+// @Description Delete a session and its slots. Participants of a published session are notified.
+// @Tags Defense
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/session/{id} [delete]
+func DeleteSession(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	session, err := lockSession(tx, tokenData, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = err.Error()
+		return c.JSON(response)
+	}
+
+	deleted := map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}
+	if err := tx.Model(&model.DefenseSlot{}).Where("SESSION_ID = ?", session.ID).Updates(deleted).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&session).Omit(clause.Associations).Updates(deleted).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if session.Published {
+		if err := notifySession(tx, session, modelNotification.NotificationDefenseChanged, "Defense session cancelled"); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AssignSlot xếp một luận văn của hội đồng vào lượt bảo vệ
+// @Summary Assign a thesis to a defense slot
+// @Description Put a thesis of the session's committee into an empty slot (thesisID 0 empties the slot). A thesis already scheduled elsewhere is moved. Rejected when the reviewer's report of the thesis is not submitted yet, or when an advisor or student of the thesis is booked at that time.
+// @Tags Defense
+// @Accept json
+// @Produce json
+// @Param id path int true "Slot ID"
+// @Param body body model.AssignSlot true "Thesis"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/slot/{id} [put]
+func AssignSlot(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.AssignSlot
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	var slot model.DefenseSlot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&slot, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	session, err := lockSession(tx, tokenData, slot.SessionID)
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = err.Error()
+		return c.JSON(response)
+	}
+
+	var thesisID interface{}
+	if payload.ThesisID != 0 {
+		var thesis modelThesis.Thesis
+		if err := tx.Select("ID, COMMITTEE_ID").First(&thesis, payload.ThesisID).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+		if thesis.CommitteeID == nil || *thesis.CommitteeID != session.CommitteeID {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"thesisID": "thesis is not assigned to the committee of this session"}
+			return c.JSON(response)
+		}
+		if reviewerController.Pending(tx, []uint{thesis.ID})[thesis.ID] {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("REVIEW_PENDING")
+			response.ValidateError = map[string]string{"thesisID": config.GetMessageCode("REVIEW_PENDING")}
+			return c.JSON(response)
+		}
+		if slot.ThesisID != nil && *slot.ThesisID != thesis.ID {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"slot": "slot already has a thesis"}
+			return c.JSON(response)
+		}
+		if err := tx.Model(&model.DefenseSlot{}).Where("THESIS_ID = ? AND ID <> ?", thesis.ID, slot.ID).
+			Updates(map[string]interface{}{"THESIS_ID": nil, "UPDATED_BY": tokenData.Code}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		thesisID = thesis.ID
+	}
+
+	if err := tx.Model(&slot).Updates(map[string]interface{}{"THESIS_ID": thesisID, "UPDATED_BY": tokenData.Code}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if conflicts := findConflicts(bookingsBetween(tx, slot.StartAt, slot.EndAt), session.ID); len(conflicts) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SCHEDULE_CONFLICT")
+		response.ValidateError = conflicts
+		return c.JSON(response)
+	}
+
+	session, _ = loadSession(tx, session.ID)
+	if session.Published {
+		if err := notifySession(tx, session, modelNotification.NotificationDefenseChanged, "Defense schedule changed"); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Data = session
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// PublishSession công bố buổi bảo vệ cho hội đồng, giảng viên hướng dẫn và sinh viên
+// @Summary Publish a defense session
+// @Description Make a session visible in the personal schedules of its committee members, advisors and students, and notify them. A session with conflicts, or with a thesis whose review report is not submitted, cannot be published.
+// @Tags Defense
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/session/{id}/publish [put]
+func PublishSession(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	session, err := lockSession(tx, tokenData, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = err.Error()
+		return c.JSON(response)
+	}
+	if conflicts := findConflicts(bookingsBetween(tx, session.StartAt, session.EndAt), session.ID); len(conflicts) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SCHEDULE_CONFLICT")
+		response.ValidateError = conflicts
+		return c.JSON(response)
+	}
+	thesisIDs := []uint{}
+	for _, slot := range session.Slots {
+		if slot.ThesisID != nil {
+			thesisIDs = append(thesisIDs, *slot.ThesisID)
+		}
+	}
+	if pending := reviewerController.Pending(tx, thesisIDs); len(pending) > 0 {
+		errors := map[string]string{}
+		for thesisID := range pending {
+			errors[fmt.Sprint(thesisID)] = config.GetMessageCode("REVIEW_PENDING")
+		}
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("REVIEW_PENDING")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	if err := tx.Model(&session).Omit(clause.Associations).Updates(map[string]interface{}{
+		"PUBLISHED":    true,
+		"PUBLISHED_AT": now,
+		"UPDATED_BY":   tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	session.Published = true
+	session.PublishedAt = &now
+
+	if err := notifySession(tx, session, modelNotification.NotificationDefenseScheduled, "Defense scheduled"); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = session
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// canManage: trưởng bộ môn và văn phòng khoa xếp lịch bảo vệ cho các hội đồng trong phạm vi của mình
+func canManage(tokenData *utils.TokenData) bool {
+	return tokenData.Role == modelUsers.HeadOfSubjectRole || tokenData.Role == modelUsers.FacultyOfficeRole
+}
+
+func parseTime(value string) (time.Time, error) {
+	return core.ParseCampusTime(value)
+}
+
+func activeRoom(db *gorm.DB, id uint) bool {
+	var count int64
+	db.Model(&model.Room{}).Where("ID = ? AND ACTIVE = ?", id, true).Count(&count)
+	return count > 0
+}
+
+// applyTimes kiểm tra và gán thời gian, độ dài lượt của buổi bảo vệ
+func applyTimes(session *model.DefenseSession, startAt, endAt string, minutes int) map[string]string {
+	errors := map[string]string{}
+	start, err := parseTime(startAt)
+	if err != nil {
+		errors["startAt"] = config.GetMessageCode("FORMAT_DATETIME")
+	}
+	end, err := parseTime(endAt)
+	if err != nil {
+		errors["endAt"] = config.GetMessageCode("FORMAT_DATETIME")
+	}
+	if minutes == 0 {
+		minutes = model.DefaultSlotMinutes
+	}
+	if minutes < model.MinSlotMinutes || minutes > model.MaxSlotMinutes {
+		errors["slotMinutes"] = fmt.Sprintf("must be between %d and %d", model.MinSlotMinutes, model.MaxSlotMinutes)
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	if !start.Before(end) || len(model.Split(start, end, minutes)) == 0 {
+		errors["endAt"] = config.GetMessageCode("INVALID_TIME_RANGE")
+		return errors
+	}
+	session.StartAt, session.EndAt, session.SlotMinutes = start, end, minutes
+	return errors
+}
+
+// createSlots chia buổi thành các lượt; assigned là các luận văn xếp lần lượt vào đầu buổi
+func createSlots(tx *gorm.DB, session *model.DefenseSession, assigned []uint, createdBy string) error {
+	slots := model.Split(session.StartAt, session.EndAt, session.SlotMinutes)
+	for i := range slots {
+		slots[i].SessionID = session.ID
+		slots[i].CreatedBy = createdBy
+		if i < len(assigned) {
+			slots[i].ThesisID = &assigned[i]
+		}
+	}
+	if err := tx.Create(&slots).Error; err != nil {
+		return err
+	}
+	session.Slots = slots
+	return nil
+}
+
+func loadSession(db *gorm.DB, id interface{}) (model.DefenseSession, error) {
+	var session model.DefenseSession
+	err := db.Preload("Room").Preload("Committee.Members").Preload("Slots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("START_AT")
+	}).Preload("Slots.Thesis").First(&session, id).Error
+	return session, err
+}
+
+// lockSession khóa buổi bảo vệ để sửa và kiểm tra người gọi quản lý hội đồng của buổi
+func lockSession(tx *gorm.DB, tokenData *utils.TokenData, id interface{}) (model.DefenseSession, error) {
+	var session model.DefenseSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, id).Error; err != nil {
+		return session, errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+	}
+	session, err := loadSession(tx, session.ID)
+	if err != nil {
+		return session, errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+	}
+	if !organizationController.ScopeOf(tx, tokenData).Allows(session.Committee.SubjectID) {
+		return session, errors.New(config.GetMessageCode("PERMISSION_DENIED"))
+	}
+	return session, nil
+}
+
+// notifySession báo cho thành viên hội đồng, giảng viên hướng dẫn và sinh viên của các luận văn trong buổi
+func notifySession(tx *gorm.DB, session model.DefenseSession, notificationType, title string) error {
+	recipients := []modelNotification.Recipient{}
+	for _, member := range session.Committee.Members {
+		recipients = append(recipients, modelNotification.Recipient{UserID: member.MemberID, Role: member.MemberRole})
+	}
+	thesisIDs := []uint{}
+	for _, slot := range session.Slots {
+		if slot.ThesisID != nil {
+			thesisIDs = append(thesisIDs, *slot.ThesisID)
+		}
+	}
+	if len(thesisIDs) > 0 {
+		var theses []modelThesis.Thesis
+		tx.Preload("Advisors").Preload("Students").Where("ID IN ?", thesisIDs).Find(&theses)
+		for _, thesis := range theses {
+			for _, advisor := range thesis.Advisors {
+				recipients = append(recipients, modelNotification.Recipient{UserID: advisor.ID, Role: modelUsers.AdvisorRole})
+			}
+			for _, student := range thesis.Students {
+				recipients = append(recipients, modelNotification.Recipient{UserID: student.ID, Role: modelUsers.StudentRole})
+			}
+		}
+	}
+
+	loc := core.CampusLocation()
+	return notificationController.Notify(tx, modelNotification.Message{
+		Type:  notificationType,
+		Title: title,
+		Body: fmt.Sprintf("Committee %s, room %s, %s - %s", session.Committee.Code, session.Room.Code,
+			session.StartAt.In(loc).Format("2006-01-02 15:04"), session.EndAt.In(loc).Format("15:04")),
+		Link: "/defense/schedule",
+	}, recipients...)
+}
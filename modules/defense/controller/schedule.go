@@ -0,0 +1,338 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/defense/model"
+	"app/utils"
+	"fmt"
+	"sort"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelCommittee "app/modules/committee/model"
+	semesterController "app/modules/semester/controller"
+	modelStudent "app/modules/student/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetConflicts liệt kê các lịch trùng của một học kỳ hoặc một khoảng thời gian
+// @Summary Defense schedule conflicts
+// @Description Every pair of sessions or slots that double-books a room, a committee member, an advisor or a student. Give a semester, or a from/to range.
+// @Tags Defense
+// @Produce json
+// @Param semester query string false "Semester"
+// @Param from query string false "Start of the range"
+// @Param to query string false "End of the range"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/conflicts [get]
+func GetConflicts(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var from, to time.Time
+	if code := c.Query("semester"); code != "" {
+		semester, err := semesterController.Lookup(db, code)
+		if err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+		var sessions []model.DefenseSession
+		db.Select("ID, START_AT, END_AT").Where("SEMESTER = ?", semester.Code).Find(&sessions)
+		for i, session := range sessions {
+			if i == 0 || session.StartAt.Before(from) {
+				from = session.StartAt
+			}
+			if i == 0 || session.EndAt.After(to) {
+				to = session.EndAt
+			}
+		}
+	} else {
+		var fromErr, toErr error
+		from, fromErr = parseTime(c.Query("from"))
+		to, toErr = parseTime(c.Query("to"))
+		if fromErr != nil || toErr != nil || !from.Before(to) {
+			response.Status = false
+			response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+			return c.JSON(response)
+		}
+	}
+
+	conflicts := []model.Conflict{}
+	if from.Before(to) {
+		conflicts = findConflicts(bookingsBetween(db, from, to), 0)
+	}
+
+	response.Data = conflicts
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetMySchedule trả về lịch bảo vệ đã công bố của người gọi
+// @Summary My defense schedule
+// @Description Published sessions where the caller sits on the committee, and the published slots of the theses the caller advises or writes
+// @Tags Defense
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/schedule [get]
+func GetMySchedule(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	entries := []model.ScheduleEntry{}
+
+	// Thành viên hội đồng: cả buổi bảo vệ
+	var members []modelCommittee.CommitteeMember
+	memberQuery := db.Where("MEMBER_ID = ? AND MEMBER_ROLE = ?", tokenData.ID, tokenData.Role)
+	if tokenData.Code != "" {
+		memberQuery = db.Where("(MEMBER_ID = ? AND MEMBER_ROLE = ?) OR MEMBER_CODE = ?", tokenData.ID, tokenData.Role, tokenData.Code)
+	}
+	memberQuery.Find(&members)
+	roles := map[uint]string{}
+	committeeIDs := []uint{}
+	for _, member := range members {
+		roles[member.CommitteeID] = member.Role
+		committeeIDs = append(committeeIDs, member.CommitteeID)
+	}
+	if len(committeeIDs) > 0 {
+		var sessions []model.DefenseSession
+		db.Preload("Room").Preload("Committee").Where("COMMITTEE_ID IN ? AND PUBLISHED = ?", committeeIDs, true).Find(&sessions)
+		for _, session := range sessions {
+			entries = append(entries, model.ScheduleEntry{
+				SessionID: session.ID,
+				Role:      roles[session.CommitteeID],
+				StartAt:   session.StartAt,
+				EndAt:     session.EndAt,
+				Room:      session.Room.Code,
+				Committee: session.Committee.Code,
+			})
+		}
+	}
+
+	// Giảng viên hướng dẫn và sinh viên: lượt bảo vệ của luận văn
+	thesisIDs := []uint{}
+	role := ""
+	switch tokenData.Role {
+	case modelUsers.AdvisorRole:
+		role = "ADVISOR"
+		query := db.Model(&modelAdvisor.Advisor{}).Where("ID = ?", tokenData.ID)
+		if tokenData.Code != "" {
+			query = db.Model(&modelAdvisor.Advisor{}).Where("ID = ? OR CODE = ?", tokenData.ID, tokenData.Code)
+		}
+		query.Where("THESIS_ID > 0").Pluck("THESIS_ID", &thesisIDs)
+	case modelUsers.StudentRole:
+		role = "STUDENT"
+		db.Model(&modelStudent.Student{}).Where("ID = ? AND THESIS_ID > 0", tokenData.ID).Pluck("THESIS_ID", &thesisIDs)
+	}
+	if len(thesisIDs) > 0 {
+		entries = append(entries, slotEntries(db, db, thesisIDs, role)...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].StartAt.Before(entries[j].StartAt) })
+	response.Data = entries
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetRoomSchedule trả về lịch đã công bố của một phòng
+// @Summary Room defense schedule
+// @Description Published defense slots held in a room
+// @Tags Defense
+// @Produce json
+// @Param id path int true "Room ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/schedule/room/{id} [get]
+func GetRoomSchedule(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var room model.Room
+	if err := db.First(&room, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	entries := slotEntries(db, db.Where("ROOM_ID = ?", room.ID), nil, "")
+	sort.Slice(entries, func(i, j int) bool { return entries[i].StartAt.Before(entries[j].StartAt) })
+
+	response.Data = entries
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// slotEntries đọc các lượt đã xếp luận văn trong các buổi đã công bố thuộc sessions,
+// chỉ của các luận văn thesisIDs nếu danh sách khác nil
+func slotEntries(db *gorm.DB, sessions *gorm.DB, thesisIDs []uint, role string) []model.ScheduleEntry {
+	published := sessions.Model(&model.DefenseSession{}).Select("ID").Where("PUBLISHED = ?", true)
+	query := db.Where("SESSION_ID IN (?) AND THESIS_ID IS NOT NULL", published)
+	if thesisIDs != nil {
+		query = query.Where("THESIS_ID IN ?", thesisIDs)
+	}
+	var slots []model.DefenseSlot
+	query.Preload("Thesis").Find(&slots)
+
+	loaded := map[uint]model.DefenseSession{}
+	entries := []model.ScheduleEntry{}
+	for _, slot := range slots {
+		session, ok := loaded[slot.SessionID]
+		if !ok {
+			db.Preload("Room").Preload("Committee").First(&session, slot.SessionID)
+			loaded[slot.SessionID] = session
+		}
+		entry := model.ScheduleEntry{
+			SessionID: session.ID,
+			SlotID:    slot.ID,
+			Role:      role,
+			StartAt:   slot.StartAt,
+			EndAt:     slot.EndAt,
+			Room:      session.Room.Code,
+			Committee: session.Committee.Code,
+			ThesisID:  slot.ThesisID,
+		}
+		if slot.Thesis != nil {
+			entry.ThesisTitle = slot.Thesis.TitleVi
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// booking là thời gian một phòng hoặc một người bị chiếm bởi một buổi (SlotID = 0) hoặc một lượt bảo vệ
+type booking struct {
+	kind      string
+	resource  string
+	label     string
+	sessionID uint
+	slotID    uint
+	start     time.Time
+	end       time.Time
+}
+
+// bookingsBetween thu thập lịch của phòng, thành viên hội đồng, giảng viên hướng dẫn và sinh viên
+// của các buổi bảo vệ giao với [from, to)
+func bookingsBetween(db *gorm.DB, from, to time.Time) []booking {
+	var sessions []model.DefenseSession
+	db.Preload("Room").Preload("Committee.Members").Preload("Slots.Thesis.Advisors").Preload("Slots.Thesis.Students").
+		Where("START_AT < ? AND END_AT > ?", to, from).Find(&sessions)
+
+	bookings := []booking{}
+	for _, session := range sessions {
+		bookings = append(bookings, booking{
+			kind: model.ConflictRoom, resource: fmt.Sprintf("room:%d", session.RoomID), label: session.Room.Code,
+			sessionID: session.ID, start: session.StartAt, end: session.EndAt,
+		})
+		for _, member := range session.Committee.Members {
+			bookings = append(bookings, booking{
+				kind: model.ConflictMember, resource: person(member.MemberCode, member.MemberRole, member.MemberID), label: member.FullName,
+				sessionID: session.ID, start: session.StartAt, end: session.EndAt,
+			})
+		}
+		for _, slot := range session.Slots {
+			if slot.Thesis == nil {
+				continue
+			}
+			for _, advisor := range slot.Thesis.Advisors {
+				bookings = append(bookings, booking{
+					kind: model.ConflictAdvisor, resource: person(advisor.Code, modelUsers.AdvisorRole, advisor.ID), label: advisor.FullName,
+					sessionID: session.ID, slotID: slot.ID, start: slot.StartAt, end: slot.EndAt,
+				})
+			}
+			for _, student := range slot.Thesis.Students {
+				bookings = append(bookings, booking{
+					kind: model.ConflictStudent, resource: fmt.Sprintf("student:%d", student.ID), label: student.FullName,
+					sessionID: session.ID, slotID: slot.ID, start: slot.StartAt, end: slot.EndAt,
+				})
+			}
+		}
+	}
+	return bookings
+}
+
+// findConflicts ghép các lịch trùng giờ của cùng phòng hoặc cùng người.
+// sessionID khác 0 thì chỉ giữ các cặp có liên quan tới buổi đó.
+func findConflicts(bookings []booking, sessionID uint) []model.Conflict {
+	byResource := map[string][]booking{}
+	for _, b := range bookings {
+		byResource[b.resource] = append(byResource[b.resource], b)
+	}
+
+	conflicts := []model.Conflict{}
+	for resource, group := range byResource {
+		sort.Slice(group, func(i, j int) bool { return group[i].start.Before(group[j].start) })
+		for i := range group {
+			for j := i + 1; j < len(group) && group[j].start.Before(group[i].end); j++ {
+				a, b := group[i], group[j]
+				if sessionID != 0 && a.sessionID != sessionID && b.sessionID != sessionID {
+					continue
+				}
+				if b.sessionID == sessionID && sessionID != 0 {
+					a, b = b, a
+				}
+				kind := a.kind
+				if kind != b.kind && b.kind == model.ConflictMember {
+					kind = b.kind
+				}
+				conflicts = append(conflicts, model.Conflict{
+					Kind:           kind,
+					Resource:       resource,
+					Label:          a.label,
+					SessionID:      a.sessionID,
+					SlotID:         a.slotID,
+					OtherSessionID: b.sessionID,
+					OtherSlotID:    b.slotID,
+					StartAt:        later(a.start, b.start),
+					EndAt:          earlier(a.end, b.end),
+				})
+			}
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].StartAt.Before(conflicts[j].StartAt) })
+	return conflicts
+}
+
+// person là khóa của một người trong lịch: mã cán bộ nếu có (cùng người có thể có tài khoản ở nhiều bảng)
+func person(code string, role int, id uint) string {
+	if code != "" {
+		return "person:" + code
+	}
+	return fmt.Sprintf("user:%d/%d", role, id)
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
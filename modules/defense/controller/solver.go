@@ -0,0 +1,628 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/defense/model"
+	"app/utils"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	modelCommittee "app/modules/committee/model"
+	organizationController "app/modules/organization/controller"
+	reviewerController "app/modules/reviewer/controller"
+	semesterController "app/modules/semester/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+)
+
+// roomChangeMinutes là chi phí (quy ra phút chờ) của việc một hội đồng đổi phòng trong ngày
+const roomChangeMinutes = 60
+
+// SolveTimetable tự động xếp lịch bảo vệ cho các luận văn chưa có lượt
+// @Summary Generate a defense timetable
+// @Description Place every approved thesis of the semester that has a committee but no defense slot yet into the given time windows and rooms. Theses whose review report is not submitted are not scheduled. The whole committee, the advisors and the students must be free; existing sessions, member unavailability and pinned slots are respected. Theses of a committee are kept back to back in one room where possible to reduce idle gaps and room changes. Theses that cannot be placed are returned with the constraints that blocked them. With apply=true the plan is saved as unpublished sessions.
+// @Tags Defense
+// @Accept json
+// @Produce json
+// @Param body body model.SolveTimetable true "Solver input"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /defense/timetable [post]
+func SolveTimetable(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canManage(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.SolveTimetable
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	errors := map[string]string{}
+	semester, err := semesterController.Lookup(db, payload.Semester)
+	if err != nil {
+		errors["semester"] = config.GetMessageCode("NOT_ID_EXISTS")
+	}
+	minutes := payload.SlotMinutes
+	if minutes == 0 {
+		minutes = model.DefaultSlotMinutes
+	}
+	if minutes < model.MinSlotMinutes || minutes > model.MaxSlotMinutes {
+		errors["slotMinutes"] = fmt.Sprintf("must be between %d and %d", model.MinSlotMinutes, model.MaxSlotMinutes)
+	}
+	if len(payload.Windows) == 0 {
+		errors["windows"] = config.GetMessageCode("REQUIRE")
+	}
+	windows := make([][2]time.Time, 0, len(payload.Windows))
+	for i, window := range payload.Windows {
+		start, startErr := parseTime(window.StartAt)
+		end, endErr := parseTime(window.EndAt)
+		if startErr != nil || endErr != nil || !start.Before(end) {
+			errors[fmt.Sprintf("windows[%d]", i)] = config.GetMessageCode("INVALID_TIME_RANGE")
+			continue
+		}
+		windows = append(windows, [2]time.Time{start, end})
+	}
+	unavailable := make([]model.Unavailability, 0, len(payload.Unavailable))
+	for i, item := range payload.Unavailable {
+		_, startErr := parseTime(item.StartAt)
+		_, endErr := parseTime(item.EndAt)
+		if strings.TrimSpace(item.MemberCode) == "" || startErr != nil || endErr != nil {
+			errors[fmt.Sprintf("unavailable[%d]", i)] = config.GetMessageCode("PARAM_ERROR")
+			continue
+		}
+		unavailable = append(unavailable, item)
+	}
+	for i, pin := range payload.Pinned {
+		if _, err := parseTime(pin.StartAt); err != nil || pin.ThesisID == 0 || pin.RoomID == 0 {
+			errors[fmt.Sprintf("pinned[%d]", i)] = config.GetMessageCode("PARAM_ERROR")
+		}
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	scope := organizationController.ScopeOf(db, tokenData)
+	committeeQuery := scope.Apply(db.Preload("Members").Where("SEMESTER = ?", semester.Code), "SUBJECT_ID")
+	if len(payload.CommitteeIDs) > 0 {
+		committeeQuery = committeeQuery.Where("ID IN ?", payload.CommitteeIDs)
+	}
+	var committees []modelCommittee.Committee
+	if err := committeeQuery.Find(&committees).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	roomQuery := db.Where("ACTIVE = ?", true).Order("CODE")
+	if len(payload.RoomIDs) > 0 {
+		roomQuery = roomQuery.Where("ID IN ?", payload.RoomIDs)
+	}
+	var rooms []model.Room
+	if err := roomQuery.Find(&rooms).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	// Luận văn đã có lượt bảo vệ giữ nguyên và chỉ được tính là lịch bận
+	scheduled := db.Model(&model.DefenseSlot{}).Select("THESIS_ID").Where("THESIS_ID IS NOT NULL")
+	thesisQuery := scope.Apply(db.Preload("Advisors").Preload("Students").
+		Where("APPROVAL_STATUS = ? AND UPPER(SEMESTER) = ? AND ID NOT IN (?)", modelThesis.ApprovalApproved, strings.ToUpper(semester.Code), scheduled), "SUBJECT_ID")
+	if len(payload.CommitteeIDs) > 0 {
+		thesisQuery = thesisQuery.Where("COMMITTEE_ID IN ?", payload.CommitteeIDs)
+	}
+	var theses []modelThesis.Thesis
+	if err := thesisQuery.Order("ID").Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	s := newSolver(time.Duration(minutes)*time.Minute, rooms, committees, windows)
+	from, to := s.horizon(payload.Pinned)
+	if from.Before(to) {
+		s.addBookings(bookingsBetween(db, from, to))
+	}
+	for _, item := range unavailable {
+		start, _ := parseTime(item.StartAt)
+		end, _ := parseTime(item.EndAt)
+		s.addBusy(person(strings.TrimSpace(item.MemberCode), 0, 0), start, end, true)
+	}
+	timetable := s.solve(theses, reviewerController.Pending(db, thesisIDs(theses)), payload.Pinned)
+
+	if payload.Apply && len(timetable.Sessions) > 0 {
+		tx := db.Begin()
+		defer tx.Commit()
+
+		for i, planned := range timetable.Sessions {
+			session := model.DefenseSession{
+				Semester:    semester.Code,
+				CommitteeID: planned.CommitteeID,
+				RoomID:      planned.RoomID,
+				StartAt:     planned.StartAt,
+				EndAt:       planned.EndAt,
+				SlotMinutes: minutes,
+			}
+			session.CreatedBy = tokenData.Code
+			if err := tx.Omit(clause.Associations).Create(&session).Error; err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("SYSTEM_ERROR")
+				return c.JSON(response)
+			}
+			if err := createSlots(tx, &session, planned.ThesisIDs, tokenData.Code); err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("SYSTEM_ERROR")
+				return c.JSON(response)
+			}
+			timetable.Sessions[i].SessionID = session.ID
+		}
+		// Kiểm tra lại trên dữ liệu đã lưu, phòng khi lịch thay đổi trong lúc xếp
+		for _, planned := range timetable.Sessions {
+			if conflicts := findConflicts(bookingsBetween(tx, planned.StartAt, planned.EndAt), planned.SessionID); len(conflicts) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("SCHEDULE_CONFLICT")
+				response.ValidateError = conflicts
+				return c.JSON(response)
+			}
+		}
+		timetable.Applied = true
+	}
+
+	response.Data = timetable
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	if timetable.Applied {
+		response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	}
+	return c.JSON(response)
+}
+
+type busyInterval struct {
+	start       time.Time
+	end         time.Time
+	unavailable bool
+}
+
+type resource struct {
+	key   string
+	kind  string
+	label string
+}
+
+type placement struct {
+	thesisID    uint
+	committeeID uint
+	roomID      uint
+	start       time.Time
+	end         time.Time
+}
+
+// solver xếp lịch tham lam: mỗi hội đồng lần lượt nhận khối lượt liên tiếp dài nhất có thể trong một phòng,
+// ưu tiên khối ít làm giảng viên phải chờ và ít đổi phòng. Mọi dữ liệu nằm trong bộ nhớ, không gọi dịch vụ ngoài.
+type solver struct {
+	slot       time.Duration
+	rooms      []model.Room
+	committees map[uint]modelCommittee.Committee
+	windows    [][2]time.Time
+	busy       map[string][]busyInterval
+	placed     []placement
+	loc        *time.Location
+}
+
+func newSolver(slot time.Duration, rooms []model.Room, committees []modelCommittee.Committee, windows [][2]time.Time) *solver {
+	s := &solver{
+		slot:       slot,
+		rooms:      rooms,
+		committees: map[uint]modelCommittee.Committee{},
+		windows:    windows,
+		busy:       map[string][]busyInterval{},
+		loc:        core.CampusLocation(),
+	}
+	for _, committee := range committees {
+		s.committees[committee.ID] = committee
+	}
+	sort.Slice(s.windows, func(i, j int) bool { return s.windows[i][0].Before(s.windows[j][0]) })
+	return s
+}
+
+// horizon là khoảng thời gian bao các khung giờ và các lượt cố định
+func (s *solver) horizon(pins []model.PinnedSlot) (time.Time, time.Time) {
+	var from, to time.Time
+	extend := func(start, end time.Time) {
+		if from.IsZero() || start.Before(from) {
+			from = start
+		}
+		if to.IsZero() || end.After(to) {
+			to = end
+		}
+	}
+	for _, window := range s.windows {
+		extend(window[0], window[1])
+	}
+	for _, pin := range pins {
+		start, _ := parseTime(pin.StartAt)
+		extend(start, start.Add(s.slot))
+	}
+	return from, to
+}
+
+func (s *solver) addBookings(bookings []booking) {
+	for _, b := range bookings {
+		s.addBusy(b.resource, b.start, b.end, false)
+	}
+}
+
+func (s *solver) addBusy(key string, start, end time.Time, unavailable bool) {
+	s.busy[key] = append(s.busy[key], busyInterval{start: start, end: end, unavailable: unavailable})
+}
+
+// resourcesOf: cả hội đồng, giảng viên hướng dẫn và sinh viên phải rảnh trong lượt bảo vệ
+func (s *solver) resourcesOf(thesis modelThesis.Thesis) []resource {
+	resources := []resource{}
+	for _, member := range s.committees[*thesis.CommitteeID].Members {
+		resources = append(resources, resource{person(member.MemberCode, member.MemberRole, member.MemberID), model.ConflictMember, member.FullName})
+	}
+	for _, advisor := range thesis.Advisors {
+		resources = append(resources, resource{person(advisor.Code, modelUsers.AdvisorRole, advisor.ID), model.ConflictAdvisor, advisor.FullName})
+	}
+	for _, student := range thesis.Students {
+		resources = append(resources, resource{fmt.Sprintf("student:%d", student.ID), model.ConflictStudent, student.FullName})
+	}
+	return resources
+}
+
+// blockers trả về các ràng buộc ngăn xếp luận văn vào phòng room trong [start, end)
+func (s *solver) blockers(resources []resource, room model.Room, start, end time.Time) []model.UnschedulableCause {
+	causes := []model.UnschedulableCause{}
+	for _, busy := range s.busy[fmt.Sprintf("room:%d", room.ID)] {
+		if model.Overlaps(start, end, busy.start, busy.end) {
+			causes = append(causes, model.UnschedulableCause{Constraint: model.ConflictRoom + "_BUSY", Detail: room.Code})
+			break
+		}
+	}
+	for _, r := range resources {
+		for _, busy := range s.busy[r.key] {
+			if model.Overlaps(start, end, busy.start, busy.end) {
+				constraint := r.kind + "_BUSY"
+				if busy.unavailable {
+					constraint = r.kind + "_UNAVAILABLE"
+				}
+				causes = append(causes, model.UnschedulableCause{Constraint: constraint, Detail: r.label})
+				break
+			}
+		}
+	}
+	return causes
+}
+
+func (s *solver) place(thesis modelThesis.Thesis, resources []resource, roomID uint, start time.Time) {
+	end := start.Add(s.slot)
+	s.addBusy(fmt.Sprintf("room:%d", roomID), start, end, false)
+	for _, r := range resources {
+		s.addBusy(r.key, start, end, false)
+	}
+	s.placed = append(s.placed, placement{thesisID: thesis.ID, committeeID: *thesis.CommitteeID, roomID: roomID, start: start, end: end})
+}
+
+func (s *solver) solve(theses []modelThesis.Thesis, unreviewed map[uint]bool, pins []model.PinnedSlot) model.Timetable {
+	timetable := model.Timetable{Sessions: []model.PlannedSession{}, Unscheduled: []model.Unschedulable{}}
+	unschedulable := func(thesisID uint, causes ...model.UnschedulableCause) {
+		timetable.Unscheduled = append(timetable.Unscheduled, model.Unschedulable{ThesisID: thesisID, Reasons: causes})
+	}
+
+	// Các ràng buộc không phụ thuộc thời gian
+	candidates := map[uint]modelThesis.Thesis{}
+	resources := map[uint][]resource{}
+	byCommittee := map[uint][]uint{}
+	for _, thesis := range theses {
+		if thesis.CommitteeID == nil {
+			unschedulable(thesis.ID, model.UnschedulableCause{Constraint: "NO_COMMITTEE", Count: 1})
+			continue
+		}
+		// Hội đồng ngoài phạm vi người xếp hoặc thuộc học kỳ khác
+		committee, ok := s.committees[*thesis.CommitteeID]
+		if !ok {
+			unschedulable(thesis.ID, model.UnschedulableCause{Constraint: "COMMITTEE_NOT_AVAILABLE", Detail: fmt.Sprint(*thesis.CommitteeID), Count: 1})
+			continue
+		}
+		if problems := committee.Composition(); len(problems) > 0 {
+			unschedulable(thesis.ID, model.UnschedulableCause{Constraint: "COMMITTEE_INCOMPLETE", Detail: committee.Code, Count: 1})
+			continue
+		}
+		if advisor, conflict := advisorOnCommittee(thesis, committee); conflict {
+			unschedulable(thesis.ID, model.UnschedulableCause{Constraint: "CONFLICT_OF_INTEREST", Detail: advisor, Count: 1})
+			continue
+		}
+		if unreviewed[thesis.ID] {
+			unschedulable(thesis.ID, model.UnschedulableCause{Constraint: "REVIEW_PENDING", Count: 1})
+			continue
+		}
+		candidates[thesis.ID] = thesis
+		resources[thesis.ID] = s.resourcesOf(thesis)
+	}
+
+	// Lượt cố định được đặt trước
+	rooms := map[uint]model.Room{}
+	for _, room := range s.rooms {
+		rooms[room.ID] = room
+	}
+	for _, pin := range pins {
+		thesis, ok := candidates[pin.ThesisID]
+		if !ok {
+			continue
+		}
+		delete(candidates, pin.ThesisID)
+		room, ok := rooms[pin.RoomID]
+		if !ok {
+			unschedulable(thesis.ID, model.UnschedulableCause{Constraint: "PINNED_ROOM_UNAVAILABLE", Detail: fmt.Sprint(pin.RoomID), Count: 1})
+			continue
+		}
+		start, _ := parseTime(pin.StartAt)
+		if causes := s.blockers(resources[thesis.ID], room, start, start.Add(s.slot)); len(causes) > 0 {
+			for i := range causes {
+				causes[i].Count = 1
+			}
+			unschedulable(thesis.ID, causes...)
+			continue
+		}
+		s.place(thesis, resources[thesis.ID], room.ID, start)
+	}
+
+	for id, thesis := range candidates {
+		byCommittee[*thesis.CommitteeID] = append(byCommittee[*thesis.CommitteeID], id)
+	}
+	order := make([]uint, 0, len(byCommittee))
+	for committeeID, ids := range byCommittee {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		order = append(order, committeeID)
+	}
+	// Hội đồng nhiều luận văn khó xếp hơn nên được xếp trước
+	sort.Slice(order, func(i, j int) bool {
+		if len(byCommittee[order[i]]) != len(byCommittee[order[j]]) {
+			return len(byCommittee[order[i]]) > len(byCommittee[order[j]])
+		}
+		return order[i] < order[j]
+	})
+
+	for _, committeeID := range order {
+		remaining := byCommittee[committeeID]
+		for len(remaining) > 0 {
+			block, room, start := s.bestBlock(committeeID, remaining, candidates, resources)
+			if len(block) == 0 {
+				break
+			}
+			placed := map[uint]bool{}
+			for i, id := range block {
+				s.place(candidates[id], resources[id], room, start.Add(time.Duration(i)*s.slot))
+				placed[id] = true
+			}
+			next := remaining[:0]
+			for _, id := range remaining {
+				if !placed[id] {
+					next = append(next, id)
+				}
+			}
+			remaining = next
+		}
+		for _, id := range remaining {
+			unschedulable(id, s.diagnose(resources[id])...)
+		}
+	}
+
+	sort.Slice(timetable.Unscheduled, func(i, j int) bool { return timetable.Unscheduled[i].ThesisID < timetable.Unscheduled[j].ThesisID })
+	timetable.Sessions = s.sessions()
+	timetable.IdleMinutes, timetable.RoomChanges = s.quality()
+	return timetable
+}
+
+// bestBlock tìm khối lượt liên tiếp tốt nhất cho hội đồng: nhiều luận văn nhất, rồi chi phí chờ
+// và đổi phòng thấp nhất, rồi sớm nhất
+func (s *solver) bestBlock(committeeID uint, remaining []uint, candidates map[uint]modelThesis.Thesis, resources map[uint][]resource) ([]uint, uint, time.Time) {
+	var bestIDs []uint
+	var bestRoom uint
+	var bestStart time.Time
+	bestCost := 0
+
+	for _, window := range s.windows {
+		for start := window[0]; !start.Add(s.slot).After(window[1]); start = start.Add(s.slot) {
+			for _, room := range s.rooms {
+				block := []uint{}
+				used := map[uint]bool{}
+				for at := start; !at.Add(s.slot).After(window[1]); at = at.Add(s.slot) {
+					found := false
+					for _, id := range remaining {
+						if !used[id] && len(s.blockers(resources[id], room, at, at.Add(s.slot))) == 0 {
+							block = append(block, id)
+							used[id] = true
+							found = true
+							break
+						}
+					}
+					if !found {
+						break
+					}
+				}
+				if len(block) == 0 {
+					continue
+				}
+				cost := s.cost(committeeID, room.ID, start, start.Add(time.Duration(len(block))*s.slot))
+				if len(block) > len(bestIDs) || (len(block) == len(bestIDs) && cost < bestCost) {
+					bestIDs, bestRoom, bestStart, bestCost = block, room.ID, start, cost
+				}
+			}
+		}
+	}
+	return bestIDs, bestRoom, bestStart
+}
+
+// cost là số phút thành viên hội đồng phải chờ giữa khối này và lịch khác trong cùng ngày,
+// cộng chi phí đổi phòng nếu hội đồng đã có lượt ở phòng khác trong ngày
+func (s *solver) cost(committeeID, roomID uint, start, end time.Time) int {
+	cost := 0
+	for _, member := range s.committees[committeeID].Members {
+		gap := -1
+		for _, busy := range s.busy[person(member.MemberCode, member.MemberRole, member.MemberID)] {
+			if busy.unavailable || !s.sameDay(busy.start, start) {
+				continue
+			}
+			distance := 0
+			if !busy.end.After(start) {
+				distance = int(start.Sub(busy.end).Minutes())
+			} else if !busy.start.Before(end) {
+				distance = int(busy.start.Sub(end).Minutes())
+			}
+			if gap < 0 || distance < gap {
+				gap = distance
+			}
+		}
+		if gap > 0 {
+			cost += gap
+		}
+	}
+	for _, p := range s.placed {
+		if p.committeeID == committeeID && p.roomID != roomID && s.sameDay(p.start, start) {
+			cost += roomChangeMinutes
+			break
+		}
+	}
+	return cost
+}
+
+// diagnose đếm các ràng buộc chặn luận văn ở mọi lượt có thể của mọi phòng
+func (s *solver) diagnose(resources []resource) []model.UnschedulableCause {
+	counts := map[model.UnschedulableCause]int{}
+	cells := 0
+	for _, window := range s.windows {
+		for at := window[0]; !at.Add(s.slot).After(window[1]); at = at.Add(s.slot) {
+			for _, room := range s.rooms {
+				cells++
+				for _, cause := range s.blockers(resources, room, at, at.Add(s.slot)) {
+					counts[cause]++
+				}
+			}
+		}
+	}
+	if cells == 0 {
+		return []model.UnschedulableCause{{Constraint: "NO_SLOT", Detail: "no room or time window can hold a slot", Count: 1}}
+	}
+
+	causes := make([]model.UnschedulableCause, 0, len(counts))
+	for cause, count := range counts {
+		cause.Count = count
+		causes = append(causes, cause)
+	}
+	sort.Slice(causes, func(i, j int) bool {
+		if causes[i].Count != causes[j].Count {
+			return causes[i].Count > causes[j].Count
+		}
+		return causes[i].Constraint+causes[i].Detail < causes[j].Constraint+causes[j].Detail
+	})
+	return causes
+}
+
+// sessions gộp các lượt liền nhau của cùng hội đồng và phòng thành buổi bảo vệ
+func (s *solver) sessions() []model.PlannedSession {
+	placed := append([]placement{}, s.placed...)
+	sort.Slice(placed, func(i, j int) bool {
+		if placed[i].committeeID != placed[j].committeeID {
+			return placed[i].committeeID < placed[j].committeeID
+		}
+		if placed[i].roomID != placed[j].roomID {
+			return placed[i].roomID < placed[j].roomID
+		}
+		return placed[i].start.Before(placed[j].start)
+	})
+
+	sessions := []model.PlannedSession{}
+	for _, p := range placed {
+		last := len(sessions) - 1
+		if last >= 0 && sessions[last].CommitteeID == p.committeeID && sessions[last].RoomID == p.roomID && sessions[last].EndAt.Equal(p.start) {
+			sessions[last].EndAt = p.end
+			sessions[last].ThesisIDs = append(sessions[last].ThesisIDs, p.thesisID)
+			continue
+		}
+		sessions = append(sessions, model.PlannedSession{CommitteeID: p.committeeID, RoomID: p.roomID, StartAt: p.start, EndAt: p.end, ThesisIDs: []uint{p.thesisID}})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartAt.Before(sessions[j].StartAt) })
+	return sessions
+}
+
+// quality tính tổng phút chờ của thành viên hội đồng và số lần hội đồng đổi phòng trong ngày theo kế hoạch
+func (s *solver) quality() (int, int) {
+	byPerson := map[string][]placement{}
+	byCommittee := map[uint][]placement{}
+	for _, p := range s.placed {
+		for _, member := range s.committees[p.committeeID].Members {
+			key := person(member.MemberCode, member.MemberRole, member.MemberID)
+			byPerson[key] = append(byPerson[key], p)
+		}
+		byCommittee[p.committeeID] = append(byCommittee[p.committeeID], p)
+	}
+
+	idle := 0
+	for _, list := range byPerson {
+		sort.Slice(list, func(i, j int) bool { return list[i].start.Before(list[j].start) })
+		for i := 1; i < len(list); i++ {
+			if s.sameDay(list[i-1].start, list[i].start) && list[i].start.After(list[i-1].end) {
+				idle += int(list[i].start.Sub(list[i-1].end).Minutes())
+			}
+		}
+	}
+	changes := 0
+	for _, list := range byCommittee {
+		sort.Slice(list, func(i, j int) bool { return list[i].start.Before(list[j].start) })
+		for i := 1; i < len(list); i++ {
+			if s.sameDay(list[i-1].start, list[i].start) && list[i-1].roomID != list[i].roomID {
+				changes++
+			}
+		}
+	}
+	return idle, changes
+}
+
+func (s *solver) sameDay(a, b time.Time) bool {
+	a, b = a.In(s.loc), b.In(s.loc)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// advisorOnCommittee kiểm tra giảng viên hướng dẫn có ngồi trong hội đồng của luận văn không
+func advisorOnCommittee(thesis modelThesis.Thesis, committee modelCommittee.Committee) (string, bool) {
+	for _, advisor := range thesis.Advisors {
+		for _, member := range committee.Members {
+			if (member.MemberCode != "" && member.MemberCode == advisor.Code) || (member.MemberRole == modelUsers.AdvisorRole && member.MemberID == advisor.ID) {
+				return advisor.FullName, true
+			}
+		}
+	}
+	return "", false
+}
+
+func thesisIDs(theses []modelThesis.Thesis) []uint {
+	ids := make([]uint, 0, len(theses))
+	for _, thesis := range theses {
+		ids = append(ids, thesis.ID)
+	}
+	return ids
+}
@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"app/modules/defense/model"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelCommittee "app/modules/committee/model"
+	modelStudent "app/modules/student/model"
+	modelThesis "app/modules/thesis/model"
+)
+
+// config.Config đọc .env ở thư mục hiện tại; test chạy trong thư mục tạm có .env rỗng và dùng múi giờ mặc định
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "defense")
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".env"), nil, 0644); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// solverDay là 09:00 giờ Việt Nam
+var solverDay = time.Date(2026, 6, 1, 2, 0, 0, 0, time.UTC)
+
+func at(hours float64) time.Time {
+	return solverDay.Add(time.Duration(hours * float64(time.Hour)))
+}
+
+func testCommittee(id uint, codes ...string) modelCommittee.Committee {
+	roles := []string{modelCommittee.RoleChair, modelCommittee.RoleSecretary, modelCommittee.RoleReviewer}
+	committee := modelCommittee.Committee{Code: "HD" + codes[0]}
+	committee.ID = id
+	for i, code := range codes {
+		role := modelCommittee.RoleMember
+		if i < len(roles) {
+			role = roles[i]
+		}
+		committee.Members = append(committee.Members, modelCommittee.CommitteeMember{CommitteeID: id, MemberCode: code, FullName: code, Role: role})
+	}
+	return committee
+}
+
+func testThesis(id uint, committeeID uint, advisorCode string) modelThesis.Thesis {
+	thesis := modelThesis.Thesis{}
+	thesis.ID = id
+	if committeeID != 0 {
+		thesis.CommitteeID = &committeeID
+	}
+	advisor := modelAdvisor.Advisor{Code: advisorCode}
+	advisor.ID = id + 1000
+	advisor.FullName = advisorCode
+	student := modelStudent.Student{}
+	student.ID = id + 2000
+	thesis.Advisors = []modelAdvisor.Advisor{advisor}
+	thesis.Students = []modelStudent.Student{student}
+	return thesis
+}
+
+func testRooms(ids ...uint) []model.Room {
+	rooms := []model.Room{}
+	for _, id := range ids {
+		room := model.Room{Code: "R" + string(rune('0'+id)), Active: true}
+		room.ID = id
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+func TestSolve(t *testing.T) {
+	committees := []modelCommittee.Committee{
+		testCommittee(1, "C1", "S1", "V1"),
+		testCommittee(2, "C2", "S2", "V2"),
+		testCommittee(3, "C3"),
+	}
+
+	tests := []struct {
+		name            string
+		theses          []modelThesis.Thesis
+		unreviewed      map[uint]bool
+		pins            []model.PinnedSlot
+		busy            func(s *solver)
+		wantSessions    []model.PlannedSession
+		wantUnscheduled map[uint]string
+		wantIdle        int
+		wantChanges     int
+	}{
+		{
+			name:   "committee theses back to back in one room",
+			theses: []modelThesis.Thesis{testThesis(10, 1, "A1"), testThesis(11, 1, "A2"), testThesis(12, 1, "A3")},
+			wantSessions: []model.PlannedSession{
+				{CommitteeID: 1, RoomID: 1, StartAt: at(0), EndAt: at(3), ThesisIDs: []uint{10, 11, 12}},
+			},
+			wantUnscheduled: map[uint]string{},
+		},
+		{
+			name:   "two committees share the rooms",
+			theses: []modelThesis.Thesis{testThesis(10, 1, "A1"), testThesis(11, 1, "A2"), testThesis(20, 2, "A3")},
+			wantSessions: []model.PlannedSession{
+				{CommitteeID: 1, RoomID: 1, StartAt: at(0), EndAt: at(2), ThesisIDs: []uint{10, 11}},
+				{CommitteeID: 2, RoomID: 2, StartAt: at(0), EndAt: at(1), ThesisIDs: []uint{20}},
+			},
+			wantUnscheduled: map[uint]string{},
+		},
+		{
+			name: "static constraints",
+			theses: []modelThesis.Thesis{
+				testThesis(10, 0, "A1"),
+				testThesis(11, 99, "A2"),
+				testThesis(12, 3, "A3"),
+				testThesis(13, 1, "C1"),
+				testThesis(14, 2, "A4"),
+			},
+			unreviewed:   map[uint]bool{14: true},
+			wantSessions: []model.PlannedSession{},
+			wantUnscheduled: map[uint]string{
+				10: "NO_COMMITTEE",
+				11: "COMMITTEE_NOT_AVAILABLE",
+				12: "COMMITTEE_INCOMPLETE",
+				13: "CONFLICT_OF_INTEREST",
+				14: "REVIEW_PENDING",
+			},
+		},
+		{
+			name: "more theses than slots of the committee",
+			theses: []modelThesis.Thesis{
+				testThesis(10, 1, "A1"), testThesis(11, 1, "A2"), testThesis(12, 1, "A3"), testThesis(13, 1, "A4"),
+			},
+			wantSessions: []model.PlannedSession{
+				{CommitteeID: 1, RoomID: 1, StartAt: at(0), EndAt: at(3), ThesisIDs: []uint{10, 11, 12}},
+			},
+			wantUnscheduled: map[uint]string{13: "MEMBER_BUSY"},
+		},
+		{
+			name:   "member unavailable for the whole window",
+			theses: []modelThesis.Thesis{testThesis(10, 1, "A1")},
+			busy: func(s *solver) {
+				s.addBusy(person("S1", 0, 0), at(0), at(3), true)
+			},
+			wantSessions:    []model.PlannedSession{},
+			wantUnscheduled: map[uint]string{10: "MEMBER_UNAVAILABLE"},
+		},
+		{
+			name:   "shared advisor is never double booked",
+			theses: []modelThesis.Thesis{testThesis(10, 1, "A1"), testThesis(20, 2, "A1")},
+			wantSessions: []model.PlannedSession{
+				{CommitteeID: 1, RoomID: 1, StartAt: at(0), EndAt: at(1), ThesisIDs: []uint{10}},
+				{CommitteeID: 2, RoomID: 1, StartAt: at(1), EndAt: at(2), ThesisIDs: []uint{20}},
+			},
+			wantUnscheduled: map[uint]string{},
+		},
+		{
+			name:   "pinned slot is kept and the rest joins it",
+			theses: []modelThesis.Thesis{testThesis(10, 1, "A1"), testThesis(11, 1, "A2")},
+			pins:   []model.PinnedSlot{{ThesisID: 10, RoomID: 2, StartAt: at(1).Format(time.RFC3339)}},
+			wantSessions: []model.PlannedSession{
+				{CommitteeID: 1, RoomID: 2, StartAt: at(0), EndAt: at(2), ThesisIDs: []uint{11, 10}},
+			},
+			wantUnscheduled: map[uint]string{},
+		},
+		{
+			name:            "pinned room outside the solver",
+			theses:          []modelThesis.Thesis{testThesis(10, 1, "A1")},
+			pins:            []model.PinnedSlot{{ThesisID: 10, RoomID: 9, StartAt: at(0).Format(time.RFC3339)}},
+			wantSessions:    []model.PlannedSession{},
+			wantUnscheduled: map[uint]string{10: "PINNED_ROOM_UNAVAILABLE"},
+		},
+		{
+			name:   "existing booking leaves a gap",
+			theses: []modelThesis.Thesis{testThesis(10, 1, "A1")},
+			busy: func(s *solver) {
+				s.addBusy(person("C1", 0, 0), at(0), at(1), false)
+			},
+			wantSessions: []model.PlannedSession{
+				{CommitteeID: 1, RoomID: 1, StartAt: at(1), EndAt: at(2), ThesisIDs: []uint{10}},
+			},
+			wantUnscheduled: map[uint]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSolver(time.Hour, testRooms(1, 2), committees, [][2]time.Time{{at(0), at(3)}})
+			if tt.busy != nil {
+				tt.busy(s)
+			}
+			timetable := s.solve(tt.theses, tt.unreviewed, tt.pins)
+
+			if len(timetable.Sessions) != len(tt.wantSessions) {
+				t.Fatalf("Sessions = %+v, want %+v", timetable.Sessions, tt.wantSessions)
+			}
+			for i, want := range tt.wantSessions {
+				got := timetable.Sessions[i]
+				if got.CommitteeID != want.CommitteeID || got.RoomID != want.RoomID || !got.StartAt.Equal(want.StartAt) ||
+					!got.EndAt.Equal(want.EndAt) || !reflect.DeepEqual(got.ThesisIDs, want.ThesisIDs) {
+					t.Errorf("Sessions[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+
+			if len(timetable.Unscheduled) != len(tt.wantUnscheduled) {
+				t.Fatalf("Unscheduled = %+v, want %v", timetable.Unscheduled, tt.wantUnscheduled)
+			}
+			for _, item := range timetable.Unscheduled {
+				want, ok := tt.wantUnscheduled[item.ThesisID]
+				if !ok || len(item.Reasons) == 0 || item.Reasons[0].Constraint != want {
+					t.Errorf("thesis %d reasons = %+v, want first %q", item.ThesisID, item.Reasons, want)
+				}
+			}
+			if timetable.IdleMinutes != tt.wantIdle || timetable.RoomChanges != tt.wantChanges {
+				t.Errorf("IdleMinutes, RoomChanges = %d, %d, want %d, %d", timetable.IdleMinutes, timetable.RoomChanges, tt.wantIdle, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestBestBlock(t *testing.T) {
+	committee := testCommittee(1, "C1", "S1", "V1")
+	theses := []modelThesis.Thesis{testThesis(10, 1, "A1"), testThesis(11, 1, "A2"), testThesis(12, 1, "A3")}
+
+	tests := []struct {
+		name      string
+		remaining []uint
+		setup     func(s *solver)
+		wantIDs   []uint
+		wantRoom  uint
+		wantStart time.Time
+	}{
+		{
+			name:      "longest block from the earliest slot",
+			remaining: []uint{10, 11},
+			wantIDs:   []uint{10, 11},
+			wantRoom:  1,
+			wantStart: at(0),
+		},
+		{
+			name:      "busy room is skipped",
+			remaining: []uint{10, 11},
+			setup: func(s *solver) {
+				s.addBusy("room:1", at(0), at(1), false)
+			},
+			wantIDs:   []uint{10, 11},
+			wantRoom:  2,
+			wantStart: at(0),
+		},
+		{
+			name:      "longer block wins over an earlier one",
+			remaining: []uint{10, 11, 12},
+			setup: func(s *solver) {
+				s.addBusy(person("A2", 0, 0), at(0), at(1), false)
+				s.addBusy(person("C1", 0, 0), at(3), at(4), true)
+			},
+			wantIDs:   []uint{10, 11, 12},
+			wantRoom:  1,
+			wantStart: at(0),
+		},
+		{
+			name:      "block next to the members' other sessions",
+			remaining: []uint{10},
+			setup: func(s *solver) {
+				s.addBusy(person("C1", 0, 0), at(2), at(3), false)
+			},
+			wantIDs:   []uint{10},
+			wantRoom:  1,
+			wantStart: at(1),
+		},
+		{
+			name:      "stays in the room the committee already uses",
+			remaining: []uint{11},
+			setup: func(s *solver) {
+				s.place(theses[0], s.resourcesOf(theses[0]), 2, at(0))
+			},
+			wantIDs:   []uint{11},
+			wantRoom:  2,
+			wantStart: at(1),
+		},
+		{
+			name:      "nothing fits",
+			remaining: []uint{10},
+			setup: func(s *solver) {
+				s.addBusy(person("V1", 0, 0), at(0), at(4), true)
+			},
+			wantIDs: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSolver(time.Hour, testRooms(1, 2), []modelCommittee.Committee{committee}, [][2]time.Time{{at(0), at(4)}})
+			candidates := map[uint]modelThesis.Thesis{}
+			resources := map[uint][]resource{}
+			for _, thesis := range theses {
+				candidates[thesis.ID] = thesis
+				resources[thesis.ID] = s.resourcesOf(thesis)
+			}
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+
+			ids, room, start := s.bestBlock(1, tt.remaining, candidates, resources)
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if len(ids) > 0 && (room != tt.wantRoom || !start.Equal(tt.wantStart)) {
+				t.Errorf("room, start = %d, %v, want %d, %v", room, start, tt.wantRoom, tt.wantStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package defenseMigrate
+
+import (
+	"app/database"
+	model "app/modules/defense/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Room{})
+	db.AutoMigrate(&model.DefenseSession{})
+	db.AutoMigrate(&model.DefenseSlot{})
+
+	return true
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"app/model"
+	"time"
+
+	modelCommittee "app/modules/committee/model"
+	modelThesis "app/modules/thesis/model"
+)
+
+var DefaultSlotMinutes, MinSlotMinutes, MaxSlotMinutes = 45, 15, 180
+
+var ConflictRoom, ConflictMember, ConflictAdvisor, ConflictStudent = "ROOM", "MEMBER", "ADVISOR", "STUDENT"
+
+// Room là phòng dùng chung cho các buổi bảo vệ
+type Room struct {
+	model.Header
+	Code     string `json:"code" gorm:"column:CODE;size:20;uniqueIndex"`
+	Name     string `json:"name" gorm:"column:NAME"`
+	Building string `json:"building" gorm:"column:BUILDING"`
+	Capacity int    `json:"capacity" gorm:"column:CAPACITY"`
+	Active   bool   `json:"active" gorm:"column:ACTIVE;default:true"`
+}
+
+// DefenseSession là một buổi bảo vệ của hội đồng tại một phòng, chia thành các lượt DefenseSlot
+// dài SlotMinutes phút. Lịch chỉ hiện với sinh viên và giảng viên sau khi được công bố.
+type DefenseSession struct {
+	model.Header
+	Semester    string                   `json:"semester" gorm:"column:SEMESTER;size:20;index"`
+	CommitteeID uint                     `json:"committeeID" gorm:"column:COMMITTEE_ID;index"`
+	RoomID      uint                     `json:"roomID" gorm:"column:ROOM_ID;index"`
+	StartAt     time.Time                `json:"startAt" gorm:"column:START_AT;index"`
+	EndAt       time.Time                `json:"endAt" gorm:"column:END_AT"`
+	SlotMinutes int                      `json:"slotMinutes" gorm:"column:SLOT_MINUTES"`
+	Published   bool                     `json:"published" gorm:"column:PUBLISHED;default:false"`
+	PublishedAt *time.Time               `json:"publishedAt" gorm:"column:PUBLISHED_AT"`
+	Room        Room                     `json:"room" gorm:"foreignKey:RoomID"`
+	Committee   modelCommittee.Committee `json:"committee" gorm:"foreignKey:CommitteeID"`
+	Slots       []DefenseSlot            `json:"slots" gorm:"foreignKey:SESSION_ID"`
+}
+
+// DefenseSlot là lượt bảo vệ của một luận văn trong buổi; ThesisID trống là lượt còn trống
+type DefenseSlot struct {
+	model.Header
+	SessionID uint                `json:"sessionID" gorm:"column:SESSION_ID;index"`
+	StartAt   time.Time           `json:"startAt" gorm:"column:START_AT"`
+	EndAt     time.Time           `json:"endAt" gorm:"column:END_AT"`
+	ThesisID  *uint               `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Thesis    *modelThesis.Thesis `json:"thesis,omitempty" gorm:"foreignKey:ThesisID"`
+}
+
+type CreateRoom struct {
+	ID       uint   `json:"id"`
+	Code     string `json:"code" validate:"required"`
+	Name     string `json:"name"`
+	Building string `json:"building"`
+	Capacity int    `json:"capacity"`
+}
+
+type UpdateRoom struct {
+	Name     string `json:"name"`
+	Building string `json:"building"`
+	Capacity int    `json:"capacity"`
+	Active   *bool  `json:"active"`
+}
+
+type CreateSession struct {
+	ID          uint   `json:"id"`
+	CommitteeID uint   `json:"committeeID" validate:"required"`
+	RoomID      uint   `json:"roomID" validate:"required"`
+	StartAt     string `json:"startAt" validate:"required"`
+	EndAt       string `json:"endAt" validate:"required"`
+	SlotMinutes int    `json:"slotMinutes"`
+}
+
+type UpdateSession struct {
+	RoomID      uint   `json:"roomID"`
+	StartAt     string `json:"startAt"`
+	EndAt       string `json:"endAt"`
+	SlotMinutes int    `json:"slotMinutes"`
+}
+
+type AssignSlot struct {
+	ThesisID uint `json:"thesisID"`
+}
+
+// Conflict là hai lịch trùng giờ của cùng một phòng hoặc cùng một người
+type Conflict struct {
+	Kind           string    `json:"kind"`
+	Resource       string    `json:"resource"`
+	Label          string    `json:"label"`
+	SessionID      uint      `json:"sessionID"`
+	SlotID         uint      `json:"slotID,omitempty"`
+	OtherSessionID uint      `json:"otherSessionID"`
+	OtherSlotID    uint      `json:"otherSlotID,omitempty"`
+	StartAt        time.Time `json:"startAt"`
+	EndAt          time.Time `json:"endAt"`
+}
+
+// ScheduleEntry là một mục trong lịch bảo vệ của một người hoặc một phòng
+type ScheduleEntry struct {
+	SessionID   uint      `json:"sessionID"`
+	SlotID      uint      `json:"slotID,omitempty"`
+	Role        string    `json:"role"`
+	StartAt     time.Time `json:"startAt"`
+	EndAt       time.Time `json:"endAt"`
+	Room        string    `json:"room"`
+	Committee   string    `json:"committee"`
+	ThesisID    *uint     `json:"thesisID,omitempty"`
+	ThesisTitle string    `json:"thesisTitle,omitempty"`
+}
+
+// SolveTimetable là đầu vào của bộ xếp lịch: các khung giờ được dùng, phòng và hội đồng tham gia
+// (để trống là mọi phòng đang dùng và mọi hội đồng của học kỳ), lịch bận của thành viên và các lượt cố định.
+// Apply = true thì lưu kết quả thành các buổi bảo vệ chưa công bố.
+type SolveTimetable struct {
+	Semester     string           `json:"semester" validate:"required"`
+	CommitteeIDs []uint           `json:"committeeIDs"`
+	RoomIDs      []uint           `json:"roomIDs"`
+	Windows      []SolveWindow    `json:"windows" validate:"required"`
+	SlotMinutes  int              `json:"slotMinutes"`
+	Unavailable  []Unavailability `json:"unavailable"`
+	Pinned       []PinnedSlot     `json:"pinned"`
+	Apply        bool             `json:"apply"`
+}
+
+type SolveWindow struct {
+	StartAt string `json:"startAt"`
+	EndAt   string `json:"endAt"`
+}
+
+// Unavailability là khoảng thời gian một cán bộ (theo mã) không thể tham gia
+type Unavailability struct {
+	MemberCode string `json:"memberCode"`
+	StartAt    string `json:"startAt"`
+	EndAt      string `json:"endAt"`
+}
+
+// PinnedSlot giữ cố định phòng và giờ bắt đầu của một luận văn
+type PinnedSlot struct {
+	ThesisID uint   `json:"thesisID"`
+	RoomID   uint   `json:"roomID"`
+	StartAt  string `json:"startAt"`
+}
+
+// PlannedSession là một buổi liền mạch của một hội đồng trong một phòng do bộ xếp lịch đề xuất
+type PlannedSession struct {
+	SessionID   uint      `json:"sessionID,omitempty"`
+	CommitteeID uint      `json:"committeeID"`
+	RoomID      uint      `json:"roomID"`
+	StartAt     time.Time `json:"startAt"`
+	EndAt       time.Time `json:"endAt"`
+	ThesisIDs   []uint    `json:"thesisIDs"`
+}
+
+// Unschedulable là luận văn không xếp được, kèm các ràng buộc đã chặn nó (Count là số lượt bị chặn)
+type Unschedulable struct {
+	ThesisID uint                 `json:"thesisID"`
+	Reasons  []UnschedulableCause `json:"reasons"`
+}
+
+type UnschedulableCause struct {
+	Constraint string `json:"constraint"`
+	Detail     string `json:"detail"`
+	Count      int    `json:"count"`
+}
+
+// Timetable là kết quả xếp lịch; IdleMinutes là tổng thời gian chờ giữa các lượt của giảng viên trong ngày,
+// RoomChanges là số lần một hội đồng đổi phòng trong ngày
+type Timetable struct {
+	Sessions    []PlannedSession `json:"sessions"`
+	Unscheduled []Unschedulable  `json:"unscheduled"`
+	IdleMinutes int              `json:"idleMinutes"`
+	RoomChanges int              `json:"roomChanges"`
+	Applied     bool             `json:"applied"`
+}
+
+// Overlaps kiểm tra hai khoảng [start, end) giao nhau
+func Overlaps(start, end, otherStart, otherEnd time.Time) bool {
+	return start.Before(otherEnd) && otherStart.Before(end)
+}
+
+// Split chia [start, end) thành các lượt dài minutes phút; phần lẻ cuối buổi bị bỏ
+func Split(start, end time.Time, minutes int) []DefenseSlot {
+	slots := []DefenseSlot{}
+	length := time.Duration(minutes) * time.Minute
+	for at := start; !at.Add(length).After(end); at = at.Add(length) {
+		slots = append(slots, DefenseSlot{StartAt: at, EndAt: at.Add(length)})
+	}
+	return slots
+}
+
+func (Room) TableName() string {
+	return "TBL_ROOM"
+}
+
+func (DefenseSession) TableName() string {
+	return "TBL_DEFENSE_SESSION"
+}
+
+func (DefenseSlot) TableName() string {
+	return "TBL_DEFENSE_SLOT"
+}
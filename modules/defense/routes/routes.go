@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"app/modules/defense/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitDefenseRoutes(app *fiber.App) {
+	defense := app.Group("/defense")
+
+	defense.Get("/room", controller.GetRooms)
+	defense.Post("/room", controller.CreateRoom)
+	defense.Put("/room/:id", controller.UpdateRoom)
+	defense.Delete("/room/:id", controller.DeleteRoom)
+
+	defense.Get("/session", controller.GetSessions)
+	defense.Get("/session/:id", controller.GetSession)
+	defense.Post("/session", controller.CreateSession)
+	defense.Put("/session/:id", controller.UpdateSession)
+	defense.Put("/session/:id/publish", controller.PublishSession)
+	defense.Delete("/session/:id", controller.DeleteSession)
+	defense.Put("/slot/:id", controller.AssignSlot)
+	defense.Post("/timetable", controller.SolveTimetable)
+
+	defense.Get("/conflicts", controller.GetConflicts)
+	defense.Get("/schedule", controller.GetMySchedule)
+	defense.Get("/schedule/room/:id", controller.GetRoomSchedule)
+}
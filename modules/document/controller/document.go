@@ -0,0 +1,458 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/document"
+	"app/modules/document/model"
+	"app/utils"
+	"fmt"
+	"strings"
+
+	modelCommittee "app/modules/committee/model"
+	modelDefense "app/modules/defense/model"
+	modelGrading "app/modules/grading/model"
+	organizationController "app/modules/organization/controller"
+	modelOrganization "app/modules/organization/model"
+	modelReviewer "app/modules/reviewer/model"
+	semesterController "app/modules/semester/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Document API
+// @version 1.0
+// @description Defense minutes and official documents rendered as PDF
+// @termsOfService http://swagger.io/terms/
+// @BasePath /document
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetMinutes trả về biên bản bảo vệ của luận văn
+// @Summary Get defense minutes
+// @Description Minutes recorded by the committee secretary for a thesis. Committee members, heads of subject and the faculty office.
+// @Tags Document
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /document/minutes/{thesisID} [get]
+func GetMinutes(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	thesis, committee, err := loadThesis(db, c.Params("thesisID"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if _, member := memberOf(committee, tokenData); !member && !canManage(db, tokenData, thesis.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	response.Data = loadMinutes(db, thesis.ID)
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// SaveMinutes ghi biên bản bảo vệ của luận văn
+// @Summary Record defense minutes
+// @Description The secretary (or the chair) of the thesis committee records the start and end time, absent members, the presentation, the questions and answers, the comments of the committee and the conclusion.
+// @Tags Document
+// @Accept json
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Param body body model.SaveMinutes true "Minutes"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /document/minutes/{thesisID} [put]
+func SaveMinutes(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.SaveMinutes
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	thesis, committee, err := loadThesis(db, c.Params("thesisID"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	member, ok := memberOf(committee, tokenData)
+	if !ok || (member.Role != modelCommittee.RoleSecretary && member.Role != modelCommittee.RoleChair) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	errors := map[string]string{}
+	minutes := loadMinutes(db, thesis.ID)
+	if payload.StartedAt != "" {
+		startedAt, err := core.ParseCampusTime(payload.StartedAt)
+		if err != nil {
+			errors["startedAt"] = config.GetMessageCode("FORMAT_DATETIME")
+		}
+		minutes.StartedAt = &startedAt
+	}
+	if payload.EndedAt != "" {
+		endedAt, err := core.ParseCampusTime(payload.EndedAt)
+		if err != nil {
+			errors["endedAt"] = config.GetMessageCode("FORMAT_DATETIME")
+		}
+		minutes.EndedAt = &endedAt
+	}
+	if len(errors) == 0 && minutes.StartedAt != nil && minutes.EndedAt != nil && !minutes.StartedAt.Before(*minutes.EndedAt) {
+		errors["endedAt"] = config.GetMessageCode("INVALID_TIME_RANGE")
+	}
+	members := map[uint]bool{}
+	for _, item := range committee.Members {
+		members[item.ID] = true
+	}
+	for _, id := range payload.AbsentMemberIDs {
+		if !members[id] {
+			errors["absentMemberIDs"] = config.GetMessageCode("NOT_ID_EXISTS")
+		}
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	minutes.ThesisID = thesis.ID
+	minutes.CommitteeID = committee.ID
+	minutes.SetAbsent(payload.AbsentMemberIDs)
+	minutes.Presentation = strings.TrimSpace(payload.Presentation)
+	minutes.Questions = strings.TrimSpace(payload.Questions)
+	minutes.Comments = strings.TrimSpace(payload.Comments)
+	minutes.Conclusion = strings.TrimSpace(payload.Conclusion)
+	if minutes.ID == 0 {
+		minutes.CreatedBy = tokenData.Code
+	}
+	minutes.UpdatedBy = tokenData.Code
+	if err := db.Save(&minutes).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = minutes
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetMinutesPDF tải biên bản bảo vệ dạng PDF
+// @Summary Download defense minutes as PDF
+// @Description Bilingual defense minutes populated from the thesis, the committee, the defense slot, the review report, the final grades and the recorded minutes. Committee members, heads of subject and the faculty office.
+// @Tags Document
+// @Produce application/pdf
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {file} file
+// @Failure 500 {object} config.DataResponse
+// @Router /document/minutes/{thesisID}/pdf [get]
+func GetMinutesPDF(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	thesis, committee, err := loadThesis(db, c.Params("thesisID"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if _, member := memberOf(committee, tokenData); !member && !canManage(db, tokenData, thesis.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	data := minutesData{
+		Issuer:    issuer(db, thesis.SubjectID),
+		Thesis:    thesis,
+		Committee: committee,
+		Minutes:   loadMinutes(db, thesis.ID),
+	}
+	var slot modelDefense.DefenseSlot
+	if err := db.Where("THESIS_ID = ?", thesis.ID).First(&slot).Error; err == nil {
+		var session modelDefense.DefenseSession
+		if err := db.Preload("Room").First(&session, slot.SessionID).Error; err == nil {
+			data.Slot, data.Session = &slot, &session
+		}
+	}
+	var review modelReviewer.ReviewerAssignment
+	if err := db.Preload("Report.Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("POSITION, ID")
+	}).Where("THESIS_ID = ? AND STATUS = ?", thesis.ID, modelReviewer.AssignmentSubmitted).First(&review).Error; err == nil {
+		data.Review = &review
+	}
+	db.Where("THESIS_ID = ?", thesis.ID).Find(&data.Grades)
+
+	return send(c, defenseMinutes(data), fmt.Sprintf("minutes-%d.pdf", thesis.ID))
+}
+
+// GetCommitteeDecisionPDF tải quyết định thành lập hội đồng dạng PDF
+// @Summary Download the committee decision as PDF
+// @Description Bilingual decision establishing a defense committee with its members and the theses it examines. Committee members, heads of subject and the faculty office.
+// @Tags Document
+// @Produce application/pdf
+// @Param id path int true "Committee ID"
+// @Param number query string false "Decision number, e.g. 123/QĐ-CNTT"
+// @Success 200 {file} file
+// @Failure 500 {object} config.DataResponse
+// @Router /document/committee/{id}/decision/pdf [get]
+func GetCommitteeDecisionPDF(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	committee, theses, err := loadCommittee(db, c.Params("id"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if _, member := memberOf(committee, tokenData); !member && !canManage(db, tokenData, committee.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	doc := committeeDecision(issuer(db, committee.SubjectID), strings.TrimSpace(c.Query("number")), committee, theses)
+	return send(c, doc, fmt.Sprintf("committee-%s.pdf", committee.Code))
+}
+
+// GetGradeSheetPDF tải bảng điểm bảo vệ của hội đồng dạng PDF
+// @Summary Download the grade sheet of a committee as PDF
+// @Description Bilingual grade sheet with the advisor, reviewer, committee and final scores of every student examined by the committee. Committee members, heads of subject and the faculty office.
+// @Tags Document
+// @Produce application/pdf
+// @Param id path int true "Committee ID"
+// @Success 200 {file} file
+// @Failure 500 {object} config.DataResponse
+// @Router /document/committee/{id}/grades/pdf [get]
+func GetGradeSheetPDF(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	committee, theses, err := loadCommittee(db, c.Params("id"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if _, member := memberOf(committee, tokenData); !member && !canManage(db, tokenData, committee.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	ids := []uint{}
+	for _, thesis := range theses {
+		ids = append(ids, thesis.ID)
+	}
+	var grades []modelGrading.FinalGrade
+	if len(ids) > 0 {
+		db.Where("THESIS_ID IN ?", ids).Find(&grades)
+	}
+
+	return send(c, gradeSheet(issuer(db, committee.SubjectID), committee, theses, grades), fmt.Sprintf("grades-%s.pdf", committee.Code))
+}
+
+// GetTopicAssignmentPDF tải quyết định giao đề tài của học kỳ dạng PDF
+// @Summary Download the topic assignment decision as PDF
+// @Description Bilingual decision assigning the approved thesis topics of a semester to their students and advisors, limited to the theses in the caller's scope and optionally to one subject. Heads of subject and the faculty office.
+// @Tags Document
+// @Produce application/pdf
+// @Param semester query string true "Semester code"
+// @Param subjectID query int false "Subject ID"
+// @Param number query string false "Decision number, e.g. 124/QĐ-CNTT"
+// @Success 200 {file} file
+// @Failure 500 {object} config.DataResponse
+// @Router /document/topic-assignment/pdf [get]
+func GetTopicAssignmentPDF(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || (tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	semester, err := semesterController.Lookup(db, c.Query("semester"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		response.ValidateError = map[string]string{"semester": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+
+	scope := organizationController.ScopeOf(db, tokenData)
+	query := scope.Apply(db.Preload("Students", func(db *gorm.DB) *gorm.DB {
+		return db.Order("CODE")
+	}).Preload("Advisors").Where("APPROVAL_STATUS = ? AND UPPER(SEMESTER) = ?", modelThesis.ApprovalApproved, strings.ToUpper(semester.Code)), "SUBJECT_ID")
+	var subjectID *uint
+	if id := uint(c.QueryInt("subjectID")); id != 0 {
+		if !scope.Allows(&id) {
+			response.Status = false
+			response.Message = config.GetMessageCode("PERMISSION_DENIED")
+			return c.JSON(response)
+		}
+		subjectID = &id
+		query = query.Where("SUBJECT_ID = ?", id)
+	}
+	var theses []modelThesis.Thesis
+	if err := query.Order("ID").Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	if subjectID == nil {
+		subjectID = organizationController.DefaultSubject(db, tokenData)
+	}
+
+	doc := topicAssignment(issuer(db, subjectID), strings.TrimSpace(c.Query("number")), semester, theses)
+	return send(c, doc, fmt.Sprintf("topics-%s.pdf", semester.Code))
+}
+
+// send dựng file PDF và trả về dạng tệp đính kèm
+func send(c *fiber.Ctx, doc document.Document, filename string) error {
+	response := new(config.DataResponse)
+
+	data, err := document.Render(doc)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		if err == document.ErrFontMissing {
+			response.Message = config.GetMessageCode("DOCUMENT_FONT_MISSING")
+		}
+		return c.JSON(response)
+	}
+
+	c.Attachment(filename)
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	return c.Send(data)
+}
+
+// loadThesis đọc luận văn cùng hội đồng của nó; luận văn chưa có hội đồng thì không có biên bản
+func loadThesis(db *gorm.DB, id interface{}) (modelThesis.Thesis, modelCommittee.Committee, error) {
+	var thesis modelThesis.Thesis
+	var committee modelCommittee.Committee
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, id).Error; err != nil {
+		return thesis, committee, err
+	}
+	if thesis.CommitteeID == nil {
+		return thesis, committee, gorm.ErrRecordNotFound
+	}
+	err := db.Preload("Members").First(&committee, *thesis.CommitteeID).Error
+	return thesis, committee, err
+}
+
+func loadCommittee(db *gorm.DB, id interface{}) (modelCommittee.Committee, []modelThesis.Thesis, error) {
+	var committee modelCommittee.Committee
+	var theses []modelThesis.Thesis
+	if err := db.Preload("Members").First(&committee, id).Error; err != nil {
+		return committee, theses, err
+	}
+	err := db.Preload("Students", func(db *gorm.DB) *gorm.DB {
+		return db.Order("CODE")
+	}).Preload("Advisors").Where("COMMITTEE_ID = ?", committee.ID).Order("ID").Find(&theses).Error
+	return committee, theses, err
+}
+
+func loadMinutes(db *gorm.DB, thesisID uint) model.DefenseMinutes {
+	var minutes model.DefenseMinutes
+	db.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "ID DESC"}}).Where("THESIS_ID = ?", thesisID).First(&minutes)
+	minutes.LoadAbsent()
+	return minutes
+}
+
+// issuer: tên trường (UNIVERSITY_NAME) và khoa của subject, in ở góc trái đầu văn bản
+func issuer(db *gorm.DB, subjectID *uint) []string {
+	lines := []string{}
+	if university := strings.TrimSpace(config.Config("UNIVERSITY_NAME")); university != "" {
+		lines = append(lines, university)
+	}
+	if subjectID == nil {
+		return lines
+	}
+	var subject modelOrganization.Subject
+	var department modelOrganization.Department
+	var faculty modelOrganization.Faculty
+	if db.First(&subject, *subjectID).Error != nil || db.First(&department, subject.DepartmentID).Error != nil ||
+		db.First(&faculty, department.FacultyID).Error != nil {
+		return lines
+	}
+	return append(lines, faculty.Name)
+}
+
+// memberOf tìm người gọi trong hội đồng theo tài khoản hoặc mã cán bộ
+func memberOf(committee modelCommittee.Committee, tokenData *utils.TokenData) (modelCommittee.CommitteeMember, bool) {
+	for _, member := range committee.Members {
+		if (member.MemberID == tokenData.ID && member.MemberRole == tokenData.Role) ||
+			(tokenData.Code != "" && member.MemberCode == tokenData.Code) {
+			return member, true
+		}
+	}
+	return modelCommittee.CommitteeMember{}, false
+}
+
+// canManage: trưởng bộ môn và văn phòng khoa trong phạm vi của subject
+func canManage(db *gorm.DB, tokenData *utils.TokenData, subjectID *uint) bool {
+	if tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole {
+		return false
+	}
+	return organizationController.ScopeOf(db, tokenData).Allows(subjectID)
+}
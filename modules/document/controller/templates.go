@@ -0,0 +1,421 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/document"
+	"app/modules/document/model"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	modelCommittee "app/modules/committee/model"
+	modelDefense "app/modules/defense/model"
+	modelGrading "app/modules/grading/model"
+	modelReviewer "app/modules/reviewer/model"
+	modelSemester "app/modules/semester/model"
+	modelThesis "app/modules/thesis/model"
+)
+
+var roleTexts = map[string]document.Text{
+	modelCommittee.RoleChair:     document.T("Chủ tịch", "Chair"),
+	modelCommittee.RoleSecretary: document.T("Thư ký", "Secretary"),
+	modelCommittee.RoleReviewer:  document.T("Ủy viên phản biện", "Reviewer"),
+	modelCommittee.RoleMember:    document.T("Ủy viên", "Member"),
+}
+
+var roleOrder = map[string]int{
+	modelCommittee.RoleChair:     0,
+	modelCommittee.RoleSecretary: 1,
+	modelCommittee.RoleReviewer:  2,
+	modelCommittee.RoleMember:    3,
+}
+
+var recommendationTexts = map[string]document.Text{
+	modelReviewer.RecommendAccept: document.T("Đồng ý cho bảo vệ", "Accepted for defense"),
+	modelReviewer.RecommendRevise: document.T("Cần chỉnh sửa trước khi bảo vệ", "Revisions required before the defense"),
+	modelReviewer.RecommendReject: document.T("Không đồng ý cho bảo vệ", "Not accepted for defense"),
+}
+
+// minutesData là dữ liệu của biên bản bảo vệ một luận văn; Session, Slot và Review có thể trống
+type minutesData struct {
+	Issuer    []string
+	Thesis    modelThesis.Thesis
+	Committee modelCommittee.Committee
+	Session   *modelDefense.DefenseSession
+	Slot      *modelDefense.DefenseSlot
+	Review    *modelReviewer.ReviewerAssignment
+	Grades    []modelGrading.FinalGrade
+	Minutes   model.DefenseMinutes
+}
+
+// defenseMinutes dựng biên bản buổi bảo vệ theo mẫu song ngữ
+func defenseMinutes(data minutesData) document.Document {
+	doc := newDocument(data.Issuer, "")
+	doc.Title = document.T("Biên bản bảo vệ luận văn tốt nghiệp", "Minutes of the thesis defense")
+	doc.Subtitle = document.T(data.Thesis.TitleVi, data.Thesis.TitleEn)
+
+	fields := []document.Field{
+		{Label: document.T("Sinh viên", "Students"), Value: studentNames(data.Thesis)},
+		{Label: document.T("Giảng viên hướng dẫn", "Advisors"), Value: advisorNames(data.Thesis)},
+		{Label: document.T("Hội đồng", "Committee"), Value: committeeName(data.Committee)},
+		{Label: document.T("Học kỳ", "Semester"), Value: data.Thesis.Semester},
+	}
+	if data.Review != nil {
+		fields = append(fields, document.Field{Label: document.T("Giảng viên phản biện", "Reviewer"), Value: data.Review.FullName})
+	}
+	start, end := data.Minutes.StartedAt, data.Minutes.EndedAt
+	if data.Slot != nil {
+		if start == nil {
+			start = &data.Slot.StartAt
+		}
+		if end == nil {
+			end = &data.Slot.EndAt
+		}
+	}
+	if start != nil {
+		value := clock(*start)
+		if end != nil {
+			value += " - " + clock(*end)
+		}
+		fields = append(fields, document.Field{Label: document.T("Thời gian", "Time"), Value: value})
+	}
+	if data.Session != nil {
+		fields = append(fields, document.Field{Label: document.T("Địa điểm", "Room"), Value: roomName(data.Session.Room)})
+	}
+	doc.Blocks = append(doc.Blocks, document.Block{Fields: fields})
+
+	members := &document.Table{Columns: []document.Column{
+		{Label: document.T("STT", "No."), Width: 0.6, Align: "C"},
+		{Label: document.T("Họ và tên", "Full name"), Width: 3},
+		{Label: document.T("Vai trò", "Role"), Width: 2},
+		{Label: document.T("Có mặt", "Present"), Width: 1.2, Align: "C"},
+	}}
+	for i, member := range sortedMembers(data.Committee.Members) {
+		present := "Có / Yes"
+		if data.Minutes.IsAbsent(member.ID) {
+			present = "Vắng / Absent"
+		}
+		members.Rows = append(members.Rows, []string{fmt.Sprint(i + 1), member.FullName, bilingual(roleTexts[member.Role]), present})
+	}
+	doc.Blocks = append(doc.Blocks, document.Block{Heading: document.T("I. Thành phần hội đồng", "Committee members"), Table: members})
+
+	doc.Blocks = append(doc.Blocks, document.Block{
+		Heading:   document.T("II. Sinh viên trình bày luận văn", "Presentation of the thesis"),
+		Paragraph: document.T(orDash(data.Minutes.Presentation), ""),
+	})
+
+	review := document.Block{Heading: document.T("III. Nhận xét của giảng viên phản biện", "Reviewer's assessment")}
+	if data.Review != nil && data.Review.Report != nil {
+		report := data.Review.Report
+		review.Paragraph = document.T(report.Summary, "")
+		review.Fields = []document.Field{
+			{Label: document.T("Kết luận của phản biện", "Recommendation"), Value: bilingual(recommendationTexts[report.Recommendation])},
+		}
+		for _, question := range report.Questions {
+			review.Lines = append(review.Lines, question.Question)
+		}
+	} else {
+		review.Paragraph = document.T("Chưa có nhận xét phản biện.", "No review report has been submitted.")
+	}
+	doc.Blocks = append(doc.Blocks, review)
+
+	doc.Blocks = append(doc.Blocks,
+		document.Block{
+			Heading:   document.T("IV. Câu hỏi của hội đồng và trả lời của sinh viên", "Questions from the committee and answers"),
+			Paragraph: document.T(orDash(data.Minutes.Questions), ""),
+		},
+		document.Block{
+			Heading:   document.T("V. Nhận xét của hội đồng", "Comments of the committee"),
+			Paragraph: document.T(orDash(data.Minutes.Comments), ""),
+		},
+	)
+
+	grades := gradeIndex(data.Grades)
+	result := &document.Table{Columns: []document.Column{
+		{Label: document.T("STT", "No."), Width: 0.6, Align: "C"},
+		{Label: document.T("Sinh viên", "Student"), Width: 3},
+		{Label: document.T("Hướng dẫn", "Advisor"), Width: 1.2, Align: "C"},
+		{Label: document.T("Phản biện", "Reviewer"), Width: 1.2, Align: "C"},
+		{Label: document.T("Hội đồng", "Committee"), Width: 1.2, Align: "C"},
+		{Label: document.T("Điểm tổng kết", "Final grade"), Width: 1.3, Align: "C"},
+	}}
+	for i, student := range data.Thesis.Students {
+		grade, ok := grades[student.ID]
+		result.Rows = append(result.Rows, []string{
+			fmt.Sprint(i + 1),
+			fmt.Sprintf("%s (%s)", student.FullName, student.Code),
+			document.Score(grade.AdvisorScore, ok),
+			document.Score(grade.ReviewerScore, ok),
+			document.Score(grade.CommitteeScore, ok),
+			finalGrade(grade, ok),
+		})
+	}
+	doc.Blocks = append(doc.Blocks, document.Block{Heading: document.T("VI. Kết quả đánh giá", "Assessment result"), Table: result})
+
+	doc.Blocks = append(doc.Blocks, document.Block{
+		Heading:   document.T("VII. Kết luận", "Conclusion"),
+		Paragraph: document.T(orDash(data.Minutes.Conclusion), ""),
+	})
+
+	doc.Signatures = []document.Signature{
+		{Title: roleTexts[modelCommittee.RoleSecretary], Name: memberName(data.Committee, modelCommittee.RoleSecretary)},
+		{Title: roleTexts[modelCommittee.RoleChair], Name: memberName(data.Committee, modelCommittee.RoleChair)},
+	}
+	return doc
+}
+
+// committeeDecision dựng quyết định thành lập hội đồng bảo vệ
+func committeeDecision(issuer []string, number string, committee modelCommittee.Committee, theses []modelThesis.Thesis) document.Document {
+	doc := newDocument(issuer, number)
+	doc.Title = document.T("Quyết định", "Decision")
+	doc.Subtitle = document.T(
+		"Về việc thành lập hội đồng đánh giá luận văn tốt nghiệp học kỳ "+committee.Semester,
+		"On the establishment of the thesis defense committee, semester "+committee.Semester,
+	)
+
+	members := &document.Table{Columns: []document.Column{
+		{Label: document.T("STT", "No."), Width: 0.6, Align: "C"},
+		{Label: document.T("Họ và tên", "Full name"), Width: 3},
+		{Label: document.T("Mã cán bộ", "Staff code"), Width: 1.4, Align: "C"},
+		{Label: document.T("Vai trò", "Role"), Width: 2},
+	}}
+	for i, member := range sortedMembers(committee.Members) {
+		members.Rows = append(members.Rows, []string{fmt.Sprint(i + 1), member.FullName, member.MemberCode, bilingual(roleTexts[member.Role])})
+	}
+
+	doc.Blocks = []document.Block{
+		{
+			Paragraph: document.T(
+				fmt.Sprintf("Điều 1. Thành lập hội đồng đánh giá luận văn tốt nghiệp %s gồm các thành viên sau:", committeeName(committee)),
+				fmt.Sprintf("Article 1. The thesis defense committee %s is established with the following members:", committeeName(committee)),
+			),
+			Table: members,
+		},
+		{
+			Paragraph: document.T(
+				"Điều 2. Hội đồng có nhiệm vụ tổ chức bảo vệ và đánh giá các luận văn sau:",
+				"Article 2. The committee organises the defense and assesses the following theses:",
+			),
+			Table: thesisTable(theses),
+		},
+		{
+			Paragraph: document.T(
+				"Điều 3. Hội đồng tự giải thể sau khi hoàn thành nhiệm vụ. Các thành viên hội đồng và các cá nhân có liên quan chịu trách nhiệm thi hành quyết định này.",
+				"Article 3. The committee is dissolved once its duties are completed. The committee members and the individuals concerned are responsible for implementing this decision.",
+			),
+		},
+	}
+	doc.Signatures = []document.Signature{{Title: document.T("Trưởng khoa", "Dean")}}
+	return doc
+}
+
+// topicAssignment dựng quyết định giao đề tài cho các sinh viên của học kỳ
+func topicAssignment(issuer []string, number string, semester modelSemester.Semester, theses []modelThesis.Thesis) document.Document {
+	doc := newDocument(issuer, number)
+	doc.Title = document.T("Quyết định", "Decision")
+	doc.Subtitle = document.T(
+		"Về việc giao đề tài luận văn tốt nghiệp học kỳ "+semester.Code,
+		"On the assignment of graduation thesis topics, semester "+semester.Code,
+	)
+
+	table := &document.Table{Columns: []document.Column{
+		{Label: document.T("STT", "No."), Width: 0.6, Align: "C"},
+		{Label: document.T("Mã SV", "Student ID"), Width: 1.3, Align: "C"},
+		{Label: document.T("Họ và tên", "Full name"), Width: 2.2},
+		{Label: document.T("Tên đề tài", "Thesis title"), Width: 4},
+		{Label: document.T("Giảng viên hướng dẫn", "Advisors"), Width: 2.2},
+	}}
+	row := 0
+	for _, thesis := range theses {
+		for _, student := range thesis.Students {
+			row++
+			table.Rows = append(table.Rows, []string{fmt.Sprint(row), student.Code, student.FullName, titles(thesis), advisorNames(thesis)})
+		}
+	}
+
+	start, end := dateOnly(semester.StartDate), dateOnly(semester.EndDate)
+	doc.Blocks = []document.Block{
+		{
+			Paragraph: document.T(
+				"Điều 1. Giao đề tài luận văn tốt nghiệp cho các sinh viên có tên sau, dưới sự hướng dẫn của các giảng viên được phân công:",
+				"Article 1. The following students are assigned the thesis topics below under the supervision of the listed advisors:",
+			),
+			Table: table,
+		},
+		{
+			Paragraph: document.T(
+				fmt.Sprintf("Điều 2. Thời gian thực hiện luận văn từ ngày %s đến ngày %s.", start, end),
+				fmt.Sprintf("Article 2. The theses are carried out from %s to %s.", start, end),
+			),
+		},
+		{
+			Paragraph: document.T(
+				"Điều 3. Sinh viên, giảng viên hướng dẫn và các đơn vị liên quan chịu trách nhiệm thi hành quyết định này.",
+				"Article 3. The students, advisors and units concerned are responsible for implementing this decision.",
+			),
+		},
+	}
+	doc.Signatures = []document.Signature{{Title: document.T("Trưởng khoa", "Dean")}}
+	return doc
+}
+
+// gradeSheet dựng bảng điểm bảo vệ của các sinh viên trong một hội đồng
+func gradeSheet(issuer []string, committee modelCommittee.Committee, theses []modelThesis.Thesis, grades []modelGrading.FinalGrade) document.Document {
+	doc := newDocument(issuer, "")
+	doc.Title = document.T("Bảng điểm bảo vệ luận văn tốt nghiệp", "Thesis defense grade sheet")
+	doc.Subtitle = document.T(
+		fmt.Sprintf("Hội đồng %s - học kỳ %s", committeeName(committee), committee.Semester),
+		fmt.Sprintf("Committee %s - semester %s", committeeName(committee), committee.Semester),
+	)
+
+	index := gradeIndex(grades)
+	table := &document.Table{Columns: []document.Column{
+		{Label: document.T("STT", "No."), Width: 0.6, Align: "C"},
+		{Label: document.T("Mã SV", "Student ID"), Width: 1.3, Align: "C"},
+		{Label: document.T("Họ và tên", "Full name"), Width: 2.2},
+		{Label: document.T("Tên đề tài", "Thesis title"), Width: 3},
+		{Label: document.T("Hướng dẫn", "Advisor"), Width: 1.1, Align: "C"},
+		{Label: document.T("Phản biện", "Reviewer"), Width: 1.1, Align: "C"},
+		{Label: document.T("Hội đồng", "Committee"), Width: 1.1, Align: "C"},
+		{Label: document.T("Tổng kết", "Final"), Width: 1.1, Align: "C"},
+	}}
+	row := 0
+	for _, thesis := range theses {
+		for _, student := range thesis.Students {
+			row++
+			grade, ok := index[student.ID]
+			table.Rows = append(table.Rows, []string{
+				fmt.Sprint(row),
+				student.Code,
+				student.FullName,
+				thesis.TitleVi,
+				document.Score(grade.AdvisorScore, ok),
+				document.Score(grade.ReviewerScore, ok),
+				document.Score(grade.CommitteeScore, ok),
+				finalGrade(grade, ok),
+			})
+		}
+	}
+	doc.Blocks = []document.Block{{Table: table}}
+	doc.Signatures = []document.Signature{
+		{Title: roleTexts[modelCommittee.RoleSecretary], Name: memberName(committee, modelCommittee.RoleSecretary)},
+		{Title: roleTexts[modelCommittee.RoleChair], Name: memberName(committee, modelCommittee.RoleChair)},
+	}
+	return doc
+}
+
+// newDocument điền đơn vị ban hành, số văn bản, địa danh (DOCUMENT_PLACE) và ngày hôm nay
+func newDocument(issuer []string, number string) document.Document {
+	return document.Document{
+		Issuer: issuer,
+		Number: number,
+		Place:  config.Config("DOCUMENT_PLACE"),
+		Date:   core.Now(),
+	}
+}
+
+func thesisTable(theses []modelThesis.Thesis) *document.Table {
+	table := &document.Table{Columns: []document.Column{
+		{Label: document.T("STT", "No."), Width: 0.6, Align: "C"},
+		{Label: document.T("Tên đề tài", "Thesis title"), Width: 4},
+		{Label: document.T("Sinh viên", "Students"), Width: 2.5},
+		{Label: document.T("Giảng viên hướng dẫn", "Advisors"), Width: 2.2},
+	}}
+	for i, thesis := range theses {
+		table.Rows = append(table.Rows, []string{fmt.Sprint(i + 1), titles(thesis), studentNames(thesis), advisorNames(thesis)})
+	}
+	return table
+}
+
+// gradeIndex: điểm tổng kết theo sinh viên
+func gradeIndex(grades []modelGrading.FinalGrade) map[uint]modelGrading.FinalGrade {
+	index := map[uint]modelGrading.FinalGrade{}
+	for _, grade := range grades {
+		index[grade.StudentID] = grade
+	}
+	return index
+}
+
+// finalGrade in điểm tổng kết; điểm đang chờ chấm lại không được in
+func finalGrade(grade modelGrading.FinalGrade, ok bool) string {
+	if ok && grade.Status == modelGrading.GradeReevaluation {
+		return "Chấm lại / Re-evaluation"
+	}
+	return document.Score(grade.Grade, ok)
+}
+
+func sortedMembers(members []modelCommittee.CommitteeMember) []modelCommittee.CommitteeMember {
+	sorted := append([]modelCommittee.CommitteeMember{}, members...)
+	sort.SliceStable(sorted, func(i, j int) bool { return roleOrder[sorted[i].Role] < roleOrder[sorted[j].Role] })
+	return sorted
+}
+
+func memberName(committee modelCommittee.Committee, role string) string {
+	for _, member := range committee.Members {
+		if member.Role == role {
+			return member.FullName
+		}
+	}
+	return ""
+}
+
+func committeeName(committee modelCommittee.Committee) string {
+	if committee.Name == "" {
+		return committee.Code
+	}
+	return fmt.Sprintf("%s (%s)", committee.Name, committee.Code)
+}
+
+func roomName(room modelDefense.Room) string {
+	if room.Building == "" {
+		return strings.TrimSpace(room.Code + " " + room.Name)
+	}
+	return fmt.Sprintf("%s, %s", strings.TrimSpace(room.Code+" "+room.Name), room.Building)
+}
+
+func studentNames(thesis modelThesis.Thesis) string {
+	names := []string{}
+	for _, student := range thesis.Students {
+		names = append(names, fmt.Sprintf("%s (%s)", student.FullName, student.Code))
+	}
+	return strings.Join(names, "; ")
+}
+
+func advisorNames(thesis modelThesis.Thesis) string {
+	names := []string{}
+	for _, advisor := range thesis.Advisors {
+		names = append(names, advisor.FullName)
+	}
+	return strings.Join(names, "; ")
+}
+
+func titles(thesis modelThesis.Thesis) string {
+	if thesis.TitleEn == "" {
+		return thesis.TitleVi
+	}
+	return thesis.TitleVi + "\n" + thesis.TitleEn
+}
+
+func bilingual(text document.Text) string {
+	if text.En == "" {
+		return text.Vi
+	}
+	return text.Vi + " / " + text.En
+}
+
+func orDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
+
+func clock(t time.Time) string {
+	return t.In(core.CampusLocation()).Format("15:04 02/01/2006")
+}
+
+func dateOnly(t time.Time) string {
+	return t.In(core.CampusLocation()).Format("02/01/2006")
+}
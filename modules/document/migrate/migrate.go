@@ -0,0 +1,14 @@
+package documentMigrate
+
+import (
+	"app/database"
+	model "app/modules/document/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.DefenseMinutes{})
+
+	return true
+}
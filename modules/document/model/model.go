@@ -0,0 +1,67 @@
+package model
+
+import (
+	"app/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefenseMinutes là biên bản buổi bảo vệ do thư ký hội đồng ghi; file PDF được dựng lại từ dữ liệu mỗi lần tải.
+// AbsentMembers là danh sách ID thành viên hội đồng vắng mặt, ngăn cách bởi dấu phẩy.
+type DefenseMinutes struct {
+	model.Header
+	ThesisID      uint       `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	CommitteeID   uint       `json:"committeeID" gorm:"column:COMMITTEE_ID;index"`
+	StartedAt     *time.Time `json:"startedAt" gorm:"column:STARTED_AT"`
+	EndedAt       *time.Time `json:"endedAt" gorm:"column:ENDED_AT"`
+	AbsentMembers string     `json:"-" gorm:"column:ABSENT_MEMBERS"`
+	Presentation  string     `json:"presentation" gorm:"column:PRESENTATION"`
+	Questions     string     `json:"questions" gorm:"column:QUESTIONS"`
+	Comments      string     `json:"comments" gorm:"column:COMMENTS"`
+	Conclusion    string     `json:"conclusion" gorm:"column:CONCLUSION"`
+	Absent        []uint     `json:"absentMemberIDs" gorm:"-"`
+}
+
+type SaveMinutes struct {
+	StartedAt       string `json:"startedAt"`
+	EndedAt         string `json:"endedAt"`
+	AbsentMemberIDs []uint `json:"absentMemberIDs"`
+	Presentation    string `json:"presentation"`
+	Questions       string `json:"questions"`
+	Comments        string `json:"comments"`
+	Conclusion      string `json:"conclusion"`
+}
+
+// SetAbsent lưu danh sách thành viên vắng mặt
+func (m *DefenseMinutes) SetAbsent(ids []uint) {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatUint(uint64(id), 10))
+	}
+	m.AbsentMembers = strings.Join(values, ",")
+	m.Absent = ids
+}
+
+// LoadAbsent đọc lại danh sách thành viên vắng mặt sau khi truy vấn
+func (m *DefenseMinutes) LoadAbsent() {
+	m.Absent = []uint{}
+	for _, value := range strings.Split(m.AbsentMembers, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+			m.Absent = append(m.Absent, uint(id))
+		}
+	}
+}
+
+func (m DefenseMinutes) IsAbsent(memberID uint) bool {
+	for _, id := range m.Absent {
+		if id == memberID {
+			return true
+		}
+	}
+	return false
+}
+
+func (DefenseMinutes) TableName() string {
+	return "TBL_DEFENSE_MINUTES"
+}
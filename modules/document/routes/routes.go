@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"app/modules/document/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitDocumentRoutes(app *fiber.App) {
+	document := app.Group("/document")
+
+	document.Get("/minutes/:thesisID", controller.GetMinutes)
+	document.Put("/minutes/:thesisID", controller.SaveMinutes)
+	document.Get("/minutes/:thesisID/pdf", controller.GetMinutesPDF)
+	document.Get("/committee/:id/decision/pdf", controller.GetCommitteeDecisionPDF)
+	document.Get("/committee/:id/grades/pdf", controller.GetGradeSheetPDF)
+	document.Get("/topic-assignment/pdf", controller.GetTopicAssignmentPDF)
+}
@@ -7,6 +7,8 @@ import (
 type FacultyOffice struct {
 	model.Info `gorm:"embedded;-:migration"`
 	Code       string `json:"code" gorm:"column:CODE;size:10;not null"`
+	// FacultyID giới hạn văn phòng khoa vào các luận văn của khoa; chưa gán thì xem toàn trường
+	FacultyID *uint `json:"facultyID" gorm:"column:FACULTY_ID;index"`
 }
 
 type CreateFacultyOffice struct {
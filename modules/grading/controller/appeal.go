@@ -0,0 +1,848 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/grading/model"
+	"app/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelCommittee "app/modules/committee/model"
+	modelHeadOfSubject "app/modules/headOfSubject/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	organizationController "app/modules/organization/controller"
+	semesterController "app/modules/semester/controller"
+	thesisController "app/modules/thesis/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PublishGrades công bố và khóa điểm tổng kết của học kỳ
+// @Summary Publish the final grades of a semester
+// @Description Publish and lock the computed final grades of the approved theses of a semester in the caller's scope, optionally of one subject. Every student must have a computed grade that is not under re-evaluation and every deliverable of the thesis must be accepted. Students are notified and may appeal until appealDeadline (YYYY-MM-DD, default DefaultAppealDays days from now). Head of subject or faculty office.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param body body model.PublishGrades true "Semester to publish"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/publication [post]
+func PublishGrades(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || (tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.PublishGrades
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Semester) == "" {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"semester": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+	semester, err := semesterController.Lookup(db, payload.Semester)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		response.ValidateError = map[string]string{"semester": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+	scope := organizationController.ScopeOf(db, tokenData)
+	var subjectID *uint
+	if payload.SubjectID != 0 {
+		if !scope.Allows(&payload.SubjectID) {
+			response.Status = false
+			response.Message = config.GetMessageCode("PERMISSION_DENIED")
+			return c.JSON(response)
+		}
+		subjectID = &payload.SubjectID
+	}
+	deadline, errors := appealDeadline(payload.AppealDeadline)
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	query := scope.Apply(tx.Preload("Students").Where("APPROVAL_STATUS = ? AND UPPER(SEMESTER) = ?", modelThesis.ApprovalApproved, strings.ToUpper(semester.Code)), "SUBJECT_ID")
+	if subjectID != nil {
+		query = query.Where("SUBJECT_ID = ?", *subjectID)
+	}
+	var theses []modelThesis.Thesis
+	if err := query.Order("ID").Find(&theses).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	ids := []uint{}
+	for _, thesis := range theses {
+		ids = append(ids, thesis.ID)
+	}
+	var grades []model.FinalGrade
+	if len(ids) > 0 {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("THESIS_ID IN ?", ids).Find(&grades).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	index := map[string]model.FinalGrade{}
+	for _, grade := range grades {
+		index[fmt.Sprintf("%d/%d", grade.ThesisID, grade.StudentID)] = grade
+	}
+	gradeIDs := []uint{}
+	publishedTheses := []modelThesis.Thesis{}
+	for _, thesis := range theses {
+		pending := []string{}
+		count := 0
+		for _, student := range thesis.Students {
+			grade, ok := index[fmt.Sprintf("%d/%d", thesis.ID, student.ID)]
+			switch {
+			case !ok:
+				pending = append(pending, fmt.Sprintf("%s: grade not computed", student.Code))
+			case grade.PublicationID != nil:
+			case grade.Status != model.GradeComputed:
+				pending = append(pending, fmt.Sprintf("%s: grade under re-evaluation", student.Code))
+			default:
+				gradeIDs = append(gradeIDs, grade.ID)
+				count++
+			}
+		}
+		// Chỉ công bố khi mọi sản phẩm bàn giao của luận văn đã được nghiệm thu
+		if count > 0 {
+			summary, err := thesisController.SummarizeDeliverables(tx, thesis.ID)
+			if err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("SYSTEM_ERROR")
+				return c.JSON(response)
+			}
+			if !summary.AllMet {
+				pending = append(pending, "deliverables not accepted: "+unmetDeliverables(summary))
+			}
+		}
+		if len(pending) > 0 {
+			errors[fmt.Sprint(thesis.ID)] = strings.Join(pending, "; ")
+		}
+		if count > 0 {
+			publishedTheses = append(publishedTheses, thesis)
+		}
+	}
+	if len(errors) == 0 && len(gradeIDs) == 0 {
+		errors["semester"] = "no computed grades left to publish"
+	}
+	if len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("GRADING_INCOMPLETE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	publication := model.GradePublication{
+		Semester:       semester.Code,
+		SubjectID:      subjectID,
+		PublishedAt:    now,
+		AppealDeadline: deadline,
+		GradeCount:     len(gradeIDs),
+	}
+	publication.CreatedBy = tokenData.Code
+	if err := tx.Create(&publication).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&model.FinalGrade{}).Where("ID IN ?", gradeIDs).Updates(map[string]interface{}{
+		"PUBLICATION_ID": publication.ID,
+		"PUBLISHED_AT":   now,
+		"STATUS":         model.GradePublished,
+		"UPDATED_BY":     tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	for _, thesis := range publishedTheses {
+		recipients := []modelNotification.Recipient{}
+		for _, student := range thesis.Students {
+			recipients = append(recipients, modelNotification.Recipient{UserID: student.ID, Role: modelUsers.StudentRole})
+		}
+		if err := notificationController.Notify(tx, modelNotification.Message{
+			Type:  modelNotification.NotificationGradesPublished,
+			Title: "Thesis grade published",
+			Body:  fmt.Sprintf("Your final grade for \"%s\" is published; appeals are accepted until %s", thesis.TitleVi, deadline.In(core.CampusLocation()).Format("2006-01-02")),
+			Link:  fmt.Sprintf("/grading/thesis/%d/grade", thesis.ID),
+		}, recipients...); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Data = publication
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetPublications trả về các lần công bố điểm
+// @Summary List grade publications
+// @Description Grade publications in the caller's scope, optionally of one semester. Head of subject or faculty office.
+// @Tags Grading
+// @Produce json
+// @Param semester query string false "Semester code"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/publication [get]
+func GetPublications(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || (tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	query := db.Where("ID IN (?)", db.Model(&model.FinalGrade{}).Select("PUBLICATION_ID").
+		Where("THESIS_ID IN (?)", scopedTheses(db, tokenData)))
+	if semester := strings.TrimSpace(c.Query("semester")); semester != "" {
+		query = query.Where("UPPER(SEMESTER) = ?", strings.ToUpper(semester))
+	}
+	var publications []model.GradePublication
+	if err := query.Order("PUBLISHED_AT DESC").Find(&publications).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = publications
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetAppeals trả về các đơn phúc khảo
+// @Summary List grade appeals
+// @Description Students see their own appeals, lecturers the appeals assigned to them, heads of subject and the faculty office the appeals on theses of their subject or faculty
+// @Tags Grading
+// @Produce json
+// @Param status query string false "SUBMITTED, ASSIGNED, UPHELD or REJECTED"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/appeal [get]
+func GetAppeals(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	query := db.Order("CREATED_AT DESC")
+	switch tokenData.Role {
+	case modelUsers.StudentRole:
+		query = query.Where("STUDENT_ID = ?", tokenData.ID)
+	case modelUsers.AdvisorRole:
+		query = query.Where("REVIEWER_ID = ?", tokenData.ID)
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		query = query.Where("THESIS_ID IN (?)", scopedTheses(db, tokenData))
+	default:
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if status := strings.ToUpper(c.Query("status")); status != "" {
+		query = query.Where("STATUS = ?", status)
+	}
+
+	var appeals []model.GradeAppeal
+	if err := query.Find(&appeals).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = appeals
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetAppeal trả về một đơn phúc khảo
+// @Summary Get a grade appeal
+// @Description Get a grade appeal with its re-evaluation outcome
+// @Tags Grading
+// @Produce json
+// @Param id path int true "Appeal ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/appeal/{id} [get]
+func GetAppeal(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var appeal model.GradeAppeal
+	if err := db.First(&appeal, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canViewAppeal(db, tokenData, appeal) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	response.Data = appeal
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateAppeal sinh viên gửi đơn phúc khảo điểm đã công bố
+// @Summary Appeal a published grade
+// @Description A student appeals their published final grade with a justification before the appeal deadline of the publication. Each grade can be appealed once. The heads of subject of the thesis are notified.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param body body model.CreateAppeal true "Appeal"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/appeal [post]
+func CreateAppeal(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateAppeal
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	vItem := map[string]string{"reason": strings.TrimSpace(payload.Reason)}
+	errors := utils.RequireCheck([]string{"reason"}, vItem, map[string]string{})
+	if payload.GradeID == 0 {
+		errors["gradeID"] = config.GetMessageCode("REQUIRE")
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	var grade model.FinalGrade
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&grade, payload.GradeID).Error; err != nil ||
+		grade.StudentID != tokenData.ID || grade.PublicationID == nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	var publication model.GradePublication
+	if err := tx.First(&publication, *grade.PublicationID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if core.Now().After(publication.AppealDeadline) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("APPEAL_DEADLINE_PASSED")
+		return c.JSON(response)
+	}
+	var count int64
+	tx.Model(&model.GradeAppeal{}).Where("GRADE_ID = ?", grade.ID).Count(&count)
+	if count > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("APPEAL_EXISTS")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := tx.Select("ID, TITLE_VI, SUBJECT_ID").First(&thesis, grade.ThesisID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	appeal := model.GradeAppeal{
+		GradeID:   grade.ID,
+		ThesisID:  grade.ThesisID,
+		StudentID: grade.StudentID,
+		Reason:    vItem["reason"],
+		Status:    model.AppealSubmitted,
+		OldGrade:  grade.Grade,
+	}
+	appeal.CreatedBy = tokenData.Code
+	if err := tx.Create(&appeal).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := notificationController.Notify(tx, modelNotification.Message{
+		Type:  modelNotification.NotificationAppealSubmitted,
+		Title: "Grade appeal submitted",
+		Body:  fmt.Sprintf("Student %s appealed the grade %.1f of \"%s\"; please assign a lecturer to re-evaluate it", tokenData.Code, grade.Grade, thesis.TitleVi),
+		Link:  fmt.Sprintf("/grading/appeal/%d", appeal.ID),
+	}, headRecipients(tx, thesis)...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = appeal
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AssignAppeal giao đơn phúc khảo cho giảng viên chấm lại
+// @Summary Assign a lecturer to re-evaluate an appeal
+// @Description Assign (or reassign) a lecturer who neither advises, graded nor sits on the committee of the thesis to re-evaluate the appealed grade. The lecturer is notified. Head of subject or faculty office.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param id path int true "Appeal ID"
+// @Param body body model.AssignAppeal true "Lecturer"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/appeal/{id}/assign [put]
+func AssignAppeal(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.AssignAppeal
+	if err := c.BodyParser(&payload); err != nil || payload.ReviewerID == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"reviewerID": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	appeal, thesis, err := lockAppeal(tx, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canCompute(tx, tokenData, thesis) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if appeal.Status != model.AppealSubmitted && appeal.Status != model.AppealAssigned {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+	var lecturer modelAdvisor.Advisor
+	if err := tx.First(&lecturer, payload.ReviewerID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		response.ValidateError = map[string]string{"reviewerID": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+	if involved(tx, thesis, lecturer) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("CONFLICT_OF_INTEREST")
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	if err := tx.Model(&appeal).Updates(map[string]interface{}{
+		"STATUS":        model.AppealAssigned,
+		"REVIEWER_ID":   lecturer.ID,
+		"REVIEWER_CODE": lecturer.Code,
+		"FULL_NAME":     lecturer.FullName,
+		"ASSIGNED_AT":   now,
+		"UPDATED_BY":    tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	appeal.Status = model.AppealAssigned
+	appeal.ReviewerID = &lecturer.ID
+	appeal.ReviewerCode = lecturer.Code
+	appeal.FullName = lecturer.FullName
+	appeal.AssignedAt = &now
+	if err := notificationController.Notify(tx, modelNotification.Message{
+		Type:  modelNotification.NotificationAppealAssigned,
+		Title: "Grade appeal assigned",
+		Body:  fmt.Sprintf("Please re-evaluate the appealed grade %.1f of \"%s\"", appeal.OldGrade, thesis.TitleVi),
+		Link:  fmt.Sprintf("/grading/appeal/%d", appeal.ID),
+	}, modelNotification.Recipient{UserID: lecturer.ID, Role: modelUsers.AdvisorRole}); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = appeal
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DecideAppeal ghi kết quả phúc khảo
+// @Summary Decide a grade appeal
+// @Description The assigned lecturer submits the re-evaluated grade with a comment: a different grade upholds the appeal, replaces the published grade and is recorded as a grade revision, the same grade rejects it. A head of subject or the faculty office may reject an appeal that has not been assigned yet by leaving grade empty. The student and the advisors are notified of the outcome.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param id path int true "Appeal ID"
+// @Param body body model.DecideAppeal true "Decision"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/appeal/{id}/decide [put]
+func DecideAppeal(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.DecideAppeal
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	payload.Comment = strings.TrimSpace(payload.Comment)
+	if payload.Comment == "" {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"comment": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	appeal, thesis, err := lockAppeal(tx, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	assigned := tokenData.Role == modelUsers.AdvisorRole && appeal.ReviewerID != nil && *appeal.ReviewerID == tokenData.ID
+	if !assigned && !canCompute(tx, tokenData, thesis) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if (assigned && appeal.Status != model.AppealAssigned) || (!assigned && appeal.Status != model.AppealSubmitted) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+	switch {
+	case assigned && payload.Grade == nil:
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"grade": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	case !assigned && payload.Grade != nil:
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"grade": "only the assigned lecturer can re-evaluate the grade"}
+		return c.JSON(response)
+	case payload.Grade != nil && (*payload.Grade < 0 || *payload.Grade > model.MaxGrade):
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"grade": fmt.Sprintf("must be between 0 and %g", model.MaxGrade)}
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	status := model.AppealRejected
+	updates := map[string]interface{}{
+		"COMMENT":    payload.Comment,
+		"DECIDED_BY": tokenData.Code,
+		"DECIDED_AT": now,
+		"UPDATED_BY": tokenData.Code,
+	}
+	if payload.Grade != nil {
+		newGrade := model.Round(*payload.Grade, 1)
+		appeal.NewGrade = &newGrade
+		updates["NEW_GRADE"] = newGrade
+		if newGrade != appeal.OldGrade {
+			status = model.AppealUpheld
+			if err := revise(tx, appeal, newGrade, payload.Comment, tokenData.Code, now); err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("SYSTEM_ERROR")
+				return c.JSON(response)
+			}
+		}
+	}
+	updates["STATUS"] = status
+	if err := tx.Model(&appeal).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	appeal.Status = status
+	appeal.Comment = payload.Comment
+	appeal.DecidedBy = tokenData.Code
+	appeal.DecidedAt = &now
+
+	body := fmt.Sprintf("The appeal on \"%s\" was rejected; the grade stays %.1f", thesis.TitleVi, appeal.OldGrade)
+	if status == model.AppealUpheld {
+		body = fmt.Sprintf("The appeal on \"%s\" was upheld; the grade changed from %.1f to %.1f", thesis.TitleVi, appeal.OldGrade, *appeal.NewGrade)
+	}
+	recipients := []modelNotification.Recipient{{UserID: appeal.StudentID, Role: modelUsers.StudentRole}}
+	for _, advisor := range thesis.Advisors {
+		recipients = append(recipients, modelNotification.Recipient{UserID: advisor.ID, Role: modelUsers.AdvisorRole})
+	}
+	if err := notificationController.Notify(tx, modelNotification.Message{
+		Type:  modelNotification.NotificationAppealDecided,
+		Title: "Grade appeal decided",
+		Body:  body,
+		Link:  fmt.Sprintf("/grading/appeal/%d", appeal.ID),
+	}, recipients...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = appeal
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetGradeRevisions trả về lịch sử sửa điểm đã công bố của luận văn
+// @Summary Get grade revisions of a thesis
+// @Description Old and new grades recorded whenever a published grade changed through an appeal. Heads of subject, the faculty office and the advisors of the thesis; students see their own revisions.
+// @Tags Grading
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/thesis/{thesisID}/grade/revision [get]
+func GetGradeRevisions(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Advisors").Preload("Students").First(&thesis, c.Params("thesisID")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	query := db.Where("THESIS_ID = ?", thesis.ID)
+	if tokenData.Role == modelUsers.StudentRole && hasStudent(thesis, tokenData.ID) {
+		query = query.Where("STUDENT_ID = ?", tokenData.ID)
+	} else if grader, ok := graderType(db, tokenData, thesis); !canCompute(db, tokenData, thesis) && (!ok || grader != model.GraderAdvisor) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var revisions []model.GradeRevision
+	if err := query.Order("REVISED_AT, ID").Find(&revisions).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = revisions
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// revise sửa điểm đã công bố theo kết quả phúc khảo và ghi lại điểm cũ, điểm mới
+func revise(tx *gorm.DB, appeal model.GradeAppeal, newGrade float64, reason, revisedBy string, now time.Time) error {
+	var grade model.FinalGrade
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&grade, appeal.GradeID).Error; err != nil {
+		return err
+	}
+	revision := model.GradeRevision{
+		GradeID:   grade.ID,
+		ThesisID:  grade.ThesisID,
+		StudentID: grade.StudentID,
+		AppealID:  appeal.ID,
+		OldGrade:  grade.Grade,
+		NewGrade:  newGrade,
+		Reason:    reason,
+		RevisedBy: revisedBy,
+		RevisedAt: now,
+	}
+	revision.CreatedBy = revisedBy
+	if err := tx.Create(&revision).Error; err != nil {
+		return err
+	}
+	return tx.Model(&grade).Updates(map[string]interface{}{
+		"GRADE":      newGrade,
+		"UPDATED_BY": revisedBy,
+	}).Error
+}
+
+// appealDeadline đọc hạn phúc khảo YYYY-MM-DD (tính hết ngày); để trống thì DefaultAppealDays ngày kể từ hôm nay
+func appealDeadline(value string) (time.Time, map[string]string) {
+	errors := map[string]string{}
+	now := core.Now()
+	deadline := core.EndOfDay(now.AddDate(0, 0, model.DefaultAppealDays))
+	if strings.TrimSpace(value) != "" {
+		if len(utils.DateFormatCheck([]string{"appealDeadline"}, map[string]string{"appealDeadline": value}, map[string]string{})) > 0 {
+			errors["appealDeadline"] = config.GetMessageCode("FORMAT_DATE")
+			return deadline, errors
+		}
+		day, _ := time.ParseInLocation("2006-01-02", value, core.CampusLocation())
+		deadline = core.EndOfDay(day)
+	}
+	if deadline.Before(now) {
+		errors["appealDeadline"] = config.GetMessageCode("INVALID_TIME_RANGE")
+	}
+	return deadline, errors
+}
+
+func lockAppeal(tx *gorm.DB, id interface{}) (model.GradeAppeal, modelThesis.Thesis, error) {
+	var appeal model.GradeAppeal
+	var thesis modelThesis.Thesis
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&appeal, id).Error; err != nil {
+		return appeal, thesis, err
+	}
+	err := tx.Preload("Advisors").First(&thesis, appeal.ThesisID).Error
+	return appeal, thesis, err
+}
+
+// involved: giảng viên hướng dẫn, đã chấm hoặc ngồi trong hội đồng của luận văn thì không được chấm phúc khảo
+func involved(db *gorm.DB, thesis modelThesis.Thesis, lecturer modelAdvisor.Advisor) bool {
+	for _, advisor := range thesis.Advisors {
+		if advisor.ID == lecturer.ID || (lecturer.Code != "" && advisor.Code == lecturer.Code) {
+			return true
+		}
+	}
+	var count int64
+	db.Model(&model.ScoreSheet{}).Where("THESIS_ID = ? AND ((GRADER_ID = ? AND GRADER_ROLE = ?) OR GRADER_CODE = ?)",
+		thesis.ID, lecturer.ID, modelUsers.AdvisorRole, lecturer.Code).Count(&count)
+	if count > 0 || thesis.CommitteeID == nil {
+		return count > 0
+	}
+	db.Model(&modelCommittee.CommitteeMember{}).Where("COMMITTEE_ID = ? AND ((MEMBER_ID = ? AND MEMBER_ROLE = ?) OR MEMBER_CODE = ?)",
+		*thesis.CommitteeID, lecturer.ID, modelUsers.AdvisorRole, lecturer.Code).Count(&count)
+	return count > 0
+}
+
+// headRecipients: trưởng bộ môn của subject của luận văn
+func headRecipients(db *gorm.DB, thesis modelThesis.Thesis) []modelNotification.Recipient {
+	recipients := []modelNotification.Recipient{}
+	if thesis.SubjectID == nil {
+		return recipients
+	}
+	var heads []modelHeadOfSubject.HeadOfSubject
+	db.Select("ID").Where("SUBJECT_ID = ?", *thesis.SubjectID).Find(&heads)
+	for _, head := range heads {
+		recipients = append(recipients, modelNotification.Recipient{UserID: head.ID, Role: modelUsers.HeadOfSubjectRole})
+	}
+	return recipients
+}
+
+// scopedTheses là truy vấn con ID các luận văn trong phạm vi của trưởng bộ môn hoặc văn phòng khoa
+func scopedTheses(db *gorm.DB, tokenData *utils.TokenData) *gorm.DB {
+	return organizationController.ScopeOf(db, tokenData).Apply(db.Model(&modelThesis.Thesis{}).Select("ID"), "SUBJECT_ID")
+}
+
+func canViewAppeal(db *gorm.DB, tokenData *utils.TokenData, appeal model.GradeAppeal) bool {
+	switch tokenData.Role {
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		var thesis modelThesis.Thesis
+		return db.Select("ID, SUBJECT_ID").First(&thesis, appeal.ThesisID).Error == nil &&
+			organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+	case modelUsers.StudentRole:
+		return appeal.StudentID == tokenData.ID
+	case modelUsers.AdvisorRole:
+		return appeal.ReviewerID != nil && *appeal.ReviewerID == tokenData.ID
+	}
+	return false
+}
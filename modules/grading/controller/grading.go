@@ -0,0 +1,967 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/grading/model"
+	"app/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	modelCommittee "app/modules/committee/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	organizationController "app/modules/organization/controller"
+	reviewerController "app/modules/reviewer/controller"
+	thesisController "app/modules/thesis/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Grading API
+// @version 1.0
+// @description Grading rubrics, score sheets and final grades
+// @termsOfService http://swagger.io/terms/
+// @BasePath /grading
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetRubrics trả về các mẫu chấm điểm
+// @Summary List grading rubrics
+// @Description Rubrics with their criteria, optionally of one thesis type; active=true keeps only the rubrics in use
+// @Tags Grading
+// @Produce json
+// @Param thesisType query int false "Thesis type"
+// @Param active query bool false "Only active rubrics"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/rubric [get]
+func GetRubrics(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	query := database.DB.Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+		return db.Order("POSITION, ID")
+	}).Order("THESIS_TYPE, ID DESC")
+	if thesisType := c.QueryInt("thesisType"); thesisType != 0 {
+		query = query.Where("THESIS_TYPE = ?", thesisType)
+	}
+	if c.Query("active") == "true" {
+		query = query.Where("ACTIVE = ?", true)
+	}
+
+	var rubrics []model.Rubric
+	if err := query.Find(&rubrics).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = rubrics
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetRubric trả về một mẫu chấm điểm
+// @Summary Get a grading rubric
+// @Description Rubric with its criteria
+// @Tags Grading
+// @Produce json
+// @Param id path int true "Rubric ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/rubric/{id} [get]
+func GetRubric(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	rubric, err := loadRubric(database.DB, c.Params("id"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Data = rubric
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateRubric thêm mẫu chấm điểm và dùng nó cho loại luận văn
+// @Summary Create a grading rubric
+// @Description Create a rubric for a thesis type: criteria with weights and score ranges, the weights of the advisor, reviewer and committee scores, whether the highest and lowest committee scores are dropped, and the largest deviation from the committee average before a re-evaluation is required. The new rubric replaces the active rubric of the thesis type. Faculty office only.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param body body model.CreateRubric true "Rubric"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/rubric [post]
+func CreateRubric(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateRubric
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	if errors := validateRubric(&payload); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	rubric := model.Rubric{ThesisType: payload.ThesisType, Active: true}
+	applyRubric(&rubric, &payload)
+	rubric.CreatedBy = tokenData.Code
+	if err := tx.Omit("Criteria").Create(&rubric).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := createCriteria(tx, &rubric, payload.Criteria, tokenData.Code); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&model.Rubric{}).Where("THESIS_TYPE = ? AND ID <> ?", rubric.ThesisType, rubric.ID).
+		Update("ACTIVE", false).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = rubric
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateRubric thay nội dung mẫu chấm điểm chưa có phiếu chấm
+// @Summary Update a grading rubric
+// @Description Replace the settings and criteria of a rubric. A rubric that already has score sheets cannot be changed; create a new rubric instead. Faculty office only.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param id path int true "Rubric ID"
+// @Param body body model.CreateRubric true "Rubric"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/rubric/{id} [put]
+func UpdateRubric(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateRubric
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	var rubric model.Rubric
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rubric, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if rubricInUse(tx, rubric.ID) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("RUBRIC_IN_USE")
+		return c.JSON(response)
+	}
+	payload.ThesisType = rubric.ThesisType
+	if errors := validateRubric(&payload); len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	applyRubric(&rubric, &payload)
+	if err := tx.Model(&rubric).Omit("Criteria").Updates(map[string]interface{}{
+		"NAME":             rubric.Name,
+		"ADVISOR_WEIGHT":   rubric.AdvisorWeight,
+		"REVIEWER_WEIGHT":  rubric.ReviewerWeight,
+		"COMMITTEE_WEIGHT": rubric.CommitteeWeight,
+		"DROP_OUTLIERS":    rubric.DropOutliers,
+		"MAX_DEVIATION":    rubric.MaxDeviation,
+		"UPDATED_BY":       tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Where("RUBRIC_ID = ?", rubric.ID).Delete(&model.RubricCriterion{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := createCriteria(tx, &rubric, payload.Criteria, tokenData.Code); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = rubric
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ActivateRubric dùng lại một mẫu chấm điểm cho loại luận văn của nó
+// @Summary Activate a grading rubric
+// @Description Make a rubric the active rubric of its thesis type. Theses that already have score sheets keep the rubric they were graded with. Faculty office only.
+// @Tags Grading
+// @Produce json
+// @Param id path int true "Rubric ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/rubric/{id}/activate [put]
+func ActivateRubric(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	var rubric model.Rubric
+	if err := tx.First(&rubric, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&model.Rubric{}).Where("THESIS_TYPE = ? AND ID <> ?", rubric.ThesisType, rubric.ID).
+		Update("ACTIVE", false).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&rubric).Omit("Criteria").Update("ACTIVE", true).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	rubric.Active = true
+
+	response.Data = rubric
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteRubric xóa mẫu chấm điểm chưa có phiếu chấm
+// @Summary Delete a grading rubric
+// @Description Delete a rubric that has no score sheets. Faculty office only.
+// @Tags Grading
+// @Produce json
+// @Param id path int true "Rubric ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/rubric/{id} [delete]
+func DeleteRubric(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var rubric model.Rubric
+	if err := db.First(&rubric, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if rubricInUse(db, rubric.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("RUBRIC_IN_USE")
+		return c.JSON(response)
+	}
+
+	deleted := map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}
+	tx := db.Begin()
+	defer tx.Commit()
+	if err := tx.Model(&model.RubricCriterion{}).Where("RUBRIC_ID = ?", rubric.ID).Updates(deleted).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&rubric).Omit("Criteria").Updates(deleted).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetScoreSheets trả về các phiếu chấm của luận văn
+// @Summary List score sheets of a thesis
+// @Description Graders see their own sheets; heads of subject and the faculty office see every sheet of the theses in their scope
+// @Tags Grading
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/thesis/{thesisID}/sheet [get]
+func GetScoreSheets(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Advisors").First(&thesis, c.Params("thesisID")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	query := db.Preload("Items").Where("THESIS_ID = ?", thesis.ID).Order("STUDENT_ID, GRADER_TYPE, ID")
+	if !canCompute(db, tokenData, thesis) {
+		if _, ok := graderType(db, tokenData, thesis); !ok {
+			response.Status = false
+			response.Message = config.GetMessageCode("PERMISSION_DENIED")
+			return c.JSON(response)
+		}
+		query = query.Where("GRADER_ID = ? AND GRADER_ROLE = ?", tokenData.ID, tokenData.Role)
+	}
+
+	var sheets []model.ScoreSheet
+	if err := query.Find(&sheets).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = sheets
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// SaveScoreSheet lưu hoặc nộp phiếu chấm của người gọi cho một sinh viên
+// @Summary Save or submit a score sheet
+// @Description The advisor, the reviewer or a committee member of the thesis scores a student on every criterion of the thesis rubric. submit=true submits the sheet; submitted sheets are read-only unless a re-evaluation was requested.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Param body body model.SaveScoreSheet true "Score sheet"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/thesis/{thesisID}/sheet [put]
+func SaveScoreSheet(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.SaveScoreSheet
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Advisors").Preload("Students").First(&thesis, c.Params("thesisID")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	grader, ok := graderType(db, tokenData, thesis)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if !hasStudent(thesis, payload.StudentID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"studentID": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+	if published(db, thesis.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("GRADE_LOCKED")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	var sheet model.ScoreSheet
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("THESIS_ID = ? AND STUDENT_ID = ? AND GRADER_ID = ? AND GRADER_ROLE = ?", thesis.ID, payload.StudentID, tokenData.ID, tokenData.Role).
+		First(&sheet).Error
+	if err == nil && sheet.Status == model.SheetSubmitted {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SHEET_SUBMITTED")
+		return c.JSON(response)
+	}
+
+	rubricID := sheet.RubricID
+	if rubricID == 0 {
+		rubricID = rubricFor(tx, thesis)
+	}
+	rubric, rubricErr := loadRubric(tx, rubricID)
+	if rubricErr != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		response.ValidateError = map[string]string{"rubric": fmt.Sprintf("no active rubric for thesis type %d", thesis.ThesisType)}
+		return c.JSON(response)
+	}
+
+	items, errors := scoreItems(rubric, payload.Items, payload.Submit)
+	if len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	sheet.ThesisID = thesis.ID
+	sheet.StudentID = payload.StudentID
+	sheet.RubricID = rubric.ID
+	sheet.GraderType = grader
+	sheet.GraderID = tokenData.ID
+	sheet.GraderRole = tokenData.Role
+	sheet.GraderCode = tokenData.Code
+	sheet.Comment = payload.Comment
+	sheet.Total = rubric.Score(items)
+	if payload.Submit {
+		now := core.Now()
+		sheet.Status = model.SheetSubmitted
+		sheet.SubmittedAt = &now
+	} else if sheet.Status == "" {
+		sheet.Status = model.SheetDraft
+	}
+	if sheet.ID == 0 {
+		sheet.CreatedBy = tokenData.Code
+	}
+	sheet.UpdatedBy = tokenData.Code
+	if err := tx.Omit("Items").Save(&sheet).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	if err := tx.Where("SHEET_ID = ?", sheet.ID).Delete(&model.ScoreItem{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	for i := range items {
+		items[i].SheetID = sheet.ID
+		items[i].CreatedBy = tokenData.Code
+	}
+	if len(items) > 0 {
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+	sheet.Items = items
+
+	response.Data = sheet
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ComputeGrades tổng hợp điểm tổng kết của các sinh viên trong luận văn
+// @Summary Compute final grades
+// @Description Aggregate the submitted sheets of every student of the thesis with the rubric settings into a grade on the 10-point scale. Committee sheets that deviate from the committee average by more than the rubric allows are reopened and the grade is marked REEVALUATION; computing again is refused until they are submitted again. Deliverables not yet accepted are listed in the grade note. Published grades are locked. Head of subject or faculty office.
+// @Tags Grading
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/thesis/{thesisID}/grade [post]
+func ComputeGrades(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Students").First(&thesis, c.Params("thesisID")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canCompute(db, tokenData, thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if published(db, thesis.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("GRADE_LOCKED")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	grades, errors, err := computeGrades(tx, thesis, tokenData.Code)
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("GRADING_INCOMPLETE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	response.Data = grades
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetGrades trả về điểm tổng kết đã tính của luận văn
+// @Summary Get final grades of a thesis
+// @Description Computed final grades per student. Heads of subject, the faculty office and the advisors of the thesis; students see their own grade once it is published.
+// @Tags Grading
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /grading/thesis/{thesisID}/grade [get]
+func GetGrades(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Advisors").Preload("Students").First(&thesis, c.Params("thesisID")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	query := db.Where("THESIS_ID = ?", thesis.ID)
+	if tokenData.Role == modelUsers.StudentRole && hasStudent(thesis, tokenData.ID) {
+		query = query.Where("STUDENT_ID = ? AND PUBLICATION_ID IS NOT NULL", tokenData.ID)
+	} else if grader, ok := graderType(db, tokenData, thesis); !canCompute(db, tokenData, thesis) && (!ok || grader != model.GraderAdvisor) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var grades []model.FinalGrade
+	if err := query.Order("STUDENT_ID").Find(&grades).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = grades
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// computeGrades tính và lưu điểm tổng kết từng sinh viên; trả về lỗi theo sinh viên nếu còn thiếu phiếu
+func computeGrades(tx *gorm.DB, thesis modelThesis.Thesis, computedBy string) ([]model.FinalGrade, map[string]string, error) {
+	errors := map[string]string{}
+	rubric, err := loadRubric(tx, rubricFor(tx, thesis))
+	if err != nil {
+		errors["rubric"] = fmt.Sprintf("no active rubric for thesis type %d", thesis.ThesisType)
+		return nil, errors, nil
+	}
+
+	// Sản phẩm chưa nghiệm thu được ghi chú vào điểm; việc công bố sẽ bị chặn cho tới khi nghiệm thu xong
+	deliverables, err := thesisController.SummarizeDeliverables(tx, thesis.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := core.Now()
+	grades := []model.FinalGrade{}
+	for _, student := range thesis.Students {
+		// Phiếu bị mở lại để chấm lại chưa nộp thì chưa tổng hợp, điểm giữ trạng thái REEVALUATION
+		var reopened []uint
+		if err := tx.Model(&model.ScoreSheet{}).Where("THESIS_ID = ? AND STUDENT_ID = ? AND STATUS = ?", thesis.ID, student.ID, model.SheetReevaluate).
+			Pluck("ID", &reopened).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(reopened) > 0 {
+			errors[fmt.Sprint(student.ID)] = fmt.Sprintf("sheets %v are under re-evaluation", reopened)
+			continue
+		}
+		var sheets []model.ScoreSheet
+		if err := tx.Where("THESIS_ID = ? AND STUDENT_ID = ? AND STATUS = ?", thesis.ID, student.ID, model.SheetSubmitted).Find(&sheets).Error; err != nil {
+			return nil, nil, err
+		}
+		if missing := missingGraders(rubric, sheets); len(missing) > 0 {
+			errors[fmt.Sprint(student.ID)] = "missing submitted sheets: " + strings.Join(missing, ", ")
+			continue
+		}
+
+		result := rubric.Aggregate(sheets)
+		grade := model.FinalGrade{}
+		tx.Where("THESIS_ID = ? AND STUDENT_ID = ?", thesis.ID, student.ID).First(&grade)
+		grade.ThesisID = thesis.ID
+		grade.StudentID = student.ID
+		grade.RubricID = rubric.ID
+		grade.AdvisorScore = result.AdvisorScore
+		grade.ReviewerScore = result.ReviewerScore
+		grade.CommitteeScore = result.CommitteeScore
+		grade.Grade = result.Grade
+		grade.Status = model.GradeComputed
+		grade.ComputedAt = &now
+		notes := []string{}
+		if len(result.Dropped) > 0 {
+			notes = append(notes, fmt.Sprintf("dropped committee sheets %v", result.Dropped))
+		}
+		if !deliverables.AllMet {
+			notes = append(notes, "deliverables not accepted: "+unmetDeliverables(deliverables))
+		}
+		if len(result.Flagged) > 0 {
+			grade.Status = model.GradeReevaluation
+			notes = append(notes, fmt.Sprintf("sheets %v deviate more than %g from the committee average", result.Flagged, rubric.MaxDeviation))
+			if err := tx.Model(&model.ScoreSheet{}).Where("ID IN ?", result.Flagged).
+				Updates(map[string]interface{}{"STATUS": model.SheetReevaluate, "UPDATED_BY": computedBy}).Error; err != nil {
+				return nil, nil, err
+			}
+			if err := notifyReevaluation(tx, thesis, result.Flagged); err != nil {
+				return nil, nil, err
+			}
+		}
+		grade.Note = strings.Join(notes, "; ")
+		if grade.ID == 0 {
+			grade.CreatedBy = computedBy
+		}
+		grade.UpdatedBy = computedBy
+		if err := tx.Save(&grade).Error; err != nil {
+			return nil, nil, err
+		}
+		grades = append(grades, grade)
+	}
+	return grades, errors, nil
+}
+
+// unmetDeliverables liệt kê tên các sản phẩm bàn giao chưa được nghiệm thu
+func unmetDeliverables(summary modelThesis.DeliverableSummary) string {
+	names := []string{}
+	for _, mission := range summary.Unmet {
+		names = append(names, mission.Value)
+	}
+	return strings.Join(names, ", ")
+}
+
+// notifyReevaluation báo cho người chấm các phiếu bị mở lại
+func notifyReevaluation(tx *gorm.DB, thesis modelThesis.Thesis, sheetIDs []uint) error {
+	var sheets []model.ScoreSheet
+	if err := tx.Select("ID, GRADER_ID, GRADER_ROLE").Where("ID IN ?", sheetIDs).Find(&sheets).Error; err != nil {
+		return err
+	}
+	recipients := []modelNotification.Recipient{}
+	for _, sheet := range sheets {
+		recipients = append(recipients, modelNotification.Recipient{UserID: sheet.GraderID, Role: sheet.GraderRole})
+	}
+	return notificationController.Notify(tx, modelNotification.Message{
+		Type:  modelNotification.NotificationReevaluationRequested,
+		Title: "Score sheet reopened",
+		Body:  fmt.Sprintf("Your score for \"%s\" deviates too far from the committee average; please review and submit it again", thesis.TitleVi),
+		Link:  fmt.Sprintf("/grading/thesis/%d/sheet", thesis.ID),
+	}, recipients...)
+}
+
+// missingGraders: mỗi nhóm có trọng số lớn hơn 0 phải có ít nhất một phiếu đã nộp
+func missingGraders(rubric model.Rubric, sheets []model.ScoreSheet) []string {
+	count := map[string]int{}
+	for _, sheet := range sheets {
+		count[sheet.GraderType]++
+	}
+	missing := []string{}
+	if rubric.AdvisorWeight > 0 && count[model.GraderAdvisor] == 0 {
+		missing = append(missing, strings.ToLower(model.GraderAdvisor))
+	}
+	if rubric.ReviewerWeight > 0 && count[model.GraderReviewer] == 0 {
+		missing = append(missing, strings.ToLower(model.GraderReviewer))
+	}
+	if rubric.CommitteeWeight > 0 && count[model.GraderCommittee] == 0 {
+		missing = append(missing, strings.ToLower(model.GraderCommittee))
+	}
+	return missing
+}
+
+// graderType xác định người gọi chấm luận văn với tư cách giảng viên hướng dẫn, phản biện (được phân công hoặc
+// thành viên hội đồng giữ vai trò phản biện) hay thành viên hội đồng
+func graderType(db *gorm.DB, tokenData *utils.TokenData, thesis modelThesis.Thesis) (string, bool) {
+	if tokenData.Role == modelUsers.AdvisorRole {
+		for _, advisor := range thesis.Advisors {
+			if advisor.ID == tokenData.ID || (tokenData.Code != "" && advisor.Code == tokenData.Code) {
+				return model.GraderAdvisor, true
+			}
+		}
+	}
+	if reviewerController.IsReviewer(db, tokenData, thesis.ID) {
+		return model.GraderReviewer, true
+	}
+	if thesis.CommitteeID == nil {
+		return "", false
+	}
+	query := db.Where("COMMITTEE_ID = ? AND MEMBER_ID = ? AND MEMBER_ROLE = ?", *thesis.CommitteeID, tokenData.ID, tokenData.Role)
+	if tokenData.Code != "" {
+		query = db.Where("COMMITTEE_ID = ? AND ((MEMBER_ID = ? AND MEMBER_ROLE = ?) OR MEMBER_CODE = ?)", *thesis.CommitteeID, tokenData.ID, tokenData.Role, tokenData.Code)
+	}
+	var member modelCommittee.CommitteeMember
+	if err := query.First(&member).Error; err != nil {
+		return "", false
+	}
+	if member.Role == modelCommittee.RoleReviewer {
+		return model.GraderReviewer, true
+	}
+	return model.GraderCommittee, true
+}
+
+// canCompute: trưởng bộ môn và văn phòng khoa quản lý luận văn
+func canCompute(db *gorm.DB, tokenData *utils.TokenData, thesis modelThesis.Thesis) bool {
+	if tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole {
+		return false
+	}
+	return organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+}
+
+// published: điểm của luận văn đã công bố thì phiếu chấm và điểm tổng kết bị khóa
+func published(db *gorm.DB, thesisID uint) bool {
+	var count int64
+	db.Model(&model.FinalGrade{}).Where("THESIS_ID = ? AND PUBLICATION_ID IS NOT NULL", thesisID).Count(&count)
+	return count > 0
+}
+
+func hasStudent(thesis modelThesis.Thesis, studentID uint) bool {
+	for _, student := range thesis.Students {
+		if student.ID == studentID {
+			return true
+		}
+	}
+	return false
+}
+
+// rubricFor: mẫu chấm đã dùng cho luận văn, hoặc mẫu đang dùng của loại luận văn
+func rubricFor(db *gorm.DB, thesis modelThesis.Thesis) uint {
+	var sheet model.ScoreSheet
+	if err := db.Select("ID, RUBRIC_ID").Where("THESIS_ID = ?", thesis.ID).First(&sheet).Error; err == nil {
+		return sheet.RubricID
+	}
+	var rubric model.Rubric
+	if err := db.Select("ID").Where("THESIS_TYPE = ? AND ACTIVE = ?", thesis.ThesisType, true).Order("ID DESC").First(&rubric).Error; err == nil {
+		return rubric.ID
+	}
+	return 0
+}
+
+func loadRubric(db *gorm.DB, id interface{}) (model.Rubric, error) {
+	var rubric model.Rubric
+	err := db.Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+		return db.Order("POSITION, ID")
+	}).First(&rubric, id).Error
+	return rubric, err
+}
+
+func rubricInUse(db *gorm.DB, rubricID uint) bool {
+	var count int64
+	db.Model(&model.ScoreSheet{}).Where("RUBRIC_ID = ?", rubricID).Count(&count)
+	return count > 0
+}
+
+// scoreItems kiểm tra điểm theo khoảng của từng tiêu chí; phiếu nộp phải có đủ mọi tiêu chí
+func scoreItems(rubric model.Rubric, payload []model.SaveScoreItem, submit bool) ([]model.ScoreItem, map[string]string) {
+	criteria := map[uint]model.RubricCriterion{}
+	for _, criterion := range rubric.Criteria {
+		criteria[criterion.ID] = criterion
+	}
+	errors := map[string]string{}
+	items := []model.ScoreItem{}
+	scored := map[uint]bool{}
+	for i, item := range payload {
+		key := fmt.Sprintf("items[%d]", i)
+		criterion, ok := criteria[item.CriterionID]
+		switch {
+		case !ok:
+			errors[key] = config.GetMessageCode("NOT_ID_EXISTS")
+		case scored[item.CriterionID]:
+			errors[key] = "criterion is scored more than once"
+		case item.Score < criterion.MinScore || item.Score > criterion.MaxScore:
+			errors[key] = fmt.Sprintf("score must be between %g and %g", criterion.MinScore, criterion.MaxScore)
+		default:
+			scored[item.CriterionID] = true
+			items = append(items, model.ScoreItem{CriterionID: item.CriterionID, Score: item.Score, Comment: item.Comment})
+		}
+	}
+	if submit {
+		for _, criterion := range rubric.Criteria {
+			if !scored[criterion.ID] {
+				errors[criterion.Code] = config.GetMessageCode("REQUIRE")
+			}
+		}
+	}
+	return items, errors
+}
+
+func validateRubric(payload *model.CreateRubric) map[string]string {
+	vItem := map[string]string{"name": strings.TrimSpace(payload.Name)}
+	errors := utils.RequireCheck([]string{"name"}, vItem, map[string]string{})
+	if payload.ThesisType == 0 {
+		errors["thesisType"] = config.GetMessageCode("REQUIRE")
+	}
+	if payload.AdvisorWeight < 0 || payload.ReviewerWeight < 0 || payload.CommitteeWeight < 0 ||
+		payload.AdvisorWeight+payload.ReviewerWeight+payload.CommitteeWeight <= 0 {
+		errors["weights"] = "advisor, reviewer and committee weights must not be negative and must not all be zero"
+	}
+	if payload.MaxDeviation < 0 || payload.MaxDeviation > model.MaxGrade {
+		errors["maxDeviation"] = fmt.Sprintf("must be between 0 and %g", model.MaxGrade)
+	}
+	if len(payload.Criteria) == 0 {
+		errors["criteria"] = config.GetMessageCode("REQUIRE")
+	}
+	codes := map[string]bool{}
+	for i := range payload.Criteria {
+		criterion := &payload.Criteria[i]
+		key := fmt.Sprintf("criteria[%d]", i)
+		criterion.Name = strings.TrimSpace(criterion.Name)
+		criterion.Code = strings.ToUpper(strings.TrimSpace(criterion.Code))
+		if criterion.Code == "" {
+			criterion.Code = fmt.Sprintf("C%d", i+1)
+		}
+		switch {
+		case criterion.Name == "":
+			errors[key] = config.GetMessageCode("REQUIRE")
+		case len(criterion.Code) > 20 || codes[criterion.Code]:
+			errors[key] = "code must be unique and at most 20 characters"
+		case criterion.Weight <= 0:
+			errors[key] = "weight must be greater than 0"
+		case criterion.MaxScore <= criterion.MinScore:
+			errors[key] = "maxScore must be greater than minScore"
+		}
+		codes[criterion.Code] = true
+	}
+	return errors
+}
+
+func applyRubric(rubric *model.Rubric, payload *model.CreateRubric) {
+	rubric.Name = strings.TrimSpace(payload.Name)
+	rubric.AdvisorWeight = payload.AdvisorWeight
+	rubric.ReviewerWeight = payload.ReviewerWeight
+	rubric.CommitteeWeight = payload.CommitteeWeight
+	rubric.DropOutliers = payload.DropOutliers
+	rubric.MaxDeviation = payload.MaxDeviation
+}
+
+func createCriteria(tx *gorm.DB, rubric *model.Rubric, payload []model.CreateCriterion, createdBy string) error {
+	criteria := make([]model.RubricCriterion, 0, len(payload))
+	for i, item := range payload {
+		criterion := model.RubricCriterion{
+			RubricID:    rubric.ID,
+			Code:        item.Code,
+			Name:        item.Name,
+			Description: item.Description,
+			Weight:      item.Weight,
+			MinScore:    item.MinScore,
+			MaxScore:    item.MaxScore,
+			Position:    i,
+		}
+		criterion.CreatedBy = createdBy
+		criteria = append(criteria, criterion)
+	}
+	if err := tx.Create(&criteria).Error; err != nil {
+		return err
+	}
+	rubric.Criteria = criteria
+	return nil
+}
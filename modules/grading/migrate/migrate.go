@@ -0,0 +1,21 @@
+package gradingMigrate
+
+import (
+	"app/database"
+	model "app/modules/grading/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Rubric{})
+	db.AutoMigrate(&model.RubricCriterion{})
+	db.AutoMigrate(&model.ScoreSheet{})
+	db.AutoMigrate(&model.ScoreItem{})
+	db.AutoMigrate(&model.FinalGrade{})
+	db.AutoMigrate(&model.GradePublication{})
+	db.AutoMigrate(&model.GradeAppeal{})
+	db.AutoMigrate(&model.GradeRevision{})
+
+	return true
+}
@@ -0,0 +1,317 @@
+package model
+
+import (
+	"app/model"
+	"math"
+	"sort"
+	"time"
+)
+
+var GraderAdvisor, GraderReviewer, GraderCommittee = "ADVISOR", "REVIEWER", "COMMITTEE"
+
+var SheetDraft, SheetSubmitted, SheetReevaluate = "DRAFT", "SUBMITTED", "REEVALUATE"
+
+var GradeComputed, GradeReevaluation, GradePublished = "COMPUTED", "REEVALUATION", "PUBLISHED"
+
+var AppealSubmitted, AppealAssigned, AppealUpheld, AppealRejected = "SUBMITTED", "ASSIGNED", "UPHELD", "REJECTED"
+
+// DefaultAppealDays là số ngày được phúc khảo sau khi công bố điểm nếu không chỉ định hạn
+var DefaultAppealDays = 7
+
+// MaxGrade là thang điểm cuối cùng; MinScoresForDrop là số phiếu hội đồng tối thiểu để bỏ điểm cao nhất và thấp nhất
+var MaxGrade, MinScoresForDrop = 10.0, 4
+
+// Rubric là mẫu chấm điểm của một loại luận văn: các tiêu chí có trọng số và khoảng điểm,
+// trọng số của giảng viên hướng dẫn, phản biện, hội đồng và cách tổng hợp điểm hội đồng.
+// Mỗi loại luận văn có một mẫu đang dùng; mẫu đã có phiếu chấm thì không sửa được.
+type Rubric struct {
+	model.Header
+	ThesisType      int               `json:"thesisType" gorm:"column:THESIS_TYPE;index"`
+	Name            string            `json:"name" gorm:"column:NAME"`
+	Active          bool              `json:"active" gorm:"column:ACTIVE;default:true"`
+	AdvisorWeight   float64           `json:"advisorWeight" gorm:"column:ADVISOR_WEIGHT"`
+	ReviewerWeight  float64           `json:"reviewerWeight" gorm:"column:REVIEWER_WEIGHT"`
+	CommitteeWeight float64           `json:"committeeWeight" gorm:"column:COMMITTEE_WEIGHT"`
+	DropOutliers    bool              `json:"dropOutliers" gorm:"column:DROP_OUTLIERS;default:false"`
+	MaxDeviation    float64           `json:"maxDeviation" gorm:"column:MAX_DEVIATION"`
+	Criteria        []RubricCriterion `json:"criteria" gorm:"foreignKey:RUBRIC_ID"`
+}
+
+type RubricCriterion struct {
+	model.Header
+	RubricID    uint    `json:"rubricID" gorm:"column:RUBRIC_ID;index"`
+	Code        string  `json:"code" gorm:"column:CODE;size:20"`
+	Name        string  `json:"name" gorm:"column:NAME"`
+	Description string  `json:"description" gorm:"column:DESCRIPTION"`
+	Weight      float64 `json:"weight" gorm:"column:WEIGHT"`
+	MinScore    float64 `json:"minScore" gorm:"column:MIN_SCORE"`
+	MaxScore    float64 `json:"maxScore" gorm:"column:MAX_SCORE"`
+	Position    int     `json:"position" gorm:"column:POSITION;default:0"`
+}
+
+// ScoreSheet là phiếu chấm của một người chấm cho một sinh viên. Total là điểm quy về thang 10.
+type ScoreSheet struct {
+	model.Header
+	ThesisID    uint        `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	StudentID   uint        `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	RubricID    uint        `json:"rubricID" gorm:"column:RUBRIC_ID"`
+	GraderType  string      `json:"graderType" gorm:"column:GRADER_TYPE;size:20"`
+	GraderID    uint        `json:"graderID" gorm:"column:GRADER_ID"`
+	GraderRole  int         `json:"graderRole" gorm:"column:GRADER_ROLE"`
+	GraderCode  string      `json:"graderCode" gorm:"column:GRADER_CODE;size:50"`
+	Status      string      `json:"status" gorm:"column:STATUS;size:20;default:DRAFT"`
+	Total       float64     `json:"total" gorm:"column:TOTAL"`
+	Comment     string      `json:"comment" gorm:"column:COMMENT"`
+	SubmittedAt *time.Time  `json:"submittedAt" gorm:"column:SUBMITTED_AT"`
+	Items       []ScoreItem `json:"items" gorm:"foreignKey:SHEET_ID"`
+}
+
+type ScoreItem struct {
+	model.Header
+	SheetID     uint    `json:"sheetID" gorm:"column:SHEET_ID;index"`
+	CriterionID uint    `json:"criterionID" gorm:"column:CRITERION_ID"`
+	Score       float64 `json:"score" gorm:"column:SCORE"`
+	Comment     string  `json:"comment" gorm:"column:COMMENT"`
+}
+
+// FinalGrade là điểm tổng kết của một sinh viên, tính lại mỗi khi tổng hợp.
+// Điểm đã công bố (PublicationID khác nil) bị khóa và chỉ thay đổi qua phúc khảo.
+type FinalGrade struct {
+	model.Header
+	ThesisID       uint       `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	StudentID      uint       `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	RubricID       uint       `json:"rubricID" gorm:"column:RUBRIC_ID"`
+	AdvisorScore   float64    `json:"advisorScore" gorm:"column:ADVISOR_SCORE"`
+	ReviewerScore  float64    `json:"reviewerScore" gorm:"column:REVIEWER_SCORE"`
+	CommitteeScore float64    `json:"committeeScore" gorm:"column:COMMITTEE_SCORE"`
+	Grade          float64    `json:"grade" gorm:"column:GRADE"`
+	Status         string     `json:"status" gorm:"column:STATUS;size:20"`
+	Note           string     `json:"note" gorm:"column:NOTE"`
+	ComputedAt     *time.Time `json:"computedAt" gorm:"column:COMPUTED_AT"`
+	PublicationID  *uint      `json:"publicationID" gorm:"column:PUBLICATION_ID;index"`
+	PublishedAt    *time.Time `json:"publishedAt" gorm:"column:PUBLISHED_AT"`
+}
+
+// GradePublication là một lần công bố điểm của học kỳ, cho một subject hoặc toàn bộ phạm vi của người công bố
+type GradePublication struct {
+	model.Header
+	Semester       string    `json:"semester" gorm:"column:SEMESTER;size:20;index"`
+	SubjectID      *uint     `json:"subjectID" gorm:"column:SUBJECT_ID;index"`
+	PublishedAt    time.Time `json:"publishedAt" gorm:"column:PUBLISHED_AT"`
+	AppealDeadline time.Time `json:"appealDeadline" gorm:"column:APPEAL_DEADLINE"`
+	GradeCount     int       `json:"gradeCount" gorm:"column:GRADE_COUNT"`
+}
+
+// GradeAppeal là đơn phúc khảo của sinh viên cho một điểm đã công bố. Trưởng bộ môn hoặc văn phòng khoa
+// giao cho một giảng viên không tham gia chấm luận văn; giảng viên chấm lại và giữ hoặc sửa điểm.
+type GradeAppeal struct {
+	model.Header
+	GradeID      uint       `json:"gradeID" gorm:"column:GRADE_ID;index"`
+	ThesisID     uint       `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	StudentID    uint       `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	Reason       string     `json:"reason" gorm:"column:REASON"`
+	Status       string     `json:"status" gorm:"column:STATUS;size:20;default:SUBMITTED;index"`
+	ReviewerID   *uint      `json:"reviewerID" gorm:"column:REVIEWER_ID;index"`
+	ReviewerCode string     `json:"reviewerCode" gorm:"column:REVIEWER_CODE;size:50"`
+	FullName     string     `json:"fullName" gorm:"column:FULL_NAME"`
+	AssignedAt   *time.Time `json:"assignedAt" gorm:"column:ASSIGNED_AT"`
+	OldGrade     float64    `json:"oldGrade" gorm:"column:OLD_GRADE"`
+	NewGrade     *float64   `json:"newGrade" gorm:"column:NEW_GRADE"`
+	Comment      string     `json:"comment" gorm:"column:COMMENT"`
+	DecidedBy    string     `json:"decidedBy" gorm:"column:DECIDED_BY;size:50"`
+	DecidedAt    *time.Time `json:"decidedAt" gorm:"column:DECIDED_AT"`
+}
+
+// GradeRevision ghi lại điểm cũ và điểm mới mỗi khi điểm đã công bố bị sửa, không sửa hoặc xóa
+type GradeRevision struct {
+	model.Header
+	GradeID   uint      `json:"gradeID" gorm:"column:GRADE_ID;index"`
+	ThesisID  uint      `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	StudentID uint      `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	AppealID  uint      `json:"appealID" gorm:"column:APPEAL_ID;index"`
+	OldGrade  float64   `json:"oldGrade" gorm:"column:OLD_GRADE"`
+	NewGrade  float64   `json:"newGrade" gorm:"column:NEW_GRADE"`
+	Reason    string    `json:"reason" gorm:"column:REASON"`
+	RevisedBy string    `json:"revisedBy" gorm:"column:REVISED_BY;size:50"`
+	RevisedAt time.Time `json:"revisedAt" gorm:"column:REVISED_AT"`
+}
+
+type CreateRubric struct {
+	ThesisType      int               `json:"thesisType" validate:"required"`
+	Name            string            `json:"name" validate:"required"`
+	AdvisorWeight   float64           `json:"advisorWeight"`
+	ReviewerWeight  float64           `json:"reviewerWeight"`
+	CommitteeWeight float64           `json:"committeeWeight"`
+	DropOutliers    bool              `json:"dropOutliers"`
+	MaxDeviation    float64           `json:"maxDeviation"`
+	Criteria        []CreateCriterion `json:"criteria" validate:"required"`
+}
+
+type CreateCriterion struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name" validate:"required"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight" validate:"required"`
+	MinScore    float64 `json:"minScore"`
+	MaxScore    float64 `json:"maxScore" validate:"required"`
+}
+
+type PublishGrades struct {
+	Semester       string `json:"semester" validate:"required"`
+	SubjectID      uint   `json:"subjectID"`
+	AppealDeadline string `json:"appealDeadline"`
+}
+
+type CreateAppeal struct {
+	GradeID uint   `json:"gradeID" validate:"required"`
+	Reason  string `json:"reason" validate:"required"`
+}
+
+type AssignAppeal struct {
+	ReviewerID uint `json:"reviewerID" validate:"required"`
+}
+
+// DecideAppeal: giảng viên chấm lại gửi Grade, bằng điểm cũ thì đơn bị bác;
+// trưởng bộ môn hoặc văn phòng khoa bác đơn chưa giao thì để trống Grade
+type DecideAppeal struct {
+	Grade   *float64 `json:"grade"`
+	Comment string   `json:"comment" validate:"required"`
+}
+
+type SaveScoreSheet struct {
+	StudentID uint            `json:"studentID" validate:"required"`
+	Items     []SaveScoreItem `json:"items"`
+	Comment   string          `json:"comment"`
+	Submit    bool            `json:"submit"`
+}
+
+type SaveScoreItem struct {
+	CriterionID uint    `json:"criterionID" validate:"required"`
+	Score       float64 `json:"score"`
+	Comment     string  `json:"comment"`
+}
+
+// Aggregate là kết quả tổng hợp điểm của một sinh viên; Flagged là các phiếu hội đồng lệch quá MaxDeviation
+type Aggregate struct {
+	AdvisorScore   float64
+	ReviewerScore  float64
+	CommitteeScore float64
+	Grade          float64
+	Dropped        []uint
+	Flagged        []uint
+}
+
+// Score quy các điểm tiêu chí về thang 10 theo trọng số; điểm ngoài khoảng của tiêu chí bị chặn lại
+func (r Rubric) Score(items []ScoreItem) float64 {
+	scores := map[uint]float64{}
+	for _, item := range items {
+		scores[item.CriterionID] = item.Score
+	}
+	total, weights := 0.0, 0.0
+	for _, criterion := range r.Criteria {
+		span := criterion.MaxScore - criterion.MinScore
+		if span <= 0 || criterion.Weight <= 0 {
+			continue
+		}
+		score := math.Max(criterion.MinScore, math.Min(criterion.MaxScore, scores[criterion.ID]))
+		total += criterion.Weight * (score - criterion.MinScore) / span
+		weights += criterion.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return Round(MaxGrade*total/weights, 2)
+}
+
+// Aggregate tổng hợp các phiếu đã nộp của một sinh viên: trung bình từng nhóm người chấm,
+// bỏ điểm cao nhất và thấp nhất của hội đồng nếu được cấu hình, đánh dấu phiếu hội đồng lệch khỏi
+// trung bình quá MaxDeviation, rồi lấy trung bình có trọng số của các nhóm làm tròn tới 0.1
+func (r Rubric) Aggregate(sheets []ScoreSheet) Aggregate {
+	result := Aggregate{Dropped: []uint{}, Flagged: []uint{}}
+	var advisor, reviewer []float64
+	committee := []ScoreSheet{}
+	for _, sheet := range sheets {
+		switch sheet.GraderType {
+		case GraderAdvisor:
+			advisor = append(advisor, sheet.Total)
+		case GraderReviewer:
+			reviewer = append(reviewer, sheet.Total)
+		case GraderCommittee:
+			committee = append(committee, sheet)
+		}
+	}
+
+	sort.Slice(committee, func(i, j int) bool { return committee[i].Total < committee[j].Total })
+	if r.DropOutliers && len(committee) >= MinScoresForDrop {
+		result.Dropped = append(result.Dropped, committee[0].ID, committee[len(committee)-1].ID)
+		committee = committee[1 : len(committee)-1]
+	}
+	scores := make([]float64, 0, len(committee))
+	for _, sheet := range committee {
+		scores = append(scores, sheet.Total)
+	}
+	result.AdvisorScore = Round(mean(advisor), 2)
+	result.ReviewerScore = Round(mean(reviewer), 2)
+	result.CommitteeScore = Round(mean(scores), 2)
+	if r.MaxDeviation > 0 {
+		for _, sheet := range committee {
+			if math.Abs(sheet.Total-result.CommitteeScore) > r.MaxDeviation {
+				result.Flagged = append(result.Flagged, sheet.ID)
+			}
+		}
+	}
+
+	total := r.AdvisorWeight*result.AdvisorScore + r.ReviewerWeight*result.ReviewerScore + r.CommitteeWeight*result.CommitteeScore
+	if weights := r.AdvisorWeight + r.ReviewerWeight + r.CommitteeWeight; weights > 0 {
+		result.Grade = Round(total/weights, 1)
+	}
+	return result
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func Round(value float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(value*scale) / scale
+}
+
+func (Rubric) TableName() string {
+	return "TBL_RUBRIC"
+}
+
+func (RubricCriterion) TableName() string {
+	return "TBL_RUBRIC_CRITERION"
+}
+
+func (ScoreSheet) TableName() string {
+	return "TBL_SCORE_SHEET"
+}
+
+func (ScoreItem) TableName() string {
+	return "TBL_SCORE_ITEM"
+}
+
+func (FinalGrade) TableName() string {
+	return "TBL_FINAL_GRADE"
+}
+
+func (GradePublication) TableName() string {
+	return "TBL_GRADE_PUBLICATION"
+}
+
+func (GradeAppeal) TableName() string {
+	return "TBL_GRADE_APPEAL"
+}
+
+func (GradeRevision) TableName() string {
+	return "TBL_GRADE_REVISION"
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func criterion(id uint, weight, min, max float64) RubricCriterion {
+	c := RubricCriterion{Weight: weight, MinScore: min, MaxScore: max}
+	c.ID = id
+	return c
+}
+
+func sheet(id uint, graderType string, total float64) ScoreSheet {
+	s := ScoreSheet{GraderType: graderType, Total: total, Status: SheetSubmitted}
+	s.ID = id
+	return s
+}
+
+func TestRubricScore(t *testing.T) {
+	rubric := Rubric{Criteria: []RubricCriterion{criterion(1, 2, 0, 10), criterion(2, 1, 1, 5)}}
+	tests := []struct {
+		name   string
+		rubric Rubric
+		items  []ScoreItem
+		want   float64
+	}{
+		{"full marks", rubric, []ScoreItem{{CriterionID: 1, Score: 10}, {CriterionID: 2, Score: 5}}, 10},
+		{"weighted", rubric, []ScoreItem{{CriterionID: 1, Score: 5}, {CriterionID: 2, Score: 5}}, 6.67},
+		{"minimum of the range counts as zero", rubric, []ScoreItem{{CriterionID: 1, Score: 0}, {CriterionID: 2, Score: 1}}, 0},
+		{"scores outside the range are clamped", rubric, []ScoreItem{{CriterionID: 1, Score: 12}, {CriterionID: 2, Score: -3}}, 6.67},
+		{"missing item scores the minimum", rubric, []ScoreItem{{CriterionID: 1, Score: 10}}, 6.67},
+		{"unknown criterion is ignored", rubric, []ScoreItem{{CriterionID: 9, Score: 10}}, 0},
+		{
+			"empty and zero-weight criteria are skipped",
+			Rubric{Criteria: []RubricCriterion{criterion(1, 1, 0, 10), criterion(2, 0, 0, 10), criterion(3, 1, 5, 5)}},
+			[]ScoreItem{{CriterionID: 1, Score: 8}, {CriterionID: 2, Score: 0}},
+			8,
+		},
+		{"no criteria", Rubric{}, []ScoreItem{{CriterionID: 1, Score: 10}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rubric.Score(tt.items); got != tt.want {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRubricAggregate(t *testing.T) {
+	weights := Rubric{AdvisorWeight: 1, ReviewerWeight: 1, CommitteeWeight: 2}
+	tests := []struct {
+		name          string
+		dropOutliers  bool
+		maxDeviation  float64
+		sheets        []ScoreSheet
+		wantAdvisor   float64
+		wantReviewer  float64
+		wantCommittee float64
+		wantGrade     float64
+		wantDropped   []uint
+		wantFlagged   []uint
+	}{
+		{
+			name:          "weighted average of the grader groups",
+			sheets:        []ScoreSheet{sheet(1, GraderAdvisor, 8), sheet(2, GraderReviewer, 6), sheet(3, GraderCommittee, 7), sheet(4, GraderCommittee, 9)},
+			wantAdvisor:   8,
+			wantReviewer:  6,
+			wantCommittee: 8,
+			wantGrade:     7.5,
+			wantDropped:   []uint{},
+			wantFlagged:   []uint{},
+		},
+		{
+			name:          "highest and lowest committee sheets dropped",
+			dropOutliers:  true,
+			sheets:        []ScoreSheet{sheet(1, GraderAdvisor, 8), sheet(2, GraderReviewer, 8), sheet(3, GraderCommittee, 2), sheet(4, GraderCommittee, 7), sheet(5, GraderCommittee, 8), sheet(6, GraderCommittee, 10)},
+			wantAdvisor:   8,
+			wantReviewer:  8,
+			wantCommittee: 7.5,
+			wantGrade:     7.8,
+			wantDropped:   []uint{3, 6},
+			wantFlagged:   []uint{},
+		},
+		{
+			name:          "too few committee sheets to drop",
+			dropOutliers:  true,
+			sheets:        []ScoreSheet{sheet(1, GraderAdvisor, 8), sheet(2, GraderReviewer, 8), sheet(3, GraderCommittee, 2), sheet(4, GraderCommittee, 7), sheet(5, GraderCommittee, 9)},
+			wantAdvisor:   8,
+			wantReviewer:  8,
+			wantCommittee: 6,
+			wantGrade:     7,
+			wantDropped:   []uint{},
+			wantFlagged:   []uint{},
+		},
+		{
+			name:          "sheets far from the committee average are flagged",
+			maxDeviation:  1.5,
+			sheets:        []ScoreSheet{sheet(1, GraderAdvisor, 8), sheet(2, GraderReviewer, 8), sheet(3, GraderCommittee, 4), sheet(4, GraderCommittee, 7), sheet(5, GraderCommittee, 7)},
+			wantAdvisor:   8,
+			wantReviewer:  8,
+			wantCommittee: 6,
+			wantGrade:     7,
+			wantDropped:   []uint{},
+			wantFlagged:   []uint{3},
+		},
+		{
+			name:          "dropped sheets are not flagged",
+			dropOutliers:  true,
+			maxDeviation:  1,
+			sheets:        []ScoreSheet{sheet(1, GraderAdvisor, 8), sheet(2, GraderReviewer, 8), sheet(3, GraderCommittee, 1), sheet(4, GraderCommittee, 7), sheet(5, GraderCommittee, 8), sheet(6, GraderCommittee, 10)},
+			wantAdvisor:   8,
+			wantReviewer:  8,
+			wantCommittee: 7.5,
+			wantGrade:     7.8,
+			wantDropped:   []uint{3, 6},
+			wantFlagged:   []uint{},
+		},
+		{
+			name:          "no deviation limit",
+			sheets:        []ScoreSheet{sheet(3, GraderCommittee, 0), sheet(4, GraderCommittee, 10)},
+			wantCommittee: 5,
+			wantGrade:     2.5,
+			wantDropped:   []uint{},
+			wantFlagged:   []uint{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rubric := weights
+			rubric.DropOutliers = tt.dropOutliers
+			rubric.MaxDeviation = tt.maxDeviation
+			got := rubric.Aggregate(tt.sheets)
+			if got.AdvisorScore != tt.wantAdvisor || got.ReviewerScore != tt.wantReviewer || got.CommitteeScore != tt.wantCommittee {
+				t.Errorf("scores = %v, %v, %v, want %v, %v, %v", got.AdvisorScore, got.ReviewerScore, got.CommitteeScore, tt.wantAdvisor, tt.wantReviewer, tt.wantCommittee)
+			}
+			if got.Grade != tt.wantGrade {
+				t.Errorf("Grade = %v, want %v", got.Grade, tt.wantGrade)
+			}
+			if !reflect.DeepEqual(got.Dropped, tt.wantDropped) {
+				t.Errorf("Dropped = %v, want %v", got.Dropped, tt.wantDropped)
+			}
+			if !reflect.DeepEqual(got.Flagged, tt.wantFlagged) {
+				t.Errorf("Flagged = %v, want %v", got.Flagged, tt.wantFlagged)
+			}
+		})
+	}
+}
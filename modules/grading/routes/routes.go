@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"app/modules/grading/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitGradingRoutes(app *fiber.App) {
+	grading := app.Group("/grading")
+
+	grading.Get("/rubric", controller.GetRubrics)
+	grading.Get("/rubric/:id", controller.GetRubric)
+	grading.Post("/rubric", controller.CreateRubric)
+	grading.Put("/rubric/:id", controller.UpdateRubric)
+	grading.Put("/rubric/:id/activate", controller.ActivateRubric)
+	grading.Delete("/rubric/:id", controller.DeleteRubric)
+
+	grading.Get("/thesis/:thesisID/sheet", controller.GetScoreSheets)
+	grading.Put("/thesis/:thesisID/sheet", controller.SaveScoreSheet)
+	grading.Get("/thesis/:thesisID/grade", controller.GetGrades)
+	grading.Post("/thesis/:thesisID/grade", controller.ComputeGrades)
+	grading.Get("/thesis/:thesisID/grade/revision", controller.GetGradeRevisions)
+
+	grading.Get("/publication", controller.GetPublications)
+	grading.Post("/publication", controller.PublishGrades)
+
+	grading.Get("/appeal", controller.GetAppeals)
+	grading.Get("/appeal/:id", controller.GetAppeal)
+	grading.Post("/appeal", controller.CreateAppeal)
+	grading.Put("/appeal/:id/assign", controller.AssignAppeal)
+	grading.Put("/appeal/:id/decide", controller.DecideAppeal)
+}
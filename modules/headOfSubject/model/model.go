@@ -7,6 +7,8 @@ import (
 type HeadOfSubject struct {
 	model.Info `gorm:"embedded;-:migration"`
 	Code       string `json:"code" gorm:"column:CODE;size:10;not null"`
+	// SubjectID giới hạn các luận văn trưởng bộ môn được xem và duyệt; chưa gán thì không thấy luận văn nào
+	SubjectID *uint `json:"subjectID" gorm:"column:SUBJECT_ID;index"`
 }
 
 type CreateHeadOfSubject struct {
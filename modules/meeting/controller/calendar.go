@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/meeting/model"
+	"app/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelStudent "app/modules/student/model"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Buổi gặp đã hủy vẫn được đưa vào lịch một thời gian để ứng dụng lịch xóa sự kiện
+const cancelledFeedDays = 30
+
+// GetCalendarLink trả về đường dẫn .ics cá nhân
+// @Summary Get my calendar feed link
+// @Description Get the personal iCalendar feed URL of the current student or advisor, creating it on first use. The feed contains meetings and task deadlines and can be subscribed to from calendar apps.
+// @Tags Meeting
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/calendar/link [get]
+func GetCalendarLink(c *fiber.Ctx) error {
+	return calendarLink(c, false)
+}
+
+// ResetCalendarLink tạo khóa mới cho đường dẫn .ics, đường dẫn cũ hết hiệu lực
+// @Summary Reset my calendar feed link
+// @Description Replace the personal iCalendar feed URL; the previous URL stops working
+// @Tags Meeting
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/calendar/link/reset [post]
+func ResetCalendarLink(c *fiber.Ctx) error {
+	return calendarLink(c, true)
+}
+
+func calendarLink(c *fiber.Ctx, reset bool) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || (tokenData.Role != modelUsers.StudentRole && tokenData.Role != modelUsers.AdvisorRole) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var feed model.CalendarFeed
+	err = db.Where("USER_ID = ? AND ROLE = ?", tokenData.ID, tokenData.Role).First(&feed).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		feed = model.CalendarFeed{UserID: tokenData.ID, Role: tokenData.Role, Token: newFeedToken()}
+		feed.CreatedBy = tokenData.Code
+		err = db.Create(&feed).Error
+	case err == nil && reset:
+		err = db.Model(&feed).Updates(map[string]interface{}{"TOKEN": newFeedToken(), "UPDATED_BY": tokenData.Code}).Error
+	}
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = model.CalendarLink{URL: "/meeting/calendar/" + feed.Token + ".ics"}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetCalendarFeed trả về lịch iCalendar của người sở hữu đường dẫn
+// @Summary iCalendar feed
+// @Description Public iCalendar feed identified by the secret in the URL: booked meetings (recently cancelled ones as cancelled events) and deadlines of open thesis tasks
+// @Tags Meeting
+// @Produce text/calendar
+// @Param token path string true "Feed secret followed by .ics"
+// @Success 200 {string} string
+// @Failure 404 {object} config.DataResponse
+// @Router /meeting/calendar/{token} [get]
+func GetCalendarFeed(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var feed model.CalendarFeed
+	token := strings.TrimSuffix(c.Params("token"), ".ics")
+	if token == "" || db.Where("TOKEN = ?", token).First(&feed).Error != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("LINK_EXPIRED")
+		return c.Status(fiber.StatusNotFound).JSON(response)
+	}
+
+	now := core.Now()
+	cal := newCalendar(now)
+
+	query, ok := bookingsOf(db, feed.UserID, feed.Role)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.Status(fiber.StatusForbidden).JSON(response)
+	}
+	var bookings []model.Booking
+	if err := query.Where("(STATUS = ? OR (STATUS = ? AND START_AT >= ?))", model.BookingBooked, model.BookingCancelled, now.AddDate(0, 0, -cancelledFeedDays)).
+		Order("START_AT").Find(&bookings).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.Status(fiber.StatusInternalServerError).JSON(response)
+	}
+	for _, booking := range bookings {
+		status := "CONFIRMED"
+		if booking.Status == model.BookingCancelled {
+			status = "CANCELLED"
+		}
+		cal.event(calendarEvent{
+			uid:         fmt.Sprintf("meeting-%d", booking.ID),
+			start:       booking.StartAt,
+			end:         booking.EndAt,
+			summary:     "Thesis meeting",
+			location:    booking.Location,
+			description: booking.Note,
+			status:      status,
+			modified:    booking.UpdatedAt,
+		})
+	}
+
+	thesisID, err := feedThesisID(db, feed)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.Status(fiber.StatusInternalServerError).JSON(response)
+	}
+	if thesisID != 0 {
+		var tasks []modelThesis.ThesisTask
+		if err := db.Where("THESIS_ID = ? AND DEADLINE_AT IS NOT NULL", thesisID).Order("DEADLINE_AT").Find(&tasks).Error; err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("GET_DATA_FAIL")
+			return c.Status(fiber.StatusInternalServerError).JSON(response)
+		}
+		for _, task := range tasks {
+			if task.IsClosed() || task.Deadline == nil {
+				continue
+			}
+			cal.event(calendarEvent{
+				uid:         fmt.Sprintf("task-%d", task.ID),
+				start:       *task.Deadline,
+				end:         *task.Deadline,
+				summary:     "Deadline: " + task.Title,
+				description: task.Description,
+				status:      "CONFIRMED",
+				modified:    task.UpdatedAt,
+			})
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="thesis.ics"`)
+	return c.SendString(cal.String())
+}
+
+// feedThesisID trả về luận văn của sinh viên hoặc giảng viên sở hữu lịch
+func feedThesisID(db *gorm.DB, feed model.CalendarFeed) (uint, error) {
+	switch feed.Role {
+	case modelUsers.StudentRole:
+		var student modelStudent.Student
+		err := db.Select("ID, THESIS_ID").First(&student, feed.UserID).Error
+		return student.ThesisID, err
+	case modelUsers.AdvisorRole:
+		var advisor modelAdvisor.Advisor
+		err := db.Select("ID, THESIS_ID").First(&advisor, feed.UserID).Error
+		return advisor.ThesisID, err
+	}
+	return 0, nil
+}
+
+func newFeedToken() string {
+	return strings.ReplaceAll(uuid.NewString()+uuid.NewString(), "-", "")
+}
+
+type calendarEvent struct {
+	uid, summary, location, description, status string
+	start, end, modified                        time.Time
+}
+
+// calendar ghi iCalendar (RFC 5545) với thời gian theo UTC
+type calendar struct {
+	b     strings.Builder
+	stamp time.Time
+}
+
+func newCalendar(now time.Time) *calendar {
+	cal := &calendar{stamp: now}
+	cal.line("BEGIN:VCALENDAR")
+	cal.line("VERSION:2.0")
+	cal.line("PRODID:-//Thesis Management//Calendar//EN")
+	cal.line("CALSCALE:GREGORIAN")
+	cal.line("METHOD:PUBLISH")
+	cal.line("X-WR-CALNAME:" + escapeText("Thesis"))
+	return cal
+}
+
+func (cal *calendar) event(e calendarEvent) {
+	cal.line("BEGIN:VEVENT")
+	cal.line("UID:" + e.uid + "@thesis-management")
+	cal.line("DTSTAMP:" + icsTime(cal.stamp))
+	cal.line("DTSTART:" + icsTime(e.start))
+	cal.line("DTEND:" + icsTime(e.end))
+	if !e.modified.IsZero() {
+		cal.line("LAST-MODIFIED:" + icsTime(e.modified))
+	}
+	cal.line("SUMMARY:" + escapeText(e.summary))
+	if e.location != "" {
+		cal.line("LOCATION:" + escapeText(e.location))
+	}
+	if e.description != "" {
+		cal.line("DESCRIPTION:" + escapeText(e.description))
+	}
+	cal.line("STATUS:" + e.status)
+	cal.line("END:VEVENT")
+}
+
+func (cal *calendar) String() string {
+	return cal.b.String() + "END:VCALENDAR\r\n"
+}
+
+// line ghi một dòng, gấp dòng dài quá 75 byte mà không cắt giữa ký tự UTF-8
+func (cal *calendar) line(content string) {
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > 75 {
+			cal.b.WriteString("\r\n ")
+			width = 1
+		}
+		cal.b.WriteRune(r)
+		width += size
+	}
+	cal.b.WriteString("\r\n")
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func escapeText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendarLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLines []int
+	}{
+		{"short", "SUMMARY:Gặp GVHD", []int{18}},
+		{"exactly 75 octets", strings.Repeat("a", 75), []int{75}},
+		{"76 octets", strings.Repeat("a", 76), []int{75, 2}},
+		{"continuation lines hold 74 octets", strings.Repeat("a", 200), []int{75, 75, 52}},
+		{"multi-byte runes are not split", "SUMMARY:" + strings.Repeat("ệ", 30), []int{74, 25}},
+		{"empty", "", []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := &calendar{}
+			cal.line(tt.content)
+			out := cal.b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line %q does not end with CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != len(tt.wantLines) {
+				t.Fatalf("got %d lines %q, want %d", len(lines), lines, len(tt.wantLines))
+			}
+			for i, line := range lines {
+				if len(line) != tt.wantLines[i] {
+					t.Errorf("line %d has %d octets, want %d", i, len(line), tt.wantLines[i])
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d %q splits a UTF-8 sequence", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d %q does not start with a space", i, line)
+				}
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.content {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.content)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "Phòng H6-304", "Phòng H6-304"},
+		{"separators", "a;b,c", `a\;b\,c`},
+		{"backslash first", `C:\docs;x`, `C:\\docs\;x`},
+		{"newlines", "line 1\r\nline 2\nline 3", `line 1\nline 2\nline 3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeText(tt.value); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarEvent(t *testing.T) {
+	ict := time.FixedZone("ICT", 7*60*60)
+	cal := newCalendar(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	cal.event(calendarEvent{
+		uid:      "booking-5",
+		summary:  "Gặp GVHD, tuần 3",
+		location: "H6-304",
+		status:   "CONFIRMED",
+		start:    time.Date(2026, 6, 1, 9, 0, 0, 0, ict),
+		end:      time.Date(2026, 6, 1, 9, 30, 0, 0, ict),
+	})
+	out := cal.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"UID:booking-5@thesis-management\r\n",
+		"DTSTAMP:20260601T000000Z\r\n",
+		"DTSTART:20260601T020000Z\r\nDTEND:20260601T023000Z\r\n",
+		"SUMMARY:Gặp GVHD\\, tuần 3\r\n",
+		"LOCATION:H6-304\r\n",
+		"STATUS:CONFIRMED\r\nEND:VEVENT\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DESCRIPTION:") || strings.Contains(out, "LAST-MODIFIED:") {
+		t.Errorf("empty optional fields were written:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n") {
+		t.Errorf("calendar is not closed:\n%s", out)
+	}
+}
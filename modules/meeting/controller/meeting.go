@@ -0,0 +1,715 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/meeting/model"
+	"app/utils"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	modelStudent "app/modules/student/model"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Meeting API
+// @version 1.0
+// @description Advisor office hours, meeting bookings and calendar feeds
+// @termsOfService http://swagger.io/terms/
+// @BasePath /meeting
+// @schemes http
+// @produce json
+// @consumes json
+
+const (
+	defaultOpenSlotDays = 14
+	maxOpenSlotDays     = 62
+)
+
+// GetMySlots trả về khung giờ tiếp sinh viên của giảng viên hiện tại
+// @Summary Get my availability slots
+// @Description Get the recurring availability slots of the current advisor
+// @Tags Meeting
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/slot [get]
+func GetMySlots(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.AdvisorRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var slots []model.AvailabilitySlot
+	if err := database.DB.Where("ADVISOR_ID = ?", tokenData.ID).Order("WEEKDAY, START_TIME").Find(&slots).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = slots
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateSlot giảng viên công bố khung giờ tiếp sinh viên hằng tuần
+// @Summary Create an availability slot
+// @Description Publish a weekly slot (weekday 0 = Sunday ... 6 = Saturday, times HH:MM in campus time) split into bookable meetings of slotMinutes (default 30). Slots of the same advisor may not overlap.
+// @Tags Meeting
+// @Accept json
+// @Produce json
+// @Param body body model.CreateAvailabilitySlot true "Availability slot"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/slot [post]
+func CreateSlot(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.AdvisorRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateAvailabilitySlot
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	slot := model.AvailabilitySlot{AdvisorID: tokenData.ID}
+	if errors := applySlot(&slot, &payload); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+	slot.CreatedBy = tokenData.Code
+
+	if other, ok := overlappingSlot(db, slot); ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("SLOT_OVERLAP")
+		response.Data = other
+		return c.JSON(response)
+	}
+
+	if err := db.Create(&slot).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = slot
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateSlot cập nhật khung giờ; các buổi đã đặt được giữ nguyên
+// @Summary Update an availability slot
+// @Description Replace a slot of the current advisor. Meetings already booked are kept.
+// @Tags Meeting
+// @Accept json
+// @Produce json
+// @Param id path int true "Slot ID"
+// @Param body body model.CreateAvailabilitySlot true "Availability slot"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/slot/{id} [put]
+func UpdateSlot(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.AdvisorRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateAvailabilitySlot
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var slot model.AvailabilitySlot
+	if err := db.Where("ADVISOR_ID = ?", tokenData.ID).First(&slot, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	if errors := applySlot(&slot, &payload); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+	slot.UpdatedBy = tokenData.Code
+
+	if other, ok := overlappingSlot(db, slot); ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("SLOT_OVERLAP")
+		response.Data = other
+		return c.JSON(response)
+	}
+
+	if err := db.Save(&slot).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = slot
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteSlot xóa khung giờ; các buổi đã đặt được giữ nguyên
+// @Summary Delete an availability slot
+// @Description Soft delete a slot of the current advisor. Meetings already booked are kept and must be cancelled separately.
+// @Tags Meeting
+// @Produce json
+// @Param id path int true "Slot ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/slot/{id} [delete]
+func DeleteSlot(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.AdvisorRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var slot model.AvailabilitySlot
+	if err := db.Where("ADVISOR_ID = ?", tokenData.ID).First(&slot, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	if err := db.Model(&slot).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetOpenSlots trả về các buổi còn trống của một giảng viên
+// @Summary Get open meeting times of an advisor
+// @Description Expand the advisor's weekly slots into bookable meetings between from and to (YYYY-MM-DD, default the next 14 days, at most 62 days), without past or already booked times
+// @Tags Meeting
+// @Produce json
+// @Param advisorId path int true "Advisor ID"
+// @Param from query string false "From date"
+// @Param to query string false "To date (inclusive)"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/advisor/{advisorId}/open [get]
+func GetOpenSlots(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	if _, err := utils.ExtractTokenData(c); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	advisorID, err := strconv.ParseUint(c.Params("advisorId"), 10, 64)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	loc := now.Location()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, defaultOpenSlotDays)
+	vItem := map[string]string{"from": c.Query("from"), "to": c.Query("to")}
+	errors := map[string]string{}
+	if vItem["from"] != "" {
+		errors = utils.DateFormatCheck([]string{"from"}, vItem, errors)
+	}
+	if vItem["to"] != "" {
+		errors = utils.DateFormatCheck([]string{"to"}, vItem, errors)
+	}
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("FORMAT_DATE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+	if vItem["from"] != "" {
+		from, _ = time.ParseInLocation("2006-01-02", vItem["from"], loc)
+	}
+	if vItem["to"] != "" {
+		to, _ = time.ParseInLocation("2006-01-02", vItem["to"], loc)
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) || to.Sub(from) > maxOpenSlotDays*24*time.Hour {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+		return c.JSON(response)
+	}
+	if from.Before(now) {
+		from = now
+	}
+
+	var slots []model.AvailabilitySlot
+	if err := db.Where("ADVISOR_ID = ?", advisorID).Find(&slots).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	var booked []model.Booking
+	if err := db.Where("ADVISOR_ID = ? AND STATUS = ? AND START_AT < ? AND END_AT > ?", advisorID, model.BookingBooked, to, from).Find(&booked).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	open := []model.OpenSlot{}
+	for _, slot := range slots {
+		for _, item := range slot.Occurrences(from, to, loc) {
+			if !overlapsBooking(booked, item.StartAt, item.EndAt) {
+				open = append(open, item)
+			}
+		}
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if !open[i].StartAt.Equal(open[j].StartAt) {
+			return open[i].StartAt.Before(open[j].StartAt)
+		}
+		return open[i].SlotID < open[j].SlotID
+	})
+
+	response.Data = open
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetMyBookings trả về các buổi gặp của người dùng hiện tại
+// @Summary Get my meeting bookings
+// @Description Get bookings of the current student or advisor. By default only upcoming booked meetings are returned.
+// @Tags Meeting
+// @Produce json
+// @Param all query bool false "Include past and cancelled meetings"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/booking [get]
+func GetMyBookings(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	query, ok := bookingsOf(database.DB, tokenData.ID, tokenData.Role)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if !c.QueryBool("all") {
+		query = query.Where("STATUS = ? AND END_AT >= ?", model.BookingBooked, core.Now())
+	}
+
+	var bookings []model.Booking
+	if err := query.Order("START_AT").Find(&bookings).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = bookings
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateBooking sinh viên đặt một buổi gặp với giảng viên hướng dẫn
+// @Summary Book a meeting
+// @Description A student books a meeting in a slot of an advisor of their thesis. startAt must be the start of a meeting in the slot. The booking is rejected if the advisor or the student already has a meeting at that time. The advisor and the thesis students are notified.
+// @Tags Meeting
+// @Accept json
+// @Produce json
+// @Param body body model.CreateBooking true "Booking"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/booking [post]
+func CreateBooking(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateBooking
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	startAt, err := core.ParseCampusTime(payload.StartAt)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("FORMAT_DATETIME")
+		response.ValidateError = map[string]string{"startAt": config.GetMessageCode("FORMAT_DATETIME")}
+		return c.JSON(response)
+	}
+
+	var student modelStudent.Student
+	if err := db.First(&student, tokenData.ID).Error; err != nil || student.ThesisID == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, student.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	// Khóa khung giờ để hai sinh viên không đặt cùng lúc một buổi
+	var slot model.AvailabilitySlot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&slot, payload.SlotID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !thesis.HasAdvisor(slot.AdvisorID) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	loc := core.CampusLocation()
+	if !startAt.After(core.Now()) || !slot.Offers(startAt, loc) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SLOT_NOT_AVAILABLE")
+		return c.JSON(response)
+	}
+	endAt := startAt.Add(time.Duration(slot.SlotMinutes) * time.Minute)
+
+	var conflicts []model.Booking
+	if err := tx.Where("STATUS = ? AND START_AT < ? AND END_AT > ?", model.BookingBooked, endAt, startAt).
+		Where("(ADVISOR_ID = ? OR STUDENT_ID = ?)", slot.AdvisorID, student.ID).
+		Find(&conflicts).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if len(conflicts) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("BOOKING_CONFLICT")
+		response.Data = conflicts
+		return c.JSON(response)
+	}
+
+	booking := model.Booking{
+		SlotID:    slot.ID,
+		AdvisorID: slot.AdvisorID,
+		ThesisID:  thesis.ID,
+		StudentID: student.ID,
+		StartAt:   startAt,
+		EndAt:     endAt,
+		Location:  slot.Location,
+		Note:      payload.Note,
+		Status:    model.BookingBooked,
+	}
+	booking.CreatedBy = tokenData.Code
+	if err := tx.Create(&booking).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	message := modelNotification.Message{
+		Type:  modelNotification.NotificationMeetingBooked,
+		Title: "Meeting booked",
+		Body:  fmt.Sprintf("%s booked a meeting on %s for \"%s\"", student.FullName, startAt.In(loc).Format("2006-01-02 15:04"), thesis.TitleVi),
+		Link:  "/meeting/booking",
+	}
+	if err := notificationController.Notify(tx, message, participants(&thesis, booking.AdvisorID)...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = booking
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// CancelBooking hủy buổi gặp; sinh viên của luận văn hoặc giảng viên được đặt lịch đều có thể hủy
+// @Summary Cancel a meeting
+// @Description Cancel an upcoming meeting. The advisor and the students of the thesis can cancel; everyone involved is notified.
+// @Tags Meeting
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param body body model.CancelBooking false "Reason"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /meeting/booking/{id}/cancel [put]
+func CancelBooking(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.CancelBooking
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	var booking model.Booking
+	if err := db.First(&booking, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Students").First(&thesis, booking.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	allowed := (tokenData.Role == modelUsers.AdvisorRole && tokenData.ID == booking.AdvisorID) ||
+		(tokenData.Role == modelUsers.StudentRole && thesis.HasStudent(tokenData.ID))
+	if !allowed {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if booking.Status != model.BookingBooked || !booking.EndAt.After(core.Now()) {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	booking.Status = model.BookingCancelled
+	booking.CancelledBy = tokenData.Code
+	booking.CancelReason = payload.Reason
+	booking.UpdatedBy = tokenData.Code
+	if err := tx.Save(&booking).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	body := fmt.Sprintf("The meeting on %s for \"%s\" was cancelled", booking.StartAt.In(core.CampusLocation()).Format("2006-01-02 15:04"), thesis.TitleVi)
+	if reason := strings.TrimSpace(payload.Reason); reason != "" {
+		body += ": " + reason
+	}
+	recipients := []modelNotification.Recipient{}
+	for _, recipient := range participants(&thesis, booking.AdvisorID) {
+		if recipient.UserID != tokenData.ID || recipient.Role != tokenData.Role {
+			recipients = append(recipients, recipient)
+		}
+	}
+	message := modelNotification.Message{
+		Type:  modelNotification.NotificationMeetingCancelled,
+		Title: "Meeting cancelled",
+		Body:  body,
+		Link:  "/meeting/booking",
+	}
+	if err := notificationController.Notify(tx, message, recipients...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = booking
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// applySlot kiểm tra dữ liệu khung giờ và gán vào slot
+func applySlot(slot *model.AvailabilitySlot, payload *model.CreateAvailabilitySlot) map[string]string {
+	vItem := map[string]string{
+		"startTime":  strings.TrimSpace(payload.StartTime),
+		"endTime":    strings.TrimSpace(payload.EndTime),
+		"validFrom":  payload.ValidFrom,
+		"validUntil": payload.ValidUntil,
+	}
+	errors := utils.RequireCheck([]string{"startTime", "endTime"}, vItem, map[string]string{})
+	if payload.ValidFrom != "" {
+		errors = utils.DateFormatCheck([]string{"validFrom"}, vItem, errors)
+	}
+	if payload.ValidUntil != "" {
+		errors = utils.DateFormatCheck([]string{"validUntil"}, vItem, errors)
+	}
+	if payload.Weekday < 0 || payload.Weekday > 6 {
+		errors["weekday"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	startMinute, err := model.ParseClock(vItem["startTime"])
+	if err != nil {
+		errors["startTime"] = config.GetMessageCode("FORMAT_DATETIME")
+	}
+	endMinute, err := model.ParseClock(vItem["endTime"])
+	if err != nil {
+		errors["endTime"] = config.GetMessageCode("FORMAT_DATETIME")
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	slotMinutes := payload.SlotMinutes
+	if slotMinutes == 0 {
+		slotMinutes = model.DefaultSlotMinutes
+	}
+	if slotMinutes < model.MinSlotMinutes || slotMinutes > model.MaxSlotMinutes {
+		errors["slotMinutes"] = config.GetMessageCode("PARAM_ERROR")
+		return errors
+	}
+	if startMinute+slotMinutes > endMinute {
+		errors["endTime"] = config.GetMessageCode("INVALID_TIME_RANGE")
+		return errors
+	}
+
+	loc := core.CampusLocation()
+	var validFrom, validUntil *time.Time
+	if payload.ValidFrom != "" {
+		t, _ := time.ParseInLocation("2006-01-02", payload.ValidFrom, loc)
+		validFrom = &t
+	}
+	if payload.ValidUntil != "" {
+		t, _ := time.ParseInLocation("2006-01-02", payload.ValidUntil, loc)
+		t = core.EndOfDay(t)
+		validUntil = &t
+	}
+	if validFrom != nil && validUntil != nil && validUntil.Before(*validFrom) {
+		errors["validUntil"] = config.GetMessageCode("INVALID_TIME_RANGE")
+		return errors
+	}
+
+	slot.Weekday = payload.Weekday
+	slot.StartTime = vItem["startTime"]
+	slot.EndTime = vItem["endTime"]
+	slot.SlotMinutes = slotMinutes
+	slot.Location = payload.Location
+	slot.ValidFrom = validFrom
+	slot.ValidUntil = validUntil
+	return errors
+}
+
+// overlappingSlot tìm khung giờ khác của cùng giảng viên giao với slot
+func overlappingSlot(db *gorm.DB, slot model.AvailabilitySlot) (model.AvailabilitySlot, bool) {
+	var others []model.AvailabilitySlot
+	db.Where("ADVISOR_ID = ? AND WEEKDAY = ? AND ID <> ?", slot.AdvisorID, slot.Weekday, slot.ID).Find(&others)
+	for _, other := range others {
+		if slot.Overlaps(other) {
+			return other, true
+		}
+	}
+	return model.AvailabilitySlot{}, false
+}
+
+// bookingsOf trả về truy vấn các buổi gặp của một sinh viên (mọi buổi của luận văn) hoặc giảng viên
+func bookingsOf(db *gorm.DB, userID uint, role int) (*gorm.DB, bool) {
+	switch role {
+	case modelUsers.AdvisorRole:
+		return db.Where("ADVISOR_ID = ?", userID), true
+	case modelUsers.StudentRole:
+		return db.Where("THESIS_ID IN (?)", db.Model(&modelStudent.Student{}).Select("THESIS_ID").Where("ID = ? AND THESIS_ID > 0", userID)), true
+	}
+	return nil, false
+}
+
+// participants: giảng viên được đặt lịch và các sinh viên của luận văn
+func participants(thesis *modelThesis.Thesis, advisorID uint) []modelNotification.Recipient {
+	recipients := []modelNotification.Recipient{{UserID: advisorID, Role: modelUsers.AdvisorRole}}
+	for _, student := range thesis.Students {
+		recipients = append(recipients, modelNotification.Recipient{UserID: student.ID, Role: modelUsers.StudentRole})
+	}
+	return recipients
+}
+
+func overlapsBooking(bookings []model.Booking, start, end time.Time) bool {
+	for _, booking := range bookings {
+		if booking.StartAt.Before(end) && booking.EndAt.After(start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package meetingMigrate
+
+import (
+	"app/database"
+	model "app/modules/meeting/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.AvailabilitySlot{})
+	db.AutoMigrate(&model.Booking{})
+	db.AutoMigrate(&model.CalendarFeed{})
+
+	return true
+}
@@ -0,0 +1,182 @@
+package model
+
+import (
+	"app/model"
+	"fmt"
+	"time"
+)
+
+var BookingBooked, BookingCancelled = "BOOKED", "CANCELLED"
+
+var DefaultSlotMinutes, MinSlotMinutes, MaxSlotMinutes = 30, 5, 240
+
+// AvailabilitySlot là khung giờ tiếp sinh viên lặp lại hằng tuần của giảng viên.
+// Khung giờ được chia thành các buổi dài SlotMinutes phút để đặt lịch.
+type AvailabilitySlot struct {
+	model.Header
+	AdvisorID   uint       `json:"advisorID" gorm:"column:ADVISOR_ID;index"`
+	Weekday     int        `json:"weekday" gorm:"column:WEEKDAY"`
+	StartTime   string     `json:"startTime" gorm:"column:START_TIME;size:5"`
+	EndTime     string     `json:"endTime" gorm:"column:END_TIME;size:5"`
+	SlotMinutes int        `json:"slotMinutes" gorm:"column:SLOT_MINUTES"`
+	Location    string     `json:"location" gorm:"column:LOCATION"`
+	ValidFrom   *time.Time `json:"validFrom" gorm:"column:VALID_FROM"`
+	ValidUntil  *time.Time `json:"validUntil" gorm:"column:VALID_UNTIL"`
+}
+
+// Booking là một buổi gặp sinh viên đã đặt trong khung giờ của giảng viên
+type Booking struct {
+	model.Header
+	SlotID       uint      `json:"slotID" gorm:"column:SLOT_ID;index"`
+	AdvisorID    uint      `json:"advisorID" gorm:"column:ADVISOR_ID;index"`
+	ThesisID     uint      `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	StudentID    uint      `json:"studentID" gorm:"column:STUDENT_ID;index"`
+	StartAt      time.Time `json:"startAt" gorm:"column:START_AT;index"`
+	EndAt        time.Time `json:"endAt" gorm:"column:END_AT"`
+	Location     string    `json:"location" gorm:"column:LOCATION"`
+	Note         string    `json:"note" gorm:"column:NOTE"`
+	Status       string    `json:"status" gorm:"column:STATUS;size:20;default:BOOKED"`
+	CancelledBy  string    `json:"cancelledBy" gorm:"column:CANCELLED_BY;size:50"`
+	CancelReason string    `json:"cancelReason" gorm:"column:CANCEL_REASON"`
+}
+
+// CalendarFeed giữ khóa bí mật của đường dẫn .ics; ứng dụng lịch tải lại định kỳ nên khóa không hết hạn
+// mà chỉ đổi khi người dùng yêu cầu
+type CalendarFeed struct {
+	model.Header
+	UserID uint   `json:"-" gorm:"column:USER_ID;index"`
+	Role   int    `json:"-" gorm:"column:ROLE"`
+	Token  string `json:"-" gorm:"column:TOKEN;size:64;uniqueIndex"`
+}
+
+type CreateAvailabilitySlot struct {
+	Weekday     int    `json:"weekday"`
+	StartTime   string `json:"startTime" validate:"required"`
+	EndTime     string `json:"endTime" validate:"required"`
+	SlotMinutes int    `json:"slotMinutes"`
+	Location    string `json:"location"`
+	ValidFrom   string `json:"validFrom"`
+	ValidUntil  string `json:"validUntil"`
+}
+
+type CreateBooking struct {
+	SlotID  uint   `json:"slotID" validate:"required"`
+	StartAt string `json:"startAt" validate:"required"`
+	Note    string `json:"note"`
+}
+
+type CancelBooking struct {
+	Reason string `json:"reason"`
+}
+
+// OpenSlot là một buổi còn trống có thể đặt
+type OpenSlot struct {
+	SlotID    uint      `json:"slotID"`
+	AdvisorID uint      `json:"advisorID"`
+	StartAt   time.Time `json:"startAt"`
+	EndAt     time.Time `json:"endAt"`
+	Location  string    `json:"location"`
+}
+
+type CalendarLink struct {
+	URL string `json:"url"`
+}
+
+// Minutes trả về phút trong ngày của START_TIME và END_TIME (dạng HH:MM)
+func (s AvailabilitySlot) Minutes() (int, int) {
+	return clockMinutes(s.StartTime), clockMinutes(s.EndTime)
+}
+
+// Occurrences liệt kê các buổi của khung giờ bắt đầu trong [from, to), theo múi giờ loc
+func (s AvailabilitySlot) Occurrences(from, to time.Time, loc *time.Location) []OpenSlot {
+	slots := []OpenSlot{}
+	if s.SlotMinutes <= 0 {
+		return slots
+	}
+	startMinute, endMinute := s.Minutes()
+	length := time.Duration(s.SlotMinutes) * time.Minute
+
+	from, to = from.In(loc), to.In(loc)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if int(day.Weekday()) != s.Weekday {
+			continue
+		}
+		for minute := startMinute; minute+s.SlotMinutes <= endMinute; minute += s.SlotMinutes {
+			start := day.Add(time.Duration(minute) * time.Minute)
+			if start.Before(from) || !start.Before(to) || !s.ValidOn(start) {
+				continue
+			}
+			slots = append(slots, OpenSlot{SlotID: s.ID, AdvisorID: s.AdvisorID, StartAt: start, EndAt: start.Add(length), Location: s.Location})
+		}
+	}
+	return slots
+}
+
+// Offers kiểm tra start có đúng là giờ bắt đầu một buổi của khung giờ không
+func (s AvailabilitySlot) Offers(start time.Time, loc *time.Location) bool {
+	start = start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for _, slot := range s.Occurrences(day, day.AddDate(0, 0, 1), loc) {
+		if slot.StartAt.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidOn: thời điểm t nằm trong thời hạn hiệu lực của khung giờ (ValidUntil tính hết ngày)
+func (s AvailabilitySlot) ValidOn(t time.Time) bool {
+	if s.ValidFrom != nil && t.Before(*s.ValidFrom) {
+		return false
+	}
+	if s.ValidUntil != nil && t.After(*s.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// Overlaps: hai khung giờ trùng thứ, giao nhau về giờ và về thời hạn hiệu lực
+func (s AvailabilitySlot) Overlaps(other AvailabilitySlot) bool {
+	if s.Weekday != other.Weekday {
+		return false
+	}
+	start, end := s.Minutes()
+	otherStart, otherEnd := other.Minutes()
+	if start >= otherEnd || otherStart >= end {
+		return false
+	}
+	if s.ValidUntil != nil && other.ValidFrom != nil && s.ValidUntil.Before(*other.ValidFrom) {
+		return false
+	}
+	if other.ValidUntil != nil && s.ValidFrom != nil && other.ValidUntil.Before(*s.ValidFrom) {
+		return false
+	}
+	return true
+}
+
+// ParseClock đọc giờ dạng HH:MM, trả về phút trong ngày
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func clockMinutes(value string) int {
+	minutes, _ := ParseClock(value)
+	return minutes
+}
+
+func (AvailabilitySlot) TableName() string {
+	return "TBL_AVAILABILITY_SLOT"
+}
+
+func (Booking) TableName() string {
+	return "TBL_MEETING_BOOKING"
+}
+
+func (CalendarFeed) TableName() string {
+	return "TBL_CALENDAR_FEED"
+}
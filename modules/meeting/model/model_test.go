@@ -0,0 +1,200 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+var ict = time.FixedZone("ICT", 7*60*60)
+
+// meetingDay là thứ Hai 01/06/2026 theo giờ Việt Nam
+func meetingDay(day, hour, minute int) time.Time {
+	return time.Date(2026, 6, day, hour, minute, 0, 0, ict)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func mondaySlot(start, end string, minutes int) AvailabilitySlot {
+	slot := AvailabilitySlot{AdvisorID: 7, Weekday: int(time.Monday), StartTime: start, EndTime: end, SlotMinutes: minutes, Location: "H6-304"}
+	slot.ID = 3
+	return slot
+}
+
+func TestOccurrences(t *testing.T) {
+	weekly := mondaySlot("09:00", "10:00", 30)
+	fromJune8 := weekly
+	fromJune8.ValidFrom = timePtr(meetingDay(8, 0, 0))
+	untilJune8 := weekly
+	untilJune8.ValidUntil = timePtr(meetingDay(8, 23, 59))
+	tuesday := weekly
+	tuesday.Weekday = int(time.Tuesday)
+	noLength := weekly
+	noLength.SlotMinutes = 0
+
+	tests := []struct {
+		name   string
+		slot   AvailabilitySlot
+		from   time.Time
+		to     time.Time
+		starts []time.Time
+	}{
+		{
+			name:   "weekly expansion",
+			slot:   weekly,
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(15, 0, 0),
+			starts: []time.Time{meetingDay(1, 9, 0), meetingDay(1, 9, 30), meetingDay(8, 9, 0), meetingDay(8, 9, 30)},
+		},
+		{
+			name:   "remainder shorter than a slot is dropped",
+			slot:   mondaySlot("09:00", "10:15", 30),
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(2, 0, 0),
+			starts: []time.Time{meetingDay(1, 9, 0), meetingDay(1, 9, 30)},
+		},
+		{
+			name:   "slots already started are cut off",
+			slot:   weekly,
+			from:   meetingDay(1, 9, 10),
+			to:     meetingDay(2, 0, 0),
+			starts: []time.Time{meetingDay(1, 9, 30)},
+		},
+		{
+			name:   "end of range is exclusive",
+			slot:   weekly,
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(1, 9, 30),
+			starts: []time.Time{meetingDay(1, 9, 0)},
+		},
+		{
+			name:   "valid from",
+			slot:   fromJune8,
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(15, 0, 0),
+			starts: []time.Time{meetingDay(8, 9, 0), meetingDay(8, 9, 30)},
+		},
+		{
+			name:   "valid until covers its whole day",
+			slot:   untilJune8,
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(22, 0, 0),
+			starts: []time.Time{meetingDay(1, 9, 0), meetingDay(1, 9, 30), meetingDay(8, 9, 0), meetingDay(8, 9, 30)},
+		},
+		{
+			name:   "range given in another zone",
+			slot:   mondaySlot("06:00", "08:00", 60),
+			from:   time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+			to:     time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC),
+			starts: []time.Time{meetingDay(1, 7, 0)},
+		},
+		{
+			name:   "other weekday",
+			slot:   tuesday,
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(2, 0, 0),
+			starts: []time.Time{},
+		},
+		{
+			name:   "no slot length",
+			slot:   noLength,
+			from:   meetingDay(1, 0, 0),
+			to:     meetingDay(8, 0, 0),
+			starts: []time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.slot.Occurrences(tt.from, tt.to, ict)
+			if len(got) != len(tt.starts) {
+				t.Fatalf("Occurrences() = %+v, want starts %v", got, tt.starts)
+			}
+			length := time.Duration(tt.slot.SlotMinutes) * time.Minute
+			for i, want := range tt.starts {
+				if !got[i].StartAt.Equal(want) || !got[i].EndAt.Equal(want.Add(length)) {
+					t.Errorf("slot %d = %v-%v, want %v-%v", i, got[i].StartAt, got[i].EndAt, want, want.Add(length))
+				}
+				if got[i].SlotID != tt.slot.ID || got[i].AdvisorID != tt.slot.AdvisorID || got[i].Location != tt.slot.Location {
+					t.Errorf("slot %d = %+v, does not carry the slot details", i, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOffers(t *testing.T) {
+	slot := mondaySlot("09:00", "10:00", 30)
+	slot.ValidUntil = timePtr(meetingDay(8, 23, 59))
+
+	tests := []struct {
+		name  string
+		start time.Time
+		want  bool
+	}{
+		{"slot start", meetingDay(1, 9, 30), true},
+		{"same instant in UTC", time.Date(2026, 6, 1, 2, 30, 0, 0, time.UTC), true},
+		{"between slot starts", meetingDay(1, 9, 15), false},
+		{"end of the window", meetingDay(1, 10, 0), false},
+		{"other weekday", meetingDay(2, 9, 0), false},
+		{"after valid until", meetingDay(15, 9, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slot.Offers(tt.start, ict); got != tt.want {
+				t.Errorf("Offers(%v) = %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	bounded := func(slot AvailabilitySlot, from, until *time.Time) AvailabilitySlot {
+		slot.ValidFrom, slot.ValidUntil = from, until
+		return slot
+	}
+	morning := mondaySlot("09:00", "10:00", 30)
+	tuesday := morning
+	tuesday.Weekday = int(time.Tuesday)
+
+	tests := []struct {
+		name string
+		a, b AvailabilitySlot
+		want bool
+	}{
+		{"same hours", morning, mondaySlot("09:00", "10:00", 15), true},
+		{"partly overlapping hours", morning, mondaySlot("09:45", "11:00", 15), true},
+		{"contained", morning, mondaySlot("09:15", "09:45", 15), true},
+		{"back to back", morning, mondaySlot("10:00", "11:00", 30), false},
+		{"other weekday", morning, tuesday, false},
+		{
+			"validity windows apart",
+			bounded(morning, nil, timePtr(meetingDay(7, 23, 59))),
+			bounded(morning, timePtr(meetingDay(8, 0, 0)), nil),
+			false,
+		},
+		{
+			"validity windows apart in reverse",
+			bounded(morning, timePtr(meetingDay(8, 0, 0)), nil),
+			bounded(morning, nil, timePtr(meetingDay(7, 23, 59))),
+			false,
+		},
+		{
+			"validity windows share a day",
+			bounded(morning, nil, timePtr(meetingDay(8, 23, 59))),
+			bounded(morning, timePtr(meetingDay(8, 0, 0)), nil),
+			true,
+		},
+		{"open-ended validity", morning, bounded(morning, timePtr(meetingDay(8, 0, 0)), nil), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Overlaps(tt.b); got != tt.want {
+				t.Errorf("Overlaps() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Overlaps(tt.a); got != tt.want {
+				t.Errorf("reverse Overlaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"app/modules/meeting/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitMeetingRoutes(app *fiber.App) {
+	meeting := app.Group("/meeting")
+
+	meeting.Get("/slot", controller.GetMySlots)
+	meeting.Post("/slot", controller.CreateSlot)
+	meeting.Put("/slot/:id", controller.UpdateSlot)
+	meeting.Delete("/slot/:id", controller.DeleteSlot)
+
+	meeting.Get("/advisor/:advisorId/open", controller.GetOpenSlots)
+
+	meeting.Get("/booking", controller.GetMyBookings)
+	meeting.Post("/booking", controller.CreateBooking)
+	meeting.Put("/booking/:id/cancel", controller.CancelBooking)
+
+	meeting.Get("/calendar/link", controller.GetCalendarLink)
+	meeting.Post("/calendar/link/reset", controller.ResetCalendarLink)
+	meeting.Get("/calendar/:token", controller.GetCalendarFeed)
+}
@@ -1,20 +1,50 @@
 package modules
 
 import (
+	researchArea "app/modules/researchArea/migrate"
 	student "app/modules/student/migrate"
 	advisor "app/modules/advisor/migrate"
 	headOfSubject "app/modules/headOfSubject/migrate"
 	council "app/modules/council/migrate"
 	facultyOffice "app/modules/facultyOffice/migrate"
 	thesis "app/modules/thesis/migrate"
+	attachment "app/modules/attachment/migrate"
+	semester "app/modules/semester/migrate"
+	taskTemplate "app/modules/taskTemplate/migrate"
+	progressLog "app/modules/progressLog/migrate"
+	notification "app/modules/notification/migrate"
+	meeting "app/modules/meeting/migrate"
+	program "app/modules/program/migrate"
+	changeRequest "app/modules/changeRequest/migrate"
+	organization "app/modules/organization/migrate"
+	committee "app/modules/committee/migrate"
+	defense "app/modules/defense/migrate"
+	grading "app/modules/grading/migrate"
+	reviewer "app/modules/reviewer/migrate"
+	document "app/modules/document/migrate"
 )
 
 func MigrateModule() bool {
+	researchArea.MigrateTable();
 	student.MigrateTable();
 	advisor.MigrateTable();
 	headOfSubject.MigrateTable();
 	council.MigrateTable();
 	facultyOffice.MigrateTable();
 	thesis.MigrateTable();
+	attachment.MigrateTable();
+	semester.MigrateTable();
+	taskTemplate.MigrateTable();
+	progressLog.MigrateTable();
+	notification.MigrateTable();
+	meeting.MigrateTable();
+	program.MigrateTable();
+	changeRequest.MigrateTable();
+	organization.MigrateTable();
+	committee.MigrateTable();
+	defense.MigrateTable();
+	grading.MigrateTable();
+	reviewer.MigrateTable();
+	document.MigrateTable();
 	return true
 }
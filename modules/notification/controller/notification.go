@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/notification/model"
+	"app/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Notification API
+// @version 1.0
+// @description In-app notifications
+// @termsOfService http://swagger.io/terms/
+// @BasePath /notification
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetNotifications trả về thông báo của người dùng hiện tại
+// @Summary Get my notifications
+// @Description Get notifications of the current user, newest first
+// @Tags Notification
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /notification [get]
+func GetNotifications(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	query := database.DB.Where("USER_ID = ? AND ROLE = ?", tokenData.ID, tokenData.Role)
+	if c.QueryBool("unread") {
+		query = query.Where("READ_AT IS NULL")
+	}
+
+	var notifications []model.Notification
+	if err := query.Order("ID DESC").Limit(200).Find(&notifications).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = notifications
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// MarkNotificationRead đánh dấu một thông báo đã đọc
+// @Summary Mark a notification as read
+// @Description Mark one of my notifications as read
+// @Tags Notification
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /notification/{id}/read [put]
+func MarkNotificationRead(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var notification model.Notification
+	if err := database.DB.Where("USER_ID = ? AND ROLE = ?", tokenData.ID, tokenData.Role).First(&notification, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	if notification.ReadAt == nil {
+		now := core.Now()
+		if err := database.DB.Model(&notification).Update("READ_AT", now).Error; err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		notification.ReadAt = &now
+	}
+
+	response.Data = notification
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// MarkAllNotificationsRead đánh dấu tất cả thông báo đã đọc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the current user as read
+// @Tags Notification
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /notification/read-all [put]
+func MarkAllNotificationsRead(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	if err := database.DB.Model(&model.Notification{}).
+		Where("USER_ID = ? AND ROLE = ? AND READ_AT IS NULL", tokenData.ID, tokenData.Role).
+		Update("READ_AT", core.Now()).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// Notify tạo cùng một thông báo cho từng người nhận (bỏ người nhận trùng).
+// Truyền tx để thông báo được lưu cùng giao dịch với thay đổi gây ra nó.
+func Notify(db *gorm.DB, message model.Message, recipients ...model.Recipient) error {
+	seen := map[model.Recipient]bool{}
+	notifications := []model.Notification{}
+	for _, recipient := range recipients {
+		if recipient.UserID == 0 || seen[recipient] {
+			continue
+		}
+		seen[recipient] = true
+		notifications = append(notifications, model.Notification{
+			UserID: recipient.UserID,
+			Role:   recipient.Role,
+			Type:   message.Type,
+			Title:  message.Title,
+			Body:   message.Body,
+			Link:   message.Link,
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return db.Create(&notifications).Error
+}
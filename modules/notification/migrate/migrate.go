@@ -0,0 +1,14 @@
+package notificationMigrate
+
+import (
+	"app/database"
+	model "app/modules/notification/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Notification{})
+
+	return true
+}
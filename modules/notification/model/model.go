@@ -0,0 +1,53 @@
+package model
+
+import (
+	"app/model"
+	"time"
+)
+
+var NotificationMeetingBooked, NotificationMeetingCancelled = "MEETING_BOOKED", "MEETING_CANCELLED"
+
+var NotificationDeliverableSubmitted, NotificationDeliverableReviewed = "DELIVERABLE_SUBMITTED", "DELIVERABLE_REVIEWED"
+
+var NotificationChangeRequested, NotificationChangeDecided = "CHANGE_REQUESTED", "CHANGE_DECIDED"
+
+var NotificationThesisReviewed = "THESIS_REVIEWED"
+
+var NotificationDefenseScheduled, NotificationDefenseChanged = "DEFENSE_SCHEDULED", "DEFENSE_CHANGED"
+
+var NotificationReevaluationRequested = "REEVALUATION_REQUESTED"
+
+var NotificationReviewerAssigned, NotificationReviewSubmitted = "REVIEWER_ASSIGNED", "REVIEW_SUBMITTED"
+
+var NotificationGradesPublished = "GRADES_PUBLISHED"
+
+var NotificationAppealSubmitted, NotificationAppealAssigned, NotificationAppealDecided = "APPEAL_SUBMITTED", "APPEAL_ASSIGNED", "APPEAL_DECIDED"
+
+// Notification là thông báo gửi tới một người dùng; người dùng được xác định bởi cặp (UserID, Role)
+// vì mỗi vai trò có bảng riêng
+type Notification struct {
+	model.Header
+	UserID uint       `json:"userID" gorm:"column:USER_ID;index"`
+	Role   int        `json:"role" gorm:"column:ROLE"`
+	Type   string     `json:"type" gorm:"column:TYPE;size:50"`
+	Title  string     `json:"title" gorm:"column:TITLE"`
+	Body   string     `json:"body" gorm:"column:BODY"`
+	Link   string     `json:"link" gorm:"column:LINK"`
+	ReadAt *time.Time `json:"readAt" gorm:"column:READ_AT"`
+}
+
+type Recipient struct {
+	UserID uint
+	Role   int
+}
+
+type Message struct {
+	Type  string
+	Title string
+	Body  string
+	Link  string
+}
+
+func (Notification) TableName() string {
+	return "TBL_NOTIFICATION"
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"app/modules/notification/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitNotificationRoutes(app *fiber.App) {
+	notification := app.Group("/notification")
+
+	notification.Get("/", controller.GetNotifications)
+
+	notification.Put("/read-all", controller.MarkAllNotificationsRead)
+	notification.Put("/:id/read", controller.MarkNotificationRead)
+}
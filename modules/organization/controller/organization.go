@@ -0,0 +1,465 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/organization/model"
+	"app/utils"
+	"strings"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelFacultyOffice "app/modules/facultyOffice/model"
+	modelHeadOfSubject "app/modules/headOfSubject/model"
+	modelProgram "app/modules/program/model"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Organization API
+// @version 1.0
+// @description Faculty, department and subject hierarchy
+// @termsOfService http://swagger.io/terms/
+// @BasePath /organization
+// @schemes http
+// @produce json
+// @consumes json
+
+// members là các bảng có thể gắn vào một đơn vị, theo "cấp/loại thành viên"
+var members = map[string]struct {
+	table  interface{}
+	column string
+}{
+	"subject/advisors":    {&modelAdvisor.Advisor{}, "SUBJECT_ID"},
+	"subject/heads":       {&modelHeadOfSubject.HeadOfSubject{}, "SUBJECT_ID"},
+	"subject/theses":      {&modelThesis.Thesis{}, "SUBJECT_ID"},
+	"department/programs": {&modelProgram.Program{}, "DEPARTMENT_ID"},
+	"faculty/offices":     {&modelFacultyOffice.FacultyOffice{}, "FACULTY_ID"},
+}
+
+// GetOrganization trả về cây khoa > bộ môn > subject
+// @Summary Get the organization tree
+// @Description Faculties with their departments and subjects
+// @Tags Organization
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /organization [get]
+func GetOrganization(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	var faculties []model.Faculty
+	if err := database.DB.Preload("Departments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("CODE")
+	}).Preload("Departments.Subjects", func(db *gorm.DB) *gorm.DB {
+		return db.Order("CODE")
+	}).Order("CODE").Find(&faculties).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = faculties
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateUnits thêm khoa, bộ môn hoặc subject
+// @Summary Create organization units
+// @Description Create faculties, departments (parentID = faculty) or subjects (parentID = department). Faculty office only.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param level path string true "faculty, department or subject"
+// @Param body body []model.CreateUnit true "Units"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /organization/{level} [post]
+func CreateUnits(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	level := c.Params("level")
+	if !validLevel(level) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var payload []*model.CreateUnit
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		vItem := map[string]string{"code": strings.TrimSpace(item.Code), "name": strings.TrimSpace(item.Name)}
+		errors := utils.RequireCheck([]string{"code", "name"}, vItem, map[string]string{})
+		errors = utils.MaxLengthCheck([]string{"code:20"}, vItem, errors)
+		if level != model.LevelFaculty && !parentExists(tx, level, item.ParentID) {
+			errors["parentID"] = config.GetMessageCode("NOT_ID_EXISTS")
+		}
+		if len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("MISSING_FIELDS")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+
+		code := strings.ToUpper(vItem["code"])
+		var err error
+		switch level {
+		case model.LevelFaculty:
+			unit := model.Faculty{Code: code, Name: vItem["name"]}
+			unit.ID = item.ID
+			unit.CreatedBy = tokenData.Code
+			err = tx.Create(&unit).Error
+		case model.LevelDepartment:
+			unit := model.Department{FacultyID: item.ParentID, Code: code, Name: vItem["name"]}
+			unit.ID = item.ID
+			unit.CreatedBy = tokenData.Code
+			err = tx.Create(&unit).Error
+		case model.LevelSubject:
+			unit := model.Subject{DepartmentID: item.ParentID, Code: code, Name: vItem["name"]}
+			unit.ID = item.ID
+			unit.CreatedBy = tokenData.Code
+			err = tx.Create(&unit).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = err.Error()
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateUnits sửa mã, tên hoặc đơn vị cha
+// @Summary Update organization units
+// @Description Update code, name or parent of faculties, departments or subjects. Empty fields are left unchanged. Faculty office only.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param level path string true "faculty, department or subject"
+// @Param body body []model.UpdateUnit true "Units"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /organization/{level} [put]
+func UpdateUnits(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	level := c.Params("level")
+	if !validLevel(level) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var payload []*model.UpdateUnit
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		table, parentColumn := unitTable(level)
+		var count int64
+		tx.Model(table).Where("ID = ?", item.ID).Count(&count)
+		if count == 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+
+		updates := map[string]interface{}{"UPDATED_BY": tokenData.Code}
+		if code := strings.TrimSpace(item.Code); code != "" {
+			updates["CODE"] = strings.ToUpper(code)
+		}
+		if name := strings.TrimSpace(item.Name); name != "" {
+			updates["NAME"] = name
+		}
+		if item.ParentID != 0 && parentColumn != "" {
+			if !parentExists(tx, level, item.ParentID) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+				response.ValidateError = map[string]string{"parentID": config.GetMessageCode("NOT_ID_EXISTS")}
+				return c.JSON(response)
+			}
+			updates[parentColumn] = item.ParentID
+		}
+
+		if err := tx.Model(table).Where("ID = ?", item.ID).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteUnit xóa đơn vị không còn đơn vị con hoặc thành viên
+// @Summary Delete an organization unit
+// @Description Soft delete a faculty, department or subject that has no child units and nothing attached to it. Faculty office only.
+// @Tags Organization
+// @Produce json
+// @Param level path string true "faculty, department or subject"
+// @Param id path int true "Unit ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /organization/{level}/{id} [delete]
+func DeleteUnit(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	level := c.Params("level")
+	if !validLevel(level) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	id, err := c.ParamsInt("id")
+	table, _ := unitTable(level)
+	var count int64
+	db.Model(table).Where("ID = ?", id).Count(&count)
+	if err != nil || count == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	inUse := int64(0)
+	switch level {
+	case model.LevelFaculty:
+		db.Model(&model.Department{}).Where("FACULTY_ID = ?", id).Count(&inUse)
+	case model.LevelDepartment:
+		db.Model(&model.Subject{}).Where("DEPARTMENT_ID = ?", id).Count(&inUse)
+	}
+	for key, member := range members {
+		if strings.HasPrefix(key, level+"/") {
+			var attached int64
+			db.Model(member.table).Where(member.column+" = ?", id).Count(&attached)
+			inUse += attached
+		}
+	}
+	if inUse > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("UNIT_IN_USE")
+		return c.JSON(response)
+	}
+
+	if err := db.Model(table).Where("ID = ?", id).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AssignMembers gắn giảng viên, trưởng bộ môn, luận văn vào subject; chương trình vào bộ môn; văn phòng khoa vào khoa
+// @Summary Attach records to an organization unit
+// @Description Attach advisors, heads or theses to a subject, programs to a department, or faculty office accounts to a faculty. Records keep their other data; attaching moves them from their previous unit. Faculty office only.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param level path string true "faculty, department or subject"
+// @Param id path int true "Unit ID"
+// @Param members path string true "advisors, heads, theses (subject), programs (department) or offices (faculty)"
+// @Param body body model.AssignMembers true "IDs of the records"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /organization/{level}/{id}/{members} [put]
+func AssignMembers(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	level := c.Params("level")
+	member, ok := members[level+"/"+c.Params("members")]
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	id, err := c.ParamsInt("id")
+	table, _ := unitTable(level)
+	var count int64
+	db.Model(table).Where("ID = ?", id).Count(&count)
+	if err != nil || count == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var payload model.AssignMembers
+	if err := c.BodyParser(&payload); err != nil || len(payload.IDs) == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	result := tx.Model(member.table).Where("ID IN ?", payload.IDs).UpdateColumn(member.column, id)
+	if result.Error != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if result.RowsAffected != int64(len(payload.IDs)) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ScopeOf trả về phạm vi dữ liệu của người dùng: trưởng bộ môn chỉ thấy subject của mình,
+// văn phòng khoa thấy các subject trong khoa (hoặc toàn trường nếu chưa gán khoa).
+// Các vai trò khác đã được giới hạn theo luận văn mình tham gia nên không bị lọc thêm.
+func ScopeOf(db *gorm.DB, tokenData *utils.TokenData) model.Scope {
+	switch tokenData.Role {
+	case modelUsers.HeadOfSubjectRole:
+		var head modelHeadOfSubject.HeadOfSubject
+		if err := db.Select("ID, SUBJECT_ID").First(&head, tokenData.ID).Error; err != nil || head.SubjectID == nil {
+			return model.Scope{}
+		}
+		return model.Scope{SubjectIDs: []uint{*head.SubjectID}}
+
+	case modelUsers.FacultyOfficeRole:
+		var office modelFacultyOffice.FacultyOffice
+		if err := db.Select("ID, FACULTY_ID").First(&office, tokenData.ID).Error; err != nil {
+			return model.Scope{}
+		}
+		if office.FacultyID == nil {
+			return model.Scope{All: true}
+		}
+		scope := model.Scope{SubjectIDs: []uint{}}
+		db.Model(&model.Subject{}).
+			Where("DEPARTMENT_ID IN (?)", db.Model(&model.Department{}).Select("ID").Where("FACULTY_ID = ?", *office.FacultyID)).
+			Pluck("ID", &scope.SubjectIDs)
+		return scope
+	}
+	return model.Scope{All: true}
+}
+
+// RequestScope là ScopeOf của người gọi; yêu cầu không có token không có phạm vi nào,
+// danh sách công khai tự giới hạn ở phần dữ liệu công khai
+func RequestScope(c *fiber.Ctx, db *gorm.DB) model.Scope {
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		return model.Scope{}
+	}
+	return ScopeOf(db, tokenData)
+}
+
+// DefaultSubject trả về subject của giảng viên hoặc trưởng bộ môn, dùng khi tạo luận văn không chỉ rõ subject
+func DefaultSubject(db *gorm.DB, tokenData *utils.TokenData) *uint {
+	switch tokenData.Role {
+	case modelUsers.AdvisorRole:
+		var advisor modelAdvisor.Advisor
+		if db.Select("ID, SUBJECT_ID").First(&advisor, tokenData.ID).Error == nil {
+			return advisor.SubjectID
+		}
+	case modelUsers.HeadOfSubjectRole:
+		var head modelHeadOfSubject.HeadOfSubject
+		if db.Select("ID, SUBJECT_ID").First(&head, tokenData.ID).Error == nil {
+			return head.SubjectID
+		}
+	}
+	return nil
+}
+
+// SubjectExists kiểm tra subject có trong cơ cấu tổ chức
+func SubjectExists(db *gorm.DB, id uint) bool {
+	var count int64
+	db.Model(&model.Subject{}).Where("ID = ?", id).Count(&count)
+	return count > 0
+}
+
+func validLevel(level string) bool {
+	return level == model.LevelFaculty || level == model.LevelDepartment || level == model.LevelSubject
+}
+
+// unitTable trả về model của cấp và cột trỏ tới đơn vị cha
+func unitTable(level string) (interface{}, string) {
+	switch level {
+	case model.LevelDepartment:
+		return &model.Department{}, "FACULTY_ID"
+	case model.LevelSubject:
+		return &model.Subject{}, "DEPARTMENT_ID"
+	}
+	return &model.Faculty{}, ""
+}
+
+// parentExists kiểm tra đơn vị cha của một bộ môn (khoa) hoặc subject (bộ môn)
+func parentExists(db *gorm.DB, level string, parentID uint) bool {
+	var count int64
+	switch level {
+	case model.LevelDepartment:
+		db.Model(&model.Faculty{}).Where("ID = ?", parentID).Count(&count)
+	case model.LevelSubject:
+		db.Model(&model.Department{}).Where("ID = ?", parentID).Count(&count)
+	}
+	return count > 0
+}
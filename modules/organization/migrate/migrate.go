@@ -0,0 +1,26 @@
+package organizationMigrate
+
+import (
+	"app/database"
+	model "app/modules/organization/model"
+
+	"gorm.io/gorm"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Faculty{})
+	db.AutoMigrate(&model.Department{})
+	db.AutoMigrate(&model.Subject{})
+
+	backfillThesisSubjects(db)
+	return true
+}
+
+// backfillThesisSubjects gắn luận văn chưa có subject vào subject của giảng viên hướng dẫn
+func backfillThesisSubjects(db *gorm.DB) error {
+	return db.Exec(`UPDATE TBL_THESIS SET SUBJECT_ID = (
+		SELECT MIN(a.SUBJECT_ID) FROM tbl_advisor a WHERE a.THESIS_ID = TBL_THESIS.ID AND a.SUBJECT_ID IS NOT NULL
+	) WHERE SUBJECT_ID IS NULL`).Error
+}
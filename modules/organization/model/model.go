@@ -0,0 +1,97 @@
+package model
+
+import (
+	"app/model"
+
+	"gorm.io/gorm"
+)
+
+// Cấp tổ chức: khoa > bộ môn (department) > chuyên ngành/môn (subject)
+var LevelFaculty, LevelDepartment, LevelSubject = "faculty", "department", "subject"
+
+type Faculty struct {
+	model.Header
+	Code        string       `json:"code" gorm:"column:CODE;size:20;uniqueIndex"`
+	Name        string       `json:"name" gorm:"column:NAME"`
+	Departments []Department `json:"departments" gorm:"foreignKey:FACULTY_ID"`
+}
+
+type Department struct {
+	model.Header
+	FacultyID uint      `json:"facultyID" gorm:"column:FACULTY_ID;index"`
+	Code      string    `json:"code" gorm:"column:CODE;size:20;uniqueIndex"`
+	Name      string    `json:"name" gorm:"column:NAME"`
+	Subjects  []Subject `json:"subjects" gorm:"foreignKey:DEPARTMENT_ID"`
+}
+
+// Subject là đơn vị nhỏ nhất; giảng viên, trưởng bộ môn và luận văn gắn với một Subject
+type Subject struct {
+	model.Header
+	DepartmentID uint   `json:"departmentID" gorm:"column:DEPARTMENT_ID;index"`
+	Code         string `json:"code" gorm:"column:CODE;size:20;uniqueIndex"`
+	Name         string `json:"name" gorm:"column:NAME"`
+}
+
+// CreateUnit dùng chung cho cả ba cấp; ParentID là khoa của bộ môn hoặc bộ môn của subject
+type CreateUnit struct {
+	ID       uint   `json:"id"`
+	ParentID uint   `json:"parentID"`
+	Code     string `json:"code" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+}
+
+type UpdateUnit struct {
+	ID       uint   `json:"id"`
+	ParentID uint   `json:"parentID"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+}
+
+type AssignMembers struct {
+	IDs []uint `json:"ids"`
+}
+
+// Scope là phạm vi dữ liệu của người dùng: toàn bộ (All) hoặc các subject được phép
+type Scope struct {
+	All        bool
+	SubjectIDs []uint
+}
+
+// Apply lọc query theo cột subject (vd: SUBJECT_ID của luận văn)
+func (s Scope) Apply(query *gorm.DB, column string) *gorm.DB {
+	if s.All {
+		return query
+	}
+	if len(s.SubjectIDs) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(column+" IN ?", s.SubjectIDs)
+}
+
+// Allows kiểm tra một bản ghi gắn với subjectID có nằm trong phạm vi không
+func (s Scope) Allows(subjectID *uint) bool {
+	if s.All {
+		return true
+	}
+	if subjectID == nil {
+		return false
+	}
+	for _, id := range s.SubjectIDs {
+		if id == *subjectID {
+			return true
+		}
+	}
+	return false
+}
+
+func (Faculty) TableName() string {
+	return "TBL_FACULTY"
+}
+
+func (Department) TableName() string {
+	return "TBL_DEPARTMENT"
+}
+
+func (Subject) TableName() string {
+	return "TBL_SUBJECT"
+}
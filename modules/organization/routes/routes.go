@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"app/modules/organization/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitOrganizationRoutes(app *fiber.App) {
+	organization := app.Group("/organization")
+
+	organization.Get("/", controller.GetOrganization)
+
+	organization.Post("/:level", controller.CreateUnits)
+	organization.Put("/:level", controller.UpdateUnits)
+	organization.Put("/:level/:id/:members", controller.AssignMembers)
+	organization.Delete("/:level/:id", controller.DeleteUnit)
+}
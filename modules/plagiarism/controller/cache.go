@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"app/config"
+	"app/similarity"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultCacheMinutes giới hạn thời gian giữ kho đã băm khi không cấu hình SIMILARITY_CACHE_MINUTES
+const defaultCacheMinutes = 30
+
+type cachedIndex struct {
+	stamp   string
+	builtAt time.Time
+	index   *similarity.Index
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]cachedIndex{}
+)
+
+// loadIndex trả về kho đã băm trong bộ nhớ nếu dữ liệu nguồn chưa đổi (cùng stamp) và chưa quá hạn,
+// nếu không thì dựng lại bằng build
+func loadIndex(key, stamp string, build func() (*similarity.Index, error)) (*similarity.Index, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if cached, ok := cache[key]; ok && cached.stamp == stamp && time.Since(cached.builtAt) < cacheTTL() {
+		return cached.index, nil
+	}
+	index, err := build()
+	if err != nil {
+		return nil, err
+	}
+	cache[key] = cachedIndex{stamp: stamp, builtAt: time.Now(), index: index}
+	return index, nil
+}
+
+func cacheTTL() time.Duration {
+	minutes, err := strconv.Atoi(config.Config("SIMILARITY_CACHE_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = defaultCacheMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// tableStamp tóm tắt trạng thái của các dòng trong query (số dòng và lần cập nhật mới nhất);
+// thêm, sửa hoặc xóa một dòng đều làm stamp thay đổi
+func tableStamp(query *gorm.DB) (string, error) {
+	var count int64
+	var latest sql.NullTime
+	if err := query.Select("COUNT(*), MAX(UPDATED_AT)").Row().Scan(&count, &latest); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d@%d", count, latest.Time.UnixNano()), nil
+}
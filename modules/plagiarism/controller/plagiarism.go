@@ -0,0 +1,377 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/plagiarism/model"
+	"app/similarity"
+	"app/utils"
+	"fmt"
+	"strconv"
+	"strings"
+
+	attachmentController "app/modules/attachment/controller"
+	attachmentModel "app/modules/attachment/model"
+	semesterController "app/modules/semester/controller"
+	semesterModel "app/modules/semester/model"
+	thesisModel "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Mô tả đề tài ngắn nên dùng shingle 3 từ, báo cáo dài dùng 5 từ
+const (
+	TopicShingleSize  = 3
+	ReportShingleSize = 5
+	defaultLimit      = 20
+	defaultMinScore   = 0.2
+)
+
+// @title Plagiarism API
+// @version 1.0
+// @description Offline similarity check across theses and submitted reports
+// @termsOfService http://swagger.io/terms/
+// @BasePath /plagiarism
+// @schemes http
+// @produce json
+// @consumes json
+
+// CheckText so sánh một đoạn văn bản với đề tài hoặc báo cáo của các học kỳ trước
+// @Summary Check a text for similarity
+// @Description Compare a text with the thesis topics (kind TOPIC) or submitted reports (kind REPORT) of semesters that ended before the current one started and return ranked matches with overlapping passages; includeCurrent also compares with the current semester
+// @Tags Plagiarism
+// @Accept json
+// @Produce json
+// @Param body body model.CheckSimilarity true "Text to check"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /plagiarism/check [post]
+func CheckText(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	if !isReviewer(c) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CheckSimilarity
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	if errors := utils.RequireCheck([]string{"text"}, map[string]string{"text": strings.TrimSpace(payload.Text)}, map[string]string{}); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	kind := payload.Kind
+	if kind == "" {
+		kind = similarity.KindTopic
+	}
+
+	semesters, err := comparedSemesters(database.DB, payload.IncludeCurrent)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	var index *similarity.Index
+	if kind == similarity.KindReport {
+		index, err = ReportIndex(database.DB, semesters)
+	} else {
+		index, err = TopicIndex(database.DB, semesters)
+	}
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = model.SimilarityReport{
+		Kind:         kind,
+		ComparedWith: index.Len(),
+		Matches:      index.Query(payload.Text, queryOptions(payload.Limit, payload.MinScore, nil)),
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CheckThesisTopic so sánh đề tài của một Thesis với đề tài các học kỳ trước
+// @Summary Check a thesis topic for recycled topics
+// @Description Compare titles, description and missions of a thesis with the theses of semesters that ended before the current one started; includeCurrent also compares with the current semester
+// @Tags Plagiarism
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Param includeCurrent query bool false "Also compare with the current semester"
+// @Param limit query int false "Maximum number of matches"
+// @Param minScore query number false "Minimum score (0-1)"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /plagiarism/thesis/{uuid} [get]
+func CheckThesisTopic(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	if !isReviewer(c) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var thesis thesisModel.Thesis
+	if err := db.Preload("Missions").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	semesters, err := comparedSemesters(db, c.QueryBool("includeCurrent"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	index, err := TopicIndex(db, semesters)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	exclude := func(doc similarity.Document) bool { return doc.ThesisID == thesis.ID }
+	response.Data = model.SimilarityReport{
+		Kind:         similarity.KindTopic,
+		ComparedWith: index.Len(),
+		Matches:      index.Query(TopicText(&thesis), queryOptions(c.QueryInt("limit"), queryFloat(c, "minScore"), exclude)),
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CheckAttachment so sánh một báo cáo đã nộp với báo cáo của các luận văn khác
+// @Summary Check a submitted report for copied content
+// @Description Compare the text of a PROPOSAL or REPORT attachment with reports and topics of the theses of semesters that ended before the current one started; includeCurrent also compares with the current semester
+// @Tags Plagiarism
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Param includeCurrent query bool false "Also compare with the current semester"
+// @Param limit query int false "Maximum number of matches"
+// @Param minScore query number false "Minimum score (0-1)"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /plagiarism/attachment/{id} [get]
+func CheckAttachment(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	if !isReviewer(c) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var attachment attachmentModel.Attachment
+	if err := db.First(&attachment, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	text, err := attachmentController.LoadText(&attachment)
+	if err != nil || strings.TrimSpace(text) == "" {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	semesters, err := comparedSemesters(db, c.QueryBool("includeCurrent"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	reports, err := ReportIndex(db, semesters)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	topics, err := TopicIndex(db, semesters)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	exclude := func(doc similarity.Document) bool { return doc.ThesisID == attachment.ThesisID }
+	opts := queryOptions(c.QueryInt("limit"), queryFloat(c, "minScore"), exclude)
+
+	response.Data = []model.SimilarityReport{
+		{Kind: similarity.KindReport, ComparedWith: reports.Len(), Matches: reports.Query(text, opts)},
+		{Kind: similarity.KindTopic, ComparedWith: topics.Len(), Matches: topics.Query(text, opts)},
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// TopicText ghép tiêu đề, mô tả và nhiệm vụ của luận văn thành văn bản để so sánh
+func TopicText(thesis *thesisModel.Thesis) string {
+	parts := []string{thesis.TitleVi, thesis.TitleEn, thesis.ThesisInfo}
+	for _, mission := range thesis.Missions {
+		parts = append(parts, mission.Value)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// TopicIndex trả về kho đề tài của các luận văn thuộc các học kỳ semesters,
+// dùng lại kho trong bộ nhớ khi luận văn và nhiệm vụ chưa thay đổi
+func TopicIndex(db *gorm.DB, semesters []string) (*similarity.Index, error) {
+	theses, err := tableStamp(db.Model(&thesisModel.Thesis{}))
+	if err != nil {
+		return nil, err
+	}
+	missions, err := tableStamp(db.Model(&thesisModel.Mission{}))
+	if err != nil {
+		return nil, err
+	}
+	return loadIndex(similarity.KindTopic+"/"+strings.Join(semesters, ","), theses+"/"+missions, func() (*similarity.Index, error) {
+		return BuildTopicIndex(db, semesters)
+	})
+}
+
+// ReportIndex trả về kho báo cáo của các luận văn thuộc các học kỳ semesters, dựng lại khi có file mới.
+// Nội dung chữ của file được LoadText lưu vào TEXT_CONTENT ở lần đọc đầu nên lần dựng sau không đọc lại kho lưu trữ.
+func ReportIndex(db *gorm.DB, semesters []string) (*similarity.Index, error) {
+	stamp, err := tableStamp(db.Model(&attachmentModel.Attachment{}).Where("SUBMISSION_TYPE IN ?", attachmentModel.TextSubmissionTypes))
+	if err != nil {
+		return nil, err
+	}
+	return loadIndex(similarity.KindReport+"/"+strings.Join(semesters, ","), stamp, func() (*similarity.Index, error) {
+		return BuildReportIndex(db, semesters)
+	})
+}
+
+// BuildTopicIndex băm đề tài của các luận văn thuộc các học kỳ semesters
+func BuildTopicIndex(db *gorm.DB, semesters []string) (*similarity.Index, error) {
+	var theses []thesisModel.Thesis
+	if err := inSemesters(db.Preload("Missions"), semesters).Find(&theses).Error; err != nil {
+		return nil, err
+	}
+
+	index := similarity.NewIndex(TopicShingleSize)
+	for i := range theses {
+		index.Add(similarity.Document{
+			ID:       theses[i].ID,
+			ThesisID: theses[i].ID,
+			Kind:     similarity.KindTopic,
+			Label:    theses[i].TitleVi,
+			Semester: theses[i].Semester,
+			Text:     TopicText(&theses[i]),
+		})
+	}
+	return index, nil
+}
+
+// BuildReportIndex băm phiên bản mới nhất của báo cáo/đề cương của từng luận văn thuộc các học kỳ semesters
+func BuildReportIndex(db *gorm.DB, semesters []string) (*similarity.Index, error) {
+	var attachments []attachmentModel.Attachment
+	if err := db.Where("SUBMISSION_TYPE IN ?", attachmentModel.TextSubmissionTypes).
+		Order("THESIS_ID").Order("SUBMISSION_TYPE").Order("VERSION DESC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	var theses []thesisModel.Thesis
+	if err := inSemesters(db.Select("ID", "SEMESTER"), semesters).Find(&theses).Error; err != nil {
+		return nil, err
+	}
+	thesisSemester := map[uint]string{}
+	for _, thesis := range theses {
+		thesisSemester[thesis.ID] = thesis.Semester
+	}
+
+	index := similarity.NewIndex(ReportShingleSize)
+	seen := map[string]bool{}
+	for i := range attachments {
+		a := &attachments[i]
+		key := fmt.Sprintf("%d/%s/%s", a.ThesisID, a.SubmissionType, a.FileName)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		semester, ok := thesisSemester[a.ThesisID]
+		if !ok {
+			continue
+		}
+
+		text, err := attachmentController.LoadText(a)
+		if err != nil {
+			continue
+		}
+		index.Add(similarity.Document{
+			ID:       a.ID,
+			ThesisID: a.ThesisID,
+			Kind:     similarity.KindReport,
+			Label:    a.FileName,
+			Semester: semester,
+			Text:     text,
+		})
+	}
+	return index, nil
+}
+
+// comparedSemesters là mã các học kỳ đã kết thúc trước khi học kỳ hiện tại bắt đầu (ngoài học kỳ thì trước hôm nay);
+// includeCurrent lấy thêm học kỳ hiện tại. Học kỳ sau học kỳ hiện tại không bao giờ được so sánh.
+func comparedSemesters(db *gorm.DB, includeCurrent bool) ([]string, error) {
+	pivot := core.Now()
+	if current, err := semesterController.Current(db); err == nil {
+		pivot = current.StartDate
+	}
+	query := db.Model(&semesterModel.Semester{}).Where("END_DATE < ?", pivot)
+	if includeCurrent {
+		query = db.Model(&semesterModel.Semester{}).Where("START_DATE <= ?", pivot)
+	}
+	var codes []string
+	if err := query.Order("CODE").Pluck("UPPER(CODE)", &codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func inSemesters(query *gorm.DB, semesters []string) *gorm.DB {
+	if len(semesters) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where("UPPER(SEMESTER) IN ?", semesters)
+}
+
+func queryOptions(limit int, minScore float64, exclude func(similarity.Document) bool) similarity.QueryOptions {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if minScore <= 0 {
+		minScore = defaultMinScore
+	}
+	return similarity.QueryOptions{Limit: limit, MinScore: minScore, Exclude: exclude, WithPassages: true}
+}
+
+func queryFloat(c *fiber.Ctx, key string) float64 {
+	value, _ := strconv.ParseFloat(c.Query(key), 64)
+	return value
+}
+
+// Sinh viên không được tra cứu trùng lặp trên bài của người khác
+func isReviewer(c *fiber.Ctx) bool {
+	tokenData, err := utils.ExtractTokenData(c)
+	return err == nil && tokenData.Role != modelUsers.StudentRole
+}
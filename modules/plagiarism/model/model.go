@@ -0,0 +1,20 @@
+package model
+
+import (
+	"app/similarity"
+)
+
+type CheckSimilarity struct {
+	Text     string  `json:"text" validate:"required"`
+	Kind     string  `json:"kind"`
+	Limit    int     `json:"limit"`
+	MinScore float64 `json:"minScore"`
+	// IncludeCurrent so sánh cả với luận văn của học kỳ hiện tại; mặc định chỉ các học kỳ trước
+	IncludeCurrent bool `json:"includeCurrent"`
+}
+
+type SimilarityReport struct {
+	Kind         string             `json:"kind"`
+	ComparedWith int                `json:"comparedWith"`
+	Matches      []similarity.Match `json:"matches"`
+}
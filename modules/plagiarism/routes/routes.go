@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"app/modules/plagiarism/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitPlagiarismRoutes(app *fiber.App) {
+	plagiarism := app.Group("/plagiarism")
+
+	plagiarism.Post("/check", controller.CheckText)
+	plagiarism.Get("/thesis/:uuid", controller.CheckThesisTopic)
+	plagiarism.Get("/attachment/:id", controller.CheckAttachment)
+}
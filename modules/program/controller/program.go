@@ -0,0 +1,360 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/program/model"
+	"app/utils"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	modelOrganization "app/modules/organization/model"
+	modelStudent "app/modules/student/model"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Program API
+// @version 1.0
+// @description Catalog of study programs
+// @termsOfService http://swagger.io/terms/
+// @BasePath /program
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetPrograms trả về danh mục chương trình đào tạo
+// @Summary Get study programs
+// @Description Get the study program catalog. Inactive programs are hidden unless all=true.
+// @Tags Program
+// @Produce json
+// @Param all query bool false "Include inactive programs"
+// @Param degreeLevel query int false "Degree level (1 bachelor, 2 engineer, 3 master, 4 doctor)"
+// @Param departmentID query int false "Department ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /program [get]
+func GetPrograms(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	query := database.DB.Order("CODE")
+	if c.Query("all") != "true" {
+		query = query.Where("ACTIVE = ?", true)
+	}
+	if degreeLevel, err := strconv.Atoi(c.Query("degreeLevel")); err == nil {
+		query = query.Where("DEGREE_LEVEL = ?", degreeLevel)
+	}
+	if departmentID, err := strconv.Atoi(c.Query("departmentID")); err == nil {
+		query = query.Where("DEPARTMENT_ID = ?", departmentID)
+	}
+
+	var programs []model.Program
+	if err := query.Find(&programs).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	if err := withDepartments(database.DB, programs); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = programs
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetProgram trả về một chương trình đào tạo
+// @Summary Get a study program
+// @Description Get a study program by ID
+// @Tags Program
+// @Produce json
+// @Param id path int true "Program ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /program/{id} [get]
+func GetProgram(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	programs := make([]model.Program, 1)
+	if err := database.DB.First(&programs[0], c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if err := withDepartments(database.DB, programs); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = programs[0]
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateProgram thêm chương trình đào tạo vào danh mục
+// @Summary Create study programs
+// @Description Create study programs (faculty office only)
+// @Tags Program
+// @Accept json
+// @Produce json
+// @Param body body []model.CreateProgram true "Study programs"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /program [post]
+func CreateProgram(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.CreateProgram
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		vItem := map[string]string{
+			"code":   strings.TrimSpace(item.Code),
+			"nameVi": strings.TrimSpace(item.NameVi),
+			"nameEn": strings.TrimSpace(item.NameEn),
+		}
+		errors := utils.RequireCheck([]string{"code", "nameVi", "nameEn"}, vItem, map[string]string{})
+		errors = utils.MaxLengthCheck([]string{"code:30"}, vItem, errors)
+		if !model.ValidDegreeLevel(item.DegreeLevel) {
+			errors["degreeLevel"] = config.GetMessageCode("PARAM_ERROR")
+		}
+		if len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("MISSING_FIELDS")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+
+		program := model.Program{
+			Code:        strings.ToUpper(vItem["code"]),
+			NameVi:      vItem["nameVi"],
+			NameEn:      vItem["nameEn"],
+			DegreeLevel: item.DegreeLevel,
+			Active:      true,
+		}
+		program.ID = item.ID
+		program.CreatedBy = tokenData.Code
+
+		if err := tx.Create(&program).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateProgram cập nhật chương trình đào tạo
+// @Summary Update study programs
+// @Description Update study programs (faculty office only). Deactivated programs stay on existing theses and students but can no longer be chosen.
+// @Tags Program
+// @Accept json
+// @Produce json
+// @Param body body []model.UpdateProgram true "Study programs"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /program [put]
+func UpdateProgram(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.UpdateProgram
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		var program model.Program
+		if err := tx.First(&program, item.ID).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+
+		if item.Code != "" {
+			program.Code = strings.ToUpper(strings.TrimSpace(item.Code))
+		}
+		if item.NameVi != "" {
+			program.NameVi = item.NameVi
+		}
+		if item.NameEn != "" {
+			program.NameEn = item.NameEn
+		}
+		if item.DegreeLevel != 0 {
+			if !model.ValidDegreeLevel(item.DegreeLevel) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = map[string]string{"degreeLevel": config.GetMessageCode("PARAM_ERROR")}
+				return c.JSON(response)
+			}
+			program.DegreeLevel = item.DegreeLevel
+		}
+		if item.Active != nil {
+			program.Active = *item.Active
+		}
+		program.UpdatedBy = tokenData.Code
+
+		if err := tx.Save(&program).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteProgram xóa chương trình đào tạo chưa được sử dụng
+// @Summary Delete a study program
+// @Description Soft delete a study program that no thesis or student refers to. Programs in use should be deactivated instead.
+// @Tags Program
+// @Produce json
+// @Param id path int true "Program ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /program/{id} [delete]
+func DeleteProgram(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var program model.Program
+	if err := db.First(&program, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var theses, students int64
+	db.Model(&modelThesis.Program{}).Where("VALUE = ?", program.ID).Count(&theses)
+	db.Model(&modelStudent.Student{}).Where("PROGRAM = ?", program.ID).Count(&students)
+	if theses > 0 || students > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PROGRAM_IN_USE")
+		return c.JSON(response)
+	}
+
+	if err := db.Model(&program).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// withDepartments điền tên bộ môn quản lý từ TBL_DEPARTMENT
+func withDepartments(db *gorm.DB, programs []model.Program) error {
+	ids := []uint{}
+	for _, program := range programs {
+		if program.DepartmentID != nil {
+			ids = append(ids, *program.DepartmentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var departments []modelOrganization.Department
+	if err := db.Where("ID IN ?", ids).Find(&departments).Error; err != nil {
+		return err
+	}
+	names := map[uint]string{}
+	for _, department := range departments {
+		names[department.ID] = department.Name
+	}
+	for i := range programs {
+		if programs[i].DepartmentID != nil {
+			programs[i].Department = names[*programs[i].DepartmentID]
+		}
+	}
+	return nil
+}
+
+// ValidatePrograms kiểm tra các ID chương trình có trong danh mục và còn hiệu lực
+func ValidatePrograms(db *gorm.DB, ids ...int) error {
+	set := map[int]bool{}
+	for _, id := range ids {
+		if id <= 0 {
+			return errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+		}
+		set[id] = true
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	unique := make([]int, 0, len(set))
+	for id := range set {
+		unique = append(unique, id)
+	}
+	var programs []model.Program
+	if err := db.Where("ID IN ?", unique).Find(&programs).Error; err != nil {
+		return err
+	}
+	if len(programs) != len(unique) {
+		return errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+	}
+	for _, program := range programs {
+		if !program.Active {
+			return errors.New(config.GetMessageCode("PROGRAM_INACTIVE"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package programMigrate
+
+import (
+	"app/database"
+	model "app/modules/program/model"
+	modelStudent "app/modules/student/model"
+	modelThesis "app/modules/thesis/model"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Program{})
+
+	seedLegacyPrograms(db)
+	return true
+}
+
+// seedLegacyPrograms thêm vào danh mục các giá trị chương trình số nguyên cũ chưa có trong danh mục,
+// giữ nguyên ID để luận văn và sinh viên đang tham chiếu không bị mất. Văn phòng khoa đặt lại tên sau.
+// Sau khi thêm, cột identity được đẩy qua MAX(ID) để chương trình tạo mới không trùng ID.
+func seedLegacyPrograms(db *gorm.DB) {
+	var values []int
+	db.Model(&modelThesis.Program{}).Distinct("VALUE").Where("VALUE > 0").Pluck("VALUE", &values)
+
+	var studentValues []int
+	db.Model(&modelStudent.Student{}).Distinct("PROGRAM").Where("PROGRAM > 0").Pluck("PROGRAM", &studentValues)
+	values = append(values, studentValues...)
+
+	seen := map[int]bool{}
+	seeded := 0
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		var count int64
+		db.Unscoped().Model(&model.Program{}).Where("ID = ?", value).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		program := model.Program{
+			Code:        fmt.Sprintf("LEGACY-%d", value),
+			NameVi:      fmt.Sprintf("Chương trình %d", value),
+			NameEn:      fmt.Sprintf("Program %d", value),
+			DegreeLevel: model.DegreeBachelor,
+			Active:      true,
+		}
+		program.ID = uint(value)
+		program.CreatedBy = "migration"
+		if err := db.Create(&program).Error; err != nil {
+			log.Printf("legacy program %d not added to catalog: %v", value, err)
+			continue
+		}
+		seeded++
+		log.Printf("legacy program %d added to catalog as %s", value, program.Code)
+	}
+
+	if seeded == 0 {
+		return
+	}
+	if err := db.Exec("ALTER TABLE TBL_STUDY_PROGRAM MODIFY ID GENERATED BY DEFAULT AS IDENTITY (START WITH LIMIT VALUE)").Error; err != nil {
+		log.Printf("program identity not advanced past legacy IDs: %v", err)
+	}
+}
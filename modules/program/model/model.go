@@ -0,0 +1,46 @@
+package model
+
+import (
+	"app/model"
+)
+
+var DegreeBachelor, DegreeEngineer, DegreeMaster, DegreeDoctor = 1, 2, 3, 4
+
+// Program là chương trình đào tạo trong danh mục. Giá trị VALUE của chương trình gắn với luận văn
+// và PROGRAM của sinh viên là ID của bảng này. DepartmentID là bộ môn quản lý chương trình (TBL_DEPARTMENT),
+// Department là tên bộ môn đó, chỉ để hiển thị.
+type Program struct {
+	model.Header
+	Code         string `json:"code" gorm:"column:CODE;size:30;uniqueIndex"`
+	NameVi       string `json:"nameVi" gorm:"column:NAME_VI"`
+	NameEn       string `json:"nameEn" gorm:"column:NAME_EN"`
+	DepartmentID *uint  `json:"departmentID" gorm:"column:DEPARTMENT_ID;index"`
+	Department   string `json:"department" gorm:"-"`
+	DegreeLevel  int    `json:"degreeLevel" gorm:"column:DEGREE_LEVEL"`
+	Active       bool   `json:"active" gorm:"column:ACTIVE;default:true"`
+}
+
+type CreateProgram struct {
+	ID          uint   `json:"id"`
+	Code        string `json:"code" validate:"required"`
+	NameVi      string `json:"nameVi" validate:"required"`
+	NameEn      string `json:"nameEn" validate:"required"`
+	DegreeLevel int    `json:"degreeLevel" validate:"required"`
+}
+
+type UpdateProgram struct {
+	ID          uint   `json:"id"`
+	Code        string `json:"code"`
+	NameVi      string `json:"nameVi"`
+	NameEn      string `json:"nameEn"`
+	DegreeLevel int    `json:"degreeLevel"`
+	Active      *bool  `json:"active"`
+}
+
+func ValidDegreeLevel(level int) bool {
+	return level >= DegreeBachelor && level <= DegreeDoctor
+}
+
+func (Program) TableName() string {
+	return "TBL_STUDY_PROGRAM"
+}
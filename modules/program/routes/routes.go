@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"app/modules/program/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitProgramRoutes(app *fiber.App) {
+	program := app.Group("/program")
+
+	program.Get("/", controller.GetPrograms)
+	program.Get("/:id", controller.GetProgram)
+
+	program.Post("/", controller.CreateProgram)
+	program.Put("/", controller.UpdateProgram)
+	program.Delete("/:id", controller.DeleteProgram)
+}
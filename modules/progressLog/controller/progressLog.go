@@ -0,0 +1,552 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/progressLog/model"
+	"app/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	organizationController "app/modules/organization/controller"
+	modelStudent "app/modules/student/model"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Progress Log API
+// @version 1.0
+// @description Advisor meeting minutes and progress logs
+// @termsOfService http://swagger.io/terms/
+// @BasePath /progress-log
+// @schemes http
+// @produce json
+// @consumes json
+
+const defaultInactiveWeeks = 2
+
+// ListProgressLogs trả về nhật ký tiến độ của một Thesis
+// @Summary List progress logs of a thesis
+// @Description List meeting logs of a thesis, newest meeting first
+// @Tags ProgressLog
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /progress-log/thesis/{uuid} [get]
+func ListProgressLogs(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canAccessThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var logs []model.ProgressLog
+	if err := db.Preload("Attendees").Where("THESIS_ID = ?", thesis.ID).Order("MEETING_DATE DESC, ID DESC").Find(&logs).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = logs
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateProgressLog ghi biên bản một buổi gặp
+// @Summary Create a progress log
+// @Description Record a meeting: date, attendees (students and advisors of the thesis), what was done, next steps and blockers. Logs written by an advisor are acknowledged automatically.
+// @Tags ProgressLog
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Param body body model.CreateProgressLog true "Meeting log"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /progress-log/thesis/{uuid} [post]
+func CreateProgressLog(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.CreateProgressLog
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !isMember(tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	log := model.ProgressLog{ThesisID: thesis.ID, AuthorID: tokenData.ID, AuthorRole: tokenData.Role}
+	log.CreatedBy = tokenData.Code
+	if errors := applyPayload(&log, &payload); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	attendees, ok := resolveAttendees(&thesis, payload.Attendees)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"attendees": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+
+	if tokenData.Role == modelUsers.AdvisorRole {
+		now := core.Now()
+		log.AcknowledgedBy = tokenData.Code
+		log.AcknowledgedAt = &now
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Create(&log).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := saveAttendees(tx, log.ID, attendees); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	log.Attendees = attendees
+	response.Data = log
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateProgressLog sửa biên bản khi giảng viên chưa xác nhận
+// @Summary Update a progress log
+// @Description The author can edit a log until an advisor acknowledges it
+// @Tags ProgressLog
+// @Accept json
+// @Produce json
+// @Param id path int true "Progress log ID"
+// @Param body body model.CreateProgressLog true "Meeting log"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /progress-log/{id} [put]
+func UpdateProgressLog(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.CreateProgressLog
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	log, thesis, message := loadOwnLog(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if errors := applyPayload(&log, &payload); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	attendees, ok := resolveAttendees(&thesis, payload.Attendees)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"attendees": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Omit("Attendees").Save(&log).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Unscoped().Where("PROGRESS_LOG_ID = ?", log.ID).Delete(&model.ProgressLogAttendee{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := saveAttendees(tx, log.ID, attendees); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	log.Attendees = attendees
+	response.Data = log
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteProgressLog xóa biên bản khi giảng viên chưa xác nhận
+// @Summary Delete a progress log
+// @Description The author can delete a log until an advisor acknowledges it
+// @Tags ProgressLog
+// @Produce json
+// @Param id path int true "Progress log ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /progress-log/{id} [delete]
+func DeleteProgressLog(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	log, _, message := loadOwnLog(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if err := db.Model(&log).Updates(map[string]interface{}{
+		"deleted_by": log.CreatedBy,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AcknowledgeProgressLog giảng viên hướng dẫn xác nhận biên bản
+// @Summary Acknowledge a progress log
+// @Description An advisor of the thesis confirms the meeting log, optionally with a comment. Acknowledged logs can no longer be edited.
+// @Tags ProgressLog
+// @Accept json
+// @Produce json
+// @Param id path int true "Progress log ID"
+// @Param body body model.AcknowledgeProgressLog false "Advisor comment"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /progress-log/{id}/acknowledge [put]
+func AcknowledgeProgressLog(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.AcknowledgeProgressLog
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			response.Status = false
+			response.Message = "Failed to parse request body"
+			return c.JSON(response)
+		}
+	}
+
+	var log model.ProgressLog
+	if err := db.First(&log, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var thesis modelThesis.Thesis
+	if err := db.Preload("Advisors").First(&thesis, log.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if tokenData.Role != modelUsers.AdvisorRole || !thesis.HasAdvisor(tokenData.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	if err := db.Model(&log).Updates(map[string]interface{}{
+		"ACKNOWLEDGED_BY": tokenData.Code,
+		"ACKNOWLEDGED_AT": now,
+		"ADVISOR_COMMENT": payload.Comment,
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	log.AcknowledgedBy = tokenData.Code
+	log.AcknowledgedAt = &now
+	log.AdvisorComment = payload.Comment
+
+	response.Data = log
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetInactiveTheses liệt kê các luận văn không có buổi gặp nào trong N tuần gần đây
+// @Summary Report theses without recent meetings
+// @Description List approved theses with students that have no logged meeting in the last N weeks (default 2). Advisors only see their own theses.
+// @Tags ProgressLog
+// @Produce json
+// @Param weeks query int false "Number of weeks (default 2)"
+// @Param semester query string false "Semester"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /progress-log/report/inactive [get]
+func GetInactiveTheses(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role == modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	weeks := defaultInactiveWeeks
+	if v, err := strconv.Atoi(c.Query("weeks")); err == nil && v > 0 {
+		weeks = v
+	}
+	now := core.Now()
+	since := now.AddDate(0, 0, -7*weeks)
+
+	query := db.Preload("Advisors").
+		Where("APPROVAL_STATUS = ?", modelThesis.ApprovalApproved).
+		Where("ID IN (?)", db.Model(&modelStudent.Student{}).Select("THESIS_ID").Where("THESIS_ID > 0")).
+		Where("ID NOT IN (?)", db.Model(&model.ProgressLog{}).Select("THESIS_ID").Where("MEETING_DATE >= ?", since))
+	if semester := c.Query("semester"); semester != "" {
+		query = query.Where("SEMESTER = ?", semester)
+	}
+	query = organizationController.ScopeOf(db, tokenData).Apply(query, "SUBJECT_ID")
+
+	var theses []modelThesis.Thesis
+	if err := query.Order("ID").Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	type lastMeeting struct {
+		ThesisID uint      `gorm:"column:THESIS_ID"`
+		Last     time.Time `gorm:"column:LAST_MEETING"`
+	}
+	var lastMeetings []lastMeeting
+	if err := db.Model(&model.ProgressLog{}).Select("THESIS_ID, MAX(MEETING_DATE) AS LAST_MEETING").Group("THESIS_ID").Scan(&lastMeetings).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	last := map[uint]time.Time{}
+	for _, row := range lastMeetings {
+		last[row.ThesisID] = row.Last
+	}
+
+	report := model.InactiveReport{Weeks: weeks, Since: since, Theses: []model.InactiveThesis{}}
+	for _, thesis := range theses {
+		if tokenData.Role == modelUsers.AdvisorRole && !thesis.HasAdvisor(tokenData.ID) {
+			continue
+		}
+		item := model.InactiveThesis{
+			ThesisID: thesis.ID,
+			TitleVi:  thesis.TitleVi,
+			TitleEn:  thesis.TitleEn,
+			Semester: thesis.Semester,
+			Advisors: []string{},
+		}
+		for _, advisor := range thesis.Advisors {
+			item.Advisors = append(item.Advisors, advisor.FullName)
+		}
+		if t, ok := last[thesis.ID]; ok {
+			t = t.In(now.Location())
+			weeksSince := int(now.Sub(t).Hours() / (24 * 7))
+			item.LastMeetingDate = &t
+			item.WeeksSince = &weeksSince
+		}
+		report.Theses = append(report.Theses, item)
+	}
+
+	// Luận văn chưa từng có buổi gặp nào đứng đầu, sau đó là buổi gặp cũ nhất
+	sort.SliceStable(report.Theses, func(i, j int) bool {
+		a, b := report.Theses[i].LastMeetingDate, report.Theses[j].LastMeetingDate
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+
+	response.Data = report
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// applyPayload kiểm tra và gán nội dung biên bản; ngày gặp không được ở tương lai
+func applyPayload(log *model.ProgressLog, payload *model.CreateProgressLog) map[string]string {
+	errors := utils.RequireCheck([]string{"meetingDate", "done"}, map[string]string{
+		"meetingDate": strings.TrimSpace(payload.MeetingDate),
+		"done":        strings.TrimSpace(payload.Done),
+	}, map[string]string{})
+	if len(errors) > 0 {
+		return errors
+	}
+
+	meetingDate, err := core.ParseCampusTime(payload.MeetingDate)
+	if err != nil {
+		errors["meetingDate"] = config.GetMessageCode("FORMAT_DATETIME")
+		return errors
+	}
+	if meetingDate.After(core.EndOfDay(core.Now())) {
+		errors["meetingDate"] = config.GetMessageCode("INVALID_TIME_RANGE")
+		return errors
+	}
+
+	log.MeetingDate = meetingDate
+	log.Done = payload.Done
+	log.NextSteps = payload.NextSteps
+	log.Blockers = payload.Blockers
+	log.Guests = payload.Guests
+	return errors
+}
+
+// resolveAttendees chỉ nhận sinh viên và giảng viên thuộc luận văn
+func resolveAttendees(thesis *modelThesis.Thesis, refs []model.AttendeeRef) ([]model.ProgressLogAttendee, bool) {
+	attendees := []model.ProgressLogAttendee{}
+	seen := map[model.AttendeeRef]bool{}
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		name, found := "", false
+		switch ref.Role {
+		case modelUsers.StudentRole:
+			for _, student := range thesis.Students {
+				if student.ID == ref.UserID {
+					name, found = student.FullName, true
+				}
+			}
+		case modelUsers.AdvisorRole:
+			for _, advisor := range thesis.Advisors {
+				if advisor.ID == ref.UserID {
+					name, found = advisor.FullName, true
+				}
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		attendees = append(attendees, model.ProgressLogAttendee{UserID: ref.UserID, Role: ref.Role, Name: name})
+	}
+	return attendees, true
+}
+
+func saveAttendees(tx *gorm.DB, logID uint, attendees []model.ProgressLogAttendee) error {
+	for i := range attendees {
+		attendees[i].ProgressLogID = logID
+	}
+	if len(attendees) == 0 {
+		return nil
+	}
+	return tx.Create(&attendees).Error
+}
+
+// loadOwnLog tìm biên bản theo :id, chỉ người viết được sửa/xóa và chỉ khi chưa được xác nhận
+func loadOwnLog(c *fiber.Ctx, db *gorm.DB) (model.ProgressLog, modelThesis.Thesis, string) {
+	var log model.ProgressLog
+	var thesis modelThesis.Thesis
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		return log, thesis, config.GetMessageCode("TOKEN_INCORRECT")
+	}
+	if err := db.First(&log, c.Params("id")).Error; err != nil {
+		return log, thesis, config.GetMessageCode("NOT_ID_EXISTS")
+	}
+	if log.AuthorID != tokenData.ID || log.AuthorRole != tokenData.Role || log.AcknowledgedAt != nil {
+		return log, thesis, config.GetMessageCode("PERMISSION_DENIED")
+	}
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, log.ThesisID).Error; err != nil {
+		return log, thesis, "Thesis not found"
+	}
+	return log, thesis, ""
+}
+
+func isMember(tokenData *utils.TokenData, thesis *modelThesis.Thesis) bool {
+	switch tokenData.Role {
+	case modelUsers.StudentRole:
+		return thesis.HasStudent(tokenData.ID)
+	case modelUsers.AdvisorRole:
+		return thesis.HasAdvisor(tokenData.ID)
+	}
+	return false
+}
+
+// canAccessThesis: thành viên luận văn, trưởng bộ môn và văn phòng khoa trong phạm vi của mình được xem
+func canAccessThesis(db *gorm.DB, tokenData *utils.TokenData, thesis *modelThesis.Thesis) bool {
+	if tokenData.Role == modelUsers.HeadOfSubjectRole || tokenData.Role == modelUsers.FacultyOfficeRole {
+		return organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+	}
+	return isMember(tokenData, thesis)
+}
@@ -0,0 +1,15 @@
+package progressLogMigrate
+
+import (
+	"app/database"
+	model "app/modules/progressLog/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.ProgressLog{})
+	db.AutoMigrate(&model.ProgressLogAttendee{})
+
+	return true
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"app/model"
+	"time"
+)
+
+// ProgressLog là biên bản một buổi gặp giảng viên hướng dẫn
+type ProgressLog struct {
+	model.Header
+	ThesisID       uint                  `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	MeetingDate    time.Time             `json:"meetingDate" gorm:"column:MEETING_DATE;index"`
+	Done           string                `json:"done" gorm:"column:DONE"`
+	NextSteps      string                `json:"nextSteps" gorm:"column:NEXT_STEPS"`
+	Blockers       string                `json:"blockers" gorm:"column:BLOCKERS"`
+	Guests         string                `json:"guests" gorm:"column:GUESTS"`
+	AuthorID       uint                  `json:"authorID" gorm:"column:AUTHOR_ID"`
+	AuthorRole     int                   `json:"authorRole" gorm:"column:AUTHOR_ROLE"`
+	AcknowledgedBy string                `json:"acknowledgedBy" gorm:"column:ACKNOWLEDGED_BY;size:50"`
+	AcknowledgedAt *time.Time            `json:"acknowledgedAt" gorm:"column:ACKNOWLEDGED_AT"`
+	AdvisorComment string                `json:"advisorComment" gorm:"column:ADVISOR_COMMENT"`
+	Attendees      []ProgressLogAttendee `json:"attendees" gorm:"foreignKey:PROGRESS_LOG_ID"`
+}
+
+// ProgressLogAttendee là sinh viên hoặc giảng viên của luận văn có mặt trong buổi gặp
+type ProgressLogAttendee struct {
+	model.Header
+	ProgressLogID uint   `json:"progressLogID" gorm:"column:PROGRESS_LOG_ID;index"`
+	UserID        uint   `json:"userID" gorm:"column:USER_ID"`
+	Role          int    `json:"role" gorm:"column:ROLE"`
+	Name          string `json:"name" gorm:"column:NAME"`
+}
+
+type AttendeeRef struct {
+	UserID uint `json:"userID"`
+	Role   int  `json:"role"`
+}
+
+type CreateProgressLog struct {
+	MeetingDate string        `json:"meetingDate" validate:"required"`
+	Attendees   []AttendeeRef `json:"attendees"`
+	Guests      string        `json:"guests"`
+	Done        string        `json:"done" validate:"required"`
+	NextSteps   string        `json:"nextSteps"`
+	Blockers    string        `json:"blockers"`
+}
+
+type AcknowledgeProgressLog struct {
+	Comment string `json:"comment"`
+}
+
+type InactiveThesis struct {
+	ThesisID        uint       `json:"thesisID"`
+	TitleVi         string     `json:"titleVi"`
+	TitleEn         string     `json:"titleEn"`
+	Semester        string     `json:"semester"`
+	Advisors        []string   `json:"advisors"`
+	LastMeetingDate *time.Time `json:"lastMeetingDate"`
+	WeeksSince      *int       `json:"weeksSince"`
+}
+
+type InactiveReport struct {
+	Weeks  int              `json:"weeks"`
+	Since  time.Time        `json:"since"`
+	Theses []InactiveThesis `json:"theses"`
+}
+
+func (ProgressLog) TableName() string {
+	return "TBL_PROGRESS_LOG"
+}
+
+func (ProgressLogAttendee) TableName() string {
+	return "TBL_PROGRESS_LOG_ATTENDEE"
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"app/modules/progressLog/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitProgressLogRoutes(app *fiber.App) {
+	progressLog := app.Group("/progress-log")
+
+	progressLog.Get("/report/inactive", controller.GetInactiveTheses)
+	progressLog.Get("/thesis/:uuid", controller.ListProgressLogs)
+
+	progressLog.Post("/thesis/:uuid", controller.CreateProgressLog)
+	progressLog.Put("/:id", controller.UpdateProgressLog)
+	progressLog.Put("/:id/acknowledge", controller.AcknowledgeProgressLog)
+	progressLog.Delete("/:id", controller.DeleteProgressLog)
+}
@@ -0,0 +1,466 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/recommendation/model"
+	"app/utils"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	modelChangeRequest "app/modules/changeRequest/model"
+	programController "app/modules/program/controller"
+	researchAreaController "app/modules/researchArea/controller"
+	modelResearchArea "app/modules/researchArea/model"
+	modelStudent "app/modules/student/model"
+	thesisController "app/modules/thesis/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Recommendation API
+// @version 1.0
+// @description Topic recommendations for the signed-in student
+// @termsOfService http://swagger.io/terms/
+// @BasePath /me
+// @schemes http
+// @produce json
+// @consumes json
+
+// Trọng số của từng tín hiệu khi xếp hạng đề tài
+const (
+	weightInterestArea    = 3.0
+	weightInterestKeyword = 2.0
+	weightPartialKeyword  = 1.0
+	weightInterestTitle   = 1.0
+	weightPastArea        = 1.5
+	weightPastKeyword     = 1.0
+	weightProgram         = 2.0
+
+	defaultRecommendLimit = 20
+	maxRecommendLimit     = 100
+	maxInterests          = 20
+)
+
+// profile gom các tín hiệu đã chuẩn hóa của sinh viên
+type profile struct {
+	program       int
+	interestAreas map[uint]bool
+	interests     map[string]string // norm -> giá trị gốc
+	pastAreas     map[uint]bool
+	pastKeywords  map[string]string
+}
+
+// GetRecommendedTheses gợi ý đề tài mở phù hợp với sinh viên đang đăng nhập
+// @Summary Recommend open topics
+// @Description Rank open topics for the signed-in student using research-area tags, the student's program, research areas and keywords of topics they withdrew from or changed away from, and stated interests. Each item explains why it was recommended.
+// @Tags Recommendation
+// @Produce json
+// @Param limit query int false "Maximum number of topics (default 20, max 100)"
+// @Param semester query string false "Semester"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /me/recommended-theses [get]
+func GetRecommendedTheses(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+	if tokenData.Role != modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	limit := defaultRecommendLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > maxRecommendLimit {
+		limit = maxRecommendLimit
+	}
+
+	var student modelStudent.Student
+	if err := db.Preload("Interests").Preload("InterestAreas").First(&student, tokenData.ID).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	var areas []modelResearchArea.ResearchArea
+	if err := db.Find(&areas).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	p, err := buildProfile(db, &student, areas)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	query := thesisController.OpenThesesQuery(db).Preload("Programs.Catalog").Preload("Advisors").Preload("ResearchAreas").Preload("Keywords")
+	if semester := c.Query("semester"); semester != "" {
+		query = query.Where("SEMESTER = ?", semester)
+	}
+	if student.Program != 0 {
+		query = thesisController.EligibleForProgram(db, query, student.Program)
+	}
+	if student.ThesisID > 0 {
+		query = query.Where("ID <> ?", student.ThesisID)
+	}
+
+	var theses []modelThesis.Thesis
+	if err := query.Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = "Failed to fetch theses"
+		return c.JSON(response)
+	}
+
+	items := make([]model.RecommendedThesis, 0, len(theses))
+	for _, thesis := range theses {
+		items = append(items, rank(thesis, p))
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Thesis.ID > items[j].Thesis.ID
+	})
+
+	total := len(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	response.Data = model.Recommendations{StudentID: student.ID, Total: total, Items: items}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetMyInterests trả về chương trình đào tạo và sở thích nghiên cứu của sinh viên
+// @Summary Get my interests
+// @Description Get the program, research areas and free-text interests used for recommendations
+// @Tags Recommendation
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /me/interests [get]
+func GetMyInterests(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var student modelStudent.Student
+	if err := database.DB.Preload("Interests").Preload("InterestAreas").First(&student, tokenData.ID).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = student
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateMyInterests cập nhật chương trình đào tạo và sở thích nghiên cứu của sinh viên
+// @Summary Update my interests
+// @Description Replace the research areas and free-text interests of the signed-in student, and optionally set their program
+// @Tags Recommendation
+// @Accept json
+// @Produce json
+// @Param body body modelStudent.UpdateStudentInterests true "Program and interests"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /me/interests [put]
+func UpdateMyInterests(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload modelStudent.UpdateStudentInterests
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	var student modelStudent.Student
+	if err := db.First(&student, tokenData.ID).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	areas, err := researchAreaController.LoadActiveAreas(db, payload.ResearchAreaIDs)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"researchAreaIDs": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+
+	interests := []modelStudent.Interest{}
+	seen := map[string]bool{}
+	for _, value := range payload.Interests {
+		value = strings.TrimSpace(value)
+		norm := utils.NormalizeText(value)
+		if norm == "" || seen[norm] {
+			continue
+		}
+		if len(value) > 100 {
+			response.Status = false
+			response.Message = config.GetMessageCode("MAX_LENGTH")
+			response.ValidateError = map[string]string{"interests": value}
+			return c.JSON(response)
+		}
+		seen[norm] = true
+		interests = append(interests, modelStudent.Interest{Value: value, Norm: norm, StudentID: student.ID})
+	}
+	if len(interests) > maxInterests {
+		response.Status = false
+		response.Message = config.GetMessageCode("MAX_LENGTH")
+		response.ValidateError = map[string]string{"interests": config.GetMessageCode("MAX_LENGTH")}
+		return c.JSON(response)
+	}
+
+	if payload.Program != nil && *payload.Program != 0 && *payload.Program != student.Program {
+		if err := programController.ValidatePrograms(db, *payload.Program); err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"program": err.Error()}
+			return c.JSON(response)
+		}
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if payload.Program != nil {
+		if err := tx.Model(&student).Updates(map[string]interface{}{"PROGRAM": *payload.Program, "UPDATED_BY": tokenData.Code}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	if err := tx.Model(&student).Association("InterestAreas").Replace(areas); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	if err := tx.Unscoped().Where("STUDENT_ID = ?", student.ID).Delete(&modelStudent.Interest{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if len(interests) > 0 {
+		if err := tx.Create(&interests).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	student.InterestAreas = areas
+	student.Interests = interests
+	response.Data = student
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// buildProfile chuẩn hóa sở thích của sinh viên. Lĩnh vực quan tâm được mở rộng xuống các lĩnh vực con,
+// và sở thích tự do trùng tên một lĩnh vực trong danh mục cũng được coi là lĩnh vực quan tâm.
+func buildProfile(db *gorm.DB, student *modelStudent.Student, areas []modelResearchArea.ResearchArea) (*profile, error) {
+	p := &profile{
+		program:       student.Program,
+		interestAreas: map[uint]bool{},
+		interests:     map[string]string{},
+		pastAreas:     map[uint]bool{},
+		pastKeywords:  map[string]string{},
+	}
+
+	children := map[uint][]uint{}
+	areaByName := map[string]uint{}
+	for _, area := range areas {
+		if area.ParentID != nil {
+			children[*area.ParentID] = append(children[*area.ParentID], area.ID)
+		}
+		areaByName[utils.NormalizeText(area.NameVi)] = area.ID
+		areaByName[utils.NormalizeText(area.NameEn)] = area.ID
+		areaByName[strings.ToLower(area.Code)] = area.ID
+	}
+
+	roots := []uint{}
+	for _, area := range student.InterestAreas {
+		roots = append(roots, area.ID)
+	}
+	for _, interest := range student.Interests {
+		p.interests[interest.Norm] = interest.Value
+		if id, ok := areaByName[interest.Norm]; ok {
+			roots = append(roots, id)
+		}
+	}
+	expand(roots, children, p.interestAreas)
+
+	// Đề tài sinh viên từng thực hiện rồi rút hoặc đổi sang đề tài khác (theo nhật ký thay đổi)
+	// cho biết hướng nghiên cứu đã quen thuộc
+	pastIDs := db.Model(&modelChangeRequest.ChangeAudit{}).Select("FROM_THESIS_ID").
+		Where("STUDENT_ID = ? AND TYPE IN ? AND FROM_THESIS_ID <> ?", student.ID, []string{modelChangeRequest.ChangeWithdrawal, modelChangeRequest.ChangeTopic}, student.ThesisID)
+	var past []modelThesis.Thesis
+	if err := db.Preload("ResearchAreas").Preload("Keywords").Where("ID IN (?)", pastIDs).Find(&past).Error; err != nil {
+		return nil, err
+	}
+	pastRoots := []uint{}
+	for _, thesis := range past {
+		for _, area := range thesis.ResearchAreas {
+			pastRoots = append(pastRoots, area.ID)
+		}
+		for _, keyword := range thesis.Keywords {
+			p.pastKeywords[keyword.Norm] = keyword.Value
+		}
+	}
+	expand(pastRoots, children, p.pastAreas)
+
+	return p, nil
+}
+
+func expand(roots []uint, children map[uint][]uint, into map[uint]bool) {
+	for len(roots) > 0 {
+		id := roots[0]
+		roots = roots[1:]
+		if into[id] {
+			continue
+		}
+		into[id] = true
+		roots = append(roots, children[id]...)
+	}
+}
+
+// rank tính điểm một đề tài theo hồ sơ sinh viên và ghi lại lý do, lý do nặng ký nhất đứng trước
+func rank(thesis modelThesis.Thesis, p *profile) model.RecommendedThesis {
+	item := model.RecommendedThesis{Thesis: thesis, Reasons: []string{}}
+	matched := map[string]bool{}
+	weights := map[string]float64{}
+	addReason := func(score float64, reason string) {
+		item.Score += score
+		if key := utils.NormalizeText(reason); !matched[key] {
+			matched[key] = true
+			item.Reasons = append(item.Reasons, reason)
+		}
+		weights[reason] += score
+	}
+
+	for _, area := range thesis.ResearchAreas {
+		name := areaName(area)
+		if p.interestAreas[area.ID] {
+			addReason(weightInterestArea, name)
+		} else if p.pastAreas[area.ID] {
+			addReason(weightPastArea, name)
+		}
+	}
+
+	for _, keyword := range thesis.Keywords {
+		if _, ok := p.interests[keyword.Norm]; ok {
+			addReason(weightInterestKeyword, keyword.Value)
+			continue
+		}
+		if _, ok := p.pastKeywords[keyword.Norm]; ok {
+			addReason(weightPastKeyword, keyword.Value)
+			continue
+		}
+		for norm := range p.interests {
+			if containsWords(keyword.Norm, norm) || containsWords(norm, keyword.Norm) {
+				addReason(weightPartialKeyword, keyword.Value)
+				break
+			}
+		}
+	}
+
+	title := utils.NormalizeText(thesis.TitleVi + " " + thesis.TitleEn)
+	for norm, value := range p.interests {
+		if !matched[norm] && containsWords(title, norm) {
+			addReason(weightInterestTitle, value)
+		}
+	}
+
+	sort.SliceStable(item.Reasons, func(i, j int) bool {
+		if weights[item.Reasons[i]] != weights[item.Reasons[j]] {
+			return weights[item.Reasons[i]] > weights[item.Reasons[j]]
+		}
+		return item.Reasons[i] < item.Reasons[j]
+	})
+
+	var programReason string
+	if p.program != 0 {
+		for _, program := range thesis.Programs {
+			if program.Value == p.program {
+				item.Score += weightProgram
+				programReason = fmt.Sprintf("your program %d", p.program)
+				if program.Catalog != nil {
+					programReason = "your program " + program.Catalog.Code
+				}
+				break
+			}
+		}
+	}
+
+	if len(item.Reasons) > 0 {
+		item.Explanation = "matches: " + strings.Join(item.Reasons, ", ")
+		if programReason != "" {
+			item.Explanation += ", " + programReason
+		}
+	} else if programReason != "" {
+		item.Explanation = programReason
+	}
+	if programReason != "" {
+		item.Reasons = append(item.Reasons, programReason)
+	}
+	return item
+}
+
+func areaName(area modelResearchArea.ResearchArea) string {
+	if strings.TrimSpace(area.NameEn) != "" {
+		return area.NameEn
+	}
+	return area.NameVi
+}
+
+// containsWords kiểm tra needle xuất hiện trong haystack như một cụm từ trọn vẹn
+func containsWords(haystack, needle string) bool {
+	if needle == "" {
+		return false
+	}
+	return strings.Contains(" "+haystack+" ", " "+needle+" ")
+}
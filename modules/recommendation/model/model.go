@@ -0,0 +1,18 @@
+package model
+
+import (
+	modelThesis "app/modules/thesis/model"
+)
+
+type RecommendedThesis struct {
+	Thesis      modelThesis.Thesis `json:"thesis"`
+	Score       float64            `json:"score"`
+	Reasons     []string           `json:"reasons"`
+	Explanation string             `json:"explanation"`
+}
+
+type Recommendations struct {
+	StudentID uint                `json:"studentID"`
+	Total     int                 `json:"total"`
+	Items     []RecommendedThesis `json:"items"`
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"app/modules/recommendation/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitRecommendationRoutes(app *fiber.App) {
+	me := app.Group("/me")
+
+	me.Get("/recommended-theses", controller.GetRecommendedTheses)
+	me.Get("/interests", controller.GetMyInterests)
+	me.Put("/interests", controller.UpdateMyInterests)
+}
@@ -0,0 +1,340 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/researchArea/model"
+	"app/utils"
+	"errors"
+	"strings"
+	"time"
+
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Research Area API
+// @version 1.0
+// @description Controlled vocabulary of research areas
+// @termsOfService http://swagger.io/terms/
+// @BasePath /research-area
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetResearchAreas trả về danh mục lĩnh vực nghiên cứu
+// @Summary Get research areas
+// @Description Get the research area vocabulary. Inactive areas are hidden unless all=true.
+// @Tags ResearchArea
+// @Produce json
+// @Param all query bool false "Include inactive areas"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /research-area [get]
+func GetResearchAreas(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	query := database.DB.Order("CODE")
+	if c.Query("all") != "true" {
+		query = query.Where("ACTIVE = ?", true)
+	}
+
+	var areas []model.ResearchArea
+	if err := query.Find(&areas).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = areas
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetResearchArea trả về một lĩnh vực nghiên cứu
+// @Summary Get a research area
+// @Description Get a research area by ID
+// @Tags ResearchArea
+// @Produce json
+// @Param id path int true "Research area ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /research-area/{id} [get]
+func GetResearchArea(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	var area model.ResearchArea
+	if err := database.DB.First(&area, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Data = area
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateResearchArea thêm lĩnh vực nghiên cứu vào danh mục
+// @Summary Create research areas
+// @Description Create research areas (faculty office or head of subject)
+// @Tags ResearchArea
+// @Accept json
+// @Produce json
+// @Param body body []model.CreateResearchArea true "Research areas"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /research-area [post]
+func CreateResearchArea(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, ok := canManage(c)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.CreateResearchArea
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		listCheck := []string{"code", "nameVi", "nameEn"}
+		vItem := map[string]string{
+			"code":   strings.TrimSpace(item.Code),
+			"nameVi": strings.TrimSpace(item.NameVi),
+			"nameEn": strings.TrimSpace(item.NameEn),
+		}
+		errs := utils.RequireCheck(listCheck, vItem, map[string]string{})
+		errs = utils.MaxLengthCheck([]string{"code:30"}, vItem, errs)
+		if len(errs) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("MISSING_FIELDS")
+			response.ValidateError = errs
+			return c.JSON(response)
+		}
+
+		area := model.ResearchArea{
+			Code:        strings.ToUpper(vItem["code"]),
+			NameVi:      vItem["nameVi"],
+			NameEn:      vItem["nameEn"],
+			Description: item.Description,
+			ParentID:    item.ParentID,
+			Active:      true,
+		}
+		area.ID = item.ID
+		area.CreatedBy = tokenData.Code
+
+		if err := tx.Create(&area).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = err.Error()
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateResearchArea cập nhật lĩnh vực nghiên cứu
+// @Summary Update research areas
+// @Description Update research areas; set active=false to retire an area without losing existing tags
+// @Tags ResearchArea
+// @Accept json
+// @Produce json
+// @Param body body []model.UpdateResearchArea true "Research areas"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /research-area [put]
+func UpdateResearchArea(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, ok := canManage(c)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.UpdateResearchArea
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		var area model.ResearchArea
+		if err := tx.First(&area, item.ID).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+
+		if item.Code != "" {
+			area.Code = strings.ToUpper(strings.TrimSpace(item.Code))
+		}
+		if item.NameVi != "" {
+			area.NameVi = item.NameVi
+		}
+		if item.NameEn != "" {
+			area.NameEn = item.NameEn
+		}
+		if item.Description != "" {
+			area.Description = item.Description
+		}
+		if item.ParentID != nil {
+			if *item.ParentID == area.ID {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				return c.JSON(response)
+			}
+			area.ParentID = item.ParentID
+		}
+		if item.Active != nil {
+			area.Active = *item.Active
+		}
+		area.UpdatedBy = tokenData.Code
+
+		if err := tx.Save(&area).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteResearchArea xóa lĩnh vực nghiên cứu
+// @Summary Delete a research area
+// @Description Soft delete a research area
+// @Tags ResearchArea
+// @Produce json
+// @Param id path int true "Research area ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /research-area/{id} [delete]
+func DeleteResearchArea(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, ok := canManage(c)
+	if !ok {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	var area model.ResearchArea
+	if err := tx.First(&area, c.Params("id")).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	if err := tx.Model(&area).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ResolveAreaIDs tìm lĩnh vực theo mã hoặc tên (không phân biệt dấu) và trả về ID kèm các lĩnh vực con
+func ResolveAreaIDs(db *gorm.DB, query string) ([]uint, error) {
+	var areas []model.ResearchArea
+	if err := db.Find(&areas).Error; err != nil {
+		return nil, err
+	}
+
+	needle := utils.NormalizeText(query)
+	children := map[uint][]uint{}
+	var matched []uint
+	for _, area := range areas {
+		if area.ParentID != nil {
+			children[*area.ParentID] = append(children[*area.ParentID], area.ID)
+		}
+		if strings.EqualFold(area.Code, strings.TrimSpace(query)) ||
+			utils.NormalizeText(area.NameVi) == needle || utils.NormalizeText(area.NameEn) == needle {
+			matched = append(matched, area.ID)
+		}
+	}
+
+	seen := map[uint]bool{}
+	var result []uint
+	for len(matched) > 0 {
+		id := matched[0]
+		matched = matched[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		matched = append(matched, children[id]...)
+	}
+	return result, nil
+}
+
+// LoadActiveAreas kiểm tra danh sách ID thuộc danh mục và còn hiệu lực
+func LoadActiveAreas(db *gorm.DB, ids []uint) ([]model.ResearchArea, error) {
+	areas := []model.ResearchArea{}
+	if len(ids) == 0 {
+		return areas, nil
+	}
+	if err := db.Where("ID IN ? AND ACTIVE = ?", ids, true).Find(&areas).Error; err != nil {
+		return nil, err
+	}
+	if len(areas) != len(uniqueIDs(ids)) {
+		return nil, errors.New(config.GetMessageCode("NOT_ID_EXISTS"))
+	}
+	return areas, nil
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	set := map[uint]bool{}
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func canManage(c *fiber.Ctx) (*utils.TokenData, bool) {
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		return nil, false
+	}
+	return tokenData, tokenData.Role == modelUsers.FacultyOfficeRole || tokenData.Role == modelUsers.HeadOfSubjectRole
+}
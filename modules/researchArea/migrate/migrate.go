@@ -0,0 +1,14 @@
+package researchAreaMigrate
+
+import (
+	"app/database"
+	model "app/modules/researchArea/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.ResearchArea{})
+
+	return true
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"app/model"
+)
+
+// ResearchArea là danh mục lĩnh vực nghiên cứu dùng chung cho đề tài và chuyên môn giảng viên
+type ResearchArea struct {
+	model.Header
+	Code        string `json:"code" gorm:"column:CODE;size:30;uniqueIndex"`
+	NameVi      string `json:"nameVi" gorm:"column:NAME_VI"`
+	NameEn      string `json:"nameEn" gorm:"column:NAME_EN"`
+	Description string `json:"description" gorm:"column:DESCRIPTION"`
+	ParentID    *uint  `json:"parentID" gorm:"column:PARENT_ID;index"`
+	Active      bool   `json:"active" gorm:"column:ACTIVE;default:true"`
+}
+
+type CreateResearchArea struct {
+	ID          uint   `json:"id"`
+	Code        string `json:"code" validate:"required"`
+	NameVi      string `json:"nameVi" validate:"required"`
+	NameEn      string `json:"nameEn" validate:"required"`
+	Description string `json:"description"`
+	ParentID    *uint  `json:"parentID"`
+}
+
+type UpdateResearchArea struct {
+	ID          uint   `json:"id"`
+	Code        string `json:"code"`
+	NameVi      string `json:"nameVi"`
+	NameEn      string `json:"nameEn"`
+	Description string `json:"description"`
+	ParentID    *uint  `json:"parentID"`
+	Active      *bool  `json:"active"`
+}
+
+func (ResearchArea) TableName() string {
+	return "TBL_RESEARCH_AREA"
+}
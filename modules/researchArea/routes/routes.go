@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"app/modules/researchArea/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitResearchAreaRoutes(app *fiber.App) {
+	researchArea := app.Group("/research-area")
+
+	researchArea.Get("/", controller.GetResearchAreas)
+	researchArea.Get("/:id", controller.GetResearchArea)
+
+	researchArea.Post("/", controller.CreateResearchArea)
+	researchArea.Put("/", controller.UpdateResearchArea)
+	researchArea.Delete("/:id", controller.DeleteResearchArea)
+}
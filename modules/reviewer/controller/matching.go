@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"app/modules/reviewer/model"
+	"sort"
+
+	modelAdvisor "app/modules/advisor/model"
+	modelResearchArea "app/modules/researchArea/model"
+	modelThesis "app/modules/thesis/model"
+
+	"gorm.io/gorm"
+)
+
+// matcher giữ cây lĩnh vực, chuyên môn và số luận văn đang phản biện của giảng viên trong một học kỳ
+// để chấm điểm nhiều luận văn mà không phải đọc lại dữ liệu
+type matcher struct {
+	parents   map[uint]uint
+	expertise map[uint]map[uint]bool
+	lecturers []modelAdvisor.Advisor
+	load      map[uint]int
+}
+
+func newMatcher(db *gorm.DB, semester string) (*matcher, error) {
+	m := &matcher{parents: map[uint]uint{}, expertise: map[uint]map[uint]bool{}, load: map[uint]int{}}
+
+	var areas []modelResearchArea.ResearchArea
+	if err := db.Select("ID, PARENT_ID").Find(&areas).Error; err != nil {
+		return nil, err
+	}
+	for _, area := range areas {
+		if area.ParentID != nil {
+			m.parents[area.ID] = *area.ParentID
+		}
+	}
+
+	var expertise []struct {
+		AdvisorID      uint
+		ResearchAreaID uint
+	}
+	if err := db.Table("TBL_ADVISOR_EXPERTISE").Select("ADVISOR_ID, RESEARCH_AREA_ID").Scan(&expertise).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range expertise {
+		if m.expertise[row.AdvisorID] == nil {
+			m.expertise[row.AdvisorID] = map[uint]bool{}
+		}
+		m.expertise[row.AdvisorID][row.ResearchAreaID] = true
+	}
+
+	if err := db.Select("ID, CODE, FULL_NAME").Order("ID").Find(&m.lecturers).Error; err != nil {
+		return nil, err
+	}
+
+	var assignments []model.ReviewerAssignment
+	if err := db.Select("ID, REVIEWER_ID").Where("SEMESTER = ? AND STATUS <> ?", semester, model.AssignmentWithdrawn).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		m.load[assignment.ReviewerID]++
+	}
+	return m, nil
+}
+
+// candidates xếp hạng các giảng viên không hướng dẫn luận văn theo Candidate.Better
+func (m *matcher) candidates(thesis modelThesis.Thesis) []model.Candidate {
+	excluded := map[uint]bool{}
+	excludedCodes := map[string]bool{}
+	for _, advisor := range thesis.Advisors {
+		excluded[advisor.ID] = true
+		if advisor.Code != "" {
+			excludedCodes[advisor.Code] = true
+		}
+	}
+
+	candidates := []model.Candidate{}
+	for _, lecturer := range m.lecturers {
+		if excluded[lecturer.ID] || excludedCodes[lecturer.Code] {
+			continue
+		}
+		candidates = append(candidates, model.Candidate{
+			ReviewerID:   lecturer.ID,
+			ReviewerCode: lecturer.Code,
+			FullName:     lecturer.FullName,
+			MatchedAreas: m.matched(lecturer.ID, thesis.ResearchAreas),
+			Load:         m.load[lecturer.ID],
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Better(candidates[j]) })
+	return candidates
+}
+
+// matched đếm số lĩnh vực của đề tài nằm trong chuyên môn của giảng viên; chuyên môn ở lĩnh vực cha bao gồm các lĩnh vực con
+func (m *matcher) matched(lecturerID uint, areas []modelResearchArea.ResearchArea) int {
+	expertise := m.expertise[lecturerID]
+	if len(expertise) == 0 {
+		return 0
+	}
+	count := 0
+	for _, area := range areas {
+		seen := map[uint]bool{}
+		for id, ok := area.ID, true; ok && !seen[id]; id, ok = m.parents[id] {
+			if expertise[id] {
+				count++
+				break
+			}
+			seen[id] = true
+		}
+	}
+	return count
+}
+
+// take ghi nhận một phân công mới để các luận văn sau được chia cho người ít việc hơn
+func (m *matcher) take(reviewerID uint) {
+	m.load[reviewerID]++
+}
+
+// sortByMatching đưa các luận văn có ít giảng viên đúng chuyên môn lên trước
+func sortByMatching(theses []modelThesis.Thesis, matching map[uint]int) {
+	sort.SliceStable(theses, func(i, j int) bool { return matching[theses[i].ID] < matching[theses[j].ID] })
+}
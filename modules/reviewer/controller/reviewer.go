@@ -0,0 +1,855 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/reviewer/model"
+	"app/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	modelAdvisor "app/modules/advisor/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	organizationController "app/modules/organization/controller"
+	semesterController "app/modules/semester/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @title Reviewer API
+// @version 1.0
+// @description Independent reviewer assignment and review reports
+// @termsOfService http://swagger.io/terms/
+// @BasePath /reviewer
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetCandidates gợi ý giảng viên phản biện cho luận văn
+// @Summary Suggest reviewers for a thesis
+// @Description Lecturers who do not supervise the thesis, ranked by matching expertise first, then by the number of theses they already review in the semester. Head of subject or faculty office.
+// @Tags Reviewer
+// @Produce json
+// @Param thesisID path int true "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/candidate/{thesisID} [get]
+func GetCandidates(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	thesis, err := loadThesis(db, c.Params("thesisID"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canManage(db, tokenData, thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	m, err := newMatcher(db, thesis.Semester)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = m.candidates(thesis)
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetAssignments trả về danh sách phân công phản biện
+// @Summary List reviewer assignments
+// @Description Heads of subject and the faculty office see the assignments of the theses in their scope; lecturers see the theses they review
+// @Tags Reviewer
+// @Produce json
+// @Param semester query string false "Semester code"
+// @Param status query string false "ASSIGNED, SUBMITTED or WITHDRAWN"
+// @Param thesisID query int false "Thesis ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment [get]
+func GetAssignments(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	query := db.Preload("Report.Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("POSITION, ID")
+	}).Order("DUE_AT, ID")
+	switch tokenData.Role {
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		theses := organizationController.ScopeOf(db, tokenData).Apply(db.Model(&modelThesis.Thesis{}).Select("ID"), "SUBJECT_ID")
+		query = query.Where("THESIS_ID IN (?)", theses)
+	case modelUsers.AdvisorRole:
+		query = query.Where("REVIEWER_ID = ?", tokenData.ID)
+	default:
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if semester := strings.TrimSpace(c.Query("semester")); semester != "" {
+		query = query.Where("UPPER(SEMESTER) = ?", strings.ToUpper(semester))
+	}
+	if status := strings.ToUpper(strings.TrimSpace(c.Query("status"))); status != "" {
+		query = query.Where("STATUS = ?", status)
+	}
+	if thesisID := c.QueryInt("thesisID"); thesisID != 0 {
+		query = query.Where("THESIS_ID = ?", thesisID)
+	}
+
+	var assignments []model.ReviewerAssignment
+	if err := query.Find(&assignments).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = assignments
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetAssignment trả về một phân công phản biện và phiếu nhận xét
+// @Summary Get a reviewer assignment
+// @Description Assignment with its review report. The reviewer and the managers of the thesis see drafts; the advisors and students of the thesis see the report once it is submitted.
+// @Tags Reviewer
+// @Produce json
+// @Param id path int true "Assignment ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment/{id} [get]
+func GetAssignment(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	assignment, err := loadAssignment(db, c.Params("id"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	thesis, err := loadThesis(db, assignment.ThesisID)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !isReviewer(tokenData, assignment) && !canManage(db, tokenData, thesis) &&
+		(assignment.Status != model.AssignmentSubmitted || !participates(tokenData, thesis)) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	response.Data = assignment
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// AssignReviewer phân công phản biện cho một luận văn
+// @Summary Assign a reviewer
+// @Description Assign an independent reviewer to an approved thesis. Without reviewerID the best ranked candidate is chosen. The reviewer cannot supervise the thesis. An assignment whose report is not submitted yet is replaced. dueAt (YYYY-MM-DD) defaults to the review deadline of the semester. Head of subject or faculty office.
+// @Tags Reviewer
+// @Accept json
+// @Produce json
+// @Param body body model.AssignReviewer true "Assignment"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment [post]
+func AssignReviewer(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.AssignReviewer
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	thesis, err := loadThesis(db, payload.ThesisID)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		response.ValidateError = map[string]string{"thesisID": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+	if !canManage(db, tokenData, thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if thesis.ApprovalStatus != modelThesis.ApprovalApproved {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		response.ValidateError = map[string]string{"thesisID": "thesis is not approved"}
+		return c.JSON(response)
+	}
+	dueAt, errors := dueDate(db, thesis.Semester, payload.DueAt)
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	m, err := newMatcher(db, thesis.Semester)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	candidates := m.candidates(thesis)
+	var chosen *model.Candidate
+	for i := range candidates {
+		if payload.ReviewerID == 0 || candidates[i].ReviewerID == payload.ReviewerID {
+			chosen = &candidates[i]
+			break
+		}
+	}
+	if chosen == nil {
+		response.Status = false
+		response.ValidateError = map[string]string{"reviewerID": "no lecturer who does not supervise the thesis is available"}
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		if payload.ReviewerID != 0 {
+			response.Message = config.GetMessageCode("CONFLICT_OF_INTEREST")
+			var lecturer modelAdvisor.Advisor
+			if err := db.Select("ID").First(&lecturer, payload.ReviewerID).Error; err != nil {
+				response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			}
+			response.ValidateError = map[string]string{"reviewerID": response.Message}
+		}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	var current model.ReviewerAssignment
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("THESIS_ID = ? AND STATUS <> ?", thesis.ID, model.AssignmentWithdrawn).First(&current).Error
+	if err == nil {
+		if current.Status == model.AssignmentSubmitted {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+			response.ValidateError = map[string]string{"thesisID": "the review of this thesis is already submitted"}
+			return c.JSON(response)
+		}
+		if err := withdraw(tx, &current, tokenData.Code); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	assignment, err := assign(tx, thesis, *chosen, dueAt, tokenData.Code)
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = assignment
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AutoAssignReviewers phân công phản biện hàng loạt cho học kỳ
+// @Summary Assign reviewers to a semester
+// @Description Give every approved thesis of the semester in scope that has no reviewer (or only the listed theses) the best candidate, spreading the theses evenly across lecturers with matching expertise. Theses with the fewest matching lecturers are assigned first. Head of subject or faculty office.
+// @Tags Reviewer
+// @Accept json
+// @Produce json
+// @Param body body model.AutoAssignReviewers true "Semester and theses"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment/auto [post]
+func AutoAssignReviewers(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || (tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.AutoAssignReviewers
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	semester, err := semesterController.Lookup(db, payload.Semester)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		response.ValidateError = map[string]string{"semester": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+	dueAt, errors := dueDate(db, semester.Code, payload.DueAt)
+	if len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	reviewed := db.Model(&model.ReviewerAssignment{}).Select("THESIS_ID").Where("STATUS <> ?", model.AssignmentWithdrawn)
+	query := organizationController.ScopeOf(db, tokenData).Apply(db.Preload("Advisors").Preload("ResearchAreas").
+		Where("APPROVAL_STATUS = ? AND UPPER(SEMESTER) = ? AND ID NOT IN (?)", modelThesis.ApprovalApproved, strings.ToUpper(semester.Code), reviewed), "SUBJECT_ID")
+	if len(payload.ThesisIDs) > 0 {
+		query = query.Where("ID IN ?", payload.ThesisIDs)
+	}
+	var theses []modelThesis.Thesis
+	if err := query.Order("ID").Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	m, err := newMatcher(db, semester.Code)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	matching := map[uint]int{}
+	for _, thesis := range theses {
+		for _, candidate := range m.candidates(thesis) {
+			if candidate.MatchedAreas > 0 {
+				matching[thesis.ID]++
+			}
+		}
+	}
+	sortByMatching(theses, matching)
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	assignments := []model.ReviewerAssignment{}
+	unassigned := map[string]string{}
+	for _, thesis := range theses {
+		candidates := m.candidates(thesis)
+		if len(candidates) == 0 {
+			unassigned[fmt.Sprint(thesis.ID)] = "no lecturer who does not supervise the thesis is available"
+			continue
+		}
+		assignment, err := assign(tx, thesis, candidates[0], dueAt, tokenData.Code)
+		if err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		m.take(assignment.ReviewerID)
+		assignments = append(assignments, assignment)
+	}
+
+	response.Data = assignments
+	if len(unassigned) > 0 {
+		response.ValidateError = unassigned
+	}
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateDeadline đổi hạn nộp nhận xét phản biện
+// @Summary Change the review deadline
+// @Description Move the due date (YYYY-MM-DD) of an assignment whose report is not submitted yet, e.g. to allow a late report. Head of subject or faculty office.
+// @Tags Reviewer
+// @Accept json
+// @Produce json
+// @Param id path int true "Assignment ID"
+// @Param body body model.UpdateDeadline true "Due date"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment/{id}/deadline [put]
+func UpdateDeadline(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.UpdateDeadline
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.DueAt) == "" {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"dueAt": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	assignment, thesis, err := lockAssignment(tx, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canManage(tx, tokenData, thesis) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if assignment.Status != model.AssignmentAssigned {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+	dueAt, errors := dueDate(tx, thesis.Semester, payload.DueAt)
+	if len(errors) > 0 {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	if err := tx.Model(&assignment).Omit(clause.Associations).Updates(map[string]interface{}{
+		"DUE_AT":     dueAt,
+		"UPDATED_BY": tokenData.Code,
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	assignment.DueAt = dueAt
+
+	response.Data = assignment
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// WithdrawAssignment rút phân công phản biện chưa nộp nhận xét
+// @Summary Withdraw a reviewer assignment
+// @Description Withdraw an assignment whose report is not submitted yet. The assignment is kept as WITHDRAWN. Head of subject or faculty office.
+// @Tags Reviewer
+// @Produce json
+// @Param id path int true "Assignment ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment/{id} [delete]
+func WithdrawAssignment(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	assignment, thesis, err := lockAssignment(tx, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !canManage(tx, tokenData, thesis) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if assignment.Status != model.AssignmentAssigned {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+	if err := withdraw(tx, &assignment, tokenData.Code); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// SaveReviewReport lưu hoặc nộp phiếu nhận xét phản biện
+// @Summary Save or submit a review report
+// @Description The assigned reviewer fills in the review form: summary, strengths, weaknesses, presentation, a recommendation (ACCEPT, REVISE or REJECT) and the questions for the defense. submit=true submits the report, after which it is read-only and the defense of the thesis can be scheduled. Reports cannot be saved after the due date.
+// @Tags Reviewer
+// @Accept json
+// @Produce json
+// @Param id path int true "Assignment ID"
+// @Param body body model.SaveReviewReport true "Review report"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /reviewer/assignment/{id}/report [put]
+func SaveReviewReport(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.SaveReviewReport
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+	payload.Recommendation = strings.ToUpper(strings.TrimSpace(payload.Recommendation))
+	questions := []string{}
+	for _, question := range payload.Questions {
+		if question = strings.TrimSpace(question); question != "" {
+			questions = append(questions, question)
+		}
+	}
+	if errors := validateReport(&payload, questions); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	assignment, thesis, err := lockAssignment(tx, c.Params("id"))
+	if err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+	if !isReviewer(tokenData, assignment) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if assignment.Status != model.AssignmentAssigned {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+	now := core.Now()
+	if now.After(assignment.DueAt) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("REVIEW_DEADLINE_PASSED")
+		return c.JSON(response)
+	}
+
+	report := model.ReviewReport{}
+	tx.Where("ASSIGNMENT_ID = ?", assignment.ID).First(&report)
+	report.AssignmentID = assignment.ID
+	report.ThesisID = assignment.ThesisID
+	report.Summary = strings.TrimSpace(payload.Summary)
+	report.Strengths = strings.TrimSpace(payload.Strengths)
+	report.Weaknesses = strings.TrimSpace(payload.Weaknesses)
+	report.Presentation = strings.TrimSpace(payload.Presentation)
+	report.Recommendation = payload.Recommendation
+	report.Status = model.ReportDraft
+	if payload.Submit {
+		report.Status = model.ReportSubmitted
+		report.SubmittedAt = &now
+	}
+	if report.ID == 0 {
+		report.CreatedBy = tokenData.Code
+	}
+	report.UpdatedBy = tokenData.Code
+	if err := tx.Omit("Questions").Save(&report).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	if err := tx.Where("REPORT_ID = ?", report.ID).Delete(&model.ReviewQuestion{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	report.Questions = []model.ReviewQuestion{}
+	for i, question := range questions {
+		item := model.ReviewQuestion{ReportID: report.ID, Position: i, Question: question}
+		item.CreatedBy = tokenData.Code
+		report.Questions = append(report.Questions, item)
+	}
+	if len(report.Questions) > 0 {
+		if err := tx.Create(&report.Questions).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	if payload.Submit {
+		if err := tx.Model(&assignment).Omit(clause.Associations).Updates(map[string]interface{}{
+			"STATUS":     model.AssignmentSubmitted,
+			"UPDATED_BY": tokenData.Code,
+		}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		assignment.Status = model.AssignmentSubmitted
+
+		recipients := []modelNotification.Recipient{}
+		for _, advisor := range thesis.Advisors {
+			recipients = append(recipients, modelNotification.Recipient{UserID: advisor.ID, Role: modelUsers.AdvisorRole})
+		}
+		for _, student := range thesis.Students {
+			recipients = append(recipients, modelNotification.Recipient{UserID: student.ID, Role: modelUsers.StudentRole})
+		}
+		if err := notificationController.Notify(tx, modelNotification.Message{
+			Type:  modelNotification.NotificationReviewSubmitted,
+			Title: "Review report submitted",
+			Body:  fmt.Sprintf("The reviewer of \"%s\" recommends %s", thesis.TitleVi, strings.ToLower(report.Recommendation)),
+			Link:  fmt.Sprintf("/reviewer/assignment/%d", assignment.ID),
+		}, recipients...); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+	assignment.Report = &report
+
+	response.Data = assignment
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// Pending trả về các luận văn trong thesisIDs chưa có nhận xét phản biện đã nộp
+func Pending(db *gorm.DB, thesisIDs []uint) map[uint]bool {
+	pending := map[uint]bool{}
+	for _, id := range thesisIDs {
+		pending[id] = true
+	}
+	if len(thesisIDs) == 0 {
+		return pending
+	}
+	var submitted []model.ReviewerAssignment
+	db.Select("ID, THESIS_ID").Where("THESIS_ID IN ? AND STATUS = ?", thesisIDs, model.AssignmentSubmitted).Find(&submitted)
+	for _, assignment := range submitted {
+		delete(pending, assignment.ThesisID)
+	}
+	return pending
+}
+
+// IsReviewer kiểm tra người gọi là phản biện được phân công (chưa rút) của luận văn
+func IsReviewer(db *gorm.DB, tokenData *utils.TokenData, thesisID uint) bool {
+	if tokenData.Role != modelUsers.AdvisorRole {
+		return false
+	}
+	var count int64
+	db.Model(&model.ReviewerAssignment{}).
+		Where("THESIS_ID = ? AND REVIEWER_ID = ? AND STATUS <> ?", thesisID, tokenData.ID, model.AssignmentWithdrawn).Count(&count)
+	return count > 0
+}
+
+func assign(tx *gorm.DB, thesis modelThesis.Thesis, candidate model.Candidate, dueAt time.Time, createdBy string) (model.ReviewerAssignment, error) {
+	assignment := model.ReviewerAssignment{
+		ThesisID:     thesis.ID,
+		Semester:     thesis.Semester,
+		ReviewerID:   candidate.ReviewerID,
+		ReviewerCode: candidate.ReviewerCode,
+		FullName:     candidate.FullName,
+		MatchedAreas: candidate.MatchedAreas,
+		DueAt:        dueAt,
+		Status:       model.AssignmentAssigned,
+	}
+	assignment.CreatedBy = createdBy
+	if err := tx.Omit(clause.Associations).Create(&assignment).Error; err != nil {
+		return assignment, err
+	}
+	err := notificationController.Notify(tx, modelNotification.Message{
+		Type:  modelNotification.NotificationReviewerAssigned,
+		Title: "Thesis review assigned",
+		Body:  fmt.Sprintf("Please review \"%s\" and submit your report by %s", thesis.TitleVi, dueAt.In(core.CampusLocation()).Format("2006-01-02")),
+		Link:  fmt.Sprintf("/reviewer/assignment/%d", assignment.ID),
+	}, modelNotification.Recipient{UserID: candidate.ReviewerID, Role: modelUsers.AdvisorRole})
+	return assignment, err
+}
+
+func withdraw(tx *gorm.DB, assignment *model.ReviewerAssignment, updatedBy string) error {
+	assignment.Status = model.AssignmentWithdrawn
+	return tx.Model(assignment).Omit(clause.Associations).Updates(map[string]interface{}{
+		"STATUS":     model.AssignmentWithdrawn,
+		"UPDATED_BY": updatedBy,
+	}).Error
+}
+
+// dueDate đọc hạn nộp YYYY-MM-DD (tính hết ngày); để trống thì dùng hạn phản biện của học kỳ,
+// hoặc DefaultReviewDays ngày kể từ hôm nay
+func dueDate(db *gorm.DB, semesterCode, value string) (time.Time, map[string]string) {
+	errors := map[string]string{}
+	now := core.Now()
+	var dueAt time.Time
+	switch {
+	case strings.TrimSpace(value) != "":
+		if len(utils.DateFormatCheck([]string{"dueAt"}, map[string]string{"dueAt": value}, map[string]string{})) > 0 {
+			errors["dueAt"] = config.GetMessageCode("FORMAT_DATE")
+			return dueAt, errors
+		}
+		day, _ := time.ParseInLocation("2006-01-02", value, core.CampusLocation())
+		dueAt = core.EndOfDay(day)
+	default:
+		if semester, err := semesterController.Lookup(db, semesterCode); err == nil && semester.ReviewDeadline != nil {
+			dueAt = *semester.ReviewDeadline
+		} else {
+			dueAt = core.EndOfDay(now.AddDate(0, 0, model.DefaultReviewDays))
+		}
+	}
+	if dueAt.Before(now) {
+		errors["dueAt"] = config.GetMessageCode("INVALID_TIME_RANGE")
+	}
+	return dueAt, errors
+}
+
+func validateReport(payload *model.SaveReviewReport, questions []string) map[string]string {
+	errors := map[string]string{}
+	if payload.Recommendation != "" && !model.ValidRecommendation(payload.Recommendation) {
+		errors["recommendation"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	if !payload.Submit {
+		return errors
+	}
+	vItem := map[string]string{
+		"summary":        strings.TrimSpace(payload.Summary),
+		"strengths":      strings.TrimSpace(payload.Strengths),
+		"weaknesses":     strings.TrimSpace(payload.Weaknesses),
+		"recommendation": payload.Recommendation,
+	}
+	errors = utils.RequireCheck([]string{"summary", "strengths", "weaknesses", "recommendation"}, vItem, errors)
+	if len(questions) < model.MinQuestions {
+		errors["questions"] = fmt.Sprintf("at least %d question for the defense is required", model.MinQuestions)
+	}
+	return errors
+}
+
+func loadThesis(db *gorm.DB, id interface{}) (modelThesis.Thesis, error) {
+	var thesis modelThesis.Thesis
+	err := db.Preload("Advisors").Preload("Students").Preload("ResearchAreas").First(&thesis, id).Error
+	return thesis, err
+}
+
+func loadAssignment(db *gorm.DB, id interface{}) (model.ReviewerAssignment, error) {
+	var assignment model.ReviewerAssignment
+	err := db.Preload("Report.Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("POSITION, ID")
+	}).First(&assignment, id).Error
+	return assignment, err
+}
+
+func lockAssignment(tx *gorm.DB, id interface{}) (model.ReviewerAssignment, modelThesis.Thesis, error) {
+	var assignment model.ReviewerAssignment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&assignment, id).Error; err != nil {
+		return assignment, modelThesis.Thesis{}, err
+	}
+	thesis, err := loadThesis(tx, assignment.ThesisID)
+	return assignment, thesis, err
+}
+
+// canManage: trưởng bộ môn và văn phòng khoa trong phạm vi của luận văn
+func canManage(db *gorm.DB, tokenData *utils.TokenData, thesis modelThesis.Thesis) bool {
+	if tokenData.Role != modelUsers.HeadOfSubjectRole && tokenData.Role != modelUsers.FacultyOfficeRole {
+		return false
+	}
+	return organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+}
+
+func isReviewer(tokenData *utils.TokenData, assignment model.ReviewerAssignment) bool {
+	return tokenData.Role == modelUsers.AdvisorRole && assignment.ReviewerID == tokenData.ID &&
+		assignment.Status != model.AssignmentWithdrawn
+}
+
+// participates: giảng viên hướng dẫn và sinh viên của luận văn
+func participates(tokenData *utils.TokenData, thesis modelThesis.Thesis) bool {
+	switch tokenData.Role {
+	case modelUsers.AdvisorRole:
+		for _, advisor := range thesis.Advisors {
+			if advisor.ID == tokenData.ID {
+				return true
+			}
+		}
+	case modelUsers.StudentRole:
+		for _, student := range thesis.Students {
+			if student.ID == tokenData.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
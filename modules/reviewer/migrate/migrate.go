@@ -0,0 +1,16 @@
+package reviewerMigrate
+
+import (
+	"app/database"
+	model "app/modules/reviewer/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.ReviewerAssignment{})
+	db.AutoMigrate(&model.ReviewReport{})
+	db.AutoMigrate(&model.ReviewQuestion{})
+
+	return true
+}
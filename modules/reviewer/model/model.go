@@ -0,0 +1,122 @@
+package model
+
+import (
+	"app/model"
+	"time"
+)
+
+var AssignmentAssigned, AssignmentSubmitted, AssignmentWithdrawn = "ASSIGNED", "SUBMITTED", "WITHDRAWN"
+
+var ReportDraft, ReportSubmitted = "DRAFT", "SUBMITTED"
+
+var RecommendAccept, RecommendRevise, RecommendReject = "ACCEPT", "REVISE", "REJECT"
+
+// DefaultReviewDays là số ngày nộp nhận xét khi học kỳ không có hạn phản biện; MinQuestions là số câu hỏi tối thiểu cho buổi bảo vệ
+var DefaultReviewDays, MinQuestions = 14, 1
+
+// ReviewerAssignment là việc phân công một giảng viên phản biện độc lập cho luận văn.
+// Mỗi luận văn có nhiều nhất một phân công chưa rút; MatchedAreas là số lĩnh vực của đề tài
+// khớp với chuyên môn của người phản biện lúc phân công.
+type ReviewerAssignment struct {
+	model.Header
+	ThesisID     uint          `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Semester     string        `json:"semester" gorm:"column:SEMESTER;size:20;index"`
+	ReviewerID   uint          `json:"reviewerID" gorm:"column:REVIEWER_ID;index"`
+	ReviewerCode string        `json:"reviewerCode" gorm:"column:REVIEWER_CODE;size:50"`
+	FullName     string        `json:"fullName" gorm:"column:FULL_NAME"`
+	MatchedAreas int           `json:"matchedAreas" gorm:"column:MATCHED_AREAS;default:0"`
+	DueAt        time.Time     `json:"dueAt" gorm:"column:DUE_AT"`
+	Status       string        `json:"status" gorm:"column:STATUS;size:20;default:ASSIGNED"`
+	Report       *ReviewReport `json:"report,omitempty" gorm:"foreignKey:ASSIGNMENT_ID"`
+}
+
+// ReviewReport là phiếu nhận xét phản biện theo mẫu, kèm các câu hỏi đặt cho sinh viên tại buổi bảo vệ
+type ReviewReport struct {
+	model.Header
+	AssignmentID   uint             `json:"assignmentID" gorm:"column:ASSIGNMENT_ID;index"`
+	ThesisID       uint             `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Summary        string           `json:"summary" gorm:"column:SUMMARY"`
+	Strengths      string           `json:"strengths" gorm:"column:STRENGTHS"`
+	Weaknesses     string           `json:"weaknesses" gorm:"column:WEAKNESSES"`
+	Presentation   string           `json:"presentation" gorm:"column:PRESENTATION"`
+	Recommendation string           `json:"recommendation" gorm:"column:RECOMMENDATION;size:20"`
+	Status         string           `json:"status" gorm:"column:STATUS;size:20;default:DRAFT"`
+	SubmittedAt    *time.Time       `json:"submittedAt" gorm:"column:SUBMITTED_AT"`
+	Questions      []ReviewQuestion `json:"questions" gorm:"foreignKey:REPORT_ID"`
+}
+
+type ReviewQuestion struct {
+	model.Header
+	ReportID uint   `json:"reportID" gorm:"column:REPORT_ID;index"`
+	Position int    `json:"position" gorm:"column:POSITION;default:0"`
+	Question string `json:"question" gorm:"column:QUESTION"`
+}
+
+// AssignReviewer phân công phản biện cho một luận văn; ReviewerID = 0 thì tự chọn người phù hợp nhất
+type AssignReviewer struct {
+	ThesisID   uint   `json:"thesisID" validate:"required"`
+	ReviewerID uint   `json:"reviewerID"`
+	DueAt      string `json:"dueAt"`
+}
+
+// AutoAssignReviewers phân công phản biện cho mọi luận văn đã duyệt chưa có phản biện của học kỳ
+// (hoặc chỉ các luận văn trong ThesisIDs), cân bằng số luận văn mỗi giảng viên phải phản biện
+type AutoAssignReviewers struct {
+	Semester  string `json:"semester" validate:"required"`
+	ThesisIDs []uint `json:"thesisIDs"`
+	DueAt     string `json:"dueAt"`
+}
+
+type UpdateDeadline struct {
+	DueAt string `json:"dueAt" validate:"required"`
+}
+
+type SaveReviewReport struct {
+	Summary        string   `json:"summary"`
+	Strengths      string   `json:"strengths"`
+	Weaknesses     string   `json:"weaknesses"`
+	Presentation   string   `json:"presentation"`
+	Recommendation string   `json:"recommendation"`
+	Questions      []string `json:"questions"`
+	Submit         bool     `json:"submit"`
+}
+
+// Candidate là một giảng viên có thể phản biện luận văn; Load là số luận văn đang phản biện trong học kỳ
+type Candidate struct {
+	ReviewerID   uint   `json:"reviewerID"`
+	ReviewerCode string `json:"reviewerCode"`
+	FullName     string `json:"fullName"`
+	MatchedAreas int    `json:"matchedAreas"`
+	Load         int    `json:"load"`
+}
+
+// Better so sánh hai ứng viên: ưu tiên người có chuyên môn khớp, sau đó người đang phản biện ít luận văn hơn,
+// rồi người khớp nhiều lĩnh vực hơn
+func (c Candidate) Better(other Candidate) bool {
+	if (c.MatchedAreas > 0) != (other.MatchedAreas > 0) {
+		return c.MatchedAreas > 0
+	}
+	if c.Load != other.Load {
+		return c.Load < other.Load
+	}
+	if c.MatchedAreas != other.MatchedAreas {
+		return c.MatchedAreas > other.MatchedAreas
+	}
+	return c.ReviewerID < other.ReviewerID
+}
+
+func ValidRecommendation(value string) bool {
+	return value == RecommendAccept || value == RecommendRevise || value == RecommendReject
+}
+
+func (ReviewerAssignment) TableName() string {
+	return "TBL_REVIEWER_ASSIGNMENT"
+}
+
+func (ReviewReport) TableName() string {
+	return "TBL_REVIEW_REPORT"
+}
+
+func (ReviewQuestion) TableName() string {
+	return "TBL_REVIEW_QUESTION"
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"app/modules/reviewer/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitReviewerRoutes(app *fiber.App) {
+	reviewer := app.Group("/reviewer")
+
+	reviewer.Get("/candidate/:thesisID", controller.GetCandidates)
+	reviewer.Get("/assignment", controller.GetAssignments)
+	reviewer.Get("/assignment/:id", controller.GetAssignment)
+	reviewer.Post("/assignment", controller.AssignReviewer)
+	reviewer.Post("/assignment/auto", controller.AutoAssignReviewers)
+	reviewer.Put("/assignment/:id/deadline", controller.UpdateDeadline)
+	reviewer.Put("/assignment/:id/report", controller.SaveReviewReport)
+	reviewer.Delete("/assignment/:id", controller.WithdrawAssignment)
+}
@@ -9,6 +9,23 @@ import (
 	facultyOfficeRoute "app/modules/facultyOffice/routes"
 	thesisRoute "app/modules/thesis/routes"
 	usersRoute "app/modules/users/routes"
+	attachmentRoute "app/modules/attachment/routes"
+	plagiarismRoute "app/modules/plagiarism/routes"
+	researchAreaRoute "app/modules/researchArea/routes"
+	recommendationRoute "app/modules/recommendation/routes"
+	semesterRoute "app/modules/semester/routes"
+	taskTemplateRoute "app/modules/taskTemplate/routes"
+	progressLogRoute "app/modules/progressLog/routes"
+	notificationRoute "app/modules/notification/routes"
+	meetingRoute "app/modules/meeting/routes"
+	programRoute "app/modules/program/routes"
+	changeRequestRoute "app/modules/changeRequest/routes"
+	organizationRoute "app/modules/organization/routes"
+	committeeRoute "app/modules/committee/routes"
+	defenseRoute "app/modules/defense/routes"
+	gradingRoute "app/modules/grading/routes"
+	reviewerRoute "app/modules/reviewer/routes"
+	documentRoute "app/modules/document/routes"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -21,4 +38,21 @@ func InitRoutes(app *fiber.App) {
 	facultyOfficeRoute.InitFacultyOfficeRoutes(app)
 	thesisRoute.InitThesisRoutes(app)
 	usersRoute.InitUsersRoutes(app)
+	attachmentRoute.InitAttachmentRoutes(app)
+	plagiarismRoute.InitPlagiarismRoutes(app)
+	researchAreaRoute.InitResearchAreaRoutes(app)
+	recommendationRoute.InitRecommendationRoutes(app)
+	semesterRoute.InitSemesterRoutes(app)
+	taskTemplateRoute.InitTaskTemplateRoutes(app)
+	progressLogRoute.InitProgressLogRoutes(app)
+	notificationRoute.InitNotificationRoutes(app)
+	meetingRoute.InitMeetingRoutes(app)
+	programRoute.InitProgramRoutes(app)
+	changeRequestRoute.InitChangeRequestRoutes(app)
+	organizationRoute.InitOrganizationRoutes(app)
+	committeeRoute.InitCommitteeRoutes(app)
+	defenseRoute.InitDefenseRoutes(app)
+	gradingRoute.InitGradingRoutes(app)
+	reviewerRoute.InitReviewerRoutes(app)
+	documentRoute.InitDocumentRoutes(app)
 }
@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/semester/model"
+	"app/utils"
+	"strings"
+	"time"
+
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @title Semester API
+// @version 1.0
+// @description Semester calendar
+// @termsOfService http://swagger.io/terms/
+// @BasePath /semester
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetSemesters trả về lịch các học kỳ
+// @Summary Get semesters
+// @Description Get the semester calendar, newest first
+// @Tags Semester
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /semester [get]
+func GetSemesters(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	var semesters []model.Semester
+	if err := database.DB.Order("START_DATE DESC").Find(&semesters).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = semesters
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetSemester trả về một học kỳ theo mã
+// @Summary Get a semester
+// @Description Get a semester by code
+// @Tags Semester
+// @Produce json
+// @Param code path string true "Semester code, e.g. 2024A"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /semester/{code} [get]
+func GetSemester(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	semester, err := Lookup(database.DB, c.Params("code"))
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Data = semester
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateSemester thêm học kỳ vào lịch
+// @Summary Create semesters
+// @Description Create semesters (faculty office only). Dates are YYYY-MM-DD in the campus time zone.
+// @Tags Semester
+// @Accept json
+// @Produce json
+// @Param body body []model.CreateSemester true "Semesters"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /semester [post]
+func CreateSemester(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.CreateSemester
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		vItem := map[string]string{
+			"code":      strings.TrimSpace(item.Code),
+			"startDate": item.StartDate,
+			"endDate":   item.EndDate,
+		}
+		errors := utils.RequireCheck([]string{"code", "startDate", "endDate"}, vItem, map[string]string{})
+		errors = utils.MaxLengthCheck([]string{"code:20"}, vItem, errors)
+		errors = utils.DateFormatCheck([]string{"startDate", "endDate"}, vItem, errors)
+		if len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("MISSING_FIELDS")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+
+		startDate, endDate := parseDate(item.StartDate), core.EndOfDay(parseDate(item.EndDate))
+		if !startDate.Before(endDate) {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+			response.ValidateError = map[string]string{"endDate": config.GetMessageCode("INVALID_TIME_RANGE")}
+			return c.JSON(response)
+		}
+
+		semester := model.Semester{
+			Code:      strings.ToUpper(vItem["code"]),
+			Name:      item.Name,
+			StartDate: startDate,
+			EndDate:   endDate,
+		}
+		if errors := applyDeadlines(&semester, item.WithdrawalDeadline, item.TopicChangeDeadline, item.AdvisorChangeDeadline, item.ReviewDeadline); len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+		semester.ID = item.ID
+		semester.CreatedBy = tokenData.Code
+
+		if err := tx.Create(&semester).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = err.Error()
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateSemester cập nhật học kỳ
+// @Summary Update semesters
+// @Description Update semester name, dates and change request deadlines (faculty office only). Empty fields are left unchanged.
+// @Tags Semester
+// @Accept json
+// @Produce json
+// @Param body body []model.UpdateSemester true "Semesters"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /semester [put]
+func UpdateSemester(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.UpdateSemester
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		var semester model.Semester
+		if err := tx.First(&semester, item.ID).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+
+		vItem := map[string]string{"startDate": item.StartDate, "endDate": item.EndDate}
+		errors := map[string]string{}
+		if item.StartDate != "" {
+			errors = utils.DateFormatCheck([]string{"startDate"}, vItem, errors)
+		}
+		if item.EndDate != "" {
+			errors = utils.DateFormatCheck([]string{"endDate"}, vItem, errors)
+		}
+		if len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("FORMAT_DATE")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+
+		if item.Name != "" {
+			semester.Name = item.Name
+		}
+		if item.StartDate != "" {
+			semester.StartDate = parseDate(item.StartDate)
+		}
+		if item.EndDate != "" {
+			semester.EndDate = core.EndOfDay(parseDate(item.EndDate))
+		}
+		if !semester.StartDate.Before(semester.EndDate) {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+			response.ValidateError = map[string]string{"endDate": config.GetMessageCode("INVALID_TIME_RANGE")}
+			return c.JSON(response)
+		}
+		if errors := applyDeadlines(&semester, item.WithdrawalDeadline, item.TopicChangeDeadline, item.AdvisorChangeDeadline, item.ReviewDeadline); len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+		semester.UpdatedBy = tokenData.Code
+
+		if err := tx.Save(&semester).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteSemester xóa học kỳ
+// @Summary Delete a semester
+// @Description Soft delete a semester (faculty office only)
+// @Tags Semester
+// @Produce json
+// @Param id path int true "Semester ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /semester/{id} [delete]
+func DeleteSemester(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var semester model.Semester
+	if err := database.DB.First(&semester, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	if err := database.DB.Model(&semester).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// applyDeadlines gán hạn gửi yêu cầu thay đổi và hạn nộp phản biện (YYYY-MM-DD, tính hết ngày); giá trị rỗng giữ nguyên.
+// Mọi hạn phải nằm trong học kỳ.
+func applyDeadlines(semester *model.Semester, withdrawal, topicChange, advisorChange, review string) map[string]string {
+	errors := map[string]string{}
+	fields := []struct {
+		key   string
+		value string
+		dest  **time.Time
+	}{
+		{"withdrawalDeadline", withdrawal, &semester.WithdrawalDeadline},
+		{"topicChangeDeadline", topicChange, &semester.TopicChangeDeadline},
+		{"advisorChangeDeadline", advisorChange, &semester.AdvisorChangeDeadline},
+		{"reviewDeadline", review, &semester.ReviewDeadline},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			if len(utils.DateFormatCheck([]string{field.key}, map[string]string{field.key: field.value}, map[string]string{})) > 0 {
+				errors[field.key] = config.GetMessageCode("FORMAT_DATE")
+				continue
+			}
+			deadline := core.EndOfDay(parseDate(field.value))
+			*field.dest = &deadline
+		}
+		if *field.dest != nil && ((*field.dest).Before(semester.StartDate) || (*field.dest).After(semester.EndDate)) {
+			errors[field.key] = config.GetMessageCode("INVALID_TIME_RANGE")
+		}
+	}
+	return errors
+}
+
+// Lookup tìm học kỳ theo mã (không phân biệt hoa thường)
+func Lookup(db *gorm.DB, code string) (model.Semester, error) {
+	var semester model.Semester
+	err := db.Where("UPPER(CODE) = ?", strings.ToUpper(strings.TrimSpace(code))).First(&semester).Error
+	return semester, err
+}
+
+// Current trả về học kỳ đang diễn ra theo lịch
+func Current(db *gorm.DB) (model.Semester, error) {
+	var semester model.Semester
+	now := core.Now()
+	err := db.Where("START_DATE <= ? AND END_DATE >= ?", now, now).Order("START_DATE DESC").First(&semester).Error
+	return semester, err
+}
+
+// parseDate đọc ngày YYYY-MM-DD (đã kiểm tra định dạng) lúc 00:00 giờ của trường
+func parseDate(value string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02", value, core.CampusLocation())
+	return t
+}
+
+// StartFor trả về ngày bắt đầu của học kỳ code, hoặc fallback nếu học kỳ chưa có trong lịch
+func StartFor(db *gorm.DB, code string, fallback time.Time) time.Time {
+	if semester, err := Lookup(db, code); err == nil && !semester.StartDate.IsZero() {
+		return semester.StartDate
+	}
+	return fallback
+}
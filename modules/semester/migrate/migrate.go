@@ -0,0 +1,14 @@
+package semesterMigrate
+
+import (
+	"app/database"
+	model "app/modules/semester/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.Semester{})
+
+	return true
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"app/model"
+	"time"
+)
+
+// Semester là lịch học kỳ; Code trùng với giá trị SEMESTER của luận văn (vd: 2024A)
+type Semester struct {
+	model.Header
+	Code      string    `json:"code" gorm:"column:CODE;size:20;uniqueIndex"`
+	Name      string    `json:"name" gorm:"column:NAME"`
+	StartDate time.Time `json:"startDate" gorm:"column:START_DATE"`
+	EndDate   time.Time `json:"endDate" gorm:"column:END_DATE"`
+	// Hạn cuối gửi yêu cầu rút đề tài, đổi đề tài, đổi giảng viên; để trống thì tính đến hết học kỳ
+	WithdrawalDeadline    *time.Time `json:"withdrawalDeadline" gorm:"column:WITHDRAWAL_DEADLINE"`
+	TopicChangeDeadline   *time.Time `json:"topicChangeDeadline" gorm:"column:TOPIC_CHANGE_DEADLINE"`
+	AdvisorChangeDeadline *time.Time `json:"advisorChangeDeadline" gorm:"column:ADVISOR_CHANGE_DEADLINE"`
+	// Hạn nộp nhận xét phản biện mặc định khi phân công phản biện
+	ReviewDeadline *time.Time `json:"reviewDeadline" gorm:"column:REVIEW_DEADLINE"`
+}
+
+type CreateSemester struct {
+	ID                    uint   `json:"id"`
+	Code                  string `json:"code" validate:"required"`
+	Name                  string `json:"name"`
+	StartDate             string `json:"startDate" validate:"required"`
+	EndDate               string `json:"endDate" validate:"required"`
+	WithdrawalDeadline    string `json:"withdrawalDeadline"`
+	TopicChangeDeadline   string `json:"topicChangeDeadline"`
+	AdvisorChangeDeadline string `json:"advisorChangeDeadline"`
+	ReviewDeadline        string `json:"reviewDeadline"`
+}
+
+type UpdateSemester struct {
+	ID                    uint   `json:"id"`
+	Name                  string `json:"name"`
+	StartDate             string `json:"startDate"`
+	EndDate               string `json:"endDate"`
+	WithdrawalDeadline    string `json:"withdrawalDeadline"`
+	TopicChangeDeadline   string `json:"topicChangeDeadline"`
+	AdvisorChangeDeadline string `json:"advisorChangeDeadline"`
+	ReviewDeadline        string `json:"reviewDeadline"`
+}
+
+func (Semester) TableName() string {
+	return "TBL_SEMESTER"
+}
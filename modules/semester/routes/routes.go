@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"app/modules/semester/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitSemesterRoutes(app *fiber.App) {
+	semester := app.Group("/semester")
+
+	semester.Get("/", controller.GetSemesters)
+	semester.Get("/:code", controller.GetSemester)
+
+	semester.Post("/", controller.CreateSemester)
+	semester.Put("/", controller.UpdateSemester)
+	semester.Delete("/:id", controller.DeleteSemester)
+}
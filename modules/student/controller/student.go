@@ -6,6 +6,7 @@ import (
 	"app/database"
 
 	"app/modules/student/model"
+	programController "app/modules/program/controller"
 	modelUsers "app/modules/users/model"
 	"encoding/json"
 
@@ -152,6 +153,16 @@ func CreateStudent(c *fiber.Ctx) error {
 			return c.JSON(response)
 		}
 
+		if item.Program != 0 {
+			if err := programController.ValidatePrograms(tx, item.Program); err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = map[string]string{"program": err.Error()}
+				return c.JSON(response)
+			}
+		}
+
 		newUser := new(model.Student)
 		newUser.ID = item.Id
 		newUser.Code = item.Code
@@ -164,6 +175,7 @@ func CreateStudent(c *fiber.Ctx) error {
 		newUser.Address = item.Address
 		newUser.Gender = item.Gender
 		newUser.Birthday = item.Birthday
+		newUser.Program = item.Program
 		newUser.Role = modelUsers.StudentRole
 
 		password, _ := controller.HashedPassword(item.Password)
@@ -228,6 +240,16 @@ func UpdateStudent(c *fiber.Ctx) error {
 					response.Message = config.GetMessageCode("UUID_NOT_FOUND")
 					return c.JSON(response)
 				}
+				// Giữ được chương trình cũ đã ngừng, chỉ kiểm tra khi đổi chương trình
+				if item.Program != 0 && item.Program != student.Program {
+					if err := programController.ValidatePrograms(tx, item.Program); err != nil {
+						tx.Rollback()
+						response.Status = false
+						response.Message = config.GetMessageCode("PARAM_ERROR")
+						response.ValidateError = map[string]string{"program": err.Error()}
+						return c.JSON(response)
+					}
+				}
 				updateFields(&student, item)
 
 				if err := tx.Save(&student).Error; err != nil {
@@ -251,6 +273,16 @@ func UpdateStudent(c *fiber.Ctx) error {
 			newUser.Gender = item.Gender
 			newUser.Birthday = item.Birthday
 			newUser.Role = modelUsers.StudentRole
+			if item.Program != 0 {
+				if err := programController.ValidatePrograms(tx, item.Program); err != nil {
+					tx.Rollback()
+					response.Status = false
+					response.Message = config.GetMessageCode("PARAM_ERROR")
+					response.ValidateError = map[string]string{"program": err.Error()}
+					return c.JSON(response)
+				}
+				newUser.Program = item.Program
+			}
 
 			password, _ := controller.HashedPassword(item.Password)
 			newUser.Password = string(password)
@@ -435,6 +467,9 @@ func updateFields(student *model.Student, update *model.UpdateStudent) {
 	if update.Birthday != "" {
 		student.Birthday = update.Birthday
 	}
+	if update.Program != 0 {
+		student.Program = update.Program
+	}
 	if update.Password != "" {
 		password, _ := controller.HashedPassword(update.Password)
 		student.Password = string(password)
@@ -9,6 +9,7 @@ func MigrateTable() bool {
 	db := database.DB
 
 	db.AutoMigrate(&model.Student{})
+	db.AutoMigrate(&model.Interest{})
 
 	return true
 }
\ No newline at end of file
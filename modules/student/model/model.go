@@ -2,7 +2,8 @@ package model
 
 import (
 	"app/model"
-
+	modelProgram "app/modules/program/model"
+	modelResearchArea "app/modules/researchArea/model"
 )
 
 type Student struct {
@@ -10,6 +11,24 @@ type Student struct {
 	Code        string `json:"code" gorm:"column:CODE;size:10"`
 	Status      bool   `json:"status" gorm:"column:STATUS;default:false"`
 	ThesisID uint   `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Program     int    `json:"program" gorm:"column:PROGRAM"`
+	ProgramInfo *modelProgram.Program `json:"programInfo,omitempty" gorm:"foreignKey:Program;-:migration"`
+	Interests     []Interest                       `json:"interests" gorm:"foreignKey:STUDENT_ID"`
+	InterestAreas []modelResearchArea.ResearchArea `json:"interestAreas" gorm:"many2many:TBL_STUDENT_INTEREST_AREAS;joinForeignKey:STUDENT_ID;joinReferences:RESEARCH_AREA_ID"`
+}
+
+// Interest là sở thích nghiên cứu do sinh viên tự khai báo, Norm dùng để so khớp không phân biệt dấu
+type Interest struct {
+	model.Header
+	Value     string `json:"value" gorm:"column:VALUE;size:100"`
+	Norm      string `json:"-" gorm:"column:NORM;size:100;index"`
+	StudentID uint   `json:"studentID" gorm:"column:STUDENT_ID;index"`
+}
+
+type UpdateStudentInterests struct {
+	Program         *int     `json:"program"`
+	ResearchAreaIDs []uint   `json:"researchAreaIDs"`
+	Interests       []string `json:"interests"`
 }
 
 type CreateStudent struct {
@@ -25,6 +44,7 @@ type CreateStudent struct {
 	Birthday    string `json:"birthday" validate:"required"`
 	Password    string `json:"password" validate:"required"`
 	Image       string `json:"image"`
+	Program     int    `json:"program"`
 }
 
 type UpdateStudent struct {
@@ -42,9 +62,14 @@ type UpdateStudent struct {
 	Image       string `json:"image"`
 	IsDeleted   bool   `json:"isDeleted"`
 	Status      bool   `json:"status"`
+	Program     int    `json:"program"`
 }
 
 // TableName overrides the table name used by GORM to tbl_student
 func (Student) TableName() string {
 	return "tbl_student"
 }
+
+func (Interest) TableName() string {
+	return "TBL_STUDENT_INTERESTS"
+}
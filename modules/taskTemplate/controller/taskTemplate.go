@@ -0,0 +1,365 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/taskTemplate/model"
+	"app/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	semesterController "app/modules/semester/controller"
+	modelThesis "app/modules/thesis/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @title Task Template API
+// @version 1.0
+// @description Standard milestones per thesis type
+// @termsOfService http://swagger.io/terms/
+// @BasePath /task-template
+// @schemes http
+// @produce json
+// @consumes json
+
+// GetTaskTemplates trả về các mẫu công việc, có thể lọc theo loại luận văn
+// @Summary Get task templates
+// @Description Get task templates ordered by thesis type and position
+// @Tags TaskTemplate
+// @Produce json
+// @Param thesisType query int false "Thesis type"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /task-template [get]
+func GetTaskTemplates(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	query := database.DB.Order("THESIS_TYPE, POSITION, ID")
+	if thesisType, err := strconv.Atoi(c.Query("thesisType")); err == nil {
+		query = query.Where("THESIS_TYPE = ?", thesisType)
+	}
+
+	var templates []model.TaskTemplate
+	if err := query.Find(&templates).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = templates
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetTaskTemplate trả về một mẫu công việc
+// @Summary Get a task template
+// @Description Get a task template by ID
+// @Tags TaskTemplate
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /task-template/{id} [get]
+func GetTaskTemplate(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	var template model.TaskTemplate
+	if err := database.DB.First(&template, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	response.Data = template
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// CreateTaskTemplate thêm mẫu công việc cho một loại luận văn
+// @Summary Create task templates
+// @Description Create standard milestones for a thesis type (faculty office only). Offsets are in days from the semester start.
+// @Tags TaskTemplate
+// @Accept json
+// @Produce json
+// @Param body body []model.CreateTaskTemplate true "Task templates"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /task-template [post]
+func CreateTaskTemplate(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.CreateTaskTemplate
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	created := []model.TaskTemplate{}
+	for _, item := range payload {
+		if errors := validateTemplate(item.Title, item.DurationDays, item.Priority); len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("MISSING_FIELDS")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+
+		template := model.TaskTemplate{
+			ThesisType:      item.ThesisType,
+			Title:           strings.TrimSpace(item.Title),
+			Description:     item.Description,
+			Priority:        item.Priority,
+			StartOffsetDays: item.StartOffsetDays,
+			DurationDays:    item.DurationDays,
+			Position:        item.Position,
+		}
+		if template.Priority == 0 {
+			template.Priority = modelThesis.TaskPriorityMedium
+		}
+		template.CreatedBy = tokenData.Code
+
+		if err := tx.Create(&template).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = err.Error()
+			return c.JSON(response)
+		}
+		created = append(created, template)
+	}
+
+	response.Data = created
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateTaskTemplate cập nhật mẫu công việc
+// @Summary Update task templates
+// @Description Update task templates (faculty office only). Existing thesis tasks are not changed until the template is propagated.
+// @Tags TaskTemplate
+// @Accept json
+// @Produce json
+// @Param body body []model.UpdateTaskTemplate true "Task templates"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /task-template [put]
+func UpdateTaskTemplate(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload []*model.UpdateTaskTemplate
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	tx := database.DB.Begin()
+	defer tx.Commit()
+
+	for _, item := range payload {
+		var template model.TaskTemplate
+		if err := tx.First(&template, item.ID).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+
+		if item.Title != "" {
+			template.Title = strings.TrimSpace(item.Title)
+		}
+		if item.Description != "" {
+			template.Description = item.Description
+		}
+		if item.Priority != 0 {
+			template.Priority = item.Priority
+		}
+		if item.StartOffsetDays != nil {
+			template.StartOffsetDays = *item.StartOffsetDays
+		}
+		if item.DurationDays != 0 {
+			template.DurationDays = item.DurationDays
+		}
+		if item.Position != nil {
+			template.Position = *item.Position
+		}
+		if errors := validateTemplate(template.Title, template.DurationDays, template.Priority); len(errors) > 0 {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+		template.UpdatedBy = tokenData.Code
+
+		if err := tx.Save(&template).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// DeleteTaskTemplate xóa mẫu công việc
+// @Summary Delete a task template
+// @Description Soft delete a task template; tasks already created from it are kept
+// @Tags TaskTemplate
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /task-template/{id} [delete]
+func DeleteTaskTemplate(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var template model.TaskTemplate
+	if err := database.DB.First(&template, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	if err := database.DB.Model(&template).Updates(map[string]interface{}{
+		"deleted_by": tokenData.Code,
+		"deleted_at": time.Now(),
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// PropagateTaskTemplate áp dụng thay đổi của mẫu cho các công việc chưa bắt đầu
+// @Summary Propagate a task template
+// @Description Copy the template title, description, priority and relative dates to every task created from it that is still TODO. Tasks already started are left unchanged and reported as skipped.
+// @Tags TaskTemplate
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /task-template/{id}/propagate [post]
+func PropagateTaskTemplate(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var template model.TaskTemplate
+	if err := db.First(&template, c.Params("id")).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	var tasks []modelThesis.ThesisTask
+	if err := db.Where("TEMPLATE_ID = ?", template.ID).Order("ID").Find(&tasks).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	report := model.PropagationReport{TemplateID: template.ID, Updated: []uint{}, Skipped: []uint{}}
+	starts := map[uint]time.Time{}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	for _, task := range tasks {
+		if status, _ := modelThesis.NormalizeTaskStatus(task.Status); status != modelThesis.TaskStatusTodo {
+			report.Skipped = append(report.Skipped, task.ID)
+			continue
+		}
+
+		semesterStart, ok := starts[task.ThesisID]
+		if !ok {
+			var thesis modelThesis.Thesis
+			if err := tx.First(&thesis, task.ThesisID).Error; err != nil {
+				report.Skipped = append(report.Skipped, task.ID)
+				continue
+			}
+			semesterStart = semesterController.StartFor(tx, thesis.Semester, thesis.StartTime)
+			if semesterStart.IsZero() {
+				semesterStart = core.Now()
+			}
+			starts[task.ThesisID] = semesterStart
+		}
+
+		start, end := template.Schedule(semesterStart)
+		if err := tx.Model(&modelThesis.ThesisTask{}).Where("ID = ?", task.ID).Updates(map[string]interface{}{
+			"TITLE":       template.Title,
+			"DESCRIPTION": template.Description,
+			"PRIORITY":    template.Priority,
+			"START_TIME":  start,
+			"END_TIME":    end,
+			"DEADLINE_AT": end,
+			"UPDATED_BY":  tokenData.Code,
+		}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		report.Updated = append(report.Updated, task.ID)
+	}
+
+	response.Data = report
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+func validateTemplate(title string, durationDays, priority int) map[string]string {
+	errors := utils.RequireCheck([]string{"title"}, map[string]string{"title": strings.TrimSpace(title)}, map[string]string{})
+	if durationDays < 1 {
+		errors["durationDays"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	if priority != 0 && !modelThesis.ValidTaskPriority(priority) {
+		errors["priority"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	return errors
+}
@@ -0,0 +1,14 @@
+package taskTemplateMigrate
+
+import (
+	"app/database"
+	model "app/modules/taskTemplate/model"
+)
+
+func MigrateTable() bool {
+	db := database.DB
+
+	db.AutoMigrate(&model.TaskTemplate{})
+
+	return true
+}
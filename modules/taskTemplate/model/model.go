@@ -0,0 +1,60 @@
+package model
+
+import (
+	"app/core"
+	"app/model"
+	"time"
+)
+
+// TaskTemplate là mốc công việc chuẩn của một loại luận văn, thời gian tính tương đối từ ngày bắt đầu học kỳ
+type TaskTemplate struct {
+	model.Header
+	ThesisType      int    `json:"thesisType" gorm:"column:THESIS_TYPE;index"`
+	Title           string `json:"title" gorm:"column:TITLE"`
+	Description     string `json:"description" gorm:"column:DESCRIPTION"`
+	Priority        int    `json:"priority" gorm:"column:PRIORITY"`
+	StartOffsetDays int    `json:"startOffsetDays" gorm:"column:START_OFFSET_DAYS"`
+	DurationDays    int    `json:"durationDays" gorm:"column:DURATION_DAYS"`
+	Position        int    `json:"position" gorm:"column:POSITION;default:0"`
+}
+
+type CreateTaskTemplate struct {
+	ThesisType      int    `json:"thesisType" validate:"required"`
+	Title           string `json:"title" validate:"required"`
+	Description     string `json:"description"`
+	Priority        int    `json:"priority"`
+	StartOffsetDays int    `json:"startOffsetDays"`
+	DurationDays    int    `json:"durationDays" validate:"required"`
+	Position        int    `json:"position"`
+}
+
+type UpdateTaskTemplate struct {
+	ID              uint   `json:"id"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	Priority        int    `json:"priority"`
+	StartOffsetDays *int   `json:"startOffsetDays"`
+	DurationDays    int    `json:"durationDays"`
+	Position        *int   `json:"position"`
+}
+
+type PropagationReport struct {
+	TemplateID uint   `json:"templateID"`
+	Updated    []uint `json:"updated"`
+	Skipped    []uint `json:"skipped"`
+}
+
+// Schedule trả về thời gian bắt đầu (00:00) và kết thúc (23:59:59 ngày cuối) theo giờ của trường
+func (t TaskTemplate) Schedule(semesterStart time.Time) (time.Time, time.Time) {
+	day := semesterStart.In(core.CampusLocation())
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location()).AddDate(0, 0, t.StartOffsetDays)
+	duration := t.DurationDays
+	if duration < 1 {
+		duration = 1
+	}
+	return start, core.EndOfDay(start.AddDate(0, 0, duration-1))
+}
+
+func (TaskTemplate) TableName() string {
+	return "TBL_TASK_TEMPLATE"
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"app/modules/taskTemplate/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitTaskTemplateRoutes(app *fiber.App) {
+	taskTemplate := app.Group("/task-template")
+
+	taskTemplate.Get("/", controller.GetTaskTemplates)
+	taskTemplate.Get("/:id", controller.GetTaskTemplate)
+
+	taskTemplate.Post("/", controller.CreateTaskTemplate)
+	taskTemplate.Post("/:id/propagate", controller.PropagateTaskTemplate)
+	taskTemplate.Put("/", controller.UpdateTaskTemplate)
+	taskTemplate.Delete("/:id", controller.DeleteTaskTemplate)
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"strings"
+
+	researchAreaController "app/modules/researchArea/controller"
+	modell "app/modules/student/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const maxKeywords = 15
+
+// UpdateThesisClassification gắn lĩnh vực nghiên cứu và từ khóa cho một Thesis
+// @Summary Set research areas and keywords of a thesis
+// @Description Replace the research areas (from the controlled vocabulary) and free keywords of a thesis
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Param body body model.ThesisClassification true "Research areas and keywords"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/classification [put]
+func UpdateThesisClassification(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var payload model.ThesisClassification
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if tokenData.Role == modelUsers.StudentRole || (tokenData.Role == modelUsers.AdvisorRole && !thesis.HasAdvisor(tokenData.ID)) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	areas, err := researchAreaController.LoadActiveAreas(db, payload.ResearchAreaIDs)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"researchAreaIDs": config.GetMessageCode("NOT_ID_EXISTS")}
+		return c.JSON(response)
+	}
+
+	keywords := []model.Keyword{}
+	seen := map[string]bool{}
+	for _, value := range payload.Keywords {
+		value = strings.TrimSpace(value)
+		norm := utils.NormalizeText(value)
+		if norm == "" || seen[norm] {
+			continue
+		}
+		if len(value) > 100 {
+			response.Status = false
+			response.Message = config.GetMessageCode("MAX_LENGTH")
+			response.ValidateError = map[string]string{"keywords": value}
+			return c.JSON(response)
+		}
+		seen[norm] = true
+		keywords = append(keywords, model.Keyword{Value: value, Norm: norm, ThesisID: thesis.ID})
+	}
+	if len(keywords) > maxKeywords {
+		response.Status = false
+		response.Message = config.GetMessageCode("MAX_LENGTH")
+		response.ValidateError = map[string]string{"keywords": config.GetMessageCode("MAX_LENGTH")}
+		return c.JSON(response)
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Model(&thesis).Association("ResearchAreas").Replace(areas); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	if err := tx.Unscoped().Where("THESIS_ID = ?", thesis.ID).Delete(&model.Keyword{}).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if len(keywords) > 0 {
+		if err := tx.Create(&keywords).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+
+	thesis.ResearchAreas = areas
+	thesis.Keywords = keywords
+	response.Data = thesis
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ListOpenTheses trả về các đề tài đã duyệt và chưa có sinh viên đăng ký
+// @Summary List open topics
+// @Description List approved topics without students, optionally filtered by research area (code or name, sub-areas included), keyword and program eligibility. Students only see topics open to their own program.
+// @Tags Thesis
+// @Produce json
+// @Param area query string false "Research area code or name, e.g. ML or machine learning"
+// @Param keyword query string false "Keyword"
+// @Param semester query string false "Semester"
+// @Param program query int false "Study program ID; only topics open to this program"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/open [get]
+func ListOpenTheses(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	query := visibleTheses(c, db, OpenThesesQuery(db)).Preload("Missions").Preload("Programs.Catalog").Preload("Advisors").Preload("ResearchAreas").Preload("Keywords")
+
+	if semester := c.Query("semester"); semester != "" {
+		query = query.Where("SEMESTER = ?", semester)
+	}
+
+	if programID, ok := programFilter(c, db); ok {
+		query = EligibleForProgram(db, query, programID)
+	}
+
+	if area := c.Query("area"); area != "" {
+		areaIDs, err := researchAreaController.ResolveAreaIDs(db, area)
+		if err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("GET_DATA_FAIL")
+			return c.JSON(response)
+		}
+		if len(areaIDs) == 0 {
+			response.Data = []model.Thesis{}
+			response.Status = true
+			response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+			return c.JSON(response)
+		}
+		query = query.Where("ID IN (?)", db.Table("TBL_THESIS_RESEARCH_AREAS").Select("THESIS_ID").Where("RESEARCH_AREA_ID IN ?", areaIDs))
+	}
+
+	if keyword := utils.NormalizeText(c.Query("keyword")); keyword != "" {
+		query = query.Where("ID IN (?)", db.Model(&model.Keyword{}).Select("THESIS_ID").Where("NORM LIKE ?", "%"+keyword+"%"))
+	}
+
+	var theses []model.Thesis
+	if err := query.Order("ID DESC").Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = "Failed to fetch theses"
+		return c.JSON(response)
+	}
+
+	response.Data = theses
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// EligibleForProgram lọc các đề tài sinh viên thuộc chương trình programID được đăng ký:
+// đề tài không giới hạn chương trình hoặc có programID trong danh sách chương trình
+func EligibleForProgram(db *gorm.DB, query *gorm.DB, programID int) *gorm.DB {
+	restricted := db.Model(&model.Program{}).Select("THESIS_ID").Where("THESIS_ID IS NOT NULL")
+	matching := db.Model(&model.Program{}).Select("THESIS_ID").Where("VALUE = ?", programID)
+	return query.Where("(ID NOT IN (?) OR ID IN (?))", restricted, matching)
+}
+
+// ProgramAllows cho biết sinh viên thuộc chương trình program được nhận đề tài có danh sách chương trình programs:
+// cùng quy tắc với EligibleForProgram, đề tài không giới hạn chương trình nhận mọi sinh viên
+func ProgramAllows(programs []int, program int) bool {
+	if len(programs) == 0 {
+		return true
+	}
+	for _, value := range programs {
+		if value == program {
+			return true
+		}
+	}
+	return false
+}
+
+// StudentEligible kiểm tra sinh viên theo danh sách chương trình đã lưu của đề tài thesisID
+func StudentEligible(db *gorm.DB, thesisID uint, student modell.Student) bool {
+	var programs []int
+	db.Model(&model.Program{}).Where("THESIS_ID = ?", thesisID).Pluck("VALUE", &programs)
+	return ProgramAllows(programs, student.Program)
+}
+
+// programFilter trả về chương trình dùng để lọc đề tài: sinh viên đăng nhập luôn chỉ thấy đề tài
+// mở cho chương trình của mình (chưa có chương trình thì chỉ thấy đề tài không giới hạn),
+// người dùng khác lọc theo tham số program nếu có
+func programFilter(c *fiber.Ctx, db *gorm.DB) (int, bool) {
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.StudentRole {
+		programID := c.QueryInt("program")
+		return programID, programID != 0
+	}
+	var student modell.Student
+	db.Select("ID, PROGRAM").First(&student, tokenData.ID)
+	return student.Program, true
+}
+
+// OpenThesesQuery: đề tài "mở" là đề tài đã được duyệt và chưa có sinh viên nào
+func OpenThesesQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&model.Thesis{}).
+		Where("APPROVAL_STATUS = ?", model.ApprovalApproved).
+		Where("ID NOT IN (?)", db.Model(&modell.Student{}).Select("THESIS_ID").Where("THESIS_ID > 0"))
+}
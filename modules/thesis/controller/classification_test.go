@@ -0,0 +1,25 @@
+package controller
+
+import "testing"
+
+func TestProgramAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		programs []int
+		program  int
+		want     bool
+	}{
+		{"unrestricted topic", nil, 3, true},
+		{"unrestricted topic, student without program", []int{}, 0, true},
+		{"program listed", []int{2, 3}, 3, true},
+		{"program not listed", []int{2, 3}, 4, false},
+		{"student without program on restricted topic", []int{2}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProgramAllows(tt.programs, tt.program); got != tt.want {
+				t.Errorf("ProgramAllows(%v, %d) = %v, want %v", tt.programs, tt.program, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"strings"
+	"time"
+
+	modelll "app/modules/advisor/model"
+	programController "app/modules/program/controller"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const lineageMaxDepth = 50
+
+// CloneThesis nhân bản một đề tài sang học kỳ mới dưới dạng bản nháp
+// @Summary Clone a thesis topic into a new semester
+// @Description Copy titles, info, subject, missions, programs and tasks (with their subtasks and dependencies) into a draft thesis for the target semester. Students, advisors and approval state are not copied; an advisor who clones the topic becomes the advisor of the clone. Task dates are shifted by the difference between the old and new start time.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Source thesis UUID"
+// @Param body body model.CloneThesis true "Target semester"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/clone [post]
+func CloneThesis(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+	if tokenData.Role == modelUsers.StudentRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.CloneThesis
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	if errors := utils.RequireCheck([]string{"semester"}, map[string]string{"semester": strings.TrimSpace(payload.Semester)}, map[string]string{}); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	var source model.Thesis
+	if err := db.Preload("Missions").Preload("Programs").Preload("ThesisTask.Dependencies").Preload("Advisors").First(&source, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if tokenData.Role == modelUsers.AdvisorRole && !source.HasAdvisor(tokenData.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	startTime, endTime := payload.StartTime, payload.EndTime
+	var shift time.Duration
+	if !startTime.IsZero() && !source.StartTime.IsZero() {
+		shift = startTime.Sub(source.StartTime)
+	}
+	if startTime.IsZero() {
+		startTime = shiftTime(source.StartTime, shift)
+	}
+	if endTime.IsZero() {
+		endTime = shiftTime(source.EndTime, shift)
+	}
+
+	sourceID := source.ID
+	clone := model.Thesis{
+		TitleVi:        firstNonEmpty(payload.TitleVi, source.TitleVi),
+		TitleEn:        firstNonEmpty(payload.TitleEn, source.TitleEn),
+		ApprovalStatus: model.ApprovalDraft,
+		ThesisType:     source.ThesisType,
+		Semester:       payload.Semester,
+		UserRoleOwner:  source.UserRoleOwner,
+		ThesisInfo:     source.ThesisInfo,
+		SubjectID:      source.SubjectID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		SourceThesisID: &sourceID,
+	}
+	clone.CreatedBy = tokenData.Code
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Create(&clone).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = "Failed to create thesis"
+		return c.JSON(response)
+	}
+
+	// Giảng viên nhân bản đề tài trở thành giảng viên hướng dẫn của bản sao
+	if tokenData.Role == modelUsers.AdvisorRole {
+		var advisor modelll.Advisor
+		if err := tx.First(&advisor, tokenData.ID).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Advisor not found"
+			return c.JSON(response)
+		}
+		if err := tx.Model(&clone).Association("Advisors").Append(&advisor); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Failed to add advisor to thesis"
+			return c.JSON(response)
+		}
+	}
+
+	for _, mission := range source.Missions {
+		// Chỉ sao chép yêu cầu của sản phẩm, không sao chép minh chứng và kết quả nghiệm thu
+		newMission := model.Mission{
+			Value:          mission.Value,
+			ThesisID:       clone.ID,
+			Description:    mission.Description,
+			DueDate:        shiftDeadline(mission.DueDate, shift),
+			EvidenceType:   mission.EvidenceType,
+			SubmissionType: mission.SubmissionType,
+			Status:         model.DeliverablePending,
+		}
+		if err := tx.Create(&newMission).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Failed to create mission"
+			return c.JSON(response)
+		}
+	}
+
+	for _, program := range source.Programs {
+		// Chương trình đã ngừng không được chuyển sang đề tài mới
+		if programController.ValidatePrograms(tx, program.Value) != nil {
+			continue
+		}
+		newProgram := model.Program{Value: program.Value, ThesisID: clone.ID}
+		if err := tx.Create(&newProgram).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Failed to create program"
+			return c.JSON(response)
+		}
+	}
+
+	// Công việc được sao chép như khuôn mẫu: trạng thái về ban đầu, thời gian dời theo học kỳ mới,
+	// công việc cha và phụ thuộc được ánh xạ sang các công việc mới
+	if err := cloneTasks(tx, source.ThesisTask, clone.ID, shift); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = "Failed to create thesis task"
+		return c.JSON(response)
+	}
+
+	if err := tx.Preload("Missions").Preload("Programs").Preload("ThesisTask").First(&clone, clone.ID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = clone
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// GetThesisLineage trả về chuỗi nhân bản của một đề tài qua các học kỳ
+// @Summary Get the clone lineage of a thesis
+// @Description Get the topics this thesis was cloned from (oldest first) and every topic cloned from it. Only topics within the caller's scope are listed; callers without a token only see approved topics.
+// @Tags Thesis
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/lineage [get]
+func GetThesisLineage(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var thesis model.Thesis
+	if err := db.Preload("Students").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+	if !canViewThesis(c, db, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	visible := func(other *model.Thesis) bool { return canViewThesis(c, db, other) }
+
+	lineage := model.ThesisLineage{Ancestors: []model.ThesisLineageNode{}}
+
+	visited := map[uint]bool{thesis.ID: true}
+	current := thesis
+	for depth := 0; current.SourceThesisID != nil && depth < lineageMaxDepth; depth++ {
+		var parent model.Thesis
+		if err := db.Preload("Students").First(&parent, *current.SourceThesisID).Error; err != nil || visited[parent.ID] || !visible(&parent) {
+			break
+		}
+		visited[parent.ID] = true
+		lineage.Ancestors = append([]model.ThesisLineageNode{lineageNode(parent)}, lineage.Ancestors...)
+		current = parent
+	}
+
+	root, err := lineageTree(db, thesis, visited, visible, 0)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+	lineage.Thesis = root
+
+	response.Data = lineage
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// lineageTree dựng cây các đề tài nhân bản từ thesis, bỏ qua nhánh người gọi không được xem
+func lineageTree(db *gorm.DB, thesis model.Thesis, visited map[uint]bool, visible func(*model.Thesis) bool, depth int) (model.ThesisLineageNode, error) {
+	node := lineageNode(thesis)
+	if depth >= lineageMaxDepth {
+		return node, nil
+	}
+
+	var clones []model.Thesis
+	if err := db.Preload("Students").Where("SOURCE_THESIS_ID = ?", thesis.ID).Order("ID").Find(&clones).Error; err != nil {
+		return node, err
+	}
+	for i := range clones {
+		clone := clones[i]
+		if visited[clone.ID] || !visible(&clone) {
+			continue
+		}
+		visited[clone.ID] = true
+		child, err := lineageTree(db, clone, visited, visible, depth+1)
+		if err != nil {
+			return node, err
+		}
+		node.Clones = append(node.Clones, child)
+	}
+	return node, nil
+}
+
+func lineageNode(thesis model.Thesis) model.ThesisLineageNode {
+	return model.ThesisLineageNode{
+		ID:             thesis.ID,
+		TitleVi:        thesis.TitleVi,
+		TitleEn:        thesis.TitleEn,
+		Semester:       thesis.Semester,
+		ApprovalStatus: thesis.ApprovalStatus,
+		StudentCount:   len(thesis.Students),
+		Clones:         []model.ThesisLineageNode{},
+	}
+}
+
+func cloneTasks(tx *gorm.DB, tasks []model.ThesisTask, thesisID uint, shift time.Duration) error {
+	ids := map[uint]uint{}
+	for _, task := range tasks {
+		newTask := model.ThesisTask{
+			Title:       task.Title,
+			Deadline:    shiftDeadline(task.Deadline, shift),
+			Status:      model.TaskStatusTodo,
+			Priority:    task.Priority,
+			Description: task.Description,
+			StartTime:   shiftTime(task.StartTime, shift),
+			EndTime:     shiftTime(task.EndTime, shift),
+			Position:    task.Position,
+			ThesisID:    thesisID,
+		}
+		if err := tx.Omit(clause.Associations).Create(&newTask).Error; err != nil {
+			return err
+		}
+		ids[task.ID] = newTask.ID
+	}
+
+	for _, task := range tasks {
+		if task.ParentID == nil {
+			continue
+		}
+		if parentID, ok := ids[*task.ParentID]; ok {
+			if err := tx.Model(&model.ThesisTask{}).Where("ID = ?", ids[task.ID]).UpdateColumn("PARENT_ID", parentID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		for _, dependency := range task.Dependencies {
+			dependsOnID, ok := ids[dependency.DependsOnID]
+			if !ok {
+				continue
+			}
+			if err := tx.Create(&model.TaskDependency{TaskID: ids[task.ID], DependsOnID: dependsOnID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func shiftTime(t time.Time, shift time.Duration) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(shift)
+}
+
+func shiftDeadline(deadline *time.Time, shift time.Duration) *time.Time {
+	if deadline == nil {
+		return nil
+	}
+	shifted := deadline.Add(shift)
+	return &shifted
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"strings"
+	"time"
+
+	thesisMigrate "app/modules/thesis/migrate"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseDeadline đọc hạn chót người dùng nhập theo giờ của trường, chuỗi rỗng nghĩa là không có hạn
+func parseDeadline(value string) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	deadline, err := core.ParseCampusTime(value)
+	if err != nil {
+		return nil, err
+	}
+	return &deadline, nil
+}
+
+// validateTaskTimes kiểm tra bắt đầu < kết thúc, hạn chót không trước lúc bắt đầu
+// và mọi mốc đều không vượt quá thời gian kết thúc của luận văn
+func validateTaskTimes(task *model.ThesisTask, thesis *model.Thesis) map[string]string {
+	errors := map[string]string{}
+	invalid := config.GetMessageCode("INVALID_TIME_RANGE")
+
+	if !task.StartTime.IsZero() && !task.EndTime.IsZero() && !task.StartTime.Before(task.EndTime) {
+		errors["endTime"] = invalid
+	}
+	if task.Deadline != nil && !task.StartTime.IsZero() && task.Deadline.Before(task.StartTime) {
+		errors["deadline"] = invalid
+	}
+	if !thesis.EndTime.IsZero() {
+		if !task.EndTime.IsZero() && task.EndTime.After(thesis.EndTime) {
+			errors["endTime"] = invalid
+		}
+		if task.Deadline != nil && task.Deadline.After(thesis.EndTime) {
+			errors["deadline"] = invalid
+		}
+	}
+	if !thesis.StartTime.IsZero() && !task.StartTime.IsZero() && task.StartTime.Before(thesis.StartTime) {
+		errors["startTime"] = invalid
+	}
+	return errors
+}
+
+// MigrateTaskDeadlines chạy lại việc chuyển đổi hạn chót cũ và trả về các dòng không đọc được
+// @Summary Migrate legacy task deadlines
+// @Description Convert free-form task deadlines into timestamps in the campus time zone and report every row that could not be parsed, including rows reported before (faculty office only)
+// @Tags Thesis
+// @Produce json
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/task/migrate-deadlines [post]
+func MigrateTaskDeadlines(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.FacultyOfficeRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	report, err := thesisMigrate.MigrateTaskDeadlines(database.DB, true)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = report
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
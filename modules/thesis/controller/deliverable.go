@@ -0,0 +1,441 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"fmt"
+	"strings"
+
+	modelAttachment "app/modules/attachment/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetThesisDeliverables trả về các sản phẩm bàn giao và tình trạng nghiệm thu
+// @Summary Get the deliverables of a thesis
+// @Description List deliverables with their evidence and acceptance status, and whether every deliverable has been accepted
+// @Tags Thesis
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/deliverables [get]
+func GetThesisDeliverables(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canWorkOnThesis(db, tokenData, &thesis) && tokenData.Role != modelUsers.CouncilRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	summary, err := SummarizeDeliverables(db, thesis.ID)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = summary
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateDeliverable sửa yêu cầu của một sản phẩm bàn giao
+// @Summary Update a deliverable
+// @Description Change the title, description, due date and required evidence of a deliverable (advisor of the thesis, head of subject or faculty office). Accepted deliverables cannot be changed.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param body body model.UpdateMission true "Deliverable"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/mission/{id} [put]
+func UpdateDeliverable(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.UpdateMission
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	tokenData, mission, thesis, message := loadDeliverable(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if tokenData.Role == modelUsers.StudentRole || !canWorkOnThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if mission.IsMet() {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	if errors := applyDeliverable(&mission, payload.Value, payload.Description, payload.DueDate, payload.EvidenceType, payload.SubmissionType); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+	mission.UpdatedBy = tokenData.Code
+
+	if err := db.Save(&mission).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = mission
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// SubmitDeliverableEvidence sinh viên gắn minh chứng và gửi sản phẩm để nghiệm thu
+// @Summary Submit evidence for a deliverable
+// @Description A student of the thesis links the required evidence (an attachment of the thesis, of the required submission type if set, or a task of the thesis that is done) and submits the deliverable for acceptance. The advisors are notified.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param body body model.SubmitDeliverableEvidence true "Evidence"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/mission/{id}/evidence [put]
+func SubmitDeliverableEvidence(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.SubmitDeliverableEvidence
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	tokenData, mission, thesis, message := loadDeliverable(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if tokenData.Role != modelUsers.StudentRole || !thesis.HasStudent(tokenData.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if mission.IsMet() {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	mission.AttachmentID = payload.AttachmentID
+	mission.TaskID = payload.TaskID
+	if errors := checkEvidence(db, &mission); len(errors) > 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = errors
+		return c.JSON(response)
+	}
+
+	now := core.Now()
+	mission.Status = model.DeliverableSubmitted
+	mission.SubmittedAt = &now
+	mission.UpdatedBy = tokenData.Code
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Save(&mission).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	recipients := []modelNotification.Recipient{}
+	for _, advisor := range thesis.Advisors {
+		recipients = append(recipients, modelNotification.Recipient{UserID: advisor.ID, Role: modelUsers.AdvisorRole})
+	}
+	notification := modelNotification.Message{
+		Type:  modelNotification.NotificationDeliverableSubmitted,
+		Title: "Deliverable submitted",
+		Body:  fmt.Sprintf("\"%s\" of \"%s\" is waiting for acceptance", mission.Value, thesis.TitleVi),
+		Link:  fmt.Sprintf("/thesis/mission/%d", mission.ID),
+	}
+	if err := notificationController.Notify(tx, notification, recipients...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = mission
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// ReviewDeliverable giảng viên hướng dẫn nghiệm thu hoặc trả lại sản phẩm
+// @Summary Accept or reject a deliverable
+// @Description An advisor of the thesis accepts (ACCEPTED) or returns (REJECTED) a submitted deliverable with a comment. Deliverables that need no evidence can be reviewed directly. The students are notified.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param body body model.ReviewDeliverable true "Review"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/mission/{id}/review [put]
+func ReviewDeliverable(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.ReviewDeliverable
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	tokenData, mission, thesis, message := loadDeliverable(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if tokenData.Role != modelUsers.AdvisorRole || !thesis.HasAdvisor(tokenData.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	status := strings.ToUpper(strings.TrimSpace(payload.Status))
+	if status != model.DeliverableAccepted && status != model.DeliverableRejected {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"status": config.GetMessageCode("PARAM_ERROR")}
+		return c.JSON(response)
+	}
+	reviewable := mission.Status == model.DeliverableSubmitted ||
+		(mission.EvidenceType == model.EvidenceNone && mission.Status != model.DeliverableAccepted)
+	if !reviewable {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	// Minh chứng có thể đã bị xóa hoặc công việc bị mở lại sau khi nộp
+	if status == model.DeliverableAccepted {
+		if errors := checkEvidence(db, &mission); len(errors) > 0 {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = errors
+			return c.JSON(response)
+		}
+	}
+
+	now := core.Now()
+	mission.Status = status
+	mission.ReviewedBy = tokenData.Code
+	mission.ReviewedAt = &now
+	mission.ReviewComment = payload.Comment
+	mission.UpdatedBy = tokenData.Code
+	mission.Overdue = !mission.IsMet() && mission.DueDate != nil && mission.DueDate.Before(now)
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if err := tx.Save(&mission).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	recipients := []modelNotification.Recipient{}
+	for _, student := range thesis.Students {
+		recipients = append(recipients, modelNotification.Recipient{UserID: student.ID, Role: modelUsers.StudentRole})
+	}
+	notification := modelNotification.Message{
+		Type:  modelNotification.NotificationDeliverableReviewed,
+		Title: "Deliverable " + strings.ToLower(status),
+		Body:  fmt.Sprintf("\"%s\" was %s", mission.Value, strings.ToLower(status)),
+		Link:  fmt.Sprintf("/thesis/mission/%d", mission.ID),
+	}
+	if payload.Comment != "" {
+		notification.Body += ": " + payload.Comment
+	}
+	if err := notificationController.Notify(tx, notification, recipients...); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = mission
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// SummarizeDeliverables đếm sản phẩm theo trạng thái; AllMet khi mọi sản phẩm đã được nghiệm thu
+func SummarizeDeliverables(db *gorm.DB, thesisID uint) (model.DeliverableSummary, error) {
+	summary := model.DeliverableSummary{ThesisID: thesisID, Unmet: []model.Mission{}, Items: []model.Mission{}}
+
+	var missions []model.Mission
+	if err := db.Where("THESIS_ID = ?", thesisID).Order("DUE_AT, ID").Find(&missions).Error; err != nil {
+		return summary, err
+	}
+
+	for _, mission := range missions {
+		summary.Total++
+		switch mission.Status {
+		case model.DeliverableAccepted:
+			summary.Accepted++
+		case model.DeliverableSubmitted:
+			summary.Submitted++
+		case model.DeliverableRejected:
+			summary.Rejected++
+		default:
+			summary.Pending++
+		}
+		if mission.Overdue {
+			summary.Overdue++
+		}
+		if !mission.IsMet() {
+			summary.Unmet = append(summary.Unmet, mission)
+		}
+	}
+	summary.Items = missions
+	summary.AllMet = summary.Accepted == summary.Total
+	return summary, nil
+}
+
+// applyDeliverable kiểm tra và gán yêu cầu của sản phẩm bàn giao
+func applyDeliverable(mission *model.Mission, title, description, dueDate, evidenceType, submissionType string) map[string]string {
+	errors := utils.RequireCheck([]string{"value"}, map[string]string{"value": strings.TrimSpace(title)}, map[string]string{})
+
+	due, err := parseDeadline(dueDate)
+	if err != nil {
+		errors["dueDate"] = config.GetMessageCode("FORMAT_DATETIME")
+	}
+
+	evidenceType = strings.ToUpper(strings.TrimSpace(evidenceType))
+	if evidenceType == "" {
+		evidenceType = model.EvidenceNone
+	}
+	if evidenceType != model.EvidenceNone && evidenceType != model.EvidenceAttachment && evidenceType != model.EvidenceTask {
+		errors["evidenceType"] = config.GetMessageCode("PARAM_ERROR")
+	}
+
+	submissionType = strings.ToUpper(strings.TrimSpace(submissionType))
+	if submissionType != "" {
+		if _, ok := modelAttachment.SubmissionLimits[submissionType]; !ok || evidenceType != model.EvidenceAttachment {
+			errors["submissionType"] = config.GetMessageCode("PARAM_ERROR")
+		}
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	mission.Value = strings.TrimSpace(title)
+	mission.Description = description
+	mission.DueDate = due
+	mission.EvidenceType = evidenceType
+	mission.SubmissionType = submissionType
+	if mission.Status == "" {
+		mission.Status = model.DeliverablePending
+	}
+	return errors
+}
+
+// checkEvidence kiểm tra minh chứng đã gắn đáp ứng yêu cầu của sản phẩm
+func checkEvidence(db *gorm.DB, mission *model.Mission) map[string]string {
+	errors := map[string]string{}
+
+	switch mission.EvidenceType {
+	case model.EvidenceAttachment:
+		if mission.AttachmentID == nil {
+			errors["attachmentID"] = config.GetMessageCode("REQUIRE")
+			return errors
+		}
+	case model.EvidenceTask:
+		if mission.TaskID == nil {
+			errors["taskID"] = config.GetMessageCode("REQUIRE")
+			return errors
+		}
+	}
+
+	if mission.AttachmentID != nil {
+		var attachment modelAttachment.Attachment
+		if err := db.Where("THESIS_ID = ?", mission.ThesisID).First(&attachment, *mission.AttachmentID).Error; err != nil {
+			errors["attachmentID"] = config.GetMessageCode("NOT_ID_EXISTS")
+		} else if mission.SubmissionType != "" && attachment.SubmissionType != mission.SubmissionType {
+			errors["attachmentID"] = config.GetMessageCode("FILE_TYPE_NOT_ALLOWED")
+		}
+	}
+	if mission.TaskID != nil {
+		var task model.ThesisTask
+		if err := db.Where("THESIS_ID = ?", mission.ThesisID).First(&task, *mission.TaskID).Error; err != nil {
+			errors["taskID"] = config.GetMessageCode("NOT_ID_EXISTS")
+		} else if !task.IsDone() {
+			errors["taskID"] = config.GetMessageCode("TASK_BLOCKED")
+		}
+	}
+	return errors
+}
+
+// loadDeliverable tìm sản phẩm theo :id cùng luận văn (kèm sinh viên và giảng viên)
+func loadDeliverable(c *fiber.Ctx, db *gorm.DB) (*utils.TokenData, model.Mission, model.Thesis, string) {
+	var mission model.Mission
+	var thesis model.Thesis
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		return nil, mission, thesis, config.GetMessageCode("TOKEN_INCORRECT")
+	}
+	if err := db.First(&mission, c.Params("id")).Error; err != nil {
+		return tokenData, mission, thesis, config.GetMessageCode("NOT_ID_EXISTS")
+	}
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, mission.ThesisID).Error; err != nil {
+		return tokenData, mission, thesis, "Thesis not found"
+	}
+	return tokenData, mission, thesis, ""
+}
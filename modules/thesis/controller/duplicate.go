@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/similarity"
+	"app/utils"
+	"sort"
+	"strings"
+	"time"
+
+	organizationController "app/modules/organization/controller"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	duplicateThreshold     = 0.5
+	duplicateLookbackYears = 2
+	duplicateMaxWarnings   = 10
+	duplicateTitleWeight   = 0.6
+)
+
+// detectDuplicates so sánh tiêu đề và mô tả của luận văn với các luận văn đang thực hiện
+// hoặc được tạo trong vài năm gần đây, lưu lại và trả về danh sách đề tài gần giống
+func detectDuplicates(db *gorm.DB, thesis *model.Thesis) ([]model.ThesisDuplicate, error) {
+	since := time.Now().AddDate(-duplicateLookbackYears, 0, 0)
+
+	var others []model.Thesis
+	if err := db.Where("ID <> ?", thesis.ID).
+		Where("END_TIME >= ? OR CREATED_AT >= ?", time.Now(), since).
+		Where("APPROVAL_STATUS <> ?", model.ApprovalRejected).
+		Find(&others).Error; err != nil {
+		return nil, err
+	}
+
+	// Dùng chỉ mục MinHash để lọc nhanh ứng viên trước khi tính điểm từng trường
+	index := similarity.NewIndex(2)
+	for _, other := range others {
+		index.Add(similarity.Document{ID: other.ID, ThesisID: other.ID, Text: duplicateText(&other)})
+	}
+	candidates := map[uint]bool{}
+	for _, match := range index.Query(duplicateText(thesis), similarity.QueryOptions{MinScore: duplicateThreshold / 2}) {
+		candidates[match.ID] = true
+	}
+
+	warnings := []model.ThesisDuplicate{}
+	for _, other := range others {
+		if !candidates[other.ID] || sameLineage(thesis, &other) {
+			continue
+		}
+		titleScore := similarity.Similarity(thesis.TitleVi, other.TitleVi, 2)
+		if s := similarity.Similarity(thesis.TitleEn, other.TitleEn, 2); s > titleScore {
+			titleScore = s
+		}
+		score := titleScore
+		infoScore := 0.0
+		if strings.TrimSpace(thesis.ThesisInfo) != "" && strings.TrimSpace(other.ThesisInfo) != "" {
+			infoScore = similarity.Similarity(thesis.ThesisInfo, other.ThesisInfo, 3)
+			score = duplicateTitleWeight*titleScore + (1-duplicateTitleWeight)*infoScore
+		}
+		if score < duplicateThreshold {
+			continue
+		}
+		warnings = append(warnings, model.ThesisDuplicate{
+			ThesisID:      thesis.ID,
+			DuplicateOfID: other.ID,
+			TitleVi:       other.TitleVi,
+			TitleEn:       other.TitleEn,
+			Semester:      other.Semester,
+			Score:         score,
+			TitleScore:    titleScore,
+			InfoScore:     infoScore,
+		})
+	}
+
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Score > warnings[j].Score })
+	if len(warnings) > duplicateMaxWarnings {
+		warnings = warnings[:duplicateMaxWarnings]
+	}
+
+	if err := db.Unscoped().Where("THESIS_ID = ?", thesis.ID).Delete(&model.ThesisDuplicate{}).Error; err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		if err := db.Create(&warnings).Error; err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// Đề tài nhân bản trực tiếp từ nhau là chủ đích, không cảnh báo trùng
+func sameLineage(a, b *model.Thesis) bool {
+	return (a.SourceThesisID != nil && *a.SourceThesisID == b.ID) || (b.SourceThesisID != nil && *b.SourceThesisID == a.ID)
+}
+
+func duplicateText(thesis *model.Thesis) string {
+	return strings.Join([]string{thesis.TitleVi, thesis.TitleEn, thesis.ThesisInfo}, "\n")
+}
+
+// GetThesisDuplicates trả về danh sách đề tài gần giống của một Thesis
+// @Summary Get near-duplicate topics of a thesis
+// @Description Get the near-duplicate warnings recorded when the thesis was created or updated. Signed-in callers within the scope of the thesis.
+// @Tags Thesis
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/duplicates [get]
+func GetThesisDuplicates(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	if _, err := utils.ExtractTokenData(c); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+	if !canViewThesis(c, db, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var warnings []model.ThesisDuplicate
+	if err := db.Where("THESIS_ID = ?", thesis.ID).Order("SCORE DESC").Find(&warnings).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = model.ThesisDuplicateReport{ThesisID: thesis.ID, Warnings: warnings}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// RequireDuplicateOverride bật/tắt yêu cầu xác nhận trùng đề tài trước khi duyệt
+// @Summary Require a duplicate override before approval
+// @Description Head of subject of the thesis marks it so it cannot be approved until the near-duplicate warning is explicitly overridden
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param body body model.RequireDuplicateOverride true "Thesis and flag"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/duplicate/require [put]
+func RequireDuplicateOverride(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.HeadOfSubjectRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.RequireDuplicateOverride
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.First(&thesis, payload.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+	if !organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	updates := map[string]interface{}{
+		"DUPLICATE_OVERRIDE_REQUIRED": payload.Required,
+		"UPDATED_BY":                  tokenData.Code,
+	}
+	// Yêu cầu mới phải được xác nhận lại từ đầu
+	if payload.Required {
+		updates["DUPLICATE_OVERRIDDEN"] = false
+		updates["DUPLICATE_OVERRIDE_BY"] = ""
+		updates["DUPLICATE_OVERRIDE_NOTE"] = ""
+	}
+	if err := db.Model(&thesis).Updates(updates).Error; err != nil {
+		response.Status = false
+		response.Message = "Update error"
+		return c.JSON(response)
+	}
+
+	response.Data = thesis
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// OverrideDuplicate xác nhận đề tài không trùng để cho phép duyệt
+// @Summary Override a near-duplicate warning
+// @Description Head of subject of the thesis confirms a thesis flagged as requiring an override may be approved despite near-duplicate topics. Only theses that are not approved or rejected yet.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param body body model.DuplicateOverride true "Thesis and justification"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/duplicate/override [put]
+func OverrideDuplicate(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || tokenData.Role != modelUsers.HeadOfSubjectRole {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var payload model.DuplicateOverride
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	if strings.TrimSpace(payload.Note) == "" {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"note": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.First(&thesis, payload.ThesisID).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+	if !organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	if !thesis.DuplicateOverrideRequired || thesis.ApprovalStatus == model.ApprovalApproved || thesis.ApprovalStatus == model.ApprovalRejected {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		return c.JSON(response)
+	}
+
+	if err := db.Model(&thesis).Updates(map[string]interface{}{
+		"DUPLICATE_OVERRIDDEN":    true,
+		"DUPLICATE_OVERRIDE_BY":   tokenData.Code,
+		"DUPLICATE_OVERRIDE_NOTE": payload.Note,
+	}).Error; err != nil {
+		response.Status = false
+		response.Message = "Update error"
+		return c.JSON(response)
+	}
+
+	response.Data = thesis
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
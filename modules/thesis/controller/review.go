@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"fmt"
+	"strings"
+
+	modelll "app/modules/advisor/model"
+	notificationController "app/modules/notification/controller"
+	modelNotification "app/modules/notification/model"
+	organizationController "app/modules/organization/controller"
+	modelOrganization "app/modules/organization/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const reviewQueueDefaultLimit, reviewQueueMaxLimit, maxBulkReview = 50, 200, 500
+
+// reviewDecisions ánh xạ trạng thái duyệt sang quyết định tương ứng của hàng đợi duyệt
+var reviewDecisions = map[int]string{
+	model.ApprovalApproved: model.ReviewApprove,
+	model.ApprovalRejected: model.ReviewReject,
+	model.ApprovalRevision: model.ReviewRevise,
+}
+
+// GetReviewQueue trả về các đề tài đang chờ duyệt thuộc phạm vi của trưởng bộ môn
+// @Summary Topic review queue
+// @Description Pending topics, oldest first. Heads of subject only see topics of their own subject; the faculty office sees the topics of its faculty.
+// @Tags Thesis
+// @Produce json
+// @Param semester query string false "Semester"
+// @Param thesisType query int false "Thesis type"
+// @Param program query int false "Study program ID"
+// @Param advisor query int false "Advisor ID"
+// @Param duplicate query bool false "Only topics flagged as near-duplicates"
+// @Param q query string false "Text in the Vietnamese or English title"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/review-queue [get]
+func GetReviewQueue(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+	if !canReview(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	scope := organizationController.ScopeOf(db, tokenData)
+	query := scope.Apply(db.Model(&model.Thesis{}).Where("APPROVAL_STATUS = ?", model.ApprovalPending), "SUBJECT_ID")
+	if semester := c.Query("semester"); semester != "" {
+		query = query.Where("SEMESTER = ?", semester)
+	}
+	if thesisType := c.QueryInt("thesisType"); thesisType != 0 {
+		query = query.Where("THESIS_TYPE = ?", thesisType)
+	}
+	if programID := c.QueryInt("program"); programID != 0 {
+		query = query.Where("ID IN (?)", db.Model(&model.Program{}).Select("THESIS_ID").Where("VALUE = ?", programID))
+	}
+	if advisorID := c.QueryInt("advisor"); advisorID != 0 {
+		query = query.Where("ID IN (?)", db.Model(&modelll.Advisor{}).Select("THESIS_ID").Where("ID = ?", advisorID))
+	}
+	if c.Query("duplicate") == "true" {
+		query = query.Where("DUPLICATE_OVERRIDE_REQUIRED = ? AND DUPLICATE_OVERRIDDEN = ?", true, false)
+	}
+	if q := strings.ToUpper(strings.TrimSpace(c.Query("q"))); q != "" {
+		query = query.Where("(UPPER(TITLE_VI) LIKE ? OR UPPER(TITLE_EN) LIKE ?)", "%"+q+"%", "%"+q+"%")
+	}
+
+	var queue model.ReviewQueue
+	if err := query.Count(&queue.Total).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	limit := c.QueryInt("limit", reviewQueueDefaultLimit)
+	if limit <= 0 || limit > reviewQueueMaxLimit {
+		limit = reviewQueueDefaultLimit
+	}
+	offset := c.QueryInt("offset")
+	if offset < 0 {
+		offset = 0
+	}
+
+	queue.Items = []model.Thesis{}
+	if err := query.Preload("Programs.Catalog").Preload("Advisors").Preload("Students").Preload("Keywords").
+		Order("UPDATED_AT, ID").Limit(limit).Offset(offset).Find(&queue.Items).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	response.Data = queue
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// BulkReviewTheses duyệt, từ chối hoặc trả về sửa nhiều đề tài cùng lúc
+// @Summary Bulk decisions on pending topics
+// @Description Approve (APPROVE), reject (REJECT) or send back for revision (REVISE) several pending topics, each with its own comment (required when rejecting or asking for revision). Every item is processed on its own and the response reports success or failure per topic.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param body body []model.ReviewDecision true "Decisions"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/review [put]
+func BulkReviewTheses(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+	if !canReview(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+	scope := organizationController.ScopeOf(db, tokenData)
+
+	var payload []model.ReviewDecision
+	if err := c.BodyParser(&payload); err != nil || len(payload) == 0 || len(payload) > maxBulkReview {
+		response.Status = false
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		return c.JSON(response)
+	}
+
+	// Kết quả từng đề tài nằm trong Data; Status chỉ cho biết yêu cầu đã được xử lý
+	results := make([]model.ReviewDecisionResult, 0, len(payload))
+	for _, item := range payload {
+		results = append(results, reviewThesis(db, tokenData, scope, item))
+	}
+
+	response.Data = results
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// reviewThesis xử lý một quyết định trong giao dịch riêng để lỗi của đề tài này không ảnh hưởng đề tài khác
+func reviewThesis(db *gorm.DB, tokenData *utils.TokenData, scope modelOrganization.Scope, item model.ReviewDecision) model.ReviewDecisionResult {
+	result := model.ReviewDecisionResult{ThesisID: item.ThesisID}
+
+	decision := strings.ToUpper(strings.TrimSpace(item.Decision))
+	status := 0
+	switch decision {
+	case model.ReviewApprove:
+		status = model.ApprovalApproved
+	case model.ReviewReject:
+		status = model.ApprovalRejected
+	case model.ReviewRevise:
+		status = model.ApprovalRevision
+	default:
+		result.Message = config.GetMessageCode("PARAM_ERROR")
+		return result
+	}
+	comment := strings.TrimSpace(item.Comment)
+	if decision != model.ReviewApprove && comment == "" {
+		result.Message = config.GetMessageCode("REQUIRE")
+		return result
+	}
+
+	tx := db.Begin()
+	fail := func(key string) model.ReviewDecisionResult {
+		tx.Rollback()
+		result.Message = config.GetMessageCode(key)
+		return result
+	}
+
+	var thesis model.Thesis
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&thesis, item.ThesisID).Error; err != nil {
+		return fail("NOT_ID_EXISTS")
+	}
+	result.ApprovalStatus = thesis.ApprovalStatus
+	if !scope.Allows(thesis.SubjectID) {
+		return fail("PERMISSION_DENIED")
+	}
+	if thesis.ApprovalStatus != model.ApprovalPending {
+		return fail("INVALID_STATUS_TRANSITION")
+	}
+	if status == model.ApprovalApproved && thesis.DuplicateOverrideRequired && !thesis.DuplicateOverridden {
+		return fail("DUPLICATE_OVERRIDE_REQUIRED")
+	}
+
+	now := core.Now()
+	if err := tx.Model(&thesis).Updates(map[string]interface{}{
+		"APPROVAL_STATUS": status,
+		"REVIEW_COMMENT":  comment,
+		"REVIEWED_BY":     tokenData.Code,
+		"REVIEWED_AT":     now,
+	}).Error; err != nil {
+		return fail("SYSTEM_ERROR")
+	}
+	thesis.ApprovalStatus = status
+
+	// Tạo các công việc chuẩn theo loại luận văn khi được duyệt, như khi duyệt từng đề tài
+	if status == model.ApprovalApproved {
+		if _, err := instantiateTemplates(tx, &thesis); err != nil {
+			return fail("SYSTEM_ERROR")
+		}
+	}
+
+	review := model.ThesisReview{ThesisID: thesis.ID, Decision: decision, ApprovalStatus: status, Comment: comment}
+	review.CreatedBy = tokenData.Code
+	if err := tx.Create(&review).Error; err != nil {
+		return fail("SYSTEM_ERROR")
+	}
+
+	if err := tx.Preload("Students").Preload("Advisors").First(&thesis, thesis.ID).Error; err != nil {
+		return fail("SYSTEM_ERROR")
+	}
+	recipients := []modelNotification.Recipient{}
+	for _, advisor := range thesis.Advisors {
+		recipients = append(recipients, modelNotification.Recipient{UserID: advisor.ID, Role: modelUsers.AdvisorRole})
+	}
+	for _, student := range thesis.Students {
+		recipients = append(recipients, modelNotification.Recipient{UserID: student.ID, Role: modelUsers.StudentRole})
+	}
+	notification := modelNotification.Message{
+		Type:  modelNotification.NotificationThesisReviewed,
+		Title: "Topic review: " + strings.ToLower(decision),
+		Body:  fmt.Sprintf("\"%s\": %s", thesis.TitleVi, comment),
+	}
+	if comment == "" {
+		notification.Body = fmt.Sprintf("\"%s\" was approved", thesis.TitleVi)
+	}
+	if err := notificationController.Notify(tx, notification, recipients...); err != nil {
+		return fail("SYSTEM_ERROR")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		result.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return result
+	}
+	result.Success = true
+	result.ApprovalStatus = status
+	result.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return result
+}
+
+// canReview: trưởng bộ môn và văn phòng khoa được duyệt đề tài trong phạm vi của mình
+func canReview(tokenData *utils.TokenData) bool {
+	return tokenData.Role == modelUsers.HeadOfSubjectRole || tokenData.Role == modelUsers.FacultyOfficeRole
+}
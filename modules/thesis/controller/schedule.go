@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"app/config"
+	"app/core"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Công việc chưa có thời gian được giả định kéo dài một ngày
+	defaultTaskDuration = 24 * time.Hour
+	slackTolerance      = time.Minute
+)
+
+var errDependencyCycle = errors.New("task dependencies contain a cycle")
+
+// GetThesisSchedule tính lịch thực hiện, đường găng và thời gian dự trữ của các công việc
+// @Summary Get the Gantt schedule of a thesis
+// @Description Compute earliest/latest start and finish, slack and the critical path of the thesis tasks from their start/end times and finish-to-start dependencies, and compare the projected completion with the thesis end time. Unfinished tasks cannot be scheduled in the past.
+// @Tags Thesis
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/schedule [get]
+func GetThesisSchedule(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canWorkOnThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var tasks []model.ThesisTask
+	if err := db.Where("THESIS_ID = ? AND STATUS <> ?", thesis.ID, model.TaskStatusCancelled).Order("ID").Find(&tasks).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	edges, err := dependencyGraph(db, thesis.ID)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	schedule, err := computeSchedule(tasks, edges, thesis.EndTime, core.Now())
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TASK_DEPENDENCY_CYCLE")
+		return c.JSON(response)
+	}
+	schedule.ThesisID = thesis.ID
+
+	response.Data = schedule
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+type scheduleNode struct {
+	task                   *model.ThesisTask
+	duration               time.Duration
+	estimated              bool
+	es, ef, ls, lf         time.Time
+	predecessors, children []uint
+}
+
+// computeSchedule chạy phương pháp đường găng (CPM): duyệt xuôi theo thứ tự topo để tính thời điểm
+// sớm nhất, duyệt ngược từ hạn luận văn (hoặc ngày hoàn thành dự kiến) để tính thời điểm muộn nhất.
+// edges là cạnh công việc -> các công việc phải xong trước nó.
+func computeSchedule(tasks []model.ThesisTask, edges map[uint][]uint, thesisEnd, now time.Time) (model.ThesisSchedule, error) {
+	schedule := model.ThesisSchedule{
+		GeneratedAt:  now,
+		OnTrack:      true,
+		CriticalPath: []uint{},
+		Tasks:        []model.TaskSchedule{},
+		Warnings:     []string{},
+	}
+	if !thesisEnd.IsZero() {
+		end := thesisEnd.In(now.Location())
+		schedule.ThesisEndTime = &end
+	}
+
+	nodes := map[uint]*scheduleNode{}
+	for i := range tasks {
+		task := &tasks[i]
+		node := &scheduleNode{task: task}
+		switch {
+		case !task.StartTime.IsZero() && task.EndTime.After(task.StartTime):
+			node.duration = task.EndTime.Sub(task.StartTime)
+		case !task.StartTime.IsZero() && task.Deadline != nil && task.Deadline.After(task.StartTime):
+			node.duration = task.Deadline.Sub(task.StartTime)
+			node.estimated = true
+		default:
+			node.duration = defaultTaskDuration
+			node.estimated = true
+			schedule.Warnings = append(schedule.Warnings, fmt.Sprintf("task %d (%s) has no start/end time, assumed %s", task.ID, task.Title, defaultTaskDuration))
+		}
+		nodes[task.ID] = node
+	}
+
+	// Bỏ các cạnh tới công việc không được lập lịch (đã hủy)
+	for id, node := range nodes {
+		for _, prerequisite := range edges[id] {
+			if _, ok := nodes[prerequisite]; ok {
+				node.predecessors = append(node.predecessors, prerequisite)
+				nodes[prerequisite].children = append(nodes[prerequisite].children, id)
+			}
+		}
+	}
+
+	order, err := topologicalOrder(tasks, nodes)
+	if err != nil {
+		return schedule, err
+	}
+	if len(order) == 0 {
+		return schedule, nil
+	}
+
+	// Duyệt xuôi
+	var projectStart, projectEnd time.Time
+	for _, id := range order {
+		node := nodes[id]
+		task := node.task
+
+		var ready time.Time
+		for _, prerequisite := range node.predecessors {
+			if ef := nodes[prerequisite].ef; ef.After(ready) {
+				ready = ef
+			}
+		}
+
+		status, _ := model.NormalizeTaskStatus(task.Status)
+		switch status {
+		case model.TaskStatusDone:
+			// Công việc đã xong giữ nguyên mốc thực tế
+			node.es = firstTime(task.StartTime, ready, now.Add(-node.duration))
+			node.ef = firstTime(task.EndTime, node.es.Add(node.duration))
+		case model.TaskStatusTodo:
+			node.es = latestTime(ready, task.StartTime, now)
+			node.ef = node.es.Add(node.duration)
+		default:
+			node.es = latestTime(ready, task.StartTime)
+			if node.es.IsZero() {
+				node.es = now
+			}
+			node.ef = latestTime(node.es.Add(node.duration), now)
+		}
+
+		if projectStart.IsZero() || node.es.Before(projectStart) {
+			projectStart = node.es
+		}
+		if node.ef.After(projectEnd) {
+			projectEnd = node.ef
+		}
+	}
+
+	// Duyệt ngược
+	deadline := projectEnd
+	if !thesisEnd.IsZero() {
+		deadline = thesisEnd
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		node := nodes[order[i]]
+		node.lf = deadline
+		for _, child := range node.children {
+			if ls := nodes[child].ls; ls.Before(node.lf) {
+				node.lf = ls
+			}
+		}
+		node.ls = node.lf.Add(-node.duration)
+	}
+
+	minSlack := time.Duration(0)
+	first := true
+	for _, node := range nodes {
+		if node.task.IsDone() {
+			continue
+		}
+		if slack := node.ls.Sub(node.es); first || slack < minSlack {
+			minSlack = slack
+			first = false
+		}
+	}
+
+	loc := now.Location()
+	for _, id := range order {
+		node := nodes[id]
+		task := node.task
+		slack := node.ls.Sub(node.es)
+		critical := !task.IsDone() && slack-minSlack <= slackTolerance
+
+		item := model.TaskSchedule{
+			TaskID:         task.ID,
+			Title:          task.Title,
+			Status:         task.Status,
+			ParentID:       task.ParentID,
+			DependsOn:      node.predecessors,
+			PlannedStart:   optionalTime(task.StartTime, loc),
+			PlannedEnd:     optionalTime(task.EndTime, loc),
+			EarliestStart:  node.es.In(loc),
+			EarliestFinish: node.ef.In(loc),
+			LatestStart:    node.ls.In(loc),
+			LatestFinish:   node.lf.In(loc),
+			DurationHours:  node.duration.Hours(),
+			SlackHours:     slack.Hours(),
+			Critical:       critical,
+			Estimated:      node.estimated,
+		}
+		if item.DependsOn == nil {
+			item.DependsOn = []uint{}
+		}
+		schedule.Tasks = append(schedule.Tasks, item)
+		if critical {
+			schedule.CriticalPath = append(schedule.CriticalPath, task.ID)
+		}
+	}
+	sort.SliceStable(schedule.Tasks, func(i, j int) bool {
+		return schedule.Tasks[i].EarliestStart.Before(schedule.Tasks[j].EarliestStart)
+	})
+
+	schedule.ProjectStart = optionalTime(projectStart, loc)
+	schedule.ProjectedCompletion = optionalTime(projectEnd, loc)
+	if !thesisEnd.IsZero() && projectEnd.After(thesisEnd) {
+		schedule.OnTrack = false
+		schedule.DelayHours = projectEnd.Sub(thesisEnd).Hours()
+	}
+	return schedule, nil
+}
+
+// topologicalOrder sắp xếp Kahn, ưu tiên ID nhỏ để kết quả ổn định
+func topologicalOrder(tasks []model.ThesisTask, nodes map[uint]*scheduleNode) ([]uint, error) {
+	indegree := map[uint]int{}
+	for id, node := range nodes {
+		indegree[id] = len(node.predecessors)
+	}
+
+	queue := []uint{}
+	for _, task := range tasks {
+		if indegree[task.ID] == 0 {
+			queue = append(queue, task.ID)
+		}
+	}
+
+	order := make([]uint, 0, len(nodes))
+	for len(queue) > 0 {
+		sort.Slice(queue, func(i, j int) bool { return queue[i] < queue[j] })
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, child := range nodes[id].children {
+			indegree[child]--
+			if indegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	if len(order) != len(nodes) {
+		return nil, errDependencyCycle
+	}
+	return order, nil
+}
+
+// latestTime trả về mốc muộn nhất trong các mốc khác không
+func latestTime(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// firstTime trả về mốc khác không đầu tiên
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func optionalTime(t time.Time, loc *time.Location) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.In(loc)
+	return &t
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"app/modules/thesis/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var scheduleNow = time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+
+func day(n float64) time.Time {
+	return scheduleNow.Add(time.Duration(n * float64(24*time.Hour)))
+}
+
+func scheduleTask(id uint, status string, start, end time.Time) model.ThesisTask {
+	task := model.ThesisTask{Title: "task", Status: status, StartTime: start, EndTime: end}
+	task.ID = id
+	return task
+}
+
+func TestComputeSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		tasks          []model.ThesisTask
+		edges          map[uint][]uint
+		thesisEnd      time.Time
+		wantErr        error
+		wantCritical   []uint
+		wantStart      map[uint]time.Time
+		wantCompletion time.Time
+		wantOnTrack    bool
+		wantDelayHours float64
+		wantWarnings   int
+	}{
+		{
+			name: "longest branch is critical",
+			tasks: []model.ThesisTask{
+				scheduleTask(1, model.TaskStatusTodo, day(1), day(2)),
+				scheduleTask(2, model.TaskStatusTodo, day(1), day(3)),
+				scheduleTask(3, model.TaskStatusTodo, day(1), day(2)),
+			},
+			edges:          map[uint][]uint{3: {1, 2}},
+			wantCritical:   []uint{2, 3},
+			wantStart:      map[uint]time.Time{1: day(1), 2: day(1), 3: day(3)},
+			wantCompletion: day(4),
+			wantOnTrack:    true,
+		},
+		{
+			name: "late against thesis end",
+			tasks: []model.ThesisTask{
+				scheduleTask(1, model.TaskStatusTodo, day(1), day(3)),
+				scheduleTask(2, model.TaskStatusTodo, day(3), day(4)),
+			},
+			edges:          map[uint][]uint{2: {1}},
+			thesisEnd:      day(2),
+			wantCritical:   []uint{1, 2},
+			wantStart:      map[uint]time.Time{1: day(1), 2: day(3)},
+			wantCompletion: day(4),
+			wantOnTrack:    false,
+			wantDelayHours: 48,
+		},
+		{
+			name: "done task keeps actual dates and is never critical",
+			tasks: []model.ThesisTask{
+				scheduleTask(1, model.TaskStatusDone, day(-3), day(-1)),
+				scheduleTask(2, model.TaskStatusTodo, time.Time{}, time.Time{}),
+			},
+			edges:          map[uint][]uint{2: {1}},
+			wantCritical:   []uint{2},
+			wantStart:      map[uint]time.Time{1: day(-3), 2: day(0)},
+			wantCompletion: day(1),
+			wantOnTrack:    true,
+			wantWarnings:   1,
+		},
+		{
+			name: "todo task never starts in the past",
+			tasks: []model.ThesisTask{
+				scheduleTask(1, model.TaskStatusTodo, day(-2), day(-1)),
+			},
+			wantCritical:   []uint{1},
+			wantStart:      map[uint]time.Time{1: day(0)},
+			wantCompletion: day(1),
+			wantOnTrack:    true,
+		},
+		{
+			name: "edges to tasks outside the schedule are ignored",
+			tasks: []model.ThesisTask{
+				scheduleTask(1, model.TaskStatusTodo, day(1), day(2)),
+			},
+			edges:          map[uint][]uint{1: {99}},
+			wantCritical:   []uint{1},
+			wantStart:      map[uint]time.Time{1: day(1)},
+			wantCompletion: day(2),
+			wantOnTrack:    true,
+		},
+		{
+			name: "cycle",
+			tasks: []model.ThesisTask{
+				scheduleTask(1, model.TaskStatusTodo, day(1), day(2)),
+				scheduleTask(2, model.TaskStatusTodo, day(1), day(2)),
+			},
+			edges:   map[uint][]uint{1: {2}, 2: {1}},
+			wantErr: errDependencyCycle,
+		},
+		{
+			name:         "no tasks",
+			wantCritical: []uint{},
+			wantOnTrack:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := computeSchedule(tt.tasks, tt.edges, tt.thesisEnd, scheduleNow)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(schedule.CriticalPath, tt.wantCritical) {
+				t.Errorf("CriticalPath = %v, want %v", schedule.CriticalPath, tt.wantCritical)
+			}
+			for _, item := range schedule.Tasks {
+				if want, ok := tt.wantStart[item.TaskID]; ok && !item.EarliestStart.Equal(want) {
+					t.Errorf("task %d EarliestStart = %v, want %v", item.TaskID, item.EarliestStart, want)
+				}
+				if item.SlackHours < 0 && tt.wantOnTrack {
+					t.Errorf("task %d has negative slack %v on an on-track schedule", item.TaskID, item.SlackHours)
+				}
+			}
+			if tt.wantCompletion.IsZero() {
+				if schedule.ProjectedCompletion != nil {
+					t.Errorf("ProjectedCompletion = %v, want nil", schedule.ProjectedCompletion)
+				}
+			} else if schedule.ProjectedCompletion == nil || !schedule.ProjectedCompletion.Equal(tt.wantCompletion) {
+				t.Errorf("ProjectedCompletion = %v, want %v", schedule.ProjectedCompletion, tt.wantCompletion)
+			}
+			if schedule.OnTrack != tt.wantOnTrack || schedule.DelayHours != tt.wantDelayHours {
+				t.Errorf("OnTrack, DelayHours = %v, %v, want %v, %v", schedule.OnTrack, schedule.DelayHours, tt.wantOnTrack, tt.wantDelayHours)
+			}
+			if len(schedule.Warnings) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want %d", schedule.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []uint
+		edges   map[uint][]uint
+		want    []uint
+		wantErr error
+	}{
+		{"independent tasks by ID", []uint{3, 1, 2}, nil, []uint{1, 2, 3}, nil},
+		{"chain against ID order", []uint{1, 2, 3}, map[uint][]uint{1: {2}, 2: {3}}, []uint{3, 2, 1}, nil},
+		{"diamond", []uint{1, 2, 3, 4}, map[uint][]uint{2: {1}, 3: {1}, 4: {2, 3}}, []uint{1, 2, 3, 4}, nil},
+		{"self loop", []uint{1}, map[uint][]uint{1: {1}}, nil, errDependencyCycle},
+		{"cycle behind a root", []uint{1, 2, 3}, map[uint][]uint{2: {1, 3}, 3: {2}}, nil, errDependencyCycle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []model.ThesisTask{}
+			nodes := map[uint]*scheduleNode{}
+			for _, id := range tt.ids {
+				tasks = append(tasks, scheduleTask(id, model.TaskStatusTodo, time.Time{}, time.Time{}))
+				nodes[id] = &scheduleNode{}
+			}
+			for id, prerequisites := range tt.edges {
+				for _, prerequisite := range prerequisites {
+					nodes[id].predecessors = append(nodes[id].predecessors, prerequisite)
+					nodes[prerequisite].children = append(nodes[prerequisite].children, id)
+				}
+			}
+
+			order, err := topologicalOrder(tasks, nodes)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(order, tt.want) {
+				t.Errorf("order = %v, want %v", order, tt.want)
+			}
+		})
+	}
+}
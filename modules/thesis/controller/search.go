@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	searchDefaultLimit = 20
+	searchMaxLimit     = 100
+	searchSnippetWords = 30
+)
+
+// Trọng số của từng trường khi xếp hạng kết quả tìm kiếm
+var searchFieldWeights = []struct {
+	Name   string
+	Weight float64
+}{
+	{"titleVi", 5},
+	{"titleEn", 5},
+	{"advisors", 3},
+	{"thesisInfo", 2},
+	{"missions", 1.5},
+}
+
+// SearchTheses tìm kiếm luận văn theo từ khóa, không phân biệt dấu tiếng Việt
+// @Summary Search theses
+// @Description Keyword search across titles, description, missions and advisor names. Matching ignores Vietnamese accents ("luan van" matches "luận văn"); every keyword must appear in at least one field. Callers without a token only see approved topics; students only see topics open to their own program.
+// @Tags Thesis
+// @Produce json
+// @Param q query string true "Keywords"
+// @Param semester query string false "Semester"
+// @Param thesisType query int false "Thesis type"
+// @Param approvalStatus query int false "Approval status"
+// @Param program query int false "Study program ID; only topics open to this program"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/search [get]
+func SearchTheses(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	terms := searchTerms(c.Query("q"))
+	if len(terms) == 0 {
+		response.Status = false
+		response.Message = config.GetMessageCode("MISSING_FIELDS")
+		response.ValidateError = map[string]string{"q": config.GetMessageCode("REQUIRE")}
+		return c.JSON(response)
+	}
+
+	query := db.Preload("Missions").Preload("Programs.Catalog").Preload("Advisors")
+	query = visibleTheses(c, db, query)
+	if semester := c.Query("semester"); semester != "" {
+		query = query.Where("SEMESTER = ?", semester)
+	}
+	if thesisType := c.QueryInt("thesisType"); thesisType != 0 {
+		query = query.Where("THESIS_TYPE = ?", thesisType)
+	}
+	if approvalStatus := c.QueryInt("approvalStatus"); approvalStatus != 0 {
+		query = query.Where("APPROVAL_STATUS = ?", approvalStatus)
+	}
+	if programID, ok := programFilter(c, db); ok {
+		query = EligibleForProgram(db, query, programID)
+	}
+
+	var theses []model.Thesis
+	if err := query.Find(&theses).Error; err != nil {
+		response.Status = false
+		response.Message = "Failed to fetch theses"
+		return c.JSON(response)
+	}
+
+	phrase := strings.Join(terms, " ")
+	results := []model.ThesisSearchResult{}
+	for _, thesis := range theses {
+		if result, ok := scoreThesis(thesis, terms, phrase); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Thesis.ID > results[j].Thesis.ID
+	})
+
+	limit := c.QueryInt("limit", searchDefaultLimit)
+	if limit <= 0 || limit > searchMaxLimit {
+		limit = searchDefaultLimit
+	}
+	offset := c.QueryInt("offset")
+	if offset < 0 {
+		offset = 0
+	}
+
+	total := len(results)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	response.Data = model.ThesisSearchResponse{Total: total, Results: results[offset:end]}
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+func searchTerms(q string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, token := range utils.Tokenize(q) {
+		if !seen[token.Norm] {
+			seen[token.Norm] = true
+			terms = append(terms, token.Norm)
+		}
+	}
+	return terms
+}
+
+func searchFields(thesis model.Thesis) map[string]string {
+	missions := make([]string, 0, len(thesis.Missions))
+	for _, mission := range thesis.Missions {
+		missions = append(missions, mission.Value)
+	}
+	advisors := make([]string, 0, len(thesis.Advisors))
+	for _, advisor := range thesis.Advisors {
+		advisors = append(advisors, advisor.FullName)
+	}
+	return map[string]string{
+		"titleVi":    thesis.TitleVi,
+		"titleEn":    thesis.TitleEn,
+		"thesisInfo": thesis.ThesisInfo,
+		"missions":   strings.Join(missions, "\n"),
+		"advisors":   strings.Join(advisors, ", "),
+	}
+}
+
+// scoreThesis tính điểm liên quan: từ khớp trọn được 1 điểm, khớp tiền tố (từ cuối đang gõ dở) 0.5 điểm,
+// cụm từ xuất hiện nguyên vẹn được cộng thêm, tất cả nhân với trọng số của trường
+func scoreThesis(thesis model.Thesis, terms []string, phrase string) (model.ThesisSearchResult, bool) {
+	result := model.ThesisSearchResult{Thesis: thesis, Highlights: map[string]string{}}
+	fields := searchFields(thesis)
+	found := map[string]bool{}
+
+	for _, field := range searchFieldWeights {
+		text := fields[field.Name]
+		if text == "" {
+			continue
+		}
+		tokens := utils.Tokenize(text)
+		marked := make([]bool, len(tokens))
+		fieldScore := 0.0
+
+		for _, term := range terms {
+			best := 0.0
+			for i, token := range tokens {
+				if token.Norm == term {
+					marked[i] = true
+					best = 1
+				} else if len(term) >= 2 && strings.HasPrefix(token.Norm, term) {
+					marked[i] = true
+					if best < 0.5 {
+						best = 0.5
+					}
+				}
+			}
+			if best > 0 {
+				found[term] = true
+				fieldScore += best
+			}
+		}
+		if fieldScore == 0 {
+			continue
+		}
+		if len(terms) > 1 && strings.Contains(" "+utils.NormalizeText(text)+" ", " "+phrase+" ") {
+			fieldScore += float64(len(terms))
+		}
+
+		result.Score += fieldScore * field.Weight
+		result.Highlights[field.Name] = highlight(text, tokens, marked)
+	}
+
+	if len(found) < len(terms) {
+		return result, false
+	}
+	return result, true
+}
+
+// highlight bọc các từ khớp bằng <mark>, văn bản dài chỉ giữ một đoạn quanh từ khớp đầu tiên
+func highlight(text string, tokens []utils.Token, marked []bool) string {
+	first, last := 0, len(tokens)-1
+	if len(tokens) > searchSnippetWords {
+		for i := range marked {
+			if marked[i] {
+				first = i - searchSnippetWords/3
+				break
+			}
+		}
+		if first < 0 {
+			first = 0
+		}
+		last = first + searchSnippetWords - 1
+		if last >= len(tokens) {
+			last = len(tokens) - 1
+		}
+	}
+
+	var b strings.Builder
+	start := tokens[first].Start
+	if first > 0 {
+		b.WriteString("…")
+	}
+	for i := first; i <= last; i++ {
+		b.WriteString(html.EscapeString(text[start:tokens[i].Start]))
+		if marked[i] {
+			b.WriteString("<mark>" + html.EscapeString(tokens[i].Word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(tokens[i].Word))
+		}
+		start = tokens[i].End
+	}
+	if last < len(tokens)-1 {
+		b.WriteString("…")
+	} else {
+		b.WriteString(html.EscapeString(text[start:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,623 @@
+package controller
+
+import (
+	"app/config"
+	"app/database"
+	"app/modules/thesis/model"
+	"app/utils"
+
+	organizationController "app/modules/organization/controller"
+	modell "app/modules/student/model"
+	modelUsers "app/modules/users/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// endOfColumn dùng làm vị trí để đặt công việc xuống cuối cột
+const endOfColumn = 1 << 30
+
+// GetTaskBoard trả về bảng công việc (Kanban) của một Thesis
+// @Summary Get the task board of a thesis
+// @Description Get top-level tasks grouped by status in board order, with subtasks, assignees, dependencies and the allowed status transitions
+// @Tags Thesis
+// @Produce json
+// @Param uuid path string true "Thesis UUID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/{uuid}/board [get]
+func GetTaskBoard(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
+	var thesis model.Thesis
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+		response.Status = false
+		response.Message = "Thesis not found"
+		return c.JSON(response)
+	}
+
+	if !canWorkOnThesis(db, tokenData, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
+	var tasks []model.ThesisTask
+	if err := db.Preload("Assignees").Preload("Dependencies").
+		Preload("Subtasks", func(db *gorm.DB) *gorm.DB { return db.Order("POSITION, ID") }).
+		Preload("Subtasks.Assignees").
+		Where("THESIS_ID = ? AND PARENT_ID IS NULL", thesis.ID).
+		Order("POSITION, ID").Find(&tasks).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
+		return c.JSON(response)
+	}
+
+	columns := map[string][]model.ThesisTask{}
+	for _, task := range tasks {
+		status, ok := model.NormalizeTaskStatus(task.Status)
+		if !ok {
+			status = model.TaskStatusTodo
+		}
+		columns[status] = append(columns[status], task)
+	}
+
+	board := model.TaskBoard{ThesisID: thesis.ID, Transitions: model.TaskTransitions}
+	for _, status := range model.TaskStatuses {
+		column := model.TaskBoardColumn{Status: status, Tasks: columns[status]}
+		if column.Tasks == nil {
+			column.Tasks = []model.ThesisTask{}
+		}
+		board.Columns = append(board.Columns, column)
+	}
+
+	response.Data = board
+	response.Status = true
+	response.Message = config.GetMessageCode("GET_DATA_SUCCESS")
+	return c.JSON(response)
+}
+
+// MoveTask chuyển trạng thái và/hoặc vị trí của công việc trên bảng
+// @Summary Move a task on the board
+// @Description Change the status of a task (only allowed transitions) and place it at a position in the target column. A task cannot start before its dependencies are done, and cannot be done while it has open subtasks.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param body body model.MoveThesisTask true "Target status and position"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/task/{id}/move [put]
+func MoveTask(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.MoveThesisTask
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	task, _, message := loadTaskForEdit(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	current, ok := model.NormalizeTaskStatus(task.Status)
+	if !ok {
+		current = model.TaskStatusTodo
+	}
+	target := current
+	if payload.Status != "" {
+		if target, ok = model.NormalizeTaskStatus(payload.Status); !ok {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"status": payload.Status}
+			return c.JSON(response)
+		}
+	}
+
+	if !model.CanTransition(current, target) {
+		response.Status = false
+		response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+		response.ValidateError = map[string]interface{}{"from": current, "to": target, "allowed": model.TaskTransitions[current]}
+		return c.JSON(response)
+	}
+
+	if target != current {
+		blockers, err := taskBlockers(db, &task, target)
+		if err != nil {
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+		if len(blockers) > 0 {
+			response.Status = false
+			response.Message = config.GetMessageCode("TASK_BLOCKED")
+			response.ValidateError = blockers
+			return c.JSON(response)
+		}
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	if target != current {
+		// Đóng khoảng trống ở cột cũ
+		if err := placeTask(tx, &task, current, -1); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
+		}
+	}
+	if err := placeTask(tx, &task, target, payload.Position); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	if err := tx.Model(&model.ThesisTask{}).Where("ID = ?", task.ID).UpdateColumn("STATUS", target).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	task.Status = target
+	response.Data = task
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateTaskAssignees giao công việc cho các sinh viên của luận văn
+// @Summary Assign a task to students
+// @Description Replace the assignees of a task; every student must belong to the thesis
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param body body model.UpdateTaskAssignees true "Student IDs"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/task/{id}/assignees [put]
+func UpdateTaskAssignees(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.UpdateTaskAssignees
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	task, thesis, message := loadTaskForEdit(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	assignees := []modell.Student{}
+	seen := map[uint]bool{}
+	for _, id := range payload.StudentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		found := false
+		for _, student := range thesis.Students {
+			if student.ID == id {
+				assignees = append(assignees, student)
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]interface{}{"studentIDs": id}
+			return c.JSON(response)
+		}
+	}
+
+	if err := db.Model(&task).Association("Assignees").Replace(assignees); err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	task.Assignees = assignees
+	response.Data = task
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// UpdateTaskParent chuyển công việc thành công việc con của công việc khác
+// @Summary Nest a task under another task
+// @Description Set the parent of a task within the same thesis; parentID null moves it back to the top level
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param body body model.UpdateTaskParent true "Parent task"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/task/{id}/parent [put]
+func UpdateTaskParent(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.UpdateTaskParent
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	task, _, message := loadTaskForEdit(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if payload.ParentID != nil {
+		var parent model.ThesisTask
+		if err := db.First(&parent, *payload.ParentID).Error; err != nil || parent.ThesisID != task.ThesisID {
+			response.Status = false
+			response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+			return c.JSON(response)
+		}
+		// Cha mới không được là chính nó hoặc một công việc con cháu của nó
+		for ancestor, depth := &parent, 0; ancestor != nil; depth++ {
+			if ancestor.ID == task.ID || depth > lineageMaxDepth {
+				response.Status = false
+				response.Message = config.GetMessageCode("TASK_DEPENDENCY_CYCLE")
+				return c.JSON(response)
+			}
+			if ancestor.ParentID == nil {
+				break
+			}
+			var next model.ThesisTask
+			if err := db.First(&next, *ancestor.ParentID).Error; err != nil {
+				break
+			}
+			ancestor = &next
+		}
+	}
+
+	tx := db.Begin()
+	defer tx.Commit()
+
+	status := task.Status
+	if err := placeTask(tx, &task, status, -1); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	task.ParentID = payload.ParentID
+	if err := tx.Model(&model.ThesisTask{}).Where("ID = ?", task.ID).UpdateColumn("PARENT_ID", payload.ParentID).Error; err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	// Đặt ở cuối danh sách anh em mới
+	if err := placeTask(tx, &task, status, endOfColumn); err != nil {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Data = task
+	response.Status = true
+	response.Message = config.GetMessageCode("UPDATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// AddTaskDependency thêm ràng buộc finish-to-start giữa hai công việc
+// @Summary Add a task dependency
+// @Description The task cannot start until the task it depends on is done. Dependencies must stay within the thesis and may not form a cycle.
+// @Tags Thesis
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param body body model.AddTaskDependency true "Prerequisite task"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/task/{id}/dependencies [post]
+func AddTaskDependency(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	var payload model.AddTaskDependency
+	if err := c.BodyParser(&payload); err != nil {
+		response.Status = false
+		response.Message = "Failed to parse request body"
+		return c.JSON(response)
+	}
+
+	task, _, message := loadTaskForEdit(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	var prerequisite model.ThesisTask
+	if err := db.First(&prerequisite, payload.DependsOnID).Error; err != nil || prerequisite.ThesisID != task.ThesisID {
+		response.Status = false
+		response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+		return c.JSON(response)
+	}
+
+	edges, err := dependencyGraph(db, task.ThesisID)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+	for _, id := range edges[task.ID] {
+		if id == prerequisite.ID {
+			response.Data = task
+			response.Status = true
+			response.Message = config.GetMessageCode("CREATE_SUCCESS")
+			return c.JSON(response)
+		}
+	}
+	if prerequisite.ID == task.ID || hasDependencyPath(edges, prerequisite.ID, task.ID) {
+		response.Status = false
+		response.Message = config.GetMessageCode("TASK_DEPENDENCY_CYCLE")
+		return c.JSON(response)
+	}
+
+	// Công việc đã bắt đầu thì không thể phụ thuộc vào công việc chưa xong
+	if task.Status != model.TaskStatusTodo && !task.IsClosed() && !prerequisite.IsClosed() {
+		response.Status = false
+		response.Message = config.GetMessageCode("TASK_BLOCKED")
+		response.ValidateError = map[string]string{"dependsOnID": prerequisite.Title}
+		return c.JSON(response)
+	}
+
+	dependency := model.TaskDependency{TaskID: task.ID, DependsOnID: prerequisite.ID}
+	if err := db.Create(&dependency).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	task.Dependencies = append(task.Dependencies, dependency)
+	response.Data = task
+	response.Status = true
+	response.Message = config.GetMessageCode("CREATE_SUCCESS")
+	return c.JSON(response)
+}
+
+// RemoveTaskDependency xóa ràng buộc giữa hai công việc
+// @Summary Remove a task dependency
+// @Description Remove a finish-to-start dependency
+// @Tags Thesis
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param dependsOnId path int true "Prerequisite task ID"
+// @Success 200 {object} config.DataResponse
+// @Failure 500 {object} config.DataResponse
+// @Router /thesis/task/{id}/dependencies/{dependsOnId} [delete]
+func RemoveTaskDependency(c *fiber.Ctx) error {
+	response := new(config.DataResponse)
+	db := database.DB
+
+	task, _, message := loadTaskForEdit(c, db)
+	if message != "" {
+		response.Status = false
+		response.Message = message
+		return c.JSON(response)
+	}
+
+	if err := db.Unscoped().Where("TASK_ID = ? AND DEPENDS_ON_ID = ?", task.ID, c.Params("dependsOnId")).Delete(&model.TaskDependency{}).Error; err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("SYSTEM_ERROR")
+		return c.JSON(response)
+	}
+
+	response.Status = true
+	response.Message = config.GetMessageCode("DELETE_SUCCESS")
+	return c.JSON(response)
+}
+
+// loadTaskForEdit tìm công việc theo :id và kiểm tra quyền trên luận văn chứa nó; trả về mã lỗi nếu không được
+func loadTaskForEdit(c *fiber.Ctx, db *gorm.DB) (model.ThesisTask, model.Thesis, string) {
+	var task model.ThesisTask
+	var thesis model.Thesis
+
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		return task, thesis, config.GetMessageCode("TOKEN_INCORRECT")
+	}
+	if err := db.Preload("Assignees").Preload("Dependencies").First(&task, c.Params("id")).Error; err != nil {
+		return task, thesis, config.GetMessageCode("NOT_ID_EXISTS")
+	}
+	if err := db.Preload("Students").Preload("Advisors").First(&thesis, task.ThesisID).Error; err != nil {
+		return task, thesis, "Thesis not found"
+	}
+	if !canWorkOnThesis(db, tokenData, &thesis) {
+		return task, thesis, config.GetMessageCode("PERMISSION_DENIED")
+	}
+	return task, thesis, ""
+}
+
+// canWorkOnThesis: sinh viên và giảng viên của luận văn, trưởng bộ môn và văn phòng khoa trong phạm vi của mình
+func canWorkOnThesis(db *gorm.DB, tokenData *utils.TokenData, thesis *model.Thesis) bool {
+	switch tokenData.Role {
+	case modelUsers.StudentRole:
+		return thesis.HasStudent(tokenData.ID)
+	case modelUsers.AdvisorRole:
+		return thesis.HasAdvisor(tokenData.ID)
+	case modelUsers.HeadOfSubjectRole, modelUsers.FacultyOfficeRole:
+		return organizationController.ScopeOf(db, tokenData).Allows(thesis.SubjectID)
+	}
+	return false
+}
+
+// taskBlockers trả về các công việc đang chặn việc chuyển sang trạng thái target
+func taskBlockers(db *gorm.DB, task *model.ThesisTask, target string) (map[string][]string, error) {
+	blockers := map[string][]string{}
+
+	if target == model.TaskStatusInProgress || target == model.TaskStatusInReview || target == model.TaskStatusDone {
+		var prerequisites []model.ThesisTask
+		if err := db.Where("ID IN (?)", db.Model(&model.TaskDependency{}).Select("DEPENDS_ON_ID").Where("TASK_ID = ?", task.ID)).
+			Find(&prerequisites).Error; err != nil {
+			return nil, err
+		}
+		for _, prerequisite := range prerequisites {
+			if !prerequisite.IsClosed() {
+				blockers["dependencies"] = append(blockers["dependencies"], prerequisite.Title)
+			}
+		}
+	}
+
+	if target == model.TaskStatusDone {
+		var subtasks []model.ThesisTask
+		if err := db.Where("PARENT_ID = ?", task.ID).Find(&subtasks).Error; err != nil {
+			return nil, err
+		}
+		for _, subtask := range subtasks {
+			if !subtask.IsClosed() {
+				blockers["subtasks"] = append(blockers["subtasks"], subtask.Title)
+			}
+		}
+	}
+	return blockers, nil
+}
+
+// placeTask đặt công việc vào vị trí position trong cột status (cùng cha) và đánh số lại cả cột.
+// position < 0 nghĩa là chỉ rút công việc ra khỏi cột.
+func placeTask(tx *gorm.DB, task *model.ThesisTask, status string, position int) error {
+	query := tx.Where("THESIS_ID = ? AND STATUS = ? AND ID <> ?", task.ThesisID, status, task.ID)
+	if task.ParentID == nil {
+		query = query.Where("PARENT_ID IS NULL")
+	} else {
+		query = query.Where("PARENT_ID = ?", *task.ParentID)
+	}
+
+	var siblings []model.ThesisTask
+	if err := query.Order("POSITION, ID").Find(&siblings).Error; err != nil {
+		return err
+	}
+
+	ordered := make([]uint, 0, len(siblings)+1)
+	for _, sibling := range siblings {
+		ordered = append(ordered, sibling.ID)
+	}
+	if position >= 0 {
+		if position > len(ordered) {
+			position = len(ordered)
+		}
+		ordered = append(ordered[:position], append([]uint{task.ID}, ordered[position:]...)...)
+		task.Position = position
+	}
+
+	current := map[uint]int{task.ID: -1}
+	for _, sibling := range siblings {
+		current[sibling.ID] = sibling.Position
+	}
+	for index, id := range ordered {
+		if current[id] == index {
+			continue
+		}
+		if err := tx.Model(&model.ThesisTask{}).Where("ID = ?", id).UpdateColumn("POSITION", index).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeThesisTasks xóa các công việc của luận văn không còn trong danh sách kept,
+// cùng các phụ thuộc trỏ tới chúng; công việc con của chúng trở thành công việc gốc
+func removeThesisTasks(db *gorm.DB, thesisID uint, kept []uint) error {
+	removed := db.Model(&model.ThesisTask{}).Select("ID").Where("THESIS_ID = ?", thesisID)
+	if len(kept) > 0 {
+		removed = removed.Where("ID NOT IN ?", kept)
+	}
+	var ids []uint
+	if err := removed.Pluck("ID", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.Unscoped().Where("TASK_ID IN ? OR DEPENDS_ON_ID IN ?", ids, ids).Delete(&model.TaskDependency{}).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&model.ThesisTask{}).Where("PARENT_ID IN ?", ids).UpdateColumn("PARENT_ID", nil).Error; err != nil {
+		return err
+	}
+	return db.Where("ID IN ?", ids).Delete(&model.ThesisTask{}).Error
+}
+
+// dependencyGraph trả về cạnh task -> các công việc nó phụ thuộc trong một luận văn
+func dependencyGraph(db *gorm.DB, thesisID uint) (map[uint][]uint, error) {
+	var dependencies []model.TaskDependency
+	if err := db.Where("TASK_ID IN (?)", db.Model(&model.ThesisTask{}).Select("ID").Where("THESIS_ID = ?", thesisID)).
+		Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	edges := map[uint][]uint{}
+	for _, dependency := range dependencies {
+		edges[dependency.TaskID] = append(edges[dependency.TaskID], dependency.DependsOnID)
+	}
+	return edges, nil
+}
+
+// hasDependencyPath kiểm tra có đường đi from -> ... -> to theo các cạnh phụ thuộc
+func hasDependencyPath(edges map[uint][]uint, from, to uint) bool {
+	visited := map[uint]bool{}
+	stack := []uint{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, edges[id]...)
+	}
+	return false
+}
+
+// normalizeTaskInput chuẩn hóa trạng thái và độ ưu tiên khi tạo/cập nhật công việc cùng luận văn.
+// Độ ưu tiên 0 được hiểu là chưa chọn và lấy mức trung bình.
+func normalizeTaskInput(status string, priority int) (string, int, map[string]string) {
+	errors := map[string]string{}
+	normalized, ok := model.NormalizeTaskStatus(status)
+	if !ok {
+		errors["status"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	if priority == 0 {
+		priority = model.TaskPriorityMedium
+	}
+	if !model.ValidTaskPriority(priority) {
+		errors["priority"] = config.GetMessageCode("PARAM_ERROR")
+	}
+	return normalized, priority, errors
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"app/core"
+	"app/modules/thesis/model"
+
+	semesterController "app/modules/semester/controller"
+	modelTaskTemplate "app/modules/taskTemplate/model"
+
+	"gorm.io/gorm"
+)
+
+// instantiateTemplates tạo các công việc chuẩn theo loại luận văn khi luận văn được duyệt.
+// Mẫu đã được tạo cho luận văn này thì bỏ qua, nên gọi lại nhiều lần vẫn an toàn.
+func instantiateTemplates(tx *gorm.DB, thesis *model.Thesis) ([]model.ThesisTask, error) {
+	created := []model.ThesisTask{}
+
+	var templates []modelTaskTemplate.TaskTemplate
+	if err := tx.Where("THESIS_TYPE = ?", thesis.ThesisType).Order("POSITION, ID").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return created, nil
+	}
+
+	var existing []model.ThesisTask
+	if err := tx.Where("THESIS_ID = ?", thesis.ID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	instantiated := map[uint]bool{}
+	position := 0
+	for _, task := range existing {
+		if task.TemplateID != nil {
+			instantiated[*task.TemplateID] = true
+		}
+		if task.ParentID == nil && task.Status == model.TaskStatusTodo && task.Position >= position {
+			position = task.Position + 1
+		}
+	}
+
+	semesterStart := semesterController.StartFor(tx, thesis.Semester, thesis.StartTime)
+	if semesterStart.IsZero() {
+		semesterStart = core.Now()
+	}
+
+	for _, template := range templates {
+		if instantiated[template.ID] {
+			continue
+		}
+		start, end := template.Schedule(semesterStart)
+		priority := template.Priority
+		if !model.ValidTaskPriority(priority) {
+			priority = model.TaskPriorityMedium
+		}
+		templateID := template.ID
+		task := model.ThesisTask{
+			Title:       template.Title,
+			Description: template.Description,
+			Status:      model.TaskStatusTodo,
+			Priority:    priority,
+			StartTime:   start,
+			EndTime:     end,
+			Deadline:    &end,
+			ThesisID:    thesis.ID,
+			Position:    position,
+			TemplateID:  &templateID,
+		}
+		if err := tx.Create(&task).Error; err != nil {
+			return nil, err
+		}
+		position++
+		created = append(created, task)
+	}
+	return created, nil
+}
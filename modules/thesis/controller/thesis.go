@@ -3,14 +3,18 @@ package controller
 import (
 	"app/config"
 	"app/database"
+	"app/utils"
 	"encoding/json"
 
 	modelll "app/modules/advisor/model"
+	organizationController "app/modules/organization/controller"
+	programController "app/modules/program/controller"
 	modell "app/modules/student/model"
 	"app/modules/thesis/model"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ThesisStatusResponse struct {
@@ -37,7 +41,7 @@ func generateRandomUUID() string {
 
 // UpdateThesisApprovalStatus updates the ApprovalStatus of a thesis
 // @Summary Update the ApprovalStatus of a thesis
-// @Description Update the ApprovalStatus of a thesis
+// @Description Approve (3), reject (4) or send back for revision (5) one pending thesis (head of subject or faculty office, within their subject or faculty). The decision is recorded and notified like a decision from the review queue; a comment is required when rejecting or asking for revision.
 // @Tags Thesis
 // @Accept json
 // @Produce json
@@ -49,6 +53,13 @@ func UpdateThesisApprovalStatus(c *fiber.Ctx) error {
 	response := new(config.DataResponse)
 	db := database.DB
 
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil || !canReview(tokenData) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
+
 	var payload model.ApprovalStatusForThesis
 	if err := c.BodyParser(&payload); err != nil {
 		response.Status = false
@@ -56,26 +67,56 @@ func UpdateThesisApprovalStatus(c *fiber.Ctx) error {
 		return c.JSON(response)
 	}
 
-	var thesis model.Thesis
-	if err := db.First(&thesis, payload.ThesisID).Error; err != nil {
+	// Chỉ nhận các trạng thái là kết quả duyệt; đề tài nháp và chờ duyệt do người tạo chuyển
+	decision, ok := reviewDecisions[payload.ApprovalStatus]
+	if !ok {
 		response.Status = false
-		response.Message = "Thesis not found"
+		response.Message = config.GetMessageCode("PARAM_ERROR")
+		response.ValidateError = map[string]string{"approvalStatus": config.GetMessageCode("PARAM_ERROR")}
+		return c.JSON(response)
+	}
+
+	// Cùng luồng với hàng đợi duyệt: ghi lịch sử duyệt, tạo công việc chuẩn và gửi thông báo
+	result := reviewThesis(db, tokenData, organizationController.ScopeOf(db, tokenData), model.ReviewDecision{
+		ThesisID: payload.ThesisID,
+		Decision: decision,
+		Comment:  payload.Comment,
+	})
+	if !result.Success {
+		response.Status = false
+		response.Message = result.Message
 		return c.JSON(response)
 	}
 
-	// Cập nhật giá trị ApprovalStatus của thesis
-	if err := db.Model(&thesis).Update("APPROVAL_STATUS", payload.ApprovalStatus).Error; err != nil {
+	var thesis model.Thesis
+	if err := db.First(&thesis, payload.ThesisID).Error; err != nil {
 		response.Status = false
-		response.Message = "Update error"
+		response.Message = config.GetMessageCode("GET_DATA_FAIL")
 		return c.JSON(response)
 	}
-	response.Data= thesis
+	response.Data = thesis
 	response.Status = true
 	response.Message = "Thesis ApprovalStatus updated successfully"
 	return c.JSON(response)
 }
 
 
+// visibleTheses lọc query luận văn theo phạm vi người gọi; người chưa đăng nhập chỉ thấy đề tài đã duyệt
+func visibleTheses(c *fiber.Ctx, db *gorm.DB, query *gorm.DB) *gorm.DB {
+	if _, err := utils.ExtractTokenData(c); err != nil {
+		return query.Where("APPROVAL_STATUS = ?", model.ApprovalApproved)
+	}
+	return organizationController.RequestScope(c, db).Apply(query, "SUBJECT_ID")
+}
+
+// canViewThesis kiểm tra một luận văn có trong phạm vi người gọi, người chưa đăng nhập chỉ xem được đề tài đã duyệt
+func canViewThesis(c *fiber.Ctx, db *gorm.DB, thesis *model.Thesis) bool {
+	if _, err := utils.ExtractTokenData(c); err != nil {
+		return thesis.ApprovalStatus == model.ApprovalApproved
+	}
+	return organizationController.RequestScope(c, db).Allows(thesis.SubjectID)
+}
+
 // GetThesesByCreateBy gets the theses created by a user based on UUID
 // @Summary Get theses created by a user
 // @Description Get theses created by a user based on UUID
@@ -93,7 +134,8 @@ func GetThesesByCreateBy(c *fiber.Ctx) error {
 	createByUUID := c.Params("createBy")
 
 	var theses []model.Thesis
-	if err := db.Preload("Missions").Preload("Programs").Preload("ThesisTask").Preload("Students").Preload("Advisors").
+	query := visibleTheses(c, db, db)
+	if err := query.Preload("Missions").Preload("Programs").Preload("ThesisTask").Preload("Students").Preload("Advisors").
 		Where("created_by = ?", createByUUID).Find(&theses).Error; err != nil {
 		response.Status = false
 		response.Message = "Failed to fetch theses"
@@ -118,7 +160,8 @@ func ListTheses(c *fiber.Ctx) error {
     db := database.DB
 
     var theses []model.Thesis
-    if err := db.Preload("Missions").Preload("Programs").Preload("ThesisTask").Preload("Students").Preload("Advisors").Find(&theses).Error; err != nil {
+    query := visibleTheses(c, db, db)
+    if err := query.Preload("Missions").Preload("Programs").Preload("ThesisTask").Preload("Students").Preload("Advisors").Find(&theses).Error; err != nil {
         response.Status = false
         response.Message = "Failed to fetch theses"
         return c.JSON(response)
@@ -144,11 +187,16 @@ func GetThesis(c *fiber.Ctx) error {
 	db := database.DB
 
 	var thesis model.Thesis
-	if err := db.Preload("Missions").Preload("Programs").Preload("ThesisTask").Preload("Students").Preload("Advisors").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
+	if err := db.Preload("Missions").Preload("Programs.Catalog").Preload("ThesisTask").Preload("Students").Preload("Advisors").Preload("ResearchAreas").Preload("Keywords").First(&thesis, "uuid = ?", c.Params("uuid")).Error; err != nil {
 		response.Status = false
 		response.Message = "Thesis not found"
 		return c.JSON(response)
 	}
+	if !canViewThesis(c, db, &thesis) {
+		response.Status = false
+		response.Message = config.GetMessageCode("PERMISSION_DENIED")
+		return c.JSON(response)
+	}
 
 	response.Status = true
 	response.Data = thesis
@@ -197,9 +245,21 @@ func PostStatusThesis(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
+// draftTransition: người soạn đề tài chuyển bản nháp hoặc đề tài bị trả về sang chờ duyệt,
+// hoặc rút đề tài đang chờ duyệt về nháp
+func draftTransition(from, to int) bool {
+	switch to {
+	case model.ApprovalPending:
+		return from == model.ApprovalDraft || from == model.ApprovalRevision
+	case model.ApprovalDraft:
+		return from == model.ApprovalPending || from == model.ApprovalRevision
+	}
+	return false
+}
+
 // CreateThesis creates a new Thesis
 // @Summary Create a new thesis
-// @Description Create a new thesis
+// @Description Create new theses as drafts, or as pending when approvalStatus is 2. Approval goes through /thesis/approval or the review queue. The subject must be within the caller's scope.
 // @Tags Thesis
 // @Accept json
 // @Produce json
@@ -218,20 +278,71 @@ func CreateThesis(c *fiber.Ctx) error {
 		return c.JSON(response)
 	}
 
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+	scope := organizationController.ScopeOf(db, tokenData)
+
+	// Luận văn không chỉ rõ subject được gắn vào subject của người tạo
+	defaultSubject := organizationController.DefaultSubject(db, tokenData)
+
 	tx := db.Begin()
 	defer tx.Commit()
 
+	duplicateReports := []model.ThesisDuplicateReport{}
 	for _, thesisPayload := range payload {
+		subjectID := defaultSubject
+		if thesisPayload.SubjectID != 0 {
+			if !organizationController.SubjectExists(tx, thesisPayload.SubjectID) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("NOT_ID_EXISTS")
+				response.ValidateError = map[string]string{"subjectID": config.GetMessageCode("NOT_ID_EXISTS")}
+				return c.JSON(response)
+			}
+			id := thesisPayload.SubjectID
+			subjectID = &id
+		}
+		if !scope.Allows(subjectID) {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PERMISSION_DENIED")
+			response.ValidateError = map[string]string{"subjectID": config.GetMessageCode("PERMISSION_DENIED")}
+			return c.JSON(response)
+		}
+
+		// Đề tài mới là bản nháp hoặc được gửi duyệt ngay, không bao giờ được duyệt khi tạo
+		approvalStatus := model.ApprovalDraft
+		if thesisPayload.ApprovalStatus == model.ApprovalPending {
+			approvalStatus = model.ApprovalPending
+		}
+
+		programIDs := []int{}
+		for _, program := range thesisPayload.Programs {
+			programIDs = append(programIDs, program.Value)
+		}
+		if err := programController.ValidatePrograms(tx, programIDs...); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"programs": err.Error()}
+			return c.JSON(response)
+		}
+
 		newThesis := model.Thesis{
 			TitleVi:        thesisPayload.TitleVi,
 			TitleEn:        thesisPayload.TitleEn,
-			ApprovalStatus: thesisPayload.ApprovalStatus,
+			ApprovalStatus: approvalStatus,
 			ThesisType:     thesisPayload.ThesisType,
 			Semester:       thesisPayload.Semester,
 			UserRoleOwner:  thesisPayload.UserRoleOwner,
 			ThesisInfo:     thesisPayload.ThesisInfo,
 			StartTime:      thesisPayload.StartTime,
 			EndTime:        thesisPayload.EndTime,
+			SubjectID:      subjectID,
 		}
 		newThesis.ID = thesisPayload.ID
 		// Handle Advisors
@@ -270,6 +381,13 @@ func CreateThesis(c *fiber.Ctx) error {
 				response.Message = "Student not found"
 				return c.JSON(response)
 			}
+			if !ProgramAllows(programIDs, student.Program) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PROGRAM_NOT_ELIGIBLE")
+				response.ValidateError = map[string]string{"students": student.Code}
+				return c.JSON(response)
+			}
 			if err := db.Model(&student).UpdateColumn("THESIS_ID", newThesis.ID).Error; err != nil {
 				tx.Rollback()
 				response.Status = false
@@ -279,9 +397,13 @@ func CreateThesis(c *fiber.Ctx) error {
 		}
 		// Handle Missions
 		for _, missionPayload := range thesisPayload.Missions {
-			newMission := model.Mission{
-				Value:    missionPayload.Value,
-				ThesisID: newThesis.ID,
+			newMission := model.Mission{ThesisID: newThesis.ID}
+			if errors := applyDeliverable(&newMission, missionPayload.Value, missionPayload.Description, missionPayload.DueDate, missionPayload.EvidenceType, missionPayload.SubmissionType); len(errors) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = errors
+				return c.JSON(response)
 			}
 			newMission.ID = missionPayload.ID
 			if err := db.Create(&newMission).Error; err != nil {
@@ -307,17 +429,40 @@ func CreateThesis(c *fiber.Ctx) error {
 
 		// Create thesis tasks for this thesis
 		for _, taskPayload := range thesisPayload.ThesisTask {
+			deadline, err := parseDeadline(taskPayload.Deadline)
+			if err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("FORMAT_DATETIME")
+				response.ValidateError = map[string]string{"deadline": taskPayload.Deadline}
+				return c.JSON(response)
+			}
+			status, priority, errors := normalizeTaskInput(taskPayload.Status, taskPayload.Priority)
+			if len(errors) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = errors
+				return c.JSON(response)
+			}
 			newTask := model.ThesisTask{
 				Title:       taskPayload.Title,
-				Deadline:    taskPayload.Deadline,
-				Status:      taskPayload.Status,
-				Priority:    taskPayload.Priority,
+				Deadline:    deadline,
+				Status:      status,
+				Priority:    priority,
 				Description: taskPayload.Description,
 				Note:        taskPayload.Note,
 				StartTime:   taskPayload.StartTime,
 				EndTime:     taskPayload.EndTime,
 				ThesisID:    newThesis.ID,
 			}
+			if errors := validateTaskTimes(&newTask, &newThesis); len(errors) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+				response.ValidateError = errors
+				return c.JSON(response)
+			}
 			newTask.ID = taskPayload.ID
 			if err := db.Create(&newTask).Error; err != nil {
 				tx.Rollback()
@@ -326,8 +471,19 @@ func CreateThesis(c *fiber.Ctx) error {
 				return c.JSON(response)
 			}
 		}
+
+		// Cảnh báo các đề tài gần giống đã có
+		warnings, err := detectDuplicates(db, &newThesis)
+		if err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Failed to check duplicate topics"
+			return c.JSON(response)
+		}
+		duplicateReports = append(duplicateReports, model.ThesisDuplicateReport{ThesisID: newThesis.ID, Warnings: warnings})
 	}
 
+	response.Data = duplicateReports
 	response.Status = true
 	response.Message = "Thesis(s) created successfully"
 	return c.JSON(response)
@@ -335,7 +491,7 @@ func CreateThesis(c *fiber.Ctx) error {
 
 // UpdateThesis updates the information of a Thesis based on UUID
 // @Summary Update thesis details by UUID
-// @Description Update thesis details by UUID
+// @Description Update thesis details by UUID. The approval status can only move between draft and pending here; approval decisions go through /thesis/approval or the review queue.
 // @Tags Thesis
 // @Accept json
 // @Produce json
@@ -347,6 +503,13 @@ func UpdateThesis(c *fiber.Ctx) error {
 	response := new(config.DataResponse)
 	db := database.DB
 
+	tokenData, err := utils.ExtractTokenData(c)
+	if err != nil {
+		response.Status = false
+		response.Message = config.GetMessageCode("TOKEN_INCORRECT")
+		return c.JSON(response)
+	}
+
 	var payload []model.UpdateThesis
 	if err := c.BodyParser(&payload); err != nil {
 		response.Status = false
@@ -354,9 +517,12 @@ func UpdateThesis(c *fiber.Ctx) error {
 		return c.JSON(response)
 	}
 
+	scope := organizationController.ScopeOf(db, tokenData)
+
 	tx := db.Begin()
 	defer tx.Commit()
 
+	duplicateReports := []model.ThesisDuplicateReport{}
 	for _, thesisPayload := range payload {
 		// Find the thesis by ID
 		var thesis model.Thesis
@@ -366,14 +532,38 @@ func UpdateThesis(c *fiber.Ctx) error {
 			response.Message = "Thesis not found"
 			return c.JSON(response)
 		}
+		if !scope.Allows(thesis.SubjectID) {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PERMISSION_DENIED")
+			return c.JSON(response)
+		}
+
+		// Chương trình đã gắn với luận văn được giữ dù đã ngừng, chương trình mới phải còn hiệu lực
+		current := map[int]bool{}
+		for _, program := range thesis.Programs {
+			current[program.Value] = true
+		}
+		programIDs, newProgramIDs := []int{}, []int{}
+		for _, program := range thesisPayload.Programs {
+			programIDs = append(programIDs, program.Value)
+			if !current[program.Value] {
+				newProgramIDs = append(newProgramIDs, program.Value)
+			}
+		}
+		if err := programController.ValidatePrograms(tx, newProgramIDs...); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("PARAM_ERROR")
+			response.ValidateError = map[string]string{"programs": err.Error()}
+			return c.JSON(response)
+		}
 
 		// Clear the old lists of students, advisors, missions, and programs
 		// Xóa dữ liệu cũ
 		db.Model(&thesis).Association("Students").Clear()
 		db.Model(&thesis).Association("Advisors").Clear()
-		db.Delete(&thesis.Missions)
 		db.Delete(&thesis.Programs)
-		db.Delete(&thesis.ThesisTask)
 
 		// Handle Students
 		for _, studentIDPayload := range thesisPayload.Students {
@@ -384,6 +574,13 @@ func UpdateThesis(c *fiber.Ctx) error {
 				response.Message = "Student not found"
 				return c.JSON(response)
 			}
+			if !ProgramAllows(programIDs, student.Program) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PROGRAM_NOT_ELIGIBLE")
+				response.ValidateError = map[string]string{"students": student.Code}
+				return c.JSON(response)
+			}
 			thesis.Students = append(thesis.Students, student)
 		}
 
@@ -400,19 +597,44 @@ func UpdateThesis(c *fiber.Ctx) error {
 		}
 
 		// Handle Missions
+		// Sản phẩm có ID được sửa tại chỗ để giữ minh chứng và kết quả nghiệm thu
+		existingMissions := map[uint]model.Mission{}
+		for _, mission := range thesis.Missions {
+			existingMissions[mission.ID] = mission
+		}
+		keptMissions := []uint{}
 		for _, missionPayload := range thesisPayload.Missions {
-			newMission := model.Mission{
-				Value:    missionPayload.Value,
-				ThesisID: thesis.ID,
+			mission, ok := existingMissions[missionPayload.ID]
+			if !ok {
+				mission = model.Mission{ThesisID: thesis.ID}
+			}
+			if errors := applyDeliverable(&mission, missionPayload.Value, missionPayload.Description, missionPayload.DueDate, missionPayload.EvidenceType, missionPayload.SubmissionType); len(errors) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = errors
+				return c.JSON(response)
 			}
 
-			if err := db.Create(&newMission).Error; err != nil {
+			if err := db.Save(&mission).Error; err != nil {
 				tx.Rollback()
 				response.Status = false
 				response.Message = "Failed to create mission"
 				return c.JSON(response)
 			}
+			keptMissions = append(keptMissions, mission.ID)
+		}
+		removed := db.Where("THESIS_ID = ?", thesis.ID)
+		if len(keptMissions) > 0 {
+			removed = removed.Where("ID NOT IN ?", keptMissions)
+		}
+		if err := removed.Delete(&model.Mission{}).Error; err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Failed to create mission"
+			return c.JSON(response)
 		}
+		thesis.Missions = nil
 
 		// Handle Programs
 		for _, programPayload := range thesisPayload.Programs {
@@ -436,7 +658,16 @@ func UpdateThesis(c *fiber.Ctx) error {
 		if thesis.TitleEn != thesisPayload.TitleEn {
 			thesis.TitleEn = thesisPayload.TitleEn
 		}
-		if thesis.ApprovalStatus != thesisPayload.ApprovalStatus {
+		if thesisPayload.ApprovalStatus != 0 && thesis.ApprovalStatus != thesisPayload.ApprovalStatus {
+			// Chỉnh sửa đề tài chỉ chuyển giữa nháp và chờ duyệt; quyết định duyệt đi qua
+			// UpdateThesisApprovalStatus hoặc hàng đợi duyệt để được ghi lịch sử và thông báo
+			if !draftTransition(thesis.ApprovalStatus, thesisPayload.ApprovalStatus) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+				response.ValidateError = map[string]string{"approvalStatus": config.GetMessageCode("INVALID_STATUS_TRANSITION")}
+				return c.JSON(response)
+			}
 			thesis.ApprovalStatus = thesisPayload.ApprovalStatus
 		}
 		if thesis.ThesisType != thesisPayload.ThesisType {
@@ -451,6 +682,17 @@ func UpdateThesis(c *fiber.Ctx) error {
 		if thesis.ThesisInfo != thesisPayload.ThesisInfo {
 			thesis.ThesisInfo = thesisPayload.ThesisInfo
 		}
+		if thesisPayload.SubjectID != 0 && (thesis.SubjectID == nil || *thesis.SubjectID != thesisPayload.SubjectID) {
+			subjectID := thesisPayload.SubjectID
+			if !organizationController.SubjectExists(tx, subjectID) || !scope.Allows(&subjectID) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = map[string]string{"subjectID": config.GetMessageCode("NOT_ID_EXISTS")}
+				return c.JSON(response)
+			}
+			thesis.SubjectID = &subjectID
+		}
 		if !thesis.StartTime.Equal(thesisPayload.StartTime) {
 			thesis.StartTime = thesisPayload.StartTime
 		}
@@ -459,33 +701,97 @@ func UpdateThesis(c *fiber.Ctx) error {
 		}
 
 		// Update thesis tasks
+		// Công việc có ID được sửa tại chỗ để giữ người thực hiện, phụ thuộc và công việc con
+		existingTasks := map[uint]model.ThesisTask{}
+		for _, task := range thesis.ThesisTask {
+			existingTasks[task.ID] = task
+		}
+		keptTasks := []uint{}
 		for _, taskPayload := range thesisPayload.ThesisTask {
 			var task model.ThesisTask
-			if taskPayload.UUID != "" {
-				if err := db.First(&task, "uuid = ?", taskPayload.UUID).Error; err != nil {
+			if taskPayload.ID != 0 {
+				existing, ok := existingTasks[taskPayload.ID]
+				if !ok {
 					tx.Rollback()
 					response.Status = false
 					response.Message = config.GetMessageCode("NOT_ID_EXISTS")
 					return c.JSON(response)
 				}
+				task = existing
 			}
-			task.Deadline = taskPayload.Deadline
+			deadline, err := parseDeadline(taskPayload.Deadline)
+			if err != nil {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("FORMAT_DATETIME")
+				response.ValidateError = map[string]string{"deadline": taskPayload.Deadline}
+				return c.JSON(response)
+			}
+			status, priority, errors := normalizeTaskInput(taskPayload.Status, taskPayload.Priority)
+			if len(errors) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("PARAM_ERROR")
+				response.ValidateError = errors
+				return c.JSON(response)
+			}
+			if current, ok := model.NormalizeTaskStatus(task.Status); task.ID != 0 && ok && !model.CanTransition(current, status) {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("INVALID_STATUS_TRANSITION")
+				response.ValidateError = map[string]interface{}{"from": current, "to": status, "allowed": model.TaskTransitions[current]}
+				return c.JSON(response)
+			}
+			// Như khi kéo thẻ trên bảng: công việc trước chưa đóng hoặc công việc con còn mở thì không chuyển được
+			if current, _ := model.NormalizeTaskStatus(task.Status); task.ID != 0 && current != status {
+				blockers, err := taskBlockers(db, &task, status)
+				if err != nil {
+					tx.Rollback()
+					response.Status = false
+					response.Message = config.GetMessageCode("SYSTEM_ERROR")
+					return c.JSON(response)
+				}
+				if len(blockers) > 0 {
+					tx.Rollback()
+					response.Status = false
+					response.Message = config.GetMessageCode("TASK_BLOCKED")
+					response.ValidateError = blockers
+					return c.JSON(response)
+				}
+			}
+			task.Deadline = deadline
 			task.Description = taskPayload.Description
 			task.EndTime = taskPayload.EndTime
 			task.Note = taskPayload.Note
-			task.Priority = taskPayload.Priority
+			task.Priority = priority
 			task.StartTime = taskPayload.StartTime
-			task.Status = taskPayload.Status
+			task.Status = status
 			task.Title = taskPayload.Title
 			task.ThesisID = thesis.ID
 
+			if errors := validateTaskTimes(&task, &thesis); len(errors) > 0 {
+				tx.Rollback()
+				response.Status = false
+				response.Message = config.GetMessageCode("INVALID_TIME_RANGE")
+				response.ValidateError = errors
+				return c.JSON(response)
+			}
+
 			if err := db.Save(&task).Error; err != nil {
 				tx.Rollback()
 				response.Status = false
 				response.Message = config.GetMessageCode("SYSTEM_ERROR")
 				return c.JSON(response)
 			}
+			keptTasks = append(keptTasks, task.ID)
+		}
+		if err := removeThesisTasks(db, thesis.ID, keptTasks); err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = config.GetMessageCode("SYSTEM_ERROR")
+			return c.JSON(response)
 		}
+		thesis.ThesisTask = nil
 
 		// Save the updated thesis
 		if err := db.Save(&thesis).Error; err != nil {
@@ -495,6 +801,16 @@ func UpdateThesis(c *fiber.Ctx) error {
 			return c.JSON(response)
 		}
 
+		// Cảnh báo các đề tài gần giống đã có
+		warnings, err := detectDuplicates(db, &thesis)
+		if err != nil {
+			tx.Rollback()
+			response.Status = false
+			response.Message = "Failed to check duplicate topics"
+			return c.JSON(response)
+		}
+		duplicateReports = append(duplicateReports, model.ThesisDuplicateReport{ThesisID: thesis.ID, Warnings: warnings})
+
 		for _, studentIDPayload := range thesisPayload.Students {
 			var student modell.Student
 			if err := tx.First(&student, "uuid = ?", studentIDPayload.StudentID).Error; err != nil {
@@ -505,6 +821,7 @@ func UpdateThesis(c *fiber.Ctx) error {
 		}
 	}
 
+	response.Data = duplicateReports
 	response.Status = true
 	response.Message = "Thesis(s) updated successfully"
 	return c.JSON(response)
@@ -586,6 +903,12 @@ func AddStudentToThesis(c *fiber.Ctx) error {
 		response.Message = "Student not found"
 		return c.JSON(response)
 	}
+	if !StudentEligible(tx, thesis.ID, student) {
+		tx.Rollback()
+		response.Status = false
+		response.Message = config.GetMessageCode("PROGRAM_NOT_ELIGIBLE")
+		return c.JSON(response)
+	}
 
 	if err := tx.Model(&thesis).Association("Students").Append(&student); err != nil {
 		tx.Rollback()
@@ -828,11 +1151,13 @@ func CreateTestTheses(c *fiber.Ctx) error {
 
 		// Create thesis tasks for this thesis
 		for _, taskPayload := range thesisPayload.ThesisTask {
+			deadline, _ := parseDeadline(taskPayload.Deadline)
+			status, priority, _ := normalizeTaskInput(taskPayload.Status, taskPayload.Priority)
 			newTask := model.ThesisTask{
 				Title:       taskPayload.Title,
-				Deadline:    taskPayload.Deadline,
-				Status:      taskPayload.Status,
-				Priority:    taskPayload.Priority,
+				Deadline:    deadline,
+				Status:      status,
+				Priority:    priority,
 				Description: taskPayload.Description,
 				Note:        taskPayload.Note,
 				StartTime:   taskPayload.StartTime,
@@ -0,0 +1,23 @@
+package thesisRoute
+
+import (
+	model "app/modules/thesis/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// normalizeApprovalStatus đưa các luận văn có APPROVAL_STATUS ngoài bảng giá trị (dữ liệu trước khi có
+// model.ApprovalDraft..ApprovalRevision) về nháp để tác giả gửi duyệt lại; chạy lại không thay đổi gì thêm
+func normalizeApprovalStatus(db *gorm.DB) {
+	statuses := []int{model.ApprovalDraft, model.ApprovalPending, model.ApprovalApproved, model.ApprovalRejected, model.ApprovalRevision}
+	result := db.Model(&model.Thesis{}).Where("APPROVAL_STATUS IS NULL OR APPROVAL_STATUS NOT IN ?", statuses).
+		UpdateColumn("APPROVAL_STATUS", model.ApprovalDraft)
+	if result.Error != nil {
+		log.Printf("thesis approval status not normalized: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("thesis approval status: %d theses with an unknown status set to draft", result.RowsAffected)
+	}
+}
@@ -0,0 +1,51 @@
+package thesisRoute
+
+import (
+	"app/core"
+	model "app/modules/thesis/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// MigrateTaskDeadlines chuyển hạn chót dạng chuỗi cũ sang DEADLINE_AT theo giờ của trường.
+// Dòng không đọc được được giữ nguyên, đánh dấu DEADLINE_REJECTED và trả về trong báo cáo để sửa tay rồi chạy lại.
+// Dòng đã đánh dấu mà chưa sửa chỉ được xét lại khi includeRejected.
+func MigrateTaskDeadlines(db *gorm.DB, includeRejected bool) (model.DeadlineMigrationReport, error) {
+	report := model.DeadlineMigrationReport{Unparseable: []model.DeadlineMigrationRow{}}
+
+	query := db.Where("DEADLINE_AT IS NULL AND DEADLINE IS NOT NULL")
+	if !includeRejected {
+		query = query.Where("DEADLINE_REJECTED IS NULL OR DEADLINE_REJECTED <> DEADLINE")
+	}
+	var tasks []model.ThesisTask
+	if err := query.Order("ID").Find(&tasks).Error; err != nil {
+		return report, err
+	}
+
+	for _, task := range tasks {
+		report.Scanned++
+		deadline, err := core.ParseCampusTime(task.LegacyDeadline)
+		if err != nil {
+			report.Unparseable = append(report.Unparseable, model.DeadlineMigrationRow{
+				TaskID:   task.ID,
+				ThesisID: task.ThesisID,
+				Title:    task.Title,
+				Value:    task.LegacyDeadline,
+			})
+			if task.LegacyDeadlineRejected == task.LegacyDeadline {
+				continue
+			}
+			if err := db.Model(&model.ThesisTask{}).Where("ID = ?", task.ID).UpdateColumn("DEADLINE_REJECTED", task.LegacyDeadline).Error; err != nil {
+				return report, err
+			}
+			log.Printf("task deadline not migrated: task=%d thesis=%d value=%q", task.ID, task.ThesisID, task.LegacyDeadline)
+			continue
+		}
+		if err := db.Model(&model.ThesisTask{}).Where("ID = ?", task.ID).UpdateColumn("DEADLINE_AT", deadline).Error; err != nil {
+			return report, err
+		}
+		report.Migrated++
+	}
+	return report, nil
+}
@@ -12,5 +12,13 @@ func MigrateTable() bool {
 	db.AutoMigrate(&model.ThesisTask{})
 	db.AutoMigrate(&model.Mission{})
 	db.AutoMigrate(&model.Program{})
+	db.AutoMigrate(&model.ThesisDuplicate{})
+	db.AutoMigrate(&model.Keyword{})
+	db.AutoMigrate(&model.TaskDependency{})
+	db.AutoMigrate(&model.ThesisReview{})
+
+	normalizeApprovalStatus(db)
+	MigrateTaskDeadlines(db, false)
+	normalizeTasks(db)
 	return true
 }
@@ -0,0 +1,35 @@
+package thesisRoute
+
+import (
+	model "app/modules/thesis/model"
+
+	"gorm.io/gorm"
+)
+
+// normalizeTasks đưa trạng thái và độ ưu tiên tự do trước đây về các giá trị đã định nghĩa.
+// Trạng thái không nhận ra được đưa về TODO, độ ưu tiên ngoài khoảng về mức trung bình.
+func normalizeTasks(db *gorm.DB) error {
+	var tasks []model.ThesisTask
+	if err := db.Where("STATUS NOT IN ? OR STATUS IS NULL OR PRIORITY NOT BETWEEN ? AND ? OR PRIORITY IS NULL",
+		model.TaskStatuses, model.TaskPriorityLow, model.TaskPriorityUrgent).Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		status, ok := model.NormalizeTaskStatus(task.Status)
+		if !ok {
+			status = model.TaskStatusTodo
+		}
+		priority := task.Priority
+		if !model.ValidTaskPriority(priority) {
+			priority = model.TaskPriorityMedium
+		}
+		if err := db.Model(&model.ThesisTask{}).Where("ID = ?", task.ID).UpdateColumns(map[string]interface{}{
+			"STATUS":   status,
+			"PRIORITY": priority,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,23 +1,74 @@
 package model
 
 import (
+    "app/core"
     "app/model"
     modelll "app/modules/advisor/model"
+    modelProgram "app/modules/program/model"
+    modelResearchArea "app/modules/researchArea/model"
     modell "app/modules/student/model"
+    "strings"
     "time"
+
+    "gorm.io/gorm"
 )
 
 
+// Giá trị APPROVAL_STATUS: 1 nháp, 2 chờ duyệt, 3 đã duyệt, 4 bị từ chối, 5 trả về để sửa.
+// Dữ liệu cũ có giá trị ngoài khoảng này được đưa về nháp khi migrate (xem normalizeApprovalStatus).
+var ApprovalDraft, ApprovalPending, ApprovalApproved, ApprovalRejected, ApprovalRevision = 1, 2, 3, 4, 5
+
+// Quyết định của trưởng bộ môn trong hàng đợi duyệt; REVISE trả đề tài về cho tác giả sửa (ApprovalRevision)
+var ReviewApprove, ReviewReject, ReviewRevise = "APPROVE", "REJECT", "REVISE"
+
+var TaskStatusTodo, TaskStatusInProgress, TaskStatusInReview, TaskStatusDone, TaskStatusCancelled = "TODO", "IN_PROGRESS", "IN_REVIEW", "DONE", "CANCELLED"
+
+var TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent = 1, 2, 3, 4
+
+// TaskStatuses theo thứ tự các cột trên bảng công việc
+var TaskStatuses = []string{TaskStatusTodo, TaskStatusInProgress, TaskStatusInReview, TaskStatusDone, TaskStatusCancelled}
+
+// TaskTransitions là các bước chuyển trạng thái hợp lệ
+var TaskTransitions = map[string][]string{
+	TaskStatusTodo:       {TaskStatusInProgress, TaskStatusCancelled},
+	TaskStatusInProgress: {TaskStatusTodo, TaskStatusInReview, TaskStatusDone, TaskStatusCancelled},
+	TaskStatusInReview:   {TaskStatusInProgress, TaskStatusDone},
+	TaskStatusDone:       {TaskStatusInProgress},
+	TaskStatusCancelled:  {TaskStatusTodo},
+}
+
+var DeliverablePending, DeliverableSubmitted, DeliverableAccepted, DeliverableRejected = "PENDING", "SUBMITTED", "ACCEPTED", "REJECTED"
+
+// Loại minh chứng bắt buộc của sản phẩm bàn giao
+var EvidenceNone, EvidenceAttachment, EvidenceTask = "NONE", "ATTACHMENT", "TASK"
+
+// Program: Value là ID chương trình đào tạo trong danh mục (TBL_STUDY_PROGRAM) mà đề tài dành cho.
+// Đề tài không có chương trình nào thì mở cho mọi chương trình.
 type Program struct {
 	model.Header
-	Value    int  `json:"value" validate:"required" gorm:"column:VALUE"`
-	ThesisID uint `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Value    int                   `json:"value" validate:"required" gorm:"column:VALUE"`
+	ThesisID uint                  `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Catalog  *modelProgram.Program `json:"catalog,omitempty" gorm:"foreignKey:Value;-:migration"`
 }
 
+// Mission là sản phẩm cần bàn giao của luận văn (Value là tên sản phẩm). Sinh viên gắn minh chứng
+// (file nộp hoặc công việc đã xong), giảng viên hướng dẫn nghiệm thu hoặc trả lại kèm nhận xét.
 type Mission struct {
 	model.Header
-	Value    string `json:"value" validate:"required" gorm:"column:VALUE"`
-	ThesisID uint   `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Value          string     `json:"value" validate:"required" gorm:"column:VALUE"`
+	ThesisID       uint       `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Description    string     `json:"description" gorm:"column:DESCRIPTION"`
+	DueDate        *time.Time `json:"dueDate" gorm:"column:DUE_AT"`
+	EvidenceType   string     `json:"evidenceType" gorm:"column:EVIDENCE_TYPE;size:20;default:NONE"`
+	SubmissionType string     `json:"submissionType" gorm:"column:SUBMISSION_TYPE;size:20"`
+	AttachmentID   *uint      `json:"attachmentID" gorm:"column:ATTACHMENT_ID"`
+	TaskID         *uint      `json:"taskID" gorm:"column:TASK_ID"`
+	Status         string     `json:"status" gorm:"column:STATUS;size:20;default:PENDING"`
+	SubmittedAt    *time.Time `json:"submittedAt" gorm:"column:SUBMITTED_AT"`
+	ReviewedBy     string     `json:"reviewedBy" gorm:"column:REVIEWED_BY;size:50"`
+	ReviewedAt     *time.Time `json:"reviewedAt" gorm:"column:REVIEWED_AT"`
+	ReviewComment  string     `json:"reviewComment" gorm:"column:REVIEW_COMMENT"`
+	Overdue        bool       `json:"overdue" gorm:"-"`
 }
 
 type CreateProgram struct {
@@ -31,13 +82,45 @@ type UpdateProgram struct {
 }
 
 type UpdateMission struct {
-	ID    uint   `json:"id"`
-	Value string `json:"value" validate:"required"`
+	ID             uint   `json:"id"`
+	Value          string `json:"value" validate:"required"`
+	Description    string `json:"description"`
+	DueDate        string `json:"dueDate"`
+	EvidenceType   string `json:"evidenceType"`
+	SubmissionType string `json:"submissionType"`
 }
 
 type CreateMission struct {
-	ID uint `json:"id"`
-	Value string `json:"value" validate:"required"`
+	ID             uint   `json:"id"`
+	Value          string `json:"value" validate:"required"`
+	Description    string `json:"description"`
+	DueDate        string `json:"dueDate"`
+	EvidenceType   string `json:"evidenceType"`
+	SubmissionType string `json:"submissionType"`
+}
+
+// SubmitDeliverableEvidence gắn minh chứng: một file nộp của luận văn hoặc một công việc đã xong
+type SubmitDeliverableEvidence struct {
+	AttachmentID *uint `json:"attachmentID"`
+	TaskID       *uint `json:"taskID"`
+}
+
+type ReviewDeliverable struct {
+	Status  string `json:"status" validate:"required"`
+	Comment string `json:"comment"`
+}
+
+type DeliverableSummary struct {
+	ThesisID  uint      `json:"thesisID"`
+	Total     int       `json:"total"`
+	Accepted  int       `json:"accepted"`
+	Submitted int       `json:"submitted"`
+	Rejected  int       `json:"rejected"`
+	Pending   int       `json:"pending"`
+	Overdue   int       `json:"overdue"`
+	AllMet    bool      `json:"allMet"`
+	Unmet     []Mission `json:"unmet"`
+	Items     []Mission `json:"items"`
 }
 
 type Thesis struct {
@@ -56,12 +139,98 @@ type Thesis struct {
 	Programs      []Program         `json:"programs" gorm:"foreignKey:THESIS_ID"`
 	StartTime time.Time `json:"startTime" gorm:"column:START_TIME"`
 	EndTime   time.Time `json:"endTime" gorm:"column:END_TIME"`
+	DuplicateOverrideRequired bool   `json:"duplicateOverrideRequired" gorm:"column:DUPLICATE_OVERRIDE_REQUIRED;default:false"`
+	DuplicateOverridden       bool   `json:"duplicateOverridden" gorm:"column:DUPLICATE_OVERRIDDEN;default:false"`
+	DuplicateOverrideBy       string `json:"duplicateOverrideBy" gorm:"column:DUPLICATE_OVERRIDE_BY;size:50"`
+	DuplicateOverrideNote     string `json:"duplicateOverrideNote" gorm:"column:DUPLICATE_OVERRIDE_NOTE"`
+	SourceThesisID            *uint  `json:"sourceThesisID" gorm:"column:SOURCE_THESIS_ID;index"`
+	ResearchAreas []modelResearchArea.ResearchArea `json:"researchAreas" gorm:"many2many:TBL_THESIS_RESEARCH_AREAS;joinForeignKey:THESIS_ID;joinReferences:RESEARCH_AREA_ID"`
+	Keywords      []Keyword                        `json:"keywords" gorm:"foreignKey:THESIS_ID"`
+	SubjectID     *uint      `json:"subjectID" gorm:"column:SUBJECT_ID;index"`
+	ReviewComment string     `json:"reviewComment" gorm:"column:REVIEW_COMMENT"`
+	ReviewedBy    string     `json:"reviewedBy" gorm:"column:REVIEWED_BY;size:50"`
+	ReviewedAt    *time.Time `json:"reviewedAt" gorm:"column:REVIEWED_AT"`
+	CommitteeID   *uint      `json:"committeeID" gorm:"column:COMMITTEE_ID;index"`
+}
+
+// ThesisReview lưu lịch sử các quyết định duyệt đề tài
+type ThesisReview struct {
+	model.Header
+	ThesisID       uint   `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	Decision       string `json:"decision" gorm:"column:DECISION;size:20"`
+	ApprovalStatus int    `json:"approvalStatus" gorm:"column:APPROVAL_STATUS"`
+	Comment        string `json:"comment" gorm:"column:COMMENT"`
+}
+
+// Keyword là từ khóa tự do của đề tài, Norm là dạng bỏ dấu dùng để lọc
+type Keyword struct {
+	model.Header
+	Value    string `json:"value" gorm:"column:VALUE;size:100"`
+	Norm     string `json:"-" gorm:"column:NORM;size:100;index"`
+	ThesisID uint   `json:"thesisID" gorm:"column:THESIS_ID;index"`
+}
+
+type ThesisClassification struct {
+	ResearchAreaIDs []uint   `json:"researchAreaIDs"`
+	Keywords        []string `json:"keywords"`
+}
+
+// ThesisDuplicate lưu các đề tài gần giống được phát hiện khi tạo/cập nhật luận văn
+type ThesisDuplicate struct {
+	model.Header
+	ThesisID      uint    `json:"thesisID" gorm:"column:THESIS_ID;index"`
+	DuplicateOfID uint    `json:"duplicateOfID" gorm:"column:DUPLICATE_OF_ID"`
+	TitleVi       string  `json:"titleVi" gorm:"column:TITLE_VI"`
+	TitleEn       string  `json:"titleEn" gorm:"column:TITLE_EN"`
+	Semester      string  `json:"semester" gorm:"column:SEMESTER"`
+	Score         float64 `json:"score" gorm:"column:SCORE"`
+	TitleScore    float64 `json:"titleScore" gorm:"column:TITLE_SCORE"`
+	InfoScore     float64 `json:"infoScore" gorm:"column:INFO_SCORE"`
+}
+
+type CloneThesis struct {
+	Semester  string    `json:"semester" validate:"required"`
+	TitleVi   string    `json:"titleVi"`
+	TitleEn   string    `json:"titleEn"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// ThesisLineageNode là một đề tài trong chuỗi nhân bản qua các học kỳ
+type ThesisLineageNode struct {
+	ID             uint                `json:"id"`
+	TitleVi        string              `json:"titleVi"`
+	TitleEn        string              `json:"titleEn"`
+	Semester       string              `json:"semester"`
+	ApprovalStatus int                 `json:"approvalStatus"`
+	StudentCount   int                 `json:"studentCount"`
+	Clones         []ThesisLineageNode `json:"clones"`
+}
+
+type ThesisLineage struct {
+	Ancestors []ThesisLineageNode `json:"ancestors"`
+	Thesis    ThesisLineageNode   `json:"thesis"`
+}
+
+type ThesisDuplicateReport struct {
+	ThesisID uint              `json:"thesisID"`
+	Warnings []ThesisDuplicate `json:"warnings"`
+}
+
+type RequireDuplicateOverride struct {
+	ThesisID uint `json:"thesisID" validate:"required"`
+	Required bool `json:"required"`
+}
+
+type DuplicateOverride struct {
+	ThesisID uint   `json:"thesisID" validate:"required"`
+	Note     string `json:"note" validate:"required"`
 }
 
 type ThesisTask struct {
 	model.Header
 	Title       string    `json:"title" validate:"required" gorm:"column:TITLE"`
-	Deadline    string    `json:"deadline" validate:"required" gorm:"column:DEADLINE"`
+	Deadline    *time.Time `json:"deadline" gorm:"column:DEADLINE_AT"`
 	Status      string    `json:"status" validate:"required" gorm:"column:STATUS"`
 	Priority    int       `json:"priority" validate:"required" gorm:"column:PRIORITY"`
 	Description string    `json:"description" gorm:"column:DESCRIPTION"`
@@ -69,13 +238,105 @@ type ThesisTask struct {
 	ThesisID    uint      `json:"thesisID" gorm:"column:THESIS_ID;index"`
 	StartTime   time.Time `json:"startTime" gorm:"column:START_TIME"`
 	EndTime     time.Time `json:"endTime" gorm:"column:END_TIME"`
+	// Hạn chót dạng chuỗi tự do trước đây, chỉ giữ lại để chuyển đổi dữ liệu cũ
+	LegacyDeadline string `json:"-" gorm:"column:DEADLINE"`
+	// Giá trị hạn chót cũ đã được ghi nhận là không đọc được; sửa DEADLINE thì dòng được chuyển đổi lại
+	LegacyDeadlineRejected string `json:"-" gorm:"column:DEADLINE_REJECTED"`
+	Overdue        bool   `json:"overdue" gorm:"-"`
+	ParentID       *uint  `json:"parentID" gorm:"column:PARENT_ID;index"`
+	Position       int    `json:"position" gorm:"column:POSITION;default:0"`
+	Subtasks       []ThesisTask      `json:"subtasks,omitempty" gorm:"foreignKey:PARENT_ID"`
+	Assignees      []modell.Student  `json:"assignees" gorm:"many2many:TBL_THESIS_TASK_ASSIGNEES;joinForeignKey:TASK_ID;joinReferences:STUDENT_ID"`
+	Dependencies   []TaskDependency  `json:"dependencies" gorm:"foreignKey:TASK_ID"`
+	TemplateID     *uint             `json:"templateID" gorm:"column:TEMPLATE_ID;index"`
+}
+
+// TaskDependency: công việc TaskID chỉ được bắt đầu khi DependsOnID đã hoàn thành (finish-to-start)
+type TaskDependency struct {
+	model.Header
+	TaskID      uint `json:"taskID" gorm:"column:TASK_ID;uniqueIndex:IDX_TASK_DEPENDENCY"`
+	DependsOnID uint `json:"dependsOnID" gorm:"column:DEPENDS_ON_ID;uniqueIndex:IDX_TASK_DEPENDENCY"`
+}
+
+type MoveThesisTask struct {
+	Status   string `json:"status"`
+	Position int    `json:"position"`
+}
+
+type UpdateTaskAssignees struct {
+	StudentIDs []uint `json:"studentIDs"`
+}
+
+type UpdateTaskParent struct {
+	ParentID *uint `json:"parentID"`
+}
+
+type AddTaskDependency struct {
+	DependsOnID uint `json:"dependsOnID" validate:"required"`
+}
+
+type TaskBoardColumn struct {
+	Status string       `json:"status"`
+	Tasks  []ThesisTask `json:"tasks"`
+}
+
+// TaskSchedule là một dòng của biểu đồ Gantt; Slack âm nghĩa là công việc đang làm trễ hạn luận văn
+type TaskSchedule struct {
+	TaskID         uint       `json:"taskID"`
+	Title          string     `json:"title"`
+	Status         string     `json:"status"`
+	ParentID       *uint      `json:"parentID"`
+	DependsOn      []uint     `json:"dependsOn"`
+	PlannedStart   *time.Time `json:"plannedStart"`
+	PlannedEnd     *time.Time `json:"plannedEnd"`
+	EarliestStart  time.Time  `json:"earliestStart"`
+	EarliestFinish time.Time  `json:"earliestFinish"`
+	LatestStart    time.Time  `json:"latestStart"`
+	LatestFinish   time.Time  `json:"latestFinish"`
+	DurationHours  float64    `json:"durationHours"`
+	SlackHours     float64    `json:"slackHours"`
+	Critical       bool       `json:"critical"`
+	Estimated      bool       `json:"estimated"`
+}
+
+type ThesisSchedule struct {
+	ThesisID            uint           `json:"thesisID"`
+	GeneratedAt         time.Time      `json:"generatedAt"`
+	ProjectStart        *time.Time     `json:"projectStart"`
+	ProjectedCompletion *time.Time     `json:"projectedCompletion"`
+	ThesisEndTime       *time.Time     `json:"thesisEndTime"`
+	OnTrack             bool           `json:"onTrack"`
+	DelayHours          float64        `json:"delayHours"`
+	CriticalPath        []uint         `json:"criticalPath"`
+	Tasks               []TaskSchedule `json:"tasks"`
+	Warnings            []string       `json:"warnings"`
+}
+
+type TaskBoard struct {
+	ThesisID    uint                `json:"thesisID"`
+	Columns     []TaskBoardColumn   `json:"columns"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// DeadlineMigrationRow là một công việc có hạn chót cũ không đọc được
+type DeadlineMigrationRow struct {
+	TaskID   uint   `json:"taskID"`
+	ThesisID uint   `json:"thesisID"`
+	Title    string `json:"title"`
+	Value    string `json:"value"`
+}
+
+type DeadlineMigrationReport struct {
+	Scanned     int                    `json:"scanned"`
+	Migrated    int                    `json:"migrated"`
+	Unparseable []DeadlineMigrationRow `json:"unparseable"`
 }
 
 type CreateThesis struct {
 	ID uint `json:"id"`
 	TitleVi        string                    `json:"titleVi" validate:"required"`
 	TitleEn        string                    `json:"titleEn" validate:"required"`
-	ApprovalStatus int                       `json:"approvalStatus" validate:"required"`
+	ApprovalStatus int                       `json:"approvalStatus"` // ApprovalPending để gửi duyệt ngay, còn lại là bản nháp
 	ThesisType     int                       `json:"thesisType" validate:"required"`
 	Semester       string                    `json:"semester" validate:"required"`
 	Programs       []CreateProgram           `json:"programs"`
@@ -85,6 +346,7 @@ type CreateThesis struct {
 	Students       []CreateStudentForThesis `json:"students"`
 	Advisors       []CreateAdvisorForThesis `json:"advisors"`
 	Missions       []CreateMission           `json:"missions"`
+	SubjectID      uint                      `json:"subjectID"`
 	StartTime time.Time `json:"startTime"`
 	EndTime   time.Time `json:"endTime"`
 }
@@ -103,6 +365,7 @@ type UpdateThesis struct {
 	Students       []*CreateStudentForThesis `json:"students"`
 	Advisors       []*CreateAdvisorForThesis `json:"advisors"`
 	Missions       []UpdateMission           `json:"missions"`
+	SubjectID      uint                      `json:"subjectID"`
 	StartTime      time.Time                 `json:"startTime"`
 	EndTime        time.Time                 `json:"endTime"`
 }
@@ -120,6 +383,7 @@ type CreateThesisTask struct {
 }
 
 type UpdateThesisTask struct {
+	ID          uint      `json:"id"`
 	UUID        string    `json:"uuid"`
 	Title       string    `json:"title" validate:"required"`
 	Deadline    string    `json:"deadline" validate:"required"`
@@ -139,9 +403,49 @@ type CreateAdvisorForThesis struct {
 	AdvisorID uint `json:"id"`
 }
 
+type ReviewDecision struct {
+	ThesisID uint   `json:"thesisID" validate:"required"`
+	Decision string `json:"decision" validate:"required"`
+	Comment  string `json:"comment"`
+}
+
+// ReviewDecisionResult là kết quả của từng đề tài trong một lần duyệt hàng loạt
+type ReviewDecisionResult struct {
+	ThesisID       uint   `json:"thesisID"`
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	ApprovalStatus int    `json:"approvalStatus"`
+}
+
+type ReviewQueue struct {
+	Total int64    `json:"total"`
+	Items []Thesis `json:"items"`
+}
+
 type ApprovalStatusForThesis struct {
 	ApprovalStatus int
 	ThesisID uint `json:"thesis_id"` 
+	Comment string `json:"comment"`
+}
+
+// HasStudent kiểm tra sinh viên có thuộc luận văn hay không (cần Preload Students)
+func (t Thesis) HasStudent(studentID uint) bool {
+	for _, student := range t.Students {
+		if student.ID == studentID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAdvisor kiểm tra giảng viên có hướng dẫn luận văn hay không (cần Preload Advisors)
+func (t Thesis) HasAdvisor(advisorID uint) bool {
+	for _, advisor := range t.Advisors {
+		if advisor.ID == advisorID {
+			return true
+		}
+	}
+	return false
 }
 
 func (Thesis) TableName() string {
@@ -152,6 +456,105 @@ func (ThesisTask) TableName() string {
 	return "TBL_THESIS_TASK"
 }
 
+func (TaskDependency) TableName() string {
+	return "TBL_THESIS_TASK_DEPENDENCY"
+}
+
+func (t ThesisTask) IsDone() bool {
+	return t.Status == TaskStatusDone
+}
+
+// IsClosed: công việc đã xong hoặc đã hủy thì không còn chặn công việc khác
+func (t ThesisTask) IsClosed() bool {
+	return t.Status == TaskStatusDone || t.Status == TaskStatusCancelled
+}
+
+// NormalizeTaskStatus chuẩn hóa trạng thái người dùng nhập, trả về false nếu không hợp lệ.
+// "Completed" là giá trị cũ còn trong dữ liệu trước khi có trạng thái chuẩn.
+func NormalizeTaskStatus(status string) (string, bool) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	status = strings.NewReplacer(" ", "_", "-", "_").Replace(status)
+	switch status {
+	case "":
+		return TaskStatusTodo, true
+	case "COMPLETED":
+		return TaskStatusDone, true
+	}
+	for _, s := range TaskStatuses {
+		if s == status {
+			return s, true
+		}
+	}
+	return status, false
+}
+
+// CanTransition kiểm tra bước chuyển trạng thái; giữ nguyên trạng thái luôn hợp lệ
+func CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range TaskTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidTaskPriority(priority int) bool {
+	return priority >= TaskPriorityLow && priority <= TaskPriorityUrgent
+}
+
+// IsOverdue cho biết công việc chưa đóng (xong hoặc hủy) mà đã quá hạn chót tại thời điểm now
+func (t ThesisTask) IsOverdue(now time.Time) bool {
+	return t.Deadline != nil && !t.IsClosed() && now.After(*t.Deadline)
+}
+
+// AfterFind đưa các mốc thời gian về giờ của trường và tính trạng thái quá hạn khi đọc
+func (t *ThesisTask) AfterFind(tx *gorm.DB) error {
+	loc := core.CampusLocation()
+	if t.Deadline != nil {
+		deadline := t.Deadline.In(loc)
+		t.Deadline = &deadline
+	}
+	if !t.StartTime.IsZero() {
+		t.StartTime = t.StartTime.In(loc)
+	}
+	if !t.EndTime.IsZero() {
+		t.EndTime = t.EndTime.In(loc)
+	}
+	t.Overdue = t.IsOverdue(core.Now())
+	return nil
+}
+
+// IsMet: sản phẩm đã được giảng viên nghiệm thu
+func (m Mission) IsMet() bool {
+	return m.Status == DeliverableAccepted
+}
+
+// AfterFind đưa các mốc thời gian về giờ của trường; sản phẩm chưa nghiệm thu mà quá hạn thì Overdue
+func (m *Mission) AfterFind(tx *gorm.DB) error {
+	loc := core.CampusLocation()
+	inCampus := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		local := t.In(loc)
+		return &local
+	}
+	m.DueDate = inCampus(m.DueDate)
+	m.SubmittedAt = inCampus(m.SubmittedAt)
+	m.ReviewedAt = inCampus(m.ReviewedAt)
+	if m.Status == "" {
+		m.Status = DeliverablePending
+	}
+	if m.EvidenceType == "" {
+		m.EvidenceType = EvidenceNone
+	}
+	m.Overdue = !m.IsMet() && m.DueDate != nil && m.DueDate.Before(core.Now())
+	return nil
+}
+
 func (Mission) TableName() string {
 	return "TBL_THESIS_MISSIONS"
 }
@@ -159,3 +562,26 @@ func (Mission) TableName() string {
 func (Program) TableName() string {
 	return "TBL_THESIS_PROGRAMS"
 }
+
+func (Keyword) TableName() string {
+	return "TBL_THESIS_KEYWORDS"
+}
+
+func (ThesisReview) TableName() string {
+	return "TBL_THESIS_REVIEW"
+}
+
+func (ThesisDuplicate) TableName() string {
+	return "TBL_THESIS_DUPLICATE"
+}
+
+type ThesisSearchResult struct {
+	Thesis     Thesis            `json:"thesis"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type ThesisSearchResponse struct {
+	Total   int                  `json:"total"`
+	Results []ThesisSearchResult `json:"results"`
+}
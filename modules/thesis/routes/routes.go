@@ -14,13 +14,35 @@ func InitThesisRoutes(app *fiber.App) {
 	thesis.Get("/", controller.ListTheses)
 
 	thesis.Get("/get-by-createby/{createBy}", controller.GetThesesByCreateBy)
+	thesis.Get("/search", controller.SearchTheses)
+	thesis.Get("/open", controller.ListOpenTheses)
+	thesis.Get("/review-queue", controller.GetReviewQueue)
 	thesis.Get("/:uuid", controller.GetThesis)
+	thesis.Get("/:uuid/duplicates", controller.GetThesisDuplicates)
+	thesis.Get("/:uuid/lineage", controller.GetThesisLineage)
+	thesis.Get("/:uuid/board", controller.GetTaskBoard)
+	thesis.Get("/:uuid/schedule", controller.GetThesisSchedule)
+	thesis.Get("/:uuid/deliverables", controller.GetThesisDeliverables)
 
 	thesis.Post("/", controller.CreateThesis)
 	thesis.Post("/create-test", controller.CreateTestTheses)
 	thesis.Post("/status-thesis", controller.PostStatusThesis)
+	thesis.Post("/:uuid/clone", controller.CloneThesis)
+	thesis.Post("/task/migrate-deadlines", controller.MigrateTaskDeadlines)
 	thesis.Put("/", controller.UpdateThesis)
 	thesis.Put("/approval",controller.UpdateThesisApprovalStatus)
+	thesis.Put("/review", controller.BulkReviewTheses)
+	thesis.Put("/duplicate/require", controller.RequireDuplicateOverride)
+	thesis.Put("/duplicate/override", controller.OverrideDuplicate)
+	thesis.Put("/:uuid/classification", controller.UpdateThesisClassification)
+	thesis.Put("/task/:id/move", controller.MoveTask)
+	thesis.Put("/task/:id/assignees", controller.UpdateTaskAssignees)
+	thesis.Put("/task/:id/parent", controller.UpdateTaskParent)
+	thesis.Post("/task/:id/dependencies", controller.AddTaskDependency)
+	thesis.Delete("/task/:id/dependencies/:dependsOnId", controller.RemoveTaskDependency)
+	thesis.Put("/mission/:id", controller.UpdateDeliverable)
+	thesis.Put("/mission/:id/evidence", controller.SubmitDeliverableEvidence)
+	thesis.Put("/mission/:id/review", controller.ReviewDeliverable)
 	thesis.Delete("/:uuid", controller.DeleteThesis)
 
 	// Additional routes for adding and removing students and advisors
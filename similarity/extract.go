@@ -0,0 +1,332 @@
+package similarity
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var ErrUnsupportedType = errors.New("TEXT_EXTRACTION_UNSUPPORTED")
+
+const maxExtractedText = 4 << 20
+
+// ExtractText lấy nội dung chữ từ file nộp (txt, docx, pptx, pdf) mà không cần dịch vụ ngoài
+func ExtractText(data []byte, mimeType string) (string, error) {
+	var text string
+	var err error
+	switch mimeType {
+	case "text/plain", "text/markdown":
+		text = string(data)
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		text, err = extractOOXML(data, func(name string) bool { return name == "word/document.xml" }, "p")
+	case "application/vnd.openxmlformats-officedocument.presentationml.presentation":
+		text, err = extractOOXML(data, func(name string) bool {
+			return strings.HasPrefix(name, "ppt/slides/slide") && strings.HasSuffix(name, ".xml")
+		}, "p")
+	case "application/pdf":
+		text, err = extractPDF(data)
+	default:
+		return "", ErrUnsupportedType
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(text) > maxExtractedText {
+		text = text[:maxExtractedText]
+	}
+	return strings.ToValidUTF8(text, ""), nil
+}
+
+// extractOOXML đọc các phần tử <t> trong file Office Open XML, xuống dòng sau mỗi đoạn <p>
+func extractOOXML(data []byte, want func(string) bool, paragraph string) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var files []*zip.File
+	for _, f := range zr.File {
+		if want(f.Name) {
+			files = append(files, f)
+		}
+	}
+	// slide10.xml phải đứng sau slide9.xml
+	sort.Slice(files, func(i, j int) bool {
+		if len(files[i].Name) != len(files[j].Name) {
+			return len(files[i].Name) < len(files[j].Name)
+		}
+		return files[i].Name < files[j].Name
+	})
+
+	var b strings.Builder
+	for _, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		decoder := xml.NewDecoder(io.LimitReader(rc, 64<<20))
+		inText := false
+		for {
+			tok, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				rc.Close()
+				return "", err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				inText = t.Name.Local == "t"
+			case xml.EndElement:
+				inText = false
+				if t.Name.Local == paragraph {
+					b.WriteByte('\n')
+				}
+			case xml.CharData:
+				if inText {
+					b.Write(t)
+				}
+			}
+		}
+		rc.Close()
+	}
+	return b.String(), nil
+}
+
+var (
+	pdfStreamRe   = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfBfCharRe   = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>`)
+	pdfBfRangeRe  = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>`)
+	pdfSectionsRe = regexp.MustCompile(`(?s)begin(bfchar|bfrange)(.*?)end(bfchar|bfrange)`)
+)
+
+// extractPDF là trình trích chữ PDF tối giản: giải nén các stream FlateDecode,
+// đọc bảng ToUnicode (nếu có) rồi lấy chuỗi trong các toán tử Tj/TJ/'/".
+// Kết quả đủ dùng cho so sánh trùng lặp, không nhằm hiển thị lại văn bản.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data[:minInt(len(data), 1024)]), []byte("%PDF")) {
+		return "", errors.New("PDF_INVALID")
+	}
+
+	var streams [][]byte
+	for _, loc := range pdfStreamRe.FindAllSubmatchIndex(data, -1) {
+		dict := data[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		raw := data[start : start+end]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			zr, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			decoded, _ := ioutil.ReadAll(io.LimitReader(zr, 32<<20))
+			zr.Close()
+			raw = decoded
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// Các bộ lọc khác (ảnh, DCT...) không chứa chữ
+			continue
+		}
+		streams = append(streams, raw)
+	}
+
+	cmap := map[string]string{}
+	for _, s := range streams {
+		if bytes.Contains(s, []byte("begincmap")) {
+			parseToUnicode(s, cmap)
+		}
+	}
+
+	var b strings.Builder
+	for _, s := range streams {
+		if bytes.Contains(s, []byte("begincmap")) {
+			continue
+		}
+		extractPDFContent(s, cmap, &b)
+	}
+	return b.String(), nil
+}
+
+func parseToUnicode(s []byte, cmap map[string]string) {
+	for _, section := range pdfSectionsRe.FindAllSubmatch(s, -1) {
+		body := section[2]
+		if string(section[1]) == "bfchar" {
+			for _, m := range pdfBfCharRe.FindAllSubmatch(body, -1) {
+				cmap[strings.ToUpper(string(m[1]))] = decodeUTF16Hex(string(m[2]))
+			}
+			continue
+		}
+		for _, m := range pdfBfRangeRe.FindAllSubmatch(body, -1) {
+			lo, err1 := strconv.ParseUint(string(m[1]), 16, 32)
+			hi, err2 := strconv.ParseUint(string(m[2]), 16, 32)
+			dst, err3 := strconv.ParseUint(string(m[3]), 16, 32)
+			if err1 != nil || err2 != nil || err3 != nil || hi < lo || hi-lo > 0xFFFF {
+				continue
+			}
+			width := len(m[1])
+			for code := lo; code <= hi; code++ {
+				key := strings.ToUpper(strconv.FormatUint(code, 16))
+				for len(key) < width {
+					key = "0" + key
+				}
+				cmap[key] = string(rune(dst + code - lo))
+			}
+		}
+	}
+}
+
+func decodeUTF16Hex(h string) string {
+	raw, err := hex.DecodeString(h)
+	if err != nil || len(raw)%2 != 0 {
+		return ""
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = uint16(raw[2*i])<<8 | uint16(raw[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// extractPDFContent quét content stream, gom các chuỗi trước toán tử hiển thị chữ
+func extractPDFContent(s []byte, cmap map[string]string, b *strings.Builder) {
+	var pending []string
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '(':
+			str, next := readLiteralString(s, i)
+			pending = append(pending, str)
+			i = next
+		case c == '<' && i+1 < len(s) && s[i+1] == '<':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(s[i:], '>')
+			if end < 0 {
+				return
+			}
+			pending = append(pending, decodeHexString(string(s[i+1:i+end]), cmap))
+			i += end + 1
+		case c == 'T' && i+1 < len(s) && (s[i+1] == 'j' || s[i+1] == 'J'):
+			b.WriteString(strings.Join(pending, ""))
+			pending = pending[:0]
+			i += 2
+		case c == '\'' || c == '"':
+			b.WriteByte('\n')
+			b.WriteString(strings.Join(pending, ""))
+			pending = pending[:0]
+			i++
+		case c == 'T' && i+1 < len(s) && (s[i+1] == 'd' || s[i+1] == 'D' || s[i+1] == '*'):
+			b.WriteByte(' ')
+			i += 2
+		case c == 'E' && i+1 < len(s) && s[i+1] == 'T':
+			b.WriteByte('\n')
+			pending = pending[:0]
+			i += 2
+		default:
+			i++
+		}
+	}
+}
+
+func readLiteralString(s []byte, start int) (string, int) {
+	var out []byte
+	depth := 0
+	i := start
+	for i < len(s) {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					out = append(out, '\n')
+				case 'r', 't', 'b', 'f':
+					out = append(out, ' ')
+				case '0', '1', '2', '3', '4', '5', '6', '7':
+					j := i
+					for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+						j++
+					}
+					v, _ := strconv.ParseUint(string(s[i:j]), 8, 8)
+					out = append(out, byte(v))
+					i = j - 1
+				default:
+					out = append(out, s[i])
+				}
+			}
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return latin1(out), i + 1
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+		i++
+	}
+	return latin1(out), i
+}
+
+func decodeHexString(h string, cmap map[string]string) string {
+	h = strings.ToUpper(strings.Join(strings.Fields(h), ""))
+	if len(cmap) > 0 {
+		var b strings.Builder
+		// Phông CID thường dùng mã 2 byte, phông đơn giản dùng 1 byte
+		for _, width := range []int{4, 2} {
+			b.Reset()
+			ok := len(h)%width == 0
+			for i := 0; ok && i < len(h); i += width {
+				v, found := cmap[h[i:i+width]]
+				if !found {
+					ok = false
+					break
+				}
+				b.WriteString(v)
+			}
+			if ok {
+				return b.String()
+			}
+		}
+	}
+	raw, err := hex.DecodeString(h)
+	if err != nil {
+		return ""
+	}
+	return latin1(raw)
+}
+
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
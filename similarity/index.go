@@ -0,0 +1,320 @@
+package similarity
+
+import (
+	"app/utils"
+	"hash/fnv"
+	"sort"
+)
+
+const (
+	numHashes   = 128
+	numBands    = 32
+	bandRows    = numHashes / numBands
+	sampleMod   = 8
+	maxPassages = 5
+	maxSeeds    = 8
+)
+
+var KindTopic, KindReport = "TOPIC", "REPORT"
+
+// Document là một văn bản trong kho so sánh: mô tả đề tài hoặc nội dung báo cáo
+type Document struct {
+	ID       uint   `json:"id"`
+	ThesisID uint   `json:"thesisID"`
+	Kind     string `json:"kind"`
+	Label    string `json:"label"`
+	Semester string `json:"semester"`
+	Text     string `json:"-"`
+}
+
+type Passage struct {
+	Text       string `json:"text"`
+	SourceText string `json:"sourceText"`
+	Words      int    `json:"words"`
+}
+
+type Match struct {
+	Document
+	Score       float64   `json:"score"`
+	Jaccard     float64   `json:"jaccard"`
+	Containment float64   `json:"containment"`
+	Passages    []Passage `json:"passages"`
+}
+
+type QueryOptions struct {
+	Limit    int
+	MinScore float64
+	// Exclude loại bỏ các văn bản không cần so sánh (ví dụ chính luận văn đang kiểm tra)
+	Exclude func(Document) bool
+	// WithPassages trích các đoạn trùng nhau, tốn thêm thời gian với văn bản dài
+	WithPassages bool
+}
+
+type fingerprint struct {
+	tokens    []utils.Token
+	shingles  []uint64
+	set       map[uint64]struct{}
+	signature [numHashes]uint64
+}
+
+type indexedDoc struct {
+	Document
+	fp fingerprint
+}
+
+// Index là kho văn bản đã băm bằng shingling/MinHash. Ứng viên được lấy qua
+// LSH (văn bản gần giống toàn bộ) và qua các shingle lấy mẫu (chép một phần),
+// sau đó tính điểm chính xác trên tập shingle.
+type Index struct {
+	shingleSize int
+	docs        []indexedDoc
+	bands       map[uint64][]int
+	samples     map[uint64][]int
+}
+
+func NewIndex(shingleSize int) *Index {
+	if shingleSize <= 0 {
+		shingleSize = 5
+	}
+	return &Index{
+		shingleSize: shingleSize,
+		bands:       map[uint64][]int{},
+		samples:     map[uint64][]int{},
+	}
+}
+
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+func (ix *Index) Add(doc Document) {
+	fp := ix.fingerprint(doc.Text)
+	if len(fp.set) == 0 {
+		return
+	}
+	pos := len(ix.docs)
+	ix.docs = append(ix.docs, indexedDoc{Document: doc, fp: fp})
+
+	for _, key := range bandKeys(&fp.signature) {
+		ix.bands[key] = append(ix.bands[key], pos)
+	}
+	for h := range fp.set {
+		if h%sampleMod == 0 {
+			ix.samples[h] = append(ix.samples[h], pos)
+		}
+	}
+}
+
+// Query trả về các văn bản giống nhất với text, sắp xếp theo điểm giảm dần
+func (ix *Index) Query(text string, opts QueryOptions) []Match {
+	fp := ix.fingerprint(text)
+	if len(fp.set) == 0 {
+		return nil
+	}
+
+	candidates := map[int]struct{}{}
+	for _, key := range bandKeys(&fp.signature) {
+		for _, pos := range ix.bands[key] {
+			candidates[pos] = struct{}{}
+		}
+	}
+	for h := range fp.set {
+		if h%sampleMod == 0 {
+			for _, pos := range ix.samples[h] {
+				candidates[pos] = struct{}{}
+			}
+		}
+	}
+
+	var matches []Match
+	for pos := range candidates {
+		doc := &ix.docs[pos]
+		if opts.Exclude != nil && opts.Exclude(doc.Document) {
+			continue
+		}
+
+		inter := 0
+		for h := range fp.set {
+			if _, ok := doc.fp.set[h]; ok {
+				inter++
+			}
+		}
+		if inter == 0 {
+			continue
+		}
+		jaccard := float64(inter) / float64(len(fp.set)+len(doc.fp.set)-inter)
+		containment := float64(inter) / float64(len(fp.set))
+		score := jaccard
+		if containment > score {
+			score = containment
+		}
+		if score < opts.MinScore {
+			continue
+		}
+
+		match := Match{Document: doc.Document, Score: score, Jaccard: jaccard, Containment: containment}
+		if opts.WithPassages {
+			match.Passages = passages(text, &fp, doc.Text, &doc.fp, ix.shingleSize)
+		}
+		matches = append(matches, match)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	return matches
+}
+
+// Similarity tính hệ số Jaccard giữa hai văn bản ngắn (tiêu đề, mô tả đề tài)
+func Similarity(a, b string, shingleSize int) float64 {
+	ix := NewIndex(shingleSize)
+	fa, fb := ix.fingerprint(a), ix.fingerprint(b)
+	if len(fa.set) == 0 || len(fb.set) == 0 {
+		return 0
+	}
+	inter := 0
+	for h := range fa.set {
+		if _, ok := fb.set[h]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(fa.set)+len(fb.set)-inter)
+}
+
+func (ix *Index) fingerprint(text string) fingerprint {
+	fp := fingerprint{tokens: utils.Tokenize(text), set: map[uint64]struct{}{}}
+	n := len(fp.tokens)
+	k := ix.shingleSize
+	if n == 0 {
+		return fp
+	}
+	// Văn bản ngắn hơn một shingle được xem như một shingle duy nhất
+	if n < k {
+		k = n
+	}
+	for i := 0; i+k <= n; i++ {
+		h := fnv.New64a()
+		for j := i; j < i+k; j++ {
+			h.Write([]byte(fp.tokens[j].Norm))
+			h.Write([]byte{' '})
+		}
+		sum := h.Sum64()
+		fp.shingles = append(fp.shingles, sum)
+		fp.set[sum] = struct{}{}
+	}
+
+	for i := range fp.signature {
+		fp.signature[i] = ^uint64(0)
+	}
+	for h := range fp.set {
+		for i := 0; i < numHashes; i++ {
+			if v := mix64(h ^ seeds[i]); v < fp.signature[i] {
+				fp.signature[i] = v
+			}
+		}
+	}
+	return fp
+}
+
+func bandKeys(signature *[numHashes]uint64) []uint64 {
+	keys := make([]uint64, 0, numBands)
+	buf := make([]byte, 8)
+	for b := 0; b < numBands; b++ {
+		h := fnv.New64a()
+		h.Write([]byte{byte(b)})
+		for r := 0; r < bandRows; r++ {
+			v := signature[b*bandRows+r]
+			for i := 0; i < 8; i++ {
+				buf[i] = byte(v >> (8 * i))
+			}
+			h.Write(buf)
+		}
+		keys = append(keys, h.Sum64())
+	}
+	return keys
+}
+
+// passages tìm các đoạn liên tiếp trùng nhau giữa văn bản truy vấn và văn bản nguồn
+func passages(text string, q *fingerprint, source string, d *fingerprint, k int) []Passage {
+	positions := map[uint64][]int{}
+	for j, h := range d.shingles {
+		if len(positions[h]) < maxSeeds {
+			positions[h] = append(positions[h], j)
+		}
+	}
+
+	var result []Passage
+	for i := 0; i < len(q.shingles); i++ {
+		bestLen, bestJ := 0, -1
+		for _, j := range positions[q.shingles[i]] {
+			l := 0
+			for i+l < len(q.shingles) && j+l < len(d.shingles) && q.shingles[i+l] == d.shingles[j+l] {
+				l++
+			}
+			if l > bestLen {
+				bestLen, bestJ = l, j
+			}
+		}
+		if bestLen == 0 {
+			continue
+		}
+
+		width := k
+		if len(q.tokens) < width {
+			width = len(q.tokens)
+		}
+		if len(d.tokens) < width {
+			width = len(d.tokens)
+		}
+		qEnd := i + bestLen - 1 + width - 1
+		dEnd := bestJ + bestLen - 1 + width - 1
+		if qEnd >= len(q.tokens) {
+			qEnd = len(q.tokens) - 1
+		}
+		if dEnd >= len(d.tokens) {
+			dEnd = len(d.tokens) - 1
+		}
+		result = append(result, Passage{
+			Text:       text[q.tokens[i].Start:q.tokens[qEnd].End],
+			SourceText: source[d.tokens[bestJ].Start:d.tokens[dEnd].End],
+			Words:      qEnd - i + 1,
+		})
+		i += bestLen - 1
+	}
+
+	sort.SliceStable(result, func(a, b int) bool {
+		return result[a].Words > result[b].Words
+	})
+	if len(result) > maxPassages {
+		result = result[:maxPassages]
+	}
+	return result
+}
+
+var seeds = buildSeeds()
+
+func buildSeeds() [numHashes]uint64 {
+	var s [numHashes]uint64
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range s {
+		x += 0x9E3779B97F4A7C15
+		s[i] = mix64(x)
+	}
+	return s
+}
+
+// mix64 là hàm trộn của splitmix64
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
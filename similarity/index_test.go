@@ -0,0 +1,102 @@
+package similarity
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		k        int
+		min, max float64
+	}{
+		{"identical", "hệ thống quản lý luận văn", "hệ thống quản lý luận văn", 2, 1, 1},
+		{"accents and case ignored", "Hệ thống quản lý luận văn", "he thong QUAN LY luan van", 2, 1, 1},
+		{"disjoint", "nhận dạng khuôn mặt", "dự báo thời tiết", 2, 0, 0},
+		{"empty side", "", "dự báo thời tiết", 2, 0, 0},
+		{"partial overlap", "hệ thống quản lý luận văn", "hệ thống quản lý thư viện", 2, 0.3, 0.5},
+		{"shorter than shingle", "blockchain", "blockchain", 5, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b, tt.k)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity(%q, %q, %d) = %v, want in [%v, %v]", tt.a, tt.b, tt.k, got, tt.min, tt.max)
+			}
+			if back := Similarity(tt.b, tt.a, tt.k); math.Abs(back-got) > 1e-9 {
+				t.Errorf("Similarity is not symmetric: %v vs %v", got, back)
+			}
+		})
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+	base := strings.Repeat("nghiên cứu ứng dụng học sâu trong chẩn đoán hình ảnh y khoa tại bệnh viện tuyến tỉnh ", 3)
+	ix := NewIndex(3)
+	ix.Add(Document{ID: 1, ThesisID: 10, Text: base})
+	ix.Add(Document{ID: 2, ThesisID: 20, Text: "xây dựng hệ thống thương mại điện tử cho doanh nghiệp vừa và nhỏ sử dụng kiến trúc microservice"})
+	ix.Add(Document{ID: 3, ThesisID: 30, Text: "  ,, "})
+
+	if ix.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 (empty documents are skipped)", ix.Len())
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		opts    QueryOptions
+		wantIDs []uint
+	}{
+		{"exact copy", base, QueryOptions{}, []uint{1}},
+		{"copied passage inside longer text", "mở đầu khác hẳn về chủ đề. " + base + " kết luận cũng khác", QueryOptions{MinScore: 0.5}, []uint{1}},
+		{"excluded", base, QueryOptions{Exclude: func(d Document) bool { return d.ThesisID == 10 }}, nil},
+		{"below min score", "học sâu trong chẩn đoán hình ảnh", QueryOptions{MinScore: 0.99, Limit: 1}, []uint{1}},
+		{"unrelated", "phân tích cảm xúc bình luận mạng xã hội", QueryOptions{MinScore: 0.1}, nil},
+		{"empty query", "", QueryOptions{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := ix.Query(tt.text, tt.opts)
+			var ids []uint
+			for _, m := range matches {
+				ids = append(ids, m.ID)
+				if m.Score < tt.opts.MinScore {
+					t.Errorf("match %d score %v below MinScore %v", m.ID, m.Score, tt.opts.MinScore)
+				}
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("Query ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("Query ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryPassages(t *testing.T) {
+	source := "Chương một trình bày tổng quan. Mạng nơ-ron tích chập được huấn luyện trên tập dữ liệu ảnh X-quang phổi. Chương hai mô tả thực nghiệm."
+	ix := NewIndex(3)
+	ix.Add(Document{ID: 1, Text: source})
+
+	query := "Ở đây chúng tôi viết khác. Mạng nơ-ron tích chập được huấn luyện trên tập dữ liệu ảnh X-quang phổi. Phần sau cũng khác hẳn."
+	matches := ix.Query(query, QueryOptions{WithPassages: true})
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1", len(matches))
+	}
+	if len(matches[0].Passages) == 0 {
+		t.Fatal("expected at least one copied passage")
+	}
+	passage := matches[0].Passages[0]
+	if !strings.Contains(passage.Text, "tích chập được huấn luyện") || !strings.Contains(passage.SourceText, "tích chập được huấn luyện") {
+		t.Errorf("longest passage = %+v, want the copied sentence", passage)
+	}
+	if !strings.Contains(query, passage.Text) || !strings.Contains(source, passage.SourceText) {
+		t.Errorf("passage is not a slice of the original texts: %+v", passage)
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	store, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		body    string
+		wantErr error
+	}{
+		{"nested key", "thesis/12/REPORT/7f3c", "report v1", nil},
+		{"overwrite", "thesis/12/REPORT/7f3c", "report v2", nil},
+		{"leading slash", "/thesis/12/SLIDES/a1", "slides", nil},
+		{"empty key", "", "x", ErrInvalidKey},
+		{"root", "/", "x", ErrInvalidKey},
+		{"parent directory", "thesis/../../etc/passwd", "x", ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.Put(tt.key, strings.NewReader(tt.body), int64(len(tt.body)), "text/plain")
+			if err != tt.wantErr {
+				t.Fatalf("Put err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, err := store.Get(tt.key); err != tt.wantErr {
+					t.Errorf("Get err = %v, want %v", err, tt.wantErr)
+				}
+				if err := store.Delete(tt.key); err != tt.wantErr {
+					t.Errorf("Delete err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			reader, err := store.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get err = %v", err)
+			}
+			data, _ := ioutil.ReadAll(reader)
+			reader.Close()
+			if string(data) != tt.body {
+				t.Errorf("Get = %q, want %q", data, tt.body)
+			}
+
+			if err := store.Delete(tt.key); err != nil {
+				t.Fatalf("Delete err = %v", err)
+			}
+			if _, err := store.Get(tt.key); err != ErrNotFound {
+				t.Errorf("Get after Delete err = %v, want %v", err, ErrNotFound)
+			}
+			if err := store.Delete(tt.key); err != nil {
+				t.Errorf("second Delete err = %v, want nil", err)
+			}
+		})
+	}
+
+	// Không còn file tạm nào sau khi ghi
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasPrefix(info.Name(), ".upload-") {
+			t.Errorf("temporary file %s left behind", path)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestS3ObjectURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		pathStyle bool
+		key       string
+		want      string
+		wantErr   error
+	}{
+		{"path style", true, "thesis/12/REPORT/a1", "http://localhost:9000/theses/thesis/12/REPORT/a1", nil},
+		{"virtual host", false, "thesis/12/REPORT/a1", "http://theses.localhost:9000/thesis/12/REPORT/a1", nil},
+		{"escaped", true, "thesis/12/REPORT/a b.pdf", "http://localhost:9000/theses/thesis/12/REPORT/a%20b.pdf", nil},
+		{"empty key", true, "", "", ErrInvalidKey},
+		{"parent directory", true, "thesis/../other", "", ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewS3(S3Config{Endpoint: "http://localhost:9000", Bucket: "theses", UsePathStyle: tt.pathStyle})
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := s.objectURL(tt.key)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && u.String() != tt.want {
+				t.Errorf("objectURL(%q) = %s, want %s", tt.key, u, tt.want)
+			}
+		})
+	}
+}
+
+// Giá trị mong đợi được tính độc lập theo đặc tả AWS Signature Version 4
+func TestS3Sign(t *testing.T) {
+	s, err := NewS3(S3Config{Endpoint: "http://127.0.0.1:9000", Region: "ap-southeast-1", Bucket: "theses", AccessKey: "access", SecretKey: "secret", UsePathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := s.objectURL("thesis/12/REPORT/a b.pdf")
+	req, _ := http.NewRequest(http.MethodPut, u.String(), nil)
+	s.sign(req, time.Date(2026, 6, 1, 2, 3, 4, 0, time.UTC))
+
+	want := map[string]string{
+		"X-Amz-Date":           "20260601T020304Z",
+		"X-Amz-Content-Sha256": "UNSIGNED-PAYLOAD",
+		"Authorization": "AWS4-HMAC-SHA256 Credential=access/20260601/ap-southeast-1/s3/aws4_request, " +
+			"SignedHeaders=host;x-amz-content-sha256;x-amz-date, " +
+			"Signature=10ed86c6b7a7e636dd5ceefb01e3f823b2e3a961a0dcb1e52f853f85bf4a5989",
+	}
+	for header, value := range want {
+		if got := req.Header.Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+// s3StandIn là máy chủ S3 tối giản trong bộ nhớ, chỉ nhận request đã ký
+type s3StandIn struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func (f *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") || r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(data)
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[r.URL.Path])
+		w.Write([]byte(data))
+	case http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3(t *testing.T) {
+	standIn := &s3StandIn{objects: map[string]string{}, types: map[string]string{}}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		accessKey string
+		key       string
+		body      string
+		wantErr   bool
+	}{
+		{"round trip", "access", "thesis/12/REPORT/a1", "report", false},
+		{"key with spaces", "access", "thesis/12/SLIDES/final slides.pptx", "slides", false},
+		{"rejected credentials", "other", "thesis/12/REPORT/a2", "report", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "theses", AccessKey: tt.accessKey, SecretKey: "secret", UsePathStyle: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.Put(tt.key, strings.NewReader(tt.body), int64(len(tt.body)), "text/plain")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Put err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := standIn.types["/theses/"+tt.key]; got != "text/plain" {
+				t.Errorf("stored Content-Type = %q, want text/plain", got)
+			}
+
+			reader, err := store.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get err = %v", err)
+			}
+			data, _ := ioutil.ReadAll(reader)
+			reader.Close()
+			if string(data) != tt.body {
+				t.Errorf("Get = %q, want %q", data, tt.body)
+			}
+
+			if err := store.Delete(tt.key); err != nil {
+				t.Fatalf("Delete err = %v", err)
+			}
+			if _, err := store.Get(tt.key); err != ErrNotFound {
+				t.Errorf("Get after Delete err = %v, want %v", err, ErrNotFound)
+			}
+			if err := store.Delete(tt.key); err != nil {
+				t.Errorf("second Delete err = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"app/config"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Storage is the backend used to keep uploaded files. Keys are slash separated
+// paths such as "thesis/12/REPORT/<uuid>".
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+var ErrNotFound = errors.New("STORAGE_OBJECT_NOT_FOUND")
+var ErrInvalidKey = errors.New("STORAGE_INVALID_KEY")
+
+var (
+	store     Storage
+	storeErr  error
+	storeOnce sync.Once
+)
+
+// Default returns the backend selected by STORAGE_DRIVER ("local" or "s3").
+// The backend is created on first use and shared afterwards.
+func Default() (Storage, error) {
+	storeOnce.Do(func() {
+		store, storeErr = New(config.Config("STORAGE_DRIVER"))
+	})
+	return store, storeErr
+}
+
+func New(driver string) (Storage, error) {
+	switch driver {
+	case "", "local":
+		dir := config.Config("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./assets/uploads"
+		}
+		return NewLocal(dir)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:     config.Config("S3_ENDPOINT"),
+			Region:       config.Config("S3_REGION"),
+			Bucket:       config.Config("S3_BUCKET"),
+			AccessKey:    config.Config("S3_ACCESS_KEY"),
+			SecretKey:    config.Config("S3_SECRET_KEY"),
+			UsePathStyle: config.Config("S3_USE_PATH_STYLE") != "false",
+		})
+	}
+	return nil, errors.New("STORAGE_DRIVER_UNKNOWN")
+}
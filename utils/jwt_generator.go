@@ -80,3 +80,31 @@ func EncodeDataTokenMobile(employeeId string, dateInSeconds int64, coordinates s
 
 	return t, nil
 }
+
+// GenerateLinkToken tạo token ngắn hạn để nhúng vào đường dẫn (tải file, lịch...).
+// Token được ký bằng khóa riêng nên không dùng được làm bku-token.
+func GenerateLinkToken(scope string, id uint) (string, error) {
+	secret := linkSecret()
+	timeExpire := config.Config("LINK_EXPIRED_TIME")
+
+	minutesCount, err := strconv.Atoi(timeExpire)
+	if err != nil || minutesCount <= 0 {
+		minutesCount = 15
+	}
+
+	claims := jwt.MapClaims{}
+
+	claims["scope"] = scope
+	claims["id"] = id
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(time.Minute * time.Duration(minutesCount)).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	t, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return t, nil
+}
@@ -48,6 +48,18 @@ func jwtKeyFunc(token *jwt.Token) (interface{}, error) {
 	return []byte(config.Config("JWT_SECRET_KEY")), nil
 }
 
+// linkSecret là khóa ký token đường dẫn: LINK_SECRET_KEY, hoặc khóa suy ra từ JWT_SECRET_KEY nếu chưa cấu hình
+func linkSecret() string {
+	if secret := config.Config("LINK_SECRET_KEY"); secret != "" {
+		return secret
+	}
+	return config.Config("JWT_SECRET_KEY") + ":link"
+}
+
+func linkKeyFunc(token *jwt.Token) (interface{}, error) {
+	return []byte(linkSecret()), nil
+}
+
 func ExtractTokenData(c *fiber.Ctx) (*TokenData, error) {
 	token, err := VerifyToken(c)
 	if err != nil {
@@ -57,23 +69,51 @@ func ExtractTokenData(c *fiber.Ctx) (*TokenData, error) {
 	// Setting and checking token and credentials.
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if ok && token.Valid {
+		// Token đường dẫn (có scope) không phải token đăng nhập
+		if _, scoped := claims["scope"]; scoped {
+			return nil, errors.New(config.GetMessageCode("TOKEN_INCORRECT"))
+		}
 		// Sử dụng type assertion để lấy giá trị uint từ claims
 		id, idOk := claims["id"].(float64)
 		if !idOk {
 			return nil, errors.New("Could not extract uint ID from token")
 		}
+		code, codeOk := claims["code"].(string)
+		role, roleOk := claims["role"].(float64)
+		iat, iatOk := claims["iat"].(float64)
+		exp, expOk := claims["exp"].(float64)
+		if !codeOk || !roleOk || !iatOk || !expOk {
+			return nil, errors.New(config.GetMessageCode("TOKEN_INCORRECT"))
+		}
 
 		return &TokenData{
 			ID:        uint(id),
-			Code:      claims["code"].(string),
-			Role:      int(claims["role"].(float64)),
-			Createdat: int64(claims["iat"].(float64)),
-			Expires:   int64(claims["exp"].(float64)),
+			Code:      code,
+			Role:      int(role),
+			Createdat: int64(iat),
+			Expires:   int64(exp),
 		}, nil
 	}
 
-	return nil, err
+	return nil, errors.New(config.GetMessageCode("TOKEN_INCORRECT"))
 }
 
+// ParseLinkToken kiểm tra token sinh bởi GenerateLinkToken và trả về ID đi kèm
+func ParseLinkToken(tokenString, scope string) (uint, error) {
+	token, err := jwt.Parse(tokenString, linkKeyFunc)
+	if err != nil {
+		return 0, errors.New(config.GetMessageCode("LINK_EXPIRED"))
+	}
 
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["scope"] != scope {
+		return 0, errors.New(config.GetMessageCode("TOKEN_INCORRECT"))
+	}
 
+	id, idOk := claims["id"].(float64)
+	if !idOk {
+		return 0, errors.New(config.GetMessageCode("TOKEN_INCORRECT"))
+	}
+
+	return uint(id), nil
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Bảng bỏ dấu tiếng Việt: mỗi nhóm ký tự có dấu được quy về ký tự gốc
+var vietnameseGroups = map[rune]string{
+	'a': "àáảãạăằắẳẵặâầấẩẫậ",
+	'e': "èéẻẽẹêềếểễệ",
+	'i': "ìíỉĩị",
+	'o': "òóỏõọôồốổỗộơờớởỡợ",
+	'u': "ùúủũụưừứửữự",
+	'y': "ỳýỷỹỵ",
+	'd': "đ",
+	'A': "ÀÁẢÃẠĂẰẮẲẴẶÂẦẤẨẪẬ",
+	'E': "ÈÉẺẼẸÊỀẾỂỄỆ",
+	'I': "ÌÍỈĨỊ",
+	'O': "ÒÓỎÕỌÔỒỐỔỖỘƠỜỚỞỠỢ",
+	'U': "ÙÚỦŨỤƯỪỨỬỮỰ",
+	'Y': "ỲÝỶỸỴ",
+	'D': "Đ",
+}
+
+var vietnameseFold = buildVietnameseFold()
+
+func buildVietnameseFold() map[rune]rune {
+	fold := map[rune]rune{}
+	for base, group := range vietnameseGroups {
+		for _, r := range group {
+			fold[r] = base
+		}
+	}
+	return fold
+}
+
+// FoldRune bỏ dấu một ký tự tiếng Việt, các dấu kết hợp (NFD) trả về 0
+func FoldRune(r rune) rune {
+	if unicode.Is(unicode.Mn, r) {
+		return 0
+	}
+	if folded, ok := vietnameseFold[r]; ok {
+		return folded
+	}
+	return r
+}
+
+// FoldVietnamese bỏ dấu tiếng Việt: "Luận văn" -> "Luan van"
+func FoldVietnamese(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if folded := FoldRune(r); folded != 0 {
+			b.WriteRune(folded)
+		}
+	}
+	return b.String()
+}
+
+// NormalizeText chuyển về chữ thường, bỏ dấu và thay ký tự không phải chữ/số bằng khoảng trắng
+func NormalizeText(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	space := true
+	for _, r := range text {
+		r = FoldRune(r)
+		if r == 0 {
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Token là một từ trong văn bản gốc kèm dạng đã chuẩn hóa và vị trí byte
+type Token struct {
+	Word  string
+	Norm  string
+	Start int
+	End   int
+}
+
+// Tokenize tách văn bản thành các từ, giữ lại vị trí để có thể trích đoạn hoặc tô sáng
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			word := text[start:end]
+			if norm := NormalizeText(word); norm != "" {
+				tokens = append(tokens, Token{Word: word, Norm: norm, Start: start, End: end})
+			}
+			start = -1
+		}
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return tokens
+}
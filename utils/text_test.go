@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"lowercase", "Luận Văn", "luan van"},
+		{"d stroke", "Đề tài ĐỒ ÁN", "de tai do an"},
+		{"punctuation collapses", "  Hệ thống -- quản lý, (v2)!  ", "he thong quan ly v2"},
+		{"combining marks", "Lua\u0323n va\u0306n", "luan van"},
+		{"digits kept", "IoT 2024", "iot 2024"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.text); got != tt.want {
+				t.Errorf("NormalizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Token
+	}{
+		{"empty", "", nil},
+		{"only punctuation", " -- ,. ", nil},
+		{
+			"byte offsets",
+			"Học máy, AI",
+			[]Token{
+				{Word: "Học", Norm: "hoc", Start: 0, End: 5},
+				{Word: "máy", Norm: "may", Start: 6, End: 10},
+				{Word: "AI", Norm: "ai", Start: 12, End: 14},
+			},
+		},
+		{
+			"combining mark stays in word",
+			"va\u0306n ba\u0309n",
+			[]Token{
+				{Word: "va\u0306n", Norm: "van", Start: 0, End: 5},
+				{Word: "ba\u0309n", Norm: "ban", Start: 6, End: 11},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for _, token := range got {
+				if tt.text[token.Start:token.End] != token.Word {
+					t.Errorf("token %+v does not match text slice %q", token, tt.text[token.Start:token.End])
+				}
+			}
+		})
+	}
+}